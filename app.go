@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// appIconTimeout bounds fetching a web app's icon, so adding an app
+// doesn't hang on a slow site
+const appIconTimeout = 5 * time.Second
+
+// appIconSize is the width and height of a generated app icon
+const appIconSize = 128
+
+// defaultAppColor fills the icon of an app whose profile has no color
+const defaultAppColor = 0x5f6368
+
+// pngMagic starts every PNG file
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+// App is an [apps.<name>] table: a web app opened in a window of its own,
+// without tabs or an address bar, from one of the profiles. `launchium app
+// launch <name>` and the shortcut made when it was added open it.
+type App struct {
+	Name    string
+	Profile string // Profile whose data, proxy and flags the app runs with
+	URL     string // Page the app window opens
+	Icon    string // Image its shortcut shows; empty uses the one made when it was added
+}
+
+// fields returns the app's config entries in the order they are written
+func (a App) fields() []configField {
+	fields := []configField{{"profile", quoteString(a.Profile)}, {"url", quoteString(a.URL)}}
+	if a.Icon != "" {
+		fields = append(fields, configField{"icon", quoteString(a.Icon)})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching app field
+func (a *App) setField(key, value string) error {
+	switch key {
+	case "profile":
+		return unquoteInto(&a.Profile, value)
+	case "url":
+		if err := unquoteInto(&a.URL, value); err != nil {
+			return err
+		}
+		return validAppURL(a.URL)
+	case "icon":
+		return unquoteInto(&a.Icon, value)
+	default:
+		return unknownKeyError{"app key", key}
+	}
+}
+
+// validAppURL checks the page of an app, which must be a web page
+func validAppURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL, got %q", value)
+	}
+	return nil
+}
+
+// appNames returns the names of the apps, sorted
+func (s Settings) appNames() []string {
+	names := make([]string, 0, len(s.Apps))
+	for name := range s.Apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// app returns the app of that name
+func (s Settings) app(name string) (App, error) {
+	if app, ok := s.Apps[name]; ok {
+		return app, nil
+	}
+	if names := s.appNames(); len(names) > 0 {
+		return App{}, fmt.Errorf("no app '%s' (have %s)", name, strings.Join(names, ", "))
+	}
+	return App{}, fmt.Errorf("no app '%s'; add one with `launchium app add`", name)
+}
+
+// launchApp opens an app in a window of its own. A running browser of its
+// profile gets the window; otherwise the profile is launched with it.
+func (cm *ChromiumManager) launchApp(ctx context.Context, name string) (string, error) {
+	app, err := cm.settings.app(name)
+	if err != nil {
+		return "", err
+	}
+	cm.override.app = app.URL
+	defer func() { cm.override.app = "" }()
+	if _, err := cm.launchBrowser(ctx, app.Profile); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Opened '%s' with profile '%s'", app.Name, app.Profile), nil
+}
+
+// addApp adds an app to the config and, unless asked not to, a shortcut
+// that opens it. It returns where the shortcut was put.
+func (cm *ChromiumManager) addApp(app App, shortcut bool) (string, error) {
+	if strings.TrimSpace(app.Name) == "" {
+		return "", fmt.Errorf("an app needs a name")
+	}
+	if _, ok := cm.settings.Apps[app.Name]; ok {
+		return "", fmt.Errorf("app '%s' already exists", app.Name)
+	}
+	if _, ok := cm.profiles[app.Profile]; !ok {
+		return "", profileNotFound(app.Profile)
+	}
+	if app.URL == "" {
+		return "", fmt.Errorf("an app needs a url")
+	}
+	if err := validAppURL(app.URL); err != nil {
+		return "", err
+	}
+	if app.Icon != "" {
+		app.Icon = expandPath(app.Icon)
+		if _, err := fsys.Stat(app.Icon); err != nil {
+			return "", fmt.Errorf("icon: %w", err)
+		}
+	}
+
+	where := ""
+	if shortcut {
+		var err error
+		if where, err = cm.installAppShortcut(app); err != nil {
+			return "", fmt.Errorf("creating shortcut: %w", err)
+		}
+	}
+	if cm.settings.Apps == nil {
+		cm.settings.Apps = map[string]App{}
+	}
+	cm.settings.Apps[app.Name] = app
+	if err := cm.saveProfiles(); err != nil {
+		delete(cm.settings.Apps, app.Name)
+		if shortcut {
+			cm.removeAppShortcut(app.Name)
+		}
+		return "", err
+	}
+	return where, nil
+}
+
+// removeApp drops an app from the config along with its shortcut and the
+// icon made for it
+func (cm *ChromiumManager) removeApp(name string) error {
+	app, err := cm.settings.app(name)
+	if err != nil {
+		return err
+	}
+	delete(cm.settings.Apps, name)
+	if err := cm.saveProfiles(); err != nil {
+		cm.settings.Apps[name] = app
+		return err
+	}
+	return cm.removeAppShortcut(name)
+}
+
+// renameAppProfile points the apps of a renamed profile at its new name
+func (cm *ChromiumManager) renameAppProfile(oldName, newName string) {
+	for name, app := range cm.settings.Apps {
+		if app.Profile == oldName {
+			app.Profile = newName
+			cm.settings.Apps[name] = app
+		}
+	}
+}
+
+// appIconPath is where the icon made for an app is kept, next to the config
+func (cm *ChromiumManager) appIconPath(name string) string {
+	return filepath.Join(filepath.Dir(cm.configFile), "apps", autostartID(name)+".png")
+}
+
+// appShortcutPath returns the desktop entry, app bundle or Start menu
+// shortcut that opens the app
+func appShortcutPath(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(homeDir, ".local", "share", "applications", "launchium-app-"+autostartID(name)+".desktop"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Applications", autostartID(name)+".app"), nil
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(homeDir, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Microsoft", "Windows", "Start Menu", "Programs", "Launchium", autostartID(name)+".lnk"), nil
+	}
+	return "", fmt.Errorf("app shortcuts are not supported on %s", runtime.GOOS)
+}
+
+// installAppShortcut makes the OS entry that opens the app, with its icon,
+// and returns where it was put
+func (cm *ChromiumManager) installAppShortcut(app App) (string, error) {
+	args, err := cm.launchiumCommand("app", "launch", app.Name)
+	if err != nil {
+		return "", err
+	}
+	path, err := appShortcutPath(app.Name)
+	if err != nil {
+		return "", err
+	}
+	icon := app.Icon
+	if icon == "" {
+		if icon, err = cm.makeAppIcon(app); err != nil {
+			return "", err
+		}
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := writeServiceFile(path, fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Comment=%s with launchium profile %s
+Exec=%s
+Icon=%s
+Terminal=false
+Categories=Network;WebBrowser;
+`, app.Name, app.URL, app.Profile, desktopExec(args), icon)); err != nil {
+			return "", err
+		}
+		exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+		return path, nil
+
+	case "darwin":
+		script := fmt.Sprintf("do shell script \"%s > /dev/null 2>&1 &\"", appleScriptString(shellCommand(args)))
+		fsys.RemoveAll(path)
+		if out, err := exec.Command("osacompile", "-o", path, "-e", script).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("osacompile: %s", strings.TrimSpace(string(out)))
+		}
+		// The applet's own icon is swapped for the app's; one sips can't
+		// convert leaves the script icon
+		exec.Command("sips", "-s", "format", "icns", icon, "--out", filepath.Join(path, "Contents", "Resources", "applet.icns")).Run()
+		exec.Command("touch", path).Run()
+		return path, nil
+
+	default:
+		// Shortcuts take .ico icons, which may hold a PNG
+		if !strings.EqualFold(filepath.Ext(icon), ".ico") {
+			ico := strings.TrimSuffix(icon, filepath.Ext(icon)) + ".ico"
+			if err := writePNGIcon(icon, ico); err == nil {
+				icon = ico
+			}
+		}
+		if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := fmt.Sprintf("$s = (New-Object -ComObject WScript.Shell).CreateShortcut(%s); $s.TargetPath = %s; $s.Arguments = %s; $s.IconLocation = %s; $s.WindowStyle = 7; $s.Save()",
+			quote(path), quote(args[0]), quote(taskCommand(args[1:])), quote(icon))
+		if out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("powershell: %s", strings.TrimSpace(string(out)))
+		}
+		return path, nil
+	}
+}
+
+// removeAppShortcut deletes the shortcut of an app and the icon made for it
+func (cm *ChromiumManager) removeAppShortcut(name string) error {
+	fsys.Remove(cm.appIconPath(name))
+	fsys.Remove(strings.TrimSuffix(cm.appIconPath(name), ".png") + ".ico")
+	path, err := appShortcutPath(name)
+	if err != nil {
+		return err
+	}
+	if err := fsys.RemoveAll(path); err != nil {
+		return err
+	}
+	if runtime.GOOS == "linux" {
+		exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+	}
+	return nil
+}
+
+// makeAppIcon saves an icon for the app: the site's apple-touch-icon when
+// it has one, otherwise a tile in the profile's color. It returns the path
+// of the PNG.
+func (cm *ChromiumManager) makeAppIcon(app App) (string, error) {
+	path := cm.appIconPath(app.Name)
+	data := fetchAppIcon(app.URL)
+	if data == nil {
+		rgb, err := parseHexColor(cm.profiles[app.Profile].Color)
+		if err != nil {
+			rgb = defaultAppColor
+		}
+		data = generateAppIcon(rgb)
+	}
+	if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := fsys.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fetchAppIcon downloads the apple-touch-icon of the app's site, which
+// most sites have as a PNG large enough for a launcher. It returns nil
+// when there isn't one.
+func fetchAppIcon(page string) []byte {
+	u, err := url.Parse(page)
+	if err != nil {
+		return nil
+	}
+	client := &http.Client{Timeout: appIconTimeout}
+	resp, err := client.Get(u.Scheme + "://" + u.Host + "/apple-touch-icon.png")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil || !bytes.HasPrefix(data, pngMagic) {
+		return nil
+	}
+	return data
+}
+
+// generateAppIcon draws a rounded square in the given color
+func generateAppIcon(rgb uint32) []byte {
+	const radius = appIconSize / 5
+	fill := color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xff}
+	img := image.NewRGBA(image.Rect(0, 0, appIconSize, appIconSize))
+	for y := 0; y < appIconSize; y++ {
+		for x := 0; x < appIconSize; x++ {
+			// Distance into the corner square, if the pixel is in one
+			dx := max(radius-x, x-(appIconSize-1-radius), 0)
+			dy := max(radius-y, y-(appIconSize-1-radius), 0)
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, fill)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// writePNGIcon wraps a PNG in an .ico file, which Windows reads as is
+func writePNGIcon(pngPath, icoPath string) error {
+	data, err := fsys.ReadFile(pngPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, pngMagic) || len(data) < 24 {
+		return fmt.Errorf("%s is not a PNG", pngPath)
+	}
+	// The entry's size bytes hold 0 for 256 pixels or more
+	side := func(n uint32) byte {
+		if n >= 256 {
+			return 0
+		}
+		return byte(n)
+	}
+	width, height := binary.BigEndian.Uint32(data[16:20]), binary.BigEndian.Uint32(data[20:24])
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint16{0, 1, 1})
+	buf.Write([]byte{side(width), side(height), 0, 0})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, 32})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(data)), 22})
+	buf.Write(data)
+	return fsys.WriteFile(icoPath, buf.Bytes(), 0644)
+}
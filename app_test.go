@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddApp(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	app := App{Name: "Mail", Profile: "work", URL: "https://mail.example.com"}
+	if _, err := cm.addApp(app, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.ReadFile(cm.configFile)
+	if err != nil || !strings.Contains(string(data), "[apps.Mail]") {
+		t.Errorf("config after adding an app = %q, %v", data, err)
+	}
+	if _, err := cm.addApp(app, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("adding it twice = %v", err)
+	}
+
+	tests := []struct {
+		app  App
+		want string
+	}{
+		{App{Name: " ", Profile: "work", URL: "https://a.example"}, "needs a name"},
+		{App{Name: "Chat", Profile: "school", URL: "https://a.example"}, "not found"},
+		{App{Name: "Chat", Profile: "work"}, "needs a url"},
+		{App{Name: "Chat", Profile: "work", URL: "ftp://a.example"}, "http or https"},
+		{App{Name: "Chat", Profile: "work", URL: "https://a.example", Icon: "/icons/missing.png"}, "icon"},
+	}
+	for _, tt := range tests {
+		if _, err := cm.addApp(tt.app, false); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("addApp(%+v) = %v, want an error about %q", tt.app, err, tt.want)
+		}
+	}
+}
+
+func TestRemoveApp(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	cm.settings.Apps = map[string]App{"Mail": {Name: "Mail", Profile: "work", URL: "https://mail.example.com"}}
+	m.MkdirAll("/config/apps", 0755)
+	m.WriteFile(cm.appIconPath("Mail"), generateAppIcon(defaultAppColor), 0644)
+	if err := cm.removeApp("Mail"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.settings.Apps["Mail"]; ok {
+		t.Error("the app is still in the settings")
+	}
+	if _, err := m.Stat(cm.appIconPath("Mail")); err == nil {
+		t.Error("the app's icon was kept")
+	}
+	if err := cm.removeApp("Mail"); err == nil {
+		t.Error("removing a missing app succeeded")
+	}
+}
+
+func TestRenameAppProfile(t *testing.T) {
+	cm := &ChromiumManager{}
+	cm.settings.Apps = map[string]App{
+		"Mail": {Name: "Mail", Profile: "work"},
+		"News": {Name: "News", Profile: "home"},
+	}
+	cm.renameAppProfile("work", "office")
+	if got := cm.settings.Apps["Mail"].Profile; got != "office" {
+		t.Errorf("Mail's profile = %q, want office", got)
+	}
+	if got := cm.settings.Apps["News"].Profile; got != "home" {
+		t.Errorf("News's profile = %q, want home", got)
+	}
+}
+
+func TestMakeAppIcon(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	cm.profiles["work"] = Profile{Name: "work", Color: "#ff0000"}
+	path, err := cm.makeAppIcon(App{Name: "Mail", Profile: "work", URL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("the icon isn't a PNG: %v", err)
+	}
+	if r, g, b, _ := img.At(appIconSize/2, appIconSize/2).RGBA(); r>>8 != 0xff || g != 0 || b != 0 {
+		t.Errorf("the icon isn't in the profile's color: %x %x %x", r, g, b)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Error("the icon's corners aren't rounded")
+	}
+}
+
+func TestFetchAppIcon(t *testing.T) {
+	icon := generateAppIcon(defaultAppColor)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apple-touch-icon.png":
+			w.Write(icon)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	if got := fetchAppIcon(server.URL + "/inbox?folder=1"); !bytes.Equal(got, icon) {
+		t.Errorf("fetched %d bytes, want the site's icon", len(got))
+	}
+
+	html := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer html.Close()
+	if got := fetchAppIcon(html.URL); got != nil {
+		t.Errorf("a page that isn't a PNG was taken as the icon")
+	}
+}
+
+func TestWritePNGIcon(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/icons", 0755)
+	m.WriteFile("/icons/mail.png", generateAppIcon(defaultAppColor), 0644)
+	if err := writePNGIcon("/icons/mail.png", "/icons/mail.ico"); err != nil {
+		t.Fatal(err)
+	}
+	ico, err := m.ReadFile("/icons/mail.ico")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]uint16, 3)
+	binary.Read(bytes.NewReader(ico), binary.LittleEndian, header)
+	if header[0] != 0 || header[1] != 1 || header[2] != 1 || ico[6] != appIconSize || ico[7] != appIconSize {
+		t.Errorf("unexpected .ico header % x", ico[:8])
+	}
+	if !bytes.HasPrefix(ico[22:], pngMagic) {
+		t.Error("the .ico doesn't hold the PNG")
+	}
+
+	m.WriteFile("/icons/mail.txt", []byte("not an image"), 0644)
+	if err := writePNGIcon("/icons/mail.txt", "/icons/bad.ico"); err == nil {
+		t.Error("a file that isn't a PNG was wrapped")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// archiveExt is added to a data dir's path for its archive, which sits
+// beside it
+const archiveExt = ".tar.zst"
+
+// archivePath returns where the profile's data dir is kept while archived
+func (cm *ChromiumManager) archivePath(profile Profile) string {
+	return cm.profilePath(profile) + archiveExt
+}
+
+// isArchived reports whether the profile's data is packed away
+func (cm *ChromiumManager) isArchived(name string) bool {
+	_, archived := cm.state.Archived[name]
+	return archived
+}
+
+// errArchived is returned for actions that need the data of an archived
+// profile
+func errArchived(name string) error {
+	return fmt.Errorf("profile '%s' is archived; restore it with launchium thaw -profile %s", name, name)
+}
+
+// archiveProfile packs a closed profile's data dir into a zstd compressed
+// tarball beside it and removes the directory. The profile stays in the
+// config, shown as archived, until it is thawed.
+func (cm *ChromiumManager) archiveProfile(ctx context.Context, name string) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if cm.isArchived(name) {
+		return "", fmt.Errorf("profile '%s' is already archived", name)
+	}
+	path := cm.profilePath(profile)
+	if _, err := fsys.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("profile '%s' has no data to archive", name)
+	}
+	if _, running := runningPID(path); running {
+		return "", fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	if err := reclaimDataDir(path); err != nil {
+		return "", err
+	}
+
+	before, err := dirSize(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	archive := cm.archivePath(profile)
+	if err := writeArchive(ctx, path, archive); err != nil {
+		return "", fmt.Errorf("archiving: %w", err)
+	}
+	if err := fsys.RemoveAll(path); err != nil {
+		return "", fmt.Errorf("removing data directory: %w", err)
+	}
+	cm.state.Archived[name] = time.Now()
+	cm.saveState()
+	cm.invalidateSize(name)
+
+	var after int64
+	if info, err := fsys.Stat(archive); err == nil {
+		after = info.Size()
+	}
+	return fmt.Sprintf("Archived '%s' to %s (%s packed into %s)", name, archive, formatBytes(before), formatBytes(after)), nil
+}
+
+// thawProfile unpacks an archived profile's data dir so it can be used
+// again, and removes the archive
+func (cm *ChromiumManager) thawProfile(ctx context.Context, name string) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if !cm.isArchived(name) {
+		return "", fmt.Errorf("profile '%s' is not archived", name)
+	}
+	path := cm.profilePath(profile)
+	if _, err := fsys.Stat(path); err == nil {
+		return "", fmt.Errorf("data directory %s already exists", path)
+	}
+
+	// Unpack next to the final location so a failure leaves nothing that
+	// looks like the profile's data
+	archive := cm.archivePath(profile)
+	staging := path + ".thaw"
+	fsys.RemoveAll(staging)
+	if err := readArchive(ctx, archive, staging); err != nil {
+		fsys.RemoveAll(staging)
+		return "", fmt.Errorf("thawing: %w", err)
+	}
+	if err := fsys.Rename(staging, path); err != nil {
+		fsys.RemoveAll(staging)
+		return "", err
+	}
+	fsys.Remove(archive)
+	delete(cm.state.Archived, name)
+	cm.saveState()
+	cm.invalidateSize(name)
+	return fmt.Sprintf("Thawed '%s' into %s", name, path), nil
+}
+
+// writeArchive packs dir into a zstd compressed tarball at archive, which
+// only appears once it is complete
+func writeArchive(ctx context.Context, dir, archive string) error {
+	tmp := archive + ".part"
+	f, err := fsys.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer fsys.Remove(tmp)
+	defer f.Close()
+
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+	if err := writeTree(ctx, tw, dir, nil); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return fsys.Rename(tmp, archive)
+}
+
+// readArchive unpacks a tarball written by writeArchive into dir
+func readArchive(ctx context.Context, archive, dir string) error {
+	f, err := fsys.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	return readTree(ctx, tar.NewReader(zr), dir)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveAndThaw(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	files := map[string]string{
+		"Local State":         `{"os_crypt":{}}`,
+		"Default/Preferences": `{"homepage":"about:blank"}`,
+		"Default/History":     "visits",
+	}
+	if err := m.MkdirAll(filepath.Join(dataDir, "Default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range files {
+		if err := m.WriteFile(filepath.Join(dataDir, path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles:   map[string]Profile{"work": {Name: "work"}},
+	}
+	cm.loadState()
+
+	if _, err := cm.archiveProfile(context.Background(), "work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(dataDir); err == nil {
+		t.Error("the data dir was left after archiving")
+	}
+	if _, err := m.Stat(dataDir + archiveExt); err != nil {
+		t.Fatalf("no archive was written: %s", err)
+	}
+	if !cm.isArchived("work") {
+		t.Error("the profile isn't marked archived")
+	}
+	if _, err := cm.archiveProfile(context.Background(), "work"); err == nil || !strings.Contains(err.Error(), "already archived") {
+		t.Errorf("archiving twice gave %v", err)
+	}
+
+	if _, err := cm.thawProfile(context.Background(), "work"); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range files {
+		got, err := m.ReadFile(filepath.Join(dataDir, path))
+		if err != nil || string(got) != data {
+			t.Errorf("%s thawed as %q, %v, want %q", path, got, err, data)
+		}
+	}
+	if _, err := m.Stat(dataDir + archiveExt); err == nil {
+		t.Error("the archive was left after thawing")
+	}
+	if cm.isArchived("work") {
+		t.Error("the profile is still marked archived")
+	}
+}
+
+func TestThawKeepsExistingData(t *testing.T) {
+	m, _ := useFakes(t)
+	if err := m.MkdirAll("/profiles/work", 0755); err != nil {
+		t.Fatal(err)
+	}
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles:   map[string]Profile{"work": {Name: "work"}},
+	}
+	cm.loadState()
+	cm.state.Archived["work"] = cm.state.LastLaunch["work"]
+	if _, err := cm.thawProfile(context.Background(), "work"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("thawing over a data dir gave %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// launchDoneMsg reports the outcome of a launch started from the TUI
+type launchDoneMsg struct {
+	name    string
+	message string
+	err     error
+}
+
+// cleanDoneMsg reports the outcome of a clean started from the TUI
+type cleanDoneMsg struct {
+	name string
+	took time.Duration
+	err  error
+}
+
+// newSpinner creates the spinner shown while background work runs
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.MiniDot), spinner.WithStyle(formSelectStyle))
+}
+
+// busy reports whether anything the spinner stands for is still running
+func (cm *ChromiumManager) busy() bool {
+	if len(cm.tasks) > 0 || cm.bulk != nil {
+		return true
+	}
+	for _, scanning := range cm.sizing {
+		if scanning {
+			return true
+		}
+	}
+	return false
+}
+
+// startSpinner starts the spinner ticking unless it already is
+func (cm *ChromiumManager) startSpinner() tea.Cmd {
+	if cm.spinning {
+		return nil
+	}
+	cm.spinning = true
+	return cm.spinner.Tick
+}
+
+// updateSpinner advances the spinner, letting it stop once nothing is busy
+func (cm *ChromiumManager) updateSpinner(msg spinner.TickMsg) tea.Cmd {
+	if !cm.busy() {
+		cm.spinning = false
+		return nil
+	}
+	var cmd tea.Cmd
+	cm.spinner, cmd = cm.spinner.Update(msg)
+	return cmd
+}
+
+// beginTask lists a background operation below the view
+func (cm *ChromiumManager) beginTask(label string) tea.Cmd {
+	cm.tasks = append(cm.tasks, label)
+	return cm.startSpinner()
+}
+
+// endTask removes a finished operation from the list
+func (cm *ChromiumManager) endTask(label string) {
+	for i, t := range cm.tasks {
+		if t == label {
+			cm.tasks = append(cm.tasks[:i], cm.tasks[i+1:]...)
+			return
+		}
+	}
+}
+
+// tasksView renders the spinner with the running operations, and the
+// progress of cleans below
+func (cm *ChromiumManager) tasksView() string {
+	s := cm.spinner.View() + " " + strings.Join(cm.tasks, ", ") + "..."
+	names := []string{}
+	for name := range cm.cleaning {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s += "\n" + name + "  " + cm.cleanProgressView(name)
+	}
+	return s
+}
+
+// launchAsync launches the profile in the background
+func (cm *ChromiumManager) launchAsync(name string) tea.Cmd {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		cm.notify(levelError, "profile '%s' not found", name)
+		return nil
+	}
+	profile = withLaunchMode(profile, cm.launchMode)
+	return tea.Batch(
+		cm.beginTask("Launching '"+name+"'"),
+		func() tea.Msg {
+			message, err := cm.startBrowser(cm.ctx, profile)
+			return launchDoneMsg{name: name, message: message, err: err}
+		},
+	)
+}
+
+// cleanAsync cleans the profile's data directory in the background
+func (cm *ChromiumManager) cleanAsync(name string) tea.Cmd {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		cm.notify(levelError, "profile '%s' not found", name)
+		return nil
+	}
+	path := cm.profilePath(profile)
+	report, wait, done := cm.beginCleanProgress(name)
+	return tea.Batch(
+		cm.beginTask("Cleaning '"+name+"'"),
+		func() tea.Msg {
+			started := time.Now()
+			err := cleanDataDirWith(cm.ctx, path, profile.CleanKeep, report)
+			done()
+			return cleanDoneMsg{name: name, took: time.Since(started), err: err}
+		},
+		wait,
+	)
+}
+
+// finishLaunch records a background launch once it is done, and watches
+// the browser for a crash
+func (cm *ChromiumManager) finishLaunch(msg launchDoneMsg) tea.Cmd {
+	cm.endTask("Launching '" + msg.name + "'")
+	cm.reportLaunch(msg.name, msg.err)
+	if msg.err != nil {
+		cm.notify(levelError, "%s", msg.err)
+		return nil
+	}
+	cm.recordLaunch(msg.name)
+	cm.invalidateSize(msg.name)
+	cm.notify(levelInfo, "%s", msg.message)
+	return cm.watchCrashes(msg.name)
+}
+
+// finishClean reports a background clean once it is done
+func (cm *ChromiumManager) finishClean(msg cleanDoneMsg) {
+	cm.endTask("Cleaning '" + msg.name + "'")
+	delete(cm.cleaning, msg.name)
+	cm.invalidateSize(msg.name)
+	cm.reportClean(msg.name, "", msg.took, msg.err)
+	if msg.err != nil {
+		cm.notify(levelError, "%s", msg.err)
+		return
+	}
+	cm.notify(levelInfo, "Profile '%s' completely cleared and reset", msg.name)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// autostartID turns a profile name into something safe to use in unit,
+// plist and task names
+func autostartID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// autostartPath returns the file that starts the profile at login, or ""
+// on Windows where the entry lives in the Task Scheduler
+func autostartPath(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(homeDir, ".config", "systemd", "user", "launchium-"+autostartID(name)+".service"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "LaunchAgents", "com.launchium."+autostartID(name)+".plist"), nil
+	case "windows":
+		return "", nil
+	}
+	return "", fmt.Errorf("autostart is not supported on %s", runtime.GOOS)
+}
+
+// autostartTask is the Task Scheduler name used for the profile on Windows
+func autostartTask(name string) string {
+	return `Launchium\` + autostartID(name)
+}
+
+// launchiumCommand is the command line an OS entry runs launchium with.
+// The config is passed explicitly so the entry keeps working when launchium
+// is started with -config or LAUNCHIUM_CONFIG, and a remote config is
+// passed as its URL so the entry keeps it up to date.
+func (cm *ChromiumManager) launchiumCommand(args ...string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locating launchium: %w", err)
+	}
+	config := cm.configFile
+	if cm.configSource != "" {
+		config = cm.configSource
+	}
+	return append([]string{exe, "-config", config}, args...), nil
+}
+
+// enableAutostart installs the OS entry that launches the profile at login
+// and returns where it was put
+func (cm *ChromiumManager) enableAutostart(name string) (string, error) {
+	if _, ok := cm.profiles[name]; !ok {
+		return "", profileNotFound(name)
+	}
+	args, err := cm.launchiumCommand("launch", "-profile", name)
+	if err != nil {
+		return "", err
+	}
+	path, err := autostartPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := writeServiceFile(path, systemdUnit(name, args)); err != nil {
+			return "", err
+		}
+		err := systemctl("daemon-reload")
+		if err == nil {
+			err = systemctl("enable", filepath.Base(path))
+		}
+		if err != nil {
+			// Don't leave a unit behind that status would report as enabled
+			os.Remove(path)
+			return "", err
+		}
+		return path, nil
+
+	case "darwin":
+		// launchd picks up agents at the next login; loading it now would
+		// launch the browser straight away
+		label := "com.launchium." + autostartID(name)
+		return path, writeServiceFile(path, launchdPlist(label, args, "<key>RunAtLoad</key>\n\t<true/>"))
+
+	default:
+		out, err := exec.Command("schtasks", "/Create", "/TN", autostartTask(name),
+			"/TR", taskCommand(args), "/SC", "ONLOGON", "/F").CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("schtasks: %s", strings.TrimSpace(string(out)))
+		}
+		return "Task Scheduler: " + autostartTask(name), nil
+	}
+}
+
+// disableAutostart removes the profile's login entry
+func disableAutostart(name string) error {
+	path, err := autostartPath(name)
+	if err != nil {
+		return err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("autostart is not enabled for '%s'", name)
+		}
+		if err := systemctl("disable", filepath.Base(path)); err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return systemctl("daemon-reload")
+
+	case "darwin":
+		if err := os.Remove(path); os.IsNotExist(err) {
+			return fmt.Errorf("autostart is not enabled for '%s'", name)
+		} else if err != nil {
+			return err
+		}
+		return nil
+
+	default:
+		out, err := exec.Command("schtasks", "/Delete", "/TN", autostartTask(name), "/F").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("schtasks: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// autostartEnabled reports whether the profile is launched at login
+func autostartEnabled(name string) (bool, error) {
+	path, err := autostartPath(name)
+	if err != nil {
+		return false, err
+	}
+	if runtime.GOOS == "windows" {
+		return exec.Command("schtasks", "/Query", "/TN", autostartTask(name)).Run() == nil, nil
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+// writeServiceFile writes a unit or launch agent, creating its directory
+func writeServiceFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
+
+// systemctl runs a systemctl command against the user's service manager
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdUnit renders the user unit that launches the profile once the
+// graphical session is up. The browser outlives the launch command, so the
+// unit stays active and stopping it leaves the browser alone.
+func systemdUnit(name string, args []string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Launchium profile %s
+After=graphical-session.target
+PartOf=graphical-session.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+KillMode=process
+ExecStart=%s
+
+[Install]
+WantedBy=graphical-session.target
+`, strings.ReplaceAll(name, "%", "%%"), systemdExec(args))
+}
+
+// systemdExec quotes a command line for ExecStart
+func systemdExec(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, `\`, `\\`)
+		arg = strings.ReplaceAll(arg, `"`, `\"`)
+		arg = strings.ReplaceAll(arg, "%", "%%")
+		quoted[i] = `"` + arg + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// taskCommand quotes a command line for schtasks /TR
+func taskCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = `"` + arg + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// launchdPlist renders a launch agent running args. extra holds the keys
+// that say when it runs.
+func launchdPlist(label string, args []string, extra string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>`)
+	xml.EscapeText(&b, []byte(label))
+	b.WriteString("</string>\n\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range args {
+		b.WriteString("\t\t<string>")
+		xml.EscapeText(&b, []byte(arg))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString("\t</array>\n\t" + extra + "\n</dict>\n</plist>\n")
+	return b.String()
+}
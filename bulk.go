@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bulkOp is an action being applied to several marked profiles, one
+// profile at a time so the progress view can redraw between steps
+type bulkOp struct {
+	action   string // "launch", "clean", "gc" or "delete"
+	names    []string
+	done     int
+	failures []string
+	bar      progress.Model
+	place    func(Profile, int) Profile // Where a launch puts each window
+}
+
+// bulkResultMsg reports that the bulk action is done for one profile
+type bulkResultMsg struct {
+	name   string
+	took   time.Duration // How long a clean took
+	reused bool          // A singleton profile was already running
+	err    error
+}
+
+// Views whose profile list supports marking with space
+func bulkSelectable(view string) bool {
+	return view == "select_profile" || view == "select_clean" || view == "select_delete"
+}
+
+// Toggle the mark on the highlighted profile and move to the next one. The
+// highlighted index is into the filtered items when a filter is set, so the
+// profile is looked up by name among all of them.
+func (cm *ChromiumManager) toggleMark() tea.Cmd {
+	i, ok := cm.profileList.SelectedItem().(item)
+	if !ok {
+		return nil
+	}
+	var cmd tea.Cmd
+	for index, li := range cm.profileList.Items() {
+		if li.(item).title == i.title {
+			i.marked = !i.marked
+			cmd = cm.profileList.SetItem(index, i)
+			break
+		}
+	}
+	cm.profileList.CursorDown()
+	return cmd
+}
+
+// Names of the profiles marked in the profile list
+func (cm *ChromiumManager) markedProfiles() []string {
+	names := []string{}
+	for _, li := range cm.profileList.Items() {
+		if i, ok := li.(item); ok && i.marked {
+			names = append(names, i.title)
+		}
+	}
+	return names
+}
+
+// Begin applying action to the named profiles
+func (cm *ChromiumManager) startBulk(action string, names []string) tea.Cmd {
+	cm.bulk = &bulkOp{
+		action: action,
+		names:  names,
+		bar:    newProgressBar(),
+	}
+	cm.bulkNames = nil
+	cm.clearMessage()
+	if action == "launch" {
+		var err error
+		if cm.bulk.place, err = cm.windowPlacer(len(names)); err != nil {
+			cm.notify(levelWarn, "%s", err)
+		}
+	}
+	cm.currentView = "bulk_progress"
+	return tea.Batch(cm.startSpinner(), cm.runBulkStep())
+}
+
+// Record the result for one profile and move on to the next
+func (cm *ChromiumManager) stepBulk(msg bulkResultMsg) tea.Cmd {
+	op := cm.bulk
+	if op == nil {
+		return nil
+	}
+	if op.action == "launch" && !msg.reused {
+		cm.reportLaunch(msg.name, msg.err)
+	}
+	var watch tea.Cmd
+	if msg.err != nil {
+		op.failures = append(op.failures, fmt.Sprintf("%s: %s", msg.name, msg.err))
+	} else if op.action == "launch" && !msg.reused {
+		cm.recordLaunch(msg.name)
+		watch = cm.watchCrashes(msg.name)
+	}
+	if op.action == "clean" {
+		delete(cm.cleaning, msg.name)
+		cm.reportClean(msg.name, "", msg.took, msg.err)
+	}
+	if op.action == "gc" {
+		cm.reportClean(msg.name, "scheduled "+cm.profiles[msg.name].CleanSchedule, msg.took, msg.err)
+		if msg.err == nil {
+			cm.state.LastClean[msg.name] = time.Now()
+			cm.saveState()
+		}
+	}
+	cm.invalidateSize(msg.name)
+	op.done++
+	return tea.Batch(watch, cm.runBulkStep())
+}
+
+// Apply the bulk action to the next profile, or finish up when all are done
+func (cm *ChromiumManager) runBulkStep() tea.Cmd {
+	op := cm.bulk
+	if op == nil {
+		return nil
+	}
+
+	if op.done < len(op.names) {
+		name := op.names[op.done]
+		profile, ok := cm.profiles[name]
+		if !ok {
+			err := profileNotFound(name)
+			return func() tea.Msg { return bulkResultMsg{name: name, err: err} }
+		}
+		return cm.applyBulkAction(op.action, profile)
+	}
+
+	// Finished
+	if op.action == "delete" {
+		if err := cm.saveProfiles(); err != nil {
+			cm.notify(levelError, "saving config: %s", err)
+			cm.loadProfiles()
+			cm.bulk = nil
+			cm.currentView = "main"
+			return nil
+		}
+	}
+	succeeded := len(op.names) - len(op.failures)
+	verb := map[string]string{"launch": "Launched", "clean": "Cleaned", "gc": "Cleaned", "delete": "Deleted"}[op.action]
+	switch {
+	case succeeded == 0:
+		cm.notify(levelError, "%s 0 of %d profiles; failed: %s",
+			verb, len(op.names), strings.Join(op.failures, "; "))
+	case len(op.failures) > 0:
+		cm.notify(levelWarn, "%s %d of %d profiles; failed: %s",
+			verb, succeeded, len(op.names), strings.Join(op.failures, "; "))
+	default:
+		cm.notify(levelInfo, "%s %d profiles", verb, succeeded)
+	}
+	cm.bulk = nil
+	cm.currentView = "main"
+	return nil
+}
+
+// Apply a single bulk action to one profile. Launches and cleans run in
+// the background; deletes change the profiles and so happen right here.
+func (cm *ChromiumManager) applyBulkAction(action string, profile Profile) tea.Cmd {
+	name := profile.Name
+	switch action {
+	case "launch":
+		profile = withLaunchMode(cm.bulk.place(profile, cm.bulk.done), cm.launchMode)
+		return func() tea.Msg {
+			if _, reused, err := cm.reuseRunning(profile, nil); reused {
+				return bulkResultMsg{name: name, reused: true, err: err}
+			}
+			_, err := cm.startBrowser(cm.ctx, profile)
+			return bulkResultMsg{name: name, err: err}
+		}
+	case "clean":
+		path := cm.profilePath(profile)
+		report, wait, done := cm.beginCleanProgress(name)
+		return tea.Batch(func() tea.Msg {
+			started := time.Now()
+			err := cleanDataDirWith(cm.ctx, path, profile.CleanKeep, report)
+			done()
+			return bulkResultMsg{name: name, took: time.Since(started), err: err}
+		}, wait)
+	case "gc":
+		return cm.gcProfile(profile)
+	}
+	err := cm.removeProfile(name, false)
+	return func() tea.Msg { return bulkResultMsg{name: name, err: err} }
+}
+
+// Render the bulk progress view
+func (cm *ChromiumManager) bulkView() string {
+	op := cm.bulk
+	if op == nil {
+		return ""
+	}
+
+	title := map[string]string{"launch": "Launching", "clean": "Cleaning", "gc": "Cleaning", "delete": "Deleting"}[op.action]
+	s := fmt.Sprintf("%s %d profiles\n\n", title, len(op.names))
+	s += op.bar.ViewAs(float64(op.done)/float64(len(op.names))) + "\n\n"
+	if op.done < len(op.names) {
+		s += fmt.Sprintf("%s %d/%d: %s", cm.spinner.View(), op.done+1, len(op.names), op.names[op.done])
+		if progress := cm.cleanProgressView(op.names[op.done]); progress != "" {
+			s += "\n" + progress
+		}
+	} else {
+		s += fmt.Sprintf("%d/%d done", op.done, len(op.names))
+	}
+	if len(op.failures) > 0 {
+		s += "\n\n" + errStyle.Render(strings.Join(op.failures, "\n"))
+	}
+	return s
+}
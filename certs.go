@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// trustedCA is a certificate authority a profile's browser trusts
+type trustedCA struct {
+	Path    string // File it was read from
+	Subject string
+	SPKI    string // Base64 SHA-256 of its public key, the form Chromium takes
+}
+
+// parsePathList splits a comma separated list of paths, which unlike tags
+// may have spaces in them
+func parsePathList(s string) []string {
+	paths := []string{}
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// readCACerts reads the certificates in a PEM bundle or a DER file
+func readCACerts(path string) ([]*x509.Certificate, error) {
+	data, err := fsys.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading CA %s: %w", path, err)
+	}
+	if !strings.Contains(string(data), "-----BEGIN") {
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA %s: %w", path, err)
+		}
+		return []*x509.Certificate{cert}, nil
+	}
+
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("reading CA %s: no certificates in it", path)
+	}
+	return certs, nil
+}
+
+// trustedCAs reads the profile's CA files as they are now, so edits to
+// them and to the profile's list take effect on its next launch
+func (p Profile) trustedCAs() ([]trustedCA, error) {
+	cas := []trustedCA{}
+	seen := map[string]bool{}
+	for _, path := range p.CACerts {
+		certs, err := readCACerts(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, cert := range certs {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			spki := base64.StdEncoding.EncodeToString(sum[:])
+			if seen[spki] {
+				continue
+			}
+			seen[spki] = true
+			cas = append(cas, trustedCA{Path: path, Subject: cert.Subject.String(), SPKI: spki})
+		}
+	}
+	return cas, nil
+}
+
+// caFlags returns the switch that makes the browser trust the profile's
+// CAs. Chromium only honors it together with --user-data-dir, which
+// launchium always passes, so the trust stays with the profile.
+func (p Profile) caFlags() ([]string, error) {
+	cas, err := p.trustedCAs()
+	if err != nil || len(cas) == 0 {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(cas))
+	for _, ca := range cas {
+		hashes = append(hashes, ca.SPKI)
+	}
+	sort.Strings(hashes)
+	return []string{"--ignore-certificate-errors-spki-list=" + strings.Join(hashes, ",")}, nil
+}
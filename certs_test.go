@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCA returns a self-signed CA certificate in DER and its SPKI hash
+func newTestCA(t *testing.T, name string) ([]byte, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return der, base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestCAFlags(t *testing.T) {
+	m, _ := useFakes(t)
+	staging, stagingSPKI := newTestCA(t, "Staging CA")
+	corp, corpSPKI := newTestCA(t, "Corp Root")
+	m.MkdirAll("/certs", 0755)
+	bundle := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: staging}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: corp})...)
+	m.WriteFile("/certs/bundle.pem", bundle, 0644)
+	m.WriteFile("/certs/corp.der", corp, 0644)
+
+	profile := Profile{Name: "staging", CACerts: []string{"/certs/bundle.pem", "/certs/corp.der"}}
+	cas, err := profile.trustedCAs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cas) != 2 || cas[0].Subject != "CN=Staging CA" || cas[1].Path != "/certs/bundle.pem" {
+		t.Errorf("trustedCAs() = %+v, want each CA once", cas)
+	}
+	flags, err := profile.caFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := []string{stagingSPKI, corpSPKI}
+	if hashes[0] > hashes[1] {
+		hashes[0], hashes[1] = hashes[1], hashes[0]
+	}
+	want := "--ignore-certificate-errors-spki-list=" + strings.Join(hashes, ",")
+	if len(flags) != 1 || flags[0] != want {
+		t.Errorf("caFlags() = %q, want %s", flags, want)
+	}
+}
+
+func TestCAFlagsErrors(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/certs", 0755)
+	m.WriteFile("/certs/key.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}), 0644)
+	m.WriteFile("/certs/junk.der", []byte("junk"), 0644)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/certs/missing.pem", "reading CA /certs/missing.pem"},
+		{"/certs/key.pem", "no certificates in it"},
+		{"/certs/junk.der", "reading CA /certs/junk.der"},
+	}
+	for _, tt := range tests {
+		_, err := Profile{CACerts: []string{tt.path}}.caFlags()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("caFlags() with %s gave %v, want %q", tt.path, err, tt.want)
+		}
+	}
+	if flags, err := (Profile{}).caFlags(); err != nil || flags != nil {
+		t.Errorf("caFlags() without CAs = %q, %v", flags, err)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Release channels a profile can pin with Profile.Channel
+const (
+	channelAny     = "" // whichever browser is detected or configured
+	channelStable  = "stable"
+	channelBeta    = "beta"
+	channelDev     = "dev"
+	channelCanary  = "canary"
+	channelFetched = "fetched" // downloaded with fetch-browser; not pinnable
+)
+
+// browserChannels lists the pinnable channels, most stable first
+var browserChannels = []string{channelStable, channelBeta, channelDev, channelCanary}
+
+// How long `launchium browsers` waits for a browser to print its version
+const browserVersionTimeout = 5 * time.Second
+
+// installedBrowser is a browser binary found on this machine
+type installedBrowser struct {
+	Product string // Chromium, Google Chrome, ...
+	Channel string
+	Path    string
+}
+
+// knownBrowsers returns where each product and channel installs on this
+// platform, in order of preference
+func knownBrowsers() []installedBrowser {
+	switch runtime.GOOS {
+	case "darwin":
+		app := func(name string) string {
+			return filepath.Join("/Applications", name+".app", "Contents", "MacOS", name)
+		}
+		return []installedBrowser{
+			{"Chromium", channelStable, app("Chromium")},
+			{"Google Chrome", channelStable, app("Google Chrome")},
+			{"Google Chrome", channelBeta, app("Google Chrome Beta")},
+			{"Google Chrome", channelDev, app("Google Chrome Dev")},
+			{"Google Chrome", channelCanary, app("Google Chrome Canary")},
+		}
+
+	case "windows":
+		programFiles := os.Getenv("ProgramFiles")
+		programFilesX86 := os.Getenv("ProgramFiles(x86)")
+		localAppData := os.Getenv("LocalAppData")
+		chrome := func(root, dir string) string {
+			return filepath.Join(root, "Google", dir, "Application", "chrome.exe")
+		}
+		return []installedBrowser{
+			{"Chromium", channelStable, filepath.Join(programFiles, "Chromium", "Application", "chrome.exe")},
+			{"Chromium", channelStable, filepath.Join(programFilesX86, "Chromium", "Application", "chrome.exe")},
+			{"Google Chrome", channelStable, chrome(programFiles, "Chrome")},
+			{"Google Chrome", channelStable, chrome(programFilesX86, "Chrome")},
+			{"Chromium", channelStable, filepath.Join(localAppData, "Chromium", "Application", "chrome.exe")},
+			{"Google Chrome", channelStable, chrome(localAppData, "Chrome")},
+			{"Google Chrome", channelBeta, chrome(programFiles, "Chrome Beta")},
+			{"Google Chrome", channelBeta, chrome(localAppData, "Chrome Beta")},
+			{"Google Chrome", channelDev, chrome(programFiles, "Chrome Dev")},
+			{"Google Chrome", channelDev, chrome(localAppData, "Chrome Dev")},
+			// Canary installs per user, as "side by side"
+			{"Google Chrome", channelCanary, chrome(localAppData, "Chrome SxS")},
+		}
+
+	case "linux":
+		return []installedBrowser{
+			{"Chromium", channelStable, "/usr/bin/chromium"},
+			{"Chromium", channelStable, "/usr/bin/chromium-browser"},
+			{"Google Chrome", channelStable, "/usr/bin/google-chrome"},
+			{"Google Chrome", channelStable, "/usr/bin/google-chrome-stable"},
+			{"Chromium", channelStable, "/snap/bin/chromium"},
+			{"Google Chrome", channelBeta, "/usr/bin/google-chrome-beta"},
+			{"Google Chrome", channelDev, "/usr/bin/google-chrome-unstable"},
+			{"Google Chrome", channelCanary, "/usr/bin/google-chrome-canary"},
+		}
+	}
+	return nil
+}
+
+// installedBrowsers returns the known browsers present on this machine,
+// followed by those fetch-browser downloaded
+func installedBrowsers() []installedBrowser {
+	return browserSource.installed()
+}
+
+// detectBrowsers looks for the known browsers on disk and the fetched ones
+func detectBrowsers() []installedBrowser {
+	found := []installedBrowser{}
+	seen := map[string]bool{}
+	for _, b := range knownBrowsers() {
+		if _, err := os.Stat(b.Path); err != nil {
+			continue
+		}
+		// google-chrome is usually a link to google-chrome-stable
+		real, err := filepath.EvalSymlinks(b.Path)
+		if err != nil {
+			real = b.Path
+		}
+		if seen[real] {
+			continue
+		}
+		seen[real] = true
+		found = append(found, b)
+	}
+	for _, path := range fetchedBrowsers() {
+		found = append(found, installedBrowser{"Chromium (" + fetchedBuildName(path) + ")", channelFetched, path})
+	}
+	return found
+}
+
+// fetchedBuildName returns the browsers dir entry a fetched browser is in
+func fetchedBuildName(path string) string {
+	dir, err := browsersDir()
+	if err != nil {
+		return filepath.Base(path)
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")[0]
+}
+
+// validChannel reports whether channel is a value Profile.Channel accepts
+func validChannel(channel string) bool {
+	return channel == channelAny || containsString(browserChannels, channel)
+}
+
+// channelBrowser returns the installed browser for a channel, preferring
+// Google Chrome since only Chrome ships the pre-release channels
+func channelBrowser(channel string) (string, error) {
+	var fallback string
+	for _, b := range installedBrowsers() {
+		if b.Channel != channel {
+			continue
+		}
+		if b.Product == "Google Chrome" {
+			return b.Path, nil
+		}
+		if fallback == "" {
+			fallback = b.Path
+		}
+	}
+	if fallback != "" {
+		return fallback, nil
+	}
+	return "", errorOf(ErrBrowserNotFound, "no %s channel browser is installed (see 'launchium browsers')", channel)
+}
+
+// browserVersion asks a browser binary for its version, returning "" if it
+// can't tell
+func browserVersion(path string) string {
+	if runtime.GOOS == "windows" {
+		// chrome.exe doesn't print its version; it sits next to a
+		// directory named after it
+		entries, err := ioutil.ReadDir(filepath.Dir(path))
+		if err != nil {
+			return ""
+		}
+		latest := ""
+		for _, entry := range entries {
+			if entry.IsDir() && strings.Count(entry.Name(), ".") == 3 {
+				if _, ok := versionNumbers(entry.Name()); ok && (latest == "" || newerVersion(entry.Name(), latest)) {
+					latest = entry.Name()
+				}
+			}
+		}
+		return latest
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), browserVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	// "Google Chrome 131.0.6778.85" or "Chromium 131.0.6778.85 snap"
+	for _, word := range strings.Fields(string(out)) {
+		if _, ok := versionNumbers(word); ok && strings.Contains(word, ".") {
+			return word
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// validCleanKeep checks a profile's clean_keep patterns, which are matched
+// against paths in the data dir like sync_exclude: a pattern matches a
+// path component, or consecutive components when it has a slash
+func validCleanKeep(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" || strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "\\") {
+			return fmt.Errorf("clean_keep: %q should be a path in the data dir such as \"Bookmarks\" or \"Default/Extensions\"", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("clean_keep: %q: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+// keptPath reports whether a path in a data dir matches one of the keep
+// patterns of a clean
+func keptPath(profilePath, file string, keep []string) bool {
+	if len(keep) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(profilePath, file)
+	return err == nil && excludedPath(filepath.ToSlash(rel), keep)
+}
+
+// keptNote adds what a clean left in place to the note printed after it
+func keptNote(note string, keep []string) string {
+	if len(keep) == 0 {
+		return note
+	}
+	kept := "kept " + strings.Join(keep, ", ")
+	if note == "" {
+		return kept
+	}
+	return note + "; " + kept
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestKeptPath(t *testing.T) {
+	keep := []string{"Default/Bookmarks", "Login Data*"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/profiles/work/Default/Bookmarks", true},
+		{"/profiles/work/Default/Login Data", true},
+		{"/profiles/work/Default/Login Data-journal", true},
+		{"/profiles/work/Default/History", false},
+		{"/profiles/work/Bookmarks", false},
+	}
+	for _, tt := range tests {
+		if got := keptPath("/profiles/work", tt.path, keep); got != tt.want {
+			t.Errorf("keptPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if keptPath("/profiles/work", "/profiles/work/Default/Bookmarks", nil) {
+		t.Error("a clean without clean_keep kept a path")
+	}
+}
+
+func TestValidCleanKeep(t *testing.T) {
+	for _, pattern := range []string{"", "/etc/passwd", `Default\Bookmarks`, "Default/[Book"} {
+		if validCleanKeep([]string{pattern}) == nil {
+			t.Errorf("clean_keep %q was accepted", pattern)
+		}
+	}
+	if err := validCleanKeep([]string{"Default/Bookmarks", "Default/Extensions"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestKeptNote(t *testing.T) {
+	if got := keptNote("", []string{"Bookmarks"}); got != "kept Bookmarks" {
+		t.Errorf("keptNote = %q", got)
+	}
+	if got := keptNote("deleted without overwriting", []string{"A", "B"}); got != "deleted without overwriting; kept A, B" {
+		t.Errorf("keptNote = %q", got)
+	}
+	if got := keptNote("note", nil); got != "note" {
+		t.Errorf("keptNote = %q", got)
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cleanWorkers is how many files a clean deletes at once. Deleting is
+// mostly waiting on the filesystem, so a few more than one pays off even
+// on a single disk.
+const cleanWorkers = 8
+
+// How often a clean reports its progress
+const cleanProgressEvery = 100 * time.Millisecond
+
+// cleanProgress is how far a clean has got
+type cleanProgress struct {
+	counting   bool // Still finding the files to delete
+	files      int64
+	totalFiles int64
+	bytes      int64
+	totalBytes int64
+	elapsed    time.Duration
+}
+
+// fraction returns how much of the clean is done, by bytes or, for
+// profiles of empty files, by files
+func (p cleanProgress) fraction() float64 {
+	switch {
+	case p.counting:
+		return 0
+	case p.totalBytes > 0:
+		return float64(p.bytes) / float64(p.totalBytes)
+	case p.totalFiles > 0:
+		return float64(p.files) / float64(p.totalFiles)
+	}
+	return 1
+}
+
+// eta estimates how long the rest of the clean takes at the speed so far
+func (p cleanProgress) eta() (time.Duration, bool) {
+	done := p.fraction()
+	if done <= 0 || done >= 1 || p.elapsed < time.Second {
+		return 0, false
+	}
+	return time.Duration(float64(p.elapsed) * (1 - done) / done), true
+}
+
+// String describes the progress in one line
+func (p cleanProgress) String() string {
+	if p.counting {
+		return fmt.Sprintf("counting files, %d so far", p.totalFiles)
+	}
+	s := fmt.Sprintf("%d/%d files, %s of %s", p.files, p.totalFiles, formatBytes(p.bytes), formatBytes(p.totalBytes))
+	if eta, ok := p.eta(); ok {
+		s += fmt.Sprintf(", about %s left", formatUptime(eta.Round(time.Second)))
+	}
+	return s
+}
+
+// textBar draws the progress as a bar of width characters for terminals
+func (p cleanProgress) textBar(width int) string {
+	filled := int(p.fraction() * float64(width))
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// cleanDataDirWith empties a profile's data directory like cleanDataDir,
+// deleting the files with a pool of workers and telling report how far it
+// got every so often and once more when it is done. report may be nil.
+// Paths matching a keep pattern, as in the clean_keep setting, are left.
+// A data dir in use by a running browser is refused.
+func cleanDataDirWith(ctx context.Context, profilePath string, keep []string, report func(cleanProgress)) error {
+	if _, err := fsys.Stat(profilePath); os.IsNotExist(err) {
+		return fmt.Errorf("Profile directory does not exist")
+	}
+	if _, running := runningPID(profilePath); running {
+		return fmt.Errorf("profile is running, close the browser first")
+	}
+	if err := reclaimDataDir(profilePath); err != nil {
+		return err
+	}
+	if report == nil {
+		report = func(cleanProgress) {}
+	}
+	started := time.Now()
+
+	// Find everything to delete first, so the progress has totals
+	type entry struct {
+		path string
+		size int64
+	}
+	var counted, totalBytes, removed, removedBytes atomic.Int64
+	var counting atomic.Bool
+	counting.Store(true)
+	snapshot := func() cleanProgress {
+		return cleanProgress{
+			counting:   counting.Load(),
+			files:      removed.Load(),
+			totalFiles: counted.Load(),
+			bytes:      removedBytes.Load(),
+			totalBytes: totalBytes.Load(),
+			elapsed:    time.Since(started),
+		}
+	}
+
+	stop := make(chan struct{})
+	reported := make(chan struct{})
+	go func() {
+		defer close(reported)
+		tick := time.NewTicker(cleanProgressEvery)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				report(snapshot())
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-reported
+		report(snapshot())
+	}()
+
+	entries := []entry{}
+	dirs := []string{} // Emptied once their files are gone, unless something was kept
+	err := walkDir(profilePath, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			// Files vanish while a browser is running; skip them
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if path != profilePath && keptPath(profilePath, path, keep) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != profilePath {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		var size int64
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size = info.Size()
+		}
+		entries = append(entries, entry{path, size})
+		counted.Add(1)
+		totalBytes.Add(size)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+	counting.Store(false)
+
+	// Delete the files, then the directories left empty
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var firstErr error
+	var errOnce sync.Once
+	work := make(chan entry)
+	var wg sync.WaitGroup
+	for i := 0; i < cleanWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range work {
+				if err := fsys.Remove(e.path); err != nil && !os.IsNotExist(err) {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				removed.Add(1)
+				removedBytes.Add(e.size)
+			}
+		}()
+	}
+feed:
+	for _, e := range entries {
+		select {
+		case work <- e:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	if firstErr != nil {
+		return fmt.Errorf("cleaning profile: %w", firstErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(keep) > 0 {
+		// Deepest first, so parents are empty by their turn. Those
+		// holding kept files stay.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			fsys.Remove(dirs[i])
+		}
+		return nil
+	}
+	top, err := fsys.ReadDir(profilePath)
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+	for _, d := range top {
+		if err := fsys.RemoveAll(filepath.Join(profilePath, d.Name())); err != nil {
+			return fmt.Errorf("cleaning profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// cleanProgressMsg carries the progress of a clean running in the TUI
+type cleanProgressMsg struct {
+	name     string
+	progress cleanProgress
+	updates  <-chan cleanProgress
+}
+
+// beginCleanProgress starts showing progress for a clean of the profile
+// and returns what the clean reports to and the command that picks the
+// reports up. done must be called once the clean is over.
+func (cm *ChromiumManager) beginCleanProgress(name string) (report func(cleanProgress), wait tea.Cmd, done func()) {
+	cm.cleaning[name] = cleanProgress{counting: true}
+	report, updates, done := cleanReporter()
+	return report, waitCleanProgress(name, updates), done
+}
+
+// cleanReporter returns a report function for cleanDataDirWith that keeps
+// the latest progress in a channel for the TUI to pick up, never blocking
+// the clean on a busy UI. The channel is closed by calling done.
+func cleanReporter() (report func(cleanProgress), updates <-chan cleanProgress, done func()) {
+	ch := make(chan cleanProgress, 1)
+	report = func(p cleanProgress) {
+		select {
+		case <-ch: // Drop the update the TUI hasn't read yet
+		default:
+		}
+		ch <- p
+	}
+	return report, ch, func() { close(ch) }
+}
+
+// waitCleanProgress delivers the next progress update of a clean
+func waitCleanProgress(name string, updates <-chan cleanProgress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return cleanProgressMsg{name: name, progress: p, updates: updates}
+	}
+}
+
+// updateCleanProgress records a clean's progress and waits for the next.
+// Updates arriving after the clean finished are dropped.
+func (cm *ChromiumManager) updateCleanProgress(msg cleanProgressMsg) tea.Cmd {
+	if _, running := cm.cleaning[msg.name]; !running {
+		return nil
+	}
+	cm.cleaning[msg.name] = msg.progress
+	return waitCleanProgress(msg.name, msg.updates)
+}
+
+// newProgressBar creates the bar shown for bulk actions and cleans
+func newProgressBar() progress.Model {
+	return progress.New(progress.WithSolidFill(theme.Accent), progress.WithoutPercentage())
+}
+
+// cleanProgressView renders a bar and the counts for a clean in the TUI
+func (cm *ChromiumManager) cleanProgressView(name string) string {
+	p, ok := cm.cleaning[name]
+	if !ok {
+		return ""
+	}
+	bar := newProgressBar()
+	bar.Width = 30
+	return fmt.Sprintf("%s  %s", bar.ViewAs(p.fraction()), p)
+}
+
+// cleanProgressPrinter returns a report function for cleanDataDirWith
+// that redraws a progress line on the terminal, and done to end the line.
+// When the output isn't a terminal nothing is printed.
+func cleanProgressPrinter() (report func(cleanProgress), done func()) {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil, func() {}
+	}
+	width := 0
+	report = func(p cleanProgress) {
+		line := "  " + p.textBar(30) + " " + p.String()
+		pad := width - len(line)
+		if pad < 0 {
+			pad = 0
+		}
+		width = len(line)
+		fmt.Print("\r" + line + strings.Repeat(" ", pad))
+	}
+	return report, func() {
+		if width > 0 {
+			fmt.Println()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanProgressFraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress cleanProgress
+		want     float64
+		bar      string
+	}{
+		{"counting", cleanProgress{counting: true, totalFiles: 10}, 0, "[----]"},
+		{"by bytes", cleanProgress{files: 9, totalFiles: 10, bytes: 50, totalBytes: 200}, 0.25, "[#---]"},
+		{"empty files", cleanProgress{files: 1, totalFiles: 2}, 0.5, "[##--]"},
+		{"nothing to delete", cleanProgress{}, 1, "[####]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.progress.fraction(); got != tt.want {
+				t.Errorf("fraction() = %v, want %v", got, tt.want)
+			}
+			if got := tt.progress.textBar(4); got != tt.bar {
+				t.Errorf("textBar(4) = %s, want %s", got, tt.bar)
+			}
+		})
+	}
+}
+
+func TestCleanProgressETA(t *testing.T) {
+	p := cleanProgress{bytes: 25, totalBytes: 100, elapsed: 10 * time.Second}
+	if eta, ok := p.eta(); !ok || eta != 30*time.Second {
+		t.Errorf("eta() = %s, %t, want 30s", eta, ok)
+	}
+	p.elapsed = time.Millisecond
+	if _, ok := p.eta(); ok {
+		t.Error("estimated the rest from too short a start")
+	}
+}
+
+func TestCleanDataDirWith(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	files := map[string]string{
+		"Local State":          "{}",
+		"Default/History":      "0123456789",
+		"Default/Cache/data_0": "cached",
+	}
+	if err := m.MkdirAll(filepath.Join(dataDir, "Default", "Cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range files {
+		if err := m.WriteFile(filepath.Join(dataDir, path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	var last cleanProgress
+	report := func(p cleanProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = p
+	}
+	if err := cleanDataDirWith(context.Background(), dataDir, nil, report); err != nil {
+		t.Fatal(err)
+	}
+	left, err := m.ReadDir(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d entries were left in the data dir", len(left))
+	}
+	if last.counting || last.files != 3 || last.totalFiles != 3 || last.bytes != 18 || last.totalBytes != 18 {
+		t.Errorf("last report was %+v, want all 3 files and 18 bytes", last)
+	}
+}
+
+func TestCleanDataDirWithCanceled(t *testing.T) {
+	m, _ := useFakes(t)
+	if err := m.MkdirAll("/profiles/work", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteFile("/profiles/work/Local State", []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cleanDataDirWith(ctx, "/profiles/work", nil, nil); err == nil {
+		t.Error("a canceled clean succeeded")
+	}
+	if _, err := m.Stat("/profiles/work/Local State"); err != nil {
+		t.Errorf("a canceled clean deleted files: %s", err)
+	}
+}
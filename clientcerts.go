@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The certificate fields Chromium can pick a client certificate by
+var certFilterKeys = []string{"CN", "L", "O", "OU"}
+
+// autoSelectRule is one entry of a profile's auto_select_certs: the sites
+// that get a client certificate without asking, and which one
+type autoSelectRule struct {
+	Pattern string                       `json:"pattern"`
+	Filter  map[string]map[string]string `json:"filter,omitempty"` // "ISSUER" or "SUBJECT" to field values
+}
+
+// parseAutoSelectRule reads an entry in the form of the
+// AutoSelectCertificateForUrls policy, such as
+// {"pattern":"https://[*.]example.com","filter":{"ISSUER":{"CN":"Corp CA"}}},
+// or the shorter "https://[*.]example.com issuer:CN=Corp CA"
+func parseAutoSelectRule(entry string) (autoSelectRule, error) {
+	entry = strings.TrimSpace(entry)
+	rule := autoSelectRule{}
+	if strings.HasPrefix(entry, "{") {
+		if err := json.Unmarshal([]byte(entry), &rule); err != nil {
+			return rule, fmt.Errorf("auto_select_certs entry %s: %s", entry, err)
+		}
+	} else {
+		pattern, filter, _ := strings.Cut(entry, " ")
+		rule.Pattern = pattern
+		if filter = strings.TrimSpace(filter); filter != "" {
+			part, field, ok := strings.Cut(filter, ":")
+			key, value, hasValue := strings.Cut(field, "=")
+			if !ok || !hasValue {
+				return rule, fmt.Errorf("auto_select_certs entry %q: the filter must be issuer:<field>=<value> or subject:<field>=<value>", entry)
+			}
+			rule.Filter = map[string]map[string]string{strings.ToUpper(part): {strings.ToUpper(key): value}}
+		}
+	}
+
+	if rule.Pattern == "" {
+		return rule, fmt.Errorf("auto_select_certs entry %q has no URL pattern", entry)
+	}
+	for part, fields := range rule.Filter {
+		if part != "ISSUER" && part != "SUBJECT" {
+			return rule, fmt.Errorf("auto_select_certs entry %q: filter by issuer or subject, not %s", entry, strings.ToLower(part))
+		}
+		for key := range fields {
+			if !containsString(certFilterKeys, key) {
+				return rule, fmt.Errorf("auto_select_certs entry %q: certificates are picked by %s, not %s", entry, strings.Join(certFilterKeys, ", "), key)
+			}
+		}
+	}
+	return rule, nil
+}
+
+// parseAutoSelectList splits the profile form's semicolon separated
+// entries, since the policy form has commas in it
+func parseAutoSelectList(s string) []string {
+	entries := []string{}
+	for _, entry := range strings.Split(s, ";") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// validAutoSelectCerts checks each of a profile's auto_select_certs entries
+func validAutoSelectCerts(entries []string) error {
+	for _, entry := range entries {
+		if _, err := parseAutoSelectRule(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoSelectPreferences returns the content settings that have the browser
+// pick client certificates by the profile's rules, as the
+// AutoSelectCertificateForUrls policy would. Rules for the same pattern
+// are tried in order.
+func autoSelectPreferences(profile Profile) map[string]interface{} {
+	if len(profile.AutoSelectCerts) == 0 {
+		return nil
+	}
+	filters := map[string][]interface{}{}
+	for _, entry := range profile.AutoSelectCerts {
+		rule, err := parseAutoSelectRule(entry)
+		if err != nil {
+			continue // Rejected when the config was read
+		}
+		filter := map[string]interface{}{}
+		for part, fields := range rule.Filter {
+			filter[part] = fields
+		}
+		// Exceptions are keyed by the site pattern and the embedder's
+		key := rule.Pattern + ",*"
+		filters[key] = append(filters[key], filter)
+	}
+
+	exceptions := map[string]interface{}{}
+	for key, list := range filters {
+		exceptions[key] = map[string]interface{}{
+			"setting": map[string]interface{}{"filters": list},
+		}
+	}
+	return map[string]interface{}{
+		"profile.content_settings.exceptions.auto_select_certificate": exceptions,
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAutoSelectRule(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    autoSelectRule
+		wantErr string
+	}{
+		{
+			entry: "https://[*.]example.com",
+			want:  autoSelectRule{Pattern: "https://[*.]example.com"},
+		},
+		{
+			entry: "https://[*.]example.com issuer:cn=Corp CA",
+			want:  autoSelectRule{Pattern: "https://[*.]example.com", Filter: map[string]map[string]string{"ISSUER": {"CN": "Corp CA"}}},
+		},
+		{
+			entry: `{"pattern":"https://vpn.example.com","filter":{"SUBJECT":{"O":"Example"}}}`,
+			want:  autoSelectRule{Pattern: "https://vpn.example.com", Filter: map[string]map[string]string{"SUBJECT": {"O": "Example"}}},
+		},
+		{entry: "https://a.example.com issuer:CN", wantErr: "the filter must be"},
+		{entry: "https://a.example.com owner:CN=me", wantErr: "filter by issuer or subject"},
+		{entry: "https://a.example.com subject:serial=1", wantErr: "certificates are picked by"},
+		{entry: `{"filter":{}}`, wantErr: "has no URL pattern"},
+		{entry: `{"pattern":`, wantErr: "auto_select_certs entry"},
+	}
+	for _, tt := range tests {
+		rule, err := parseAutoSelectRule(tt.entry)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseAutoSelectRule(%q) error = %v, want %q", tt.entry, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAutoSelectRule(%q) = %v", tt.entry, err)
+		} else if !reflect.DeepEqual(rule, tt.want) {
+			t.Errorf("parseAutoSelectRule(%q) = %+v, want %+v", tt.entry, rule, tt.want)
+		}
+	}
+}
+
+func TestAutoSelectPreferences(t *testing.T) {
+	profile := Profile{AutoSelectCerts: []string{
+		"https://[*.]example.com issuer:CN=Corp CA",
+		"https://[*.]example.com subject:O=Example",
+		"https://vpn.example.com",
+	}}
+	values := autoSelectPreferences(profile)
+	exceptions, ok := values["profile.content_settings.exceptions.auto_select_certificate"].(map[string]interface{})
+	if !ok || len(exceptions) != 2 {
+		t.Fatalf("exceptions are %v, want one per pattern", values)
+	}
+	setting := exceptions["https://[*.]example.com,*"].(map[string]interface{})["setting"].(map[string]interface{})
+	filters := setting["filters"].([]interface{})
+	if len(filters) != 2 || !reflect.DeepEqual(filters[0], map[string]interface{}{"ISSUER": map[string]string{"CN": "Corp CA"}}) {
+		t.Errorf("filters for example.com are %v, in the order given", filters)
+	}
+	if autoSelectPreferences(Profile{}) != nil {
+		t.Error("a profile without rules got preferences")
+	}
+}
+
+func TestParseAutoSelectList(t *testing.T) {
+	got := parseAutoSelectList(` https://a.example.com ; {"pattern":"https://b.example.com","filter":{"ISSUER":{"CN":"A, B"}}};;`)
+	want := []string{"https://a.example.com", `{"pattern":"https://b.example.com","filter":{"ISSUER":{"CN":"A, B"}}}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAutoSelectList() = %q, want %q", got, want)
+	}
+}
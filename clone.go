@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Directories whose files Chromium never changes in place: an extension
+// update unpacks into a new version directory. Their files can be shared
+// between profiles with hard links when the filesystem can't reflink.
+var immutableDirs = []string{"Extensions"}
+
+// forkStats counts how the files of a forked data dir were made
+type forkStats struct {
+	reflinked, linked, copied int
+	copiedBytes               int64
+}
+
+// String sums up how the files were made, as in "12 files: 10 reflinked,
+// 2 hard linked, 0 copied, 0 B"
+func (s forkStats) String() string {
+	return fmt.Sprintf("%d files: %d reflinked, %d hard linked, %d copied, %s",
+		s.reflinked+s.linked+s.copied, s.reflinked, s.linked, s.copied, formatBytes(s.copiedBytes))
+}
+
+// cloneProfile adds a profile with the settings of an existing one and a
+// copy of its data. The copy shares as much as it can with the original:
+// files are reflinked on filesystems that support it (btrfs, XFS, APFS),
+// so a large profile forks in moments, and extensions are hard linked
+// elsewhere. Caches aren't copied.
+func (cm *ChromiumManager) cloneProfile(ctx context.Context, name, newName string) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if newName == "" {
+		return "", fmt.Errorf("new profile name is required")
+	}
+	if err := validProfileName(newName); err != nil {
+		return "", err
+	}
+	if cm.configSource != "" {
+		return "", cm.errRemoteConfig()
+	}
+	if _, exists := cm.profiles[newName]; exists {
+		return "", fmt.Errorf("profile '%s' already exists", newName)
+	}
+	if cm.isArchived(name) {
+		return "", errArchived(name)
+	}
+	srcPath := cm.profilePath(profile)
+	if _, running := runningPID(srcPath); running {
+		return "", fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+
+	// The clone keeps its data in the default place even when the
+	// original has a data_dir of its own, and leaves the slot to it
+	clone := profile
+	clone.Name = newName
+	clone.DataDir = ""
+	clone.Slot = 0
+	dstPath := cm.profilePath(clone)
+	if _, err := os.Stat(dstPath); err == nil {
+		return "", fmt.Errorf("data directory %s already exists", dstPath)
+	}
+
+	var stats forkStats
+	if _, err := os.Stat(srcPath); err == nil {
+		if stats, err = forkDir(ctx, srcPath, dstPath); err != nil {
+			os.RemoveAll(dstPath)
+			return "", fmt.Errorf("copying data directory: %w", err)
+		}
+	}
+
+	cm.profiles[newName] = clone
+	if err := cm.saveProfiles(); err != nil {
+		delete(cm.profiles, newName)
+		os.RemoveAll(dstPath)
+		return "", fmt.Errorf("saving config: %w", err)
+	}
+
+	message := fmt.Sprintf("Cloned '%s' to '%s'", name, newName)
+	if stats.reflinked+stats.linked+stats.copied > 0 {
+		message += fmt.Sprintf(" (%s)", stats)
+	}
+	return message, nil
+}
+
+// forkDir copies a data dir to dst like copyDir, reflinking files where
+// the filesystem can and hard linking those in immutableDirs where it
+// can't. Lock files, caches and the launch log are left out, as are files
+// a running browser deletes while they are copied.
+func forkDir(ctx context.Context, src, dst string) (forkStats, error) {
+	var stats forkStats
+	skip := map[string]bool{launchLogName: true}
+	for _, dir := range dataDirCacheDirs {
+		skip[dir] = true
+	}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path != src {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if skip[rel] || isProfileCache(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), "Singleton") {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+
+		if err := reflinkFile(path, target); err == nil {
+			stats.reflinked++
+			return nil
+		}
+		if inImmutableDir(rel) {
+			if err := os.Link(path, target); err == nil {
+				stats.linked++
+				return nil
+			}
+		}
+		if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		stats.copied++
+		stats.copiedBytes += info.Size()
+		return nil
+	})
+	return stats, err
+}
+
+// isProfileCache reports whether rel, relative to a data dir, is one of
+// the cache directories of a profile in it
+func isProfileCache(rel string) bool {
+	parts := strings.SplitN(rel, string(os.PathSeparator), 2)
+	if len(parts) != 2 {
+		return false
+	}
+	return containsString(profileCacheDirs, parts[1])
+}
+
+// inImmutableDir reports whether rel, relative to a data dir, is inside
+// one of the immutableDirs of a profile
+func inImmutableDir(rel string) bool {
+	parts := strings.Split(rel, string(os.PathSeparator))
+	return len(parts) > 2 && containsString(immutableDirs, parts[1])
+}
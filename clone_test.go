@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsProfileCache(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{filepath.Join("Default", "Cache"), true},
+		{filepath.Join("Profile 1", "Code Cache"), true},
+		{filepath.Join("Default", "Service Worker", "CacheStorage"), true},
+		{filepath.Join("Default", "Service Worker"), false},
+		{filepath.Join("Default", "History"), false},
+		{"Cache", false},
+	}
+	for _, tt := range tests {
+		if got := isProfileCache(tt.rel); got != tt.want {
+			t.Errorf("isProfileCache(%q) = %t, want %t", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestInImmutableDir(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{filepath.Join("Default", "Extensions", "abc", "1.0", "manifest.json"), true},
+		{filepath.Join("Default", "Extensions"), false},
+		{filepath.Join("Default", "Extension State", "LOG"), false},
+		{filepath.Join("Extensions", "abc"), false},
+	}
+	for _, tt := range tests {
+		if got := inImmutableDir(tt.rel); got != tt.want {
+			t.Errorf("inImmutableDir(%q) = %t, want %t", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestForkDir(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "work")
+	dst := filepath.Join(t.TempDir(), "copy")
+	write := func(rel, data string) {
+		t.Helper()
+		path := filepath.Join(src, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Local State", "{}")
+	write(filepath.Join("Default", "History"), "visits")
+	write(filepath.Join("Default", "Extensions", "abc", "1.0", "manifest.json"), "{}")
+	write(filepath.Join("Default", "Cache", "data_0"), "cached")
+	write(filepath.Join("ShaderCache", "shader"), "cached")
+	write(launchLogName, "log")
+	if err := os.Symlink("myhost-4242", filepath.Join(src, "SingletonLock")); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := forkDir(context.Background(), src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := stats.reflinked + stats.linked + stats.copied; total != 3 {
+		t.Errorf("forked %d files, want 3: %+v", total, stats)
+	}
+	for _, rel := range []string{"Local State", filepath.Join("Default", "History"), filepath.Join("Default", "Extensions", "abc", "1.0", "manifest.json")} {
+		if _, err := os.Stat(filepath.Join(dst, rel)); err != nil {
+			t.Errorf("%s wasn't forked: %s", rel, err)
+		}
+	}
+	for _, rel := range []string{filepath.Join("Default", "Cache"), "ShaderCache", launchLogName, "SingletonLock"} {
+		if _, err := os.Lstat(filepath.Join(dst, rel)); err == nil {
+			t.Errorf("%s was forked", rel)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SQLite databases in each profile directory that compact vacuums. Chrome
+// moved Cookies into Network/ in version 96; both places are tried.
+var profileDatabases = []string{
+	"History",
+	"Cookies",
+	filepath.Join("Network", "Cookies"),
+	"Web Data",
+	"Favicons",
+	"Top Sites",
+	"Shortcuts",
+	"Network Action Predictor",
+}
+
+// sqliteCommand is the SQLite shell compact runs VACUUM with
+const sqliteCommand = "sqlite3"
+
+// compactProfile shrinks a closed profile's data dir without losing
+// anything but caches: the caches are removed as by a "cache" clean and
+// the SQLite databases are vacuumed. It returns a summary of the space
+// reclaimed.
+func (cm *ChromiumManager) compactProfile(ctx context.Context, name string) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if cm.isArchived(name) {
+		return "", errArchived(name)
+	}
+	path := cm.profilePath(profile)
+	if _, err := fsys.Stat(path); os.IsNotExist(err) {
+		return fmt.Sprintf("Profile '%s' has no data to compact", name), nil
+	}
+	if _, running := runningPID(path); running {
+		return "", fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	if err := reclaimDataDir(path); err != nil {
+		return "", err
+	}
+	defer cm.invalidateSize(name)
+
+	before, err := dirSize(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if err := cleanCaches(path); err != nil {
+		return "", err
+	}
+	vacuumed, err := vacuumDatabases(ctx, path)
+	after, sizeErr := dirSize(ctx, path)
+	if sizeErr != nil {
+		return "", sizeErr
+	}
+
+	message := fmt.Sprintf("Compacted '%s' from %s to %s", name, formatBytes(before), formatBytes(after))
+	if errors.Is(err, exec.ErrNotFound) {
+		return message + "; only the caches were removed since " + sqliteCommand + " isn't installed to vacuum the databases", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s, vacuuming %d databases", message, vacuumed), nil
+}
+
+// vacuumDatabases runs VACUUM on the known databases of every profile
+// directory in a data dir and returns how many it vacuumed
+func vacuumDatabases(ctx context.Context, dataDir string) (int, error) {
+	entries, err := fsys.ReadDir(dataDir)
+	if err != nil {
+		return 0, fmt.Errorf("reading directory: %w", err)
+	}
+	sqlite, err := procs.LookPath(sqliteCommand)
+	if err != nil {
+		return 0, err
+	}
+	vacuumed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, db := range profileDatabases {
+			path := filepath.Join(dataDir, entry.Name(), db)
+			if info, err := fsys.Stat(path); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			var out bytes.Buffer
+			cmd := exec.CommandContext(ctx, sqlite, path, "VACUUM;")
+			cmd.Stdout, cmd.Stderr = &out, &out
+			err := procs.Start(cmd)
+			if err == nil {
+				err = procs.Wait(cmd)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return vacuumed, ctx.Err()
+				}
+				return vacuumed, fmt.Errorf("vacuuming %s: %s", path, strings.TrimSpace(out.String()))
+			}
+			vacuumed++
+		}
+	}
+	return vacuumed, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// compactFixture sets up a closed profile with caches and databases
+func compactFixture(t *testing.T) (*ChromiumManager, *memFS, *fakeRunner) {
+	t.Helper()
+	m, runner := useFakes(t)
+	dataDir := "/profiles/work"
+	for _, dir := range []string{"Default/Cache", "Default/Network", "ShaderCache"} {
+		if err := m.MkdirAll(filepath.Join(dataDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{"Default/Cache/data_0", "ShaderCache/shader", "Default/History", "Default/Network/Cookies", "Default/Bookmarks"} {
+		if err := m.WriteFile(filepath.Join(dataDir, path), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles:   map[string]Profile{"work": {Name: "work"}},
+	}
+	cm.loadState()
+	return cm, m, runner
+}
+
+func TestCompactProfile(t *testing.T) {
+	cm, m, runner := compactFixture(t)
+	runner.paths[sqliteCommand] = true
+	message, err := cm.compactProfile(context.Background(), "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(message, "vacuuming 2 databases") {
+		t.Errorf("message %q, want 2 databases vacuumed", message)
+	}
+	vacuumed := []string{}
+	for _, args := range runner.commands() {
+		if args[0] != sqliteCommand || args[len(args)-1] != "VACUUM;" {
+			t.Errorf("ran %q, want a VACUUM", args)
+		}
+		vacuumed = append(vacuumed, args[1])
+	}
+	want := "/profiles/work/Default/History|/profiles/work/Default/Network/Cookies"
+	if strings.Join(vacuumed, "|") != want {
+		t.Errorf("vacuumed %q, want %s", vacuumed, want)
+	}
+	for _, path := range []string{"Default/Cache", "ShaderCache"} {
+		if _, err := m.Stat(filepath.Join("/profiles/work", path)); err == nil {
+			t.Errorf("cache %s was left", path)
+		}
+	}
+	if _, err := m.Stat("/profiles/work/Default/Bookmarks"); err != nil {
+		t.Errorf("compacting lost data: %s", err)
+	}
+}
+
+func TestCompactProfileWithoutSQLite(t *testing.T) {
+	cm, m, runner := compactFixture(t)
+	message, err := cm.compactProfile(context.Background(), "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(message, "only the caches were removed") {
+		t.Errorf("message %q doesn't say the databases weren't vacuumed", message)
+	}
+	if len(runner.commands()) != 0 {
+		t.Errorf("ran %q without sqlite3", runner.commands())
+	}
+	if _, err := m.Stat("/profiles/work/Default/Cache"); err == nil {
+		t.Error("the caches were left")
+	}
+}
+
+func TestCompactProfileVacuumFails(t *testing.T) {
+	cm, _, runner := compactFixture(t)
+	runner.paths[sqliteCommand] = true
+	runner.exitErr = errors.New("exit status 1")
+	if _, err := cm.compactProfile(context.Background(), "work"); err == nil || !strings.Contains(err.Error(), "vacuuming") {
+		t.Errorf("got error %v, want the failed vacuum", err)
+	}
+}
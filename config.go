@@ -0,0 +1,1077 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configEnvVar names the environment variable that overrides the config path
+const configEnvVar = "LAUNCHIUM_CONFIG"
+
+// Default config file names inside the profile directory
+const (
+	configFileName       = "profiles.toml"
+	legacyConfigFileName = "profiles.conf"
+)
+
+// resolveConfigPath picks the config file to use. An explicit --config flag
+// wins over LAUNCHIUM_CONFIG, which wins over the file in the profile dir.
+// Either may be a URL to fetch the config from.
+func resolveConfigPath(flagPath, profileDir string) string {
+	path := flagPath
+	if path == "" {
+		path = os.Getenv(configEnvVar)
+	}
+	if path == "" {
+		return filepath.Join(profileDir, configFileName)
+	}
+	if isRemoteConfig(path) {
+		return path
+	}
+	return expandPath(path)
+}
+
+// expandPath resolves a leading ~ and makes the path absolute, so paths
+// quoted in the config or on the command line behave like shell paths.
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// Settings holds global options from the [settings] table
+type Settings struct {
+	DefaultProfile string              // Profile used when none is given on the command line
+	Browser        string              // Browser binary chosen at setup; empty detects one
+	ProfileDir     string              // Where profile data is kept; empty uses ~/.chrome_profiles
+	Theme          string              // TUI theme name; empty or "auto" follows the terminal
+	Notifications  bool                // Desktop notifications for profiles that don't say otherwise
+	NoUpdateCheck  bool                // check_updates = false: `launchium version` stays offline
+	SyncRemote     string              // Where `launchium sync` keeps profile data
+	SyncExclude    []string            // Extra paths sync leaves out, beyond caches
+	ConfigHistory  bool                // Record every save in a git history of the config
+	LaunchCheck    int                 // Seconds a launched browser must stay up; 0 is the default, negative is off
+	RecentProfiles int                 // Recently launched profiles on the main menu; 0 is the default, negative is off
+	ConfirmSize    string              // Size from which deleting or cleaning a profile asks for its name; empty is the default, "off" never asks
+	Tile           string              // How the windows of a multi-profile launch are laid out; empty cascades them
+	FallbackOrder  []string            // Browsers fallback profiles try, as channels or paths; empty tries every installed one
+	Keys           map[string][]string // TUI key overrides from the [keys] table, by action
+	Themes         map[string]Theme    // User themes from [themes.<name>] tables
+	Webhooks       map[string]Webhook  // Event receivers from [webhooks.<name>] tables
+	FirstRun       map[string]FirstRun // New data dir setups from [first_run.<name>] tables
+	Apps           map[string]App      // Web apps opened in windows of their own, from [apps.<name>] tables
+	Unknown        []string            // Keys and tables this version doesn't know; kept when saving
+}
+
+// parseConfig reads settings and profiles from the TOML config format:
+//
+//	[settings]
+//	default_profile = "work"
+//
+//	[keys]
+//	mark = ["space", "x"]
+//
+//	[themes.solarized]
+//	base = "dark"
+//	accent = "#268bd2"
+//
+//	[webhooks.slack]
+//	url = "https://hooks.slack.com/services/..."
+//	events = ["launched", "launch_failed"]
+//
+//	[first_run.default]
+//	disable_metrics = true
+//
+//	[apps.Example]
+//	profile = "work"
+//	url = "https://app.example.com"
+//
+//	[profiles.work]
+//	proxy = "127.0.0.1:8080"
+//	proxy_type = "socks5"
+//	flags = "--no-first-run"
+//
+//	[profiles.work.flags]
+//	--window-size = { value = "1280,800", enabled = false }
+//
+//	[profiles.work.sessions.standup]
+//	urls = ["https://meet.example.com/standup", "https://jira.example.com"]
+//	window_size = "1280,800"
+//
+// Only the subset of TOML that launchium writes is supported: tables,
+// quoted strings, booleans, integers, arrays of strings and the inline
+// tables of flags. Keys and tables launchium doesn't know, such as those of
+// a newer version, are listed in Settings.Unknown and otherwise ignored.
+func parseConfig(data []byte) (map[string]Profile, Settings, error) {
+	profiles, settings, err := parseTables(data)
+	if err != nil {
+		return nil, settings, err
+	}
+	if err := checkReferences(profiles, settings); err != nil {
+		return nil, settings, err
+	}
+	return profiles, settings, nil
+}
+
+// parseTables reads a config file on its own, without checking what its
+// profiles refer to, for files such as drop-ins that are only part of the
+// config
+func parseTables(data []byte) (map[string]Profile, Settings, error) {
+	profiles := make(map[string]Profile)
+	settings := Settings{}
+
+	var current *Profile
+	var currentTheme *Theme
+	var currentWebhook *Webhook
+	var currentFirstRun *FirstRun
+	var currentApp *App
+	var currentSession *Session
+	inSettings := false
+	inKeys := false
+	inFlags := false
+	inUnknown := false
+	flush := func() {
+		if currentSession != nil {
+			if current.Sessions == nil {
+				current.Sessions = Sessions{}
+			}
+			current.Sessions[currentSession.Name] = *currentSession
+		}
+		if current != nil {
+			profiles[current.Name] = *current
+		}
+		if currentTheme != nil {
+			if settings.Themes == nil {
+				settings.Themes = map[string]Theme{}
+			}
+			settings.Themes[currentTheme.Name] = *currentTheme
+		}
+		if currentWebhook != nil {
+			if settings.Webhooks == nil {
+				settings.Webhooks = map[string]Webhook{}
+			}
+			settings.Webhooks[currentWebhook.Name] = *currentWebhook
+		}
+		if currentFirstRun != nil {
+			if settings.FirstRun == nil {
+				settings.FirstRun = map[string]FirstRun{}
+			}
+			settings.FirstRun[currentFirstRun.Name] = *currentFirstRun
+		}
+		if currentApp != nil {
+			if settings.Apps == nil {
+				settings.Apps = map[string]App{}
+			}
+			settings.Apps[currentApp.Name] = *currentApp
+		}
+	}
+
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		// Table header
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, settings, fmt.Errorf("line %d: unterminated table header", n+1)
+			}
+			flush()
+			current = nil
+			currentTheme = nil
+			currentWebhook = nil
+			currentFirstRun = nil
+			currentApp = nil
+			currentSession = nil
+			inFlags = false
+			inUnknown = false
+
+			header := strings.TrimSpace(line[1 : len(line)-1])
+			inSettings = header == "settings"
+			inKeys = header == "keys"
+			if inSettings || inKeys {
+				continue
+			}
+			if strings.HasPrefix(header, "themes.") {
+				name, err := parseKey(strings.TrimPrefix(header, "themes."))
+				if err != nil {
+					return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+				}
+				if _, ok := builtinThemes[name]; ok || name == themeAuto {
+					return nil, settings, fmt.Errorf("line %d: theme %q is built in and can't be redefined", n+1, name)
+				}
+				currentTheme = &Theme{Name: name}
+				continue
+			}
+			if strings.HasPrefix(header, "webhooks.") {
+				name, err := parseKey(strings.TrimPrefix(header, "webhooks."))
+				if err != nil {
+					return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+				}
+				currentWebhook = &Webhook{Name: name}
+				continue
+			}
+			if strings.HasPrefix(header, "first_run.") {
+				name, err := parseKey(strings.TrimPrefix(header, "first_run."))
+				if err != nil {
+					return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+				}
+				setup := defaultFirstRun(name)
+				currentFirstRun = &setup
+				continue
+			}
+			if strings.HasPrefix(header, "apps.") {
+				name, err := parseKey(strings.TrimPrefix(header, "apps."))
+				if err != nil {
+					return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+				}
+				currentApp = &App{Name: name}
+				continue
+			}
+			if !strings.HasPrefix(header, "profiles.") {
+				settings.Unknown = append(settings.Unknown, fmt.Sprintf("line %d: unknown table [%s]", n+1, header))
+				inUnknown = true
+				continue
+			}
+			rest := strings.TrimPrefix(header, "profiles.")
+			name, session, isSession := sessionTableName(rest)
+			flags := false
+			if !isSession {
+				var err error
+				if name, flags, err = profileTableName(rest); err != nil {
+					return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+				}
+			}
+			// Flags and session tables add to their profile, whichever comes first
+			profile, ok := profiles[name]
+			if !ok {
+				profile = Profile{Name: name, Proxy: "none", ProxyType: "none"}
+			}
+			current = &profile
+			inFlags = flags
+			if isSession {
+				currentSession = &Session{Name: session}
+			}
+			continue
+		}
+
+		// Key/value pair
+		if inUnknown {
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, settings, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+		key, err := parseKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+		}
+		value := strings.TrimSpace(line[eq+1:])
+
+		switch {
+		case inSettings:
+			err = settings.setField(key, value)
+		case inKeys:
+			err = settings.setKey(key, value)
+		case inFlags:
+			err = current.setFlagField(key, value)
+		case currentSession != nil:
+			err = currentSession.setField(key, value)
+		case current != nil:
+			err = current.setField(key, value)
+		case currentTheme != nil:
+			err = currentTheme.setField(key, value)
+		case currentWebhook != nil:
+			err = currentWebhook.setField(key, value)
+		case currentFirstRun != nil:
+			err = currentFirstRun.setField(key, value)
+		case currentApp != nil:
+			err = currentApp.setField(key, value)
+		default:
+			err = fmt.Errorf("key outside of a [settings], [keys], [themes.<name>], [webhooks.<name>], [first_run.<name>], [apps.<name>] or [profiles.<name>] table")
+		}
+		if _, ok := err.(unknownKeyError); ok {
+			settings.Unknown = append(settings.Unknown, fmt.Sprintf("line %d: %s", n+1, err))
+			err = nil
+		}
+		if err != nil {
+			return nil, settings, fmt.Errorf("line %d: %s", n+1, err)
+		}
+	}
+	flush()
+
+	for name, hook := range settings.Webhooks {
+		if hook.URL == "" {
+			return nil, settings, fmt.Errorf("webhook %q needs a url", name)
+		}
+	}
+	for name, app := range settings.Apps {
+		if app.URL == "" || app.Profile == "" {
+			return nil, settings, fmt.Errorf("app %q needs a profile and a url", name)
+		}
+	}
+	if settings.Theme != "" && !settings.validThemeName(settings.Theme) {
+		return nil, settings, fmt.Errorf("unknown theme %q", settings.Theme)
+	}
+	return profiles, settings, nil
+}
+
+// checkReferences checks what the profiles of a whole config refer to:
+// the [first_run.<name>] tables they name and the slots they take
+func checkReferences(profiles map[string]Profile, settings Settings) error {
+	for _, name := range sortedProfileNames(profiles) {
+		if err := settings.validFirstRun(profiles[name].FirstRun); err != nil {
+			return fmt.Errorf("profile %q: %s", name, err)
+		}
+	}
+	return checkSlots(profiles)
+}
+
+// parseLegacyConfig reads the original pipe-delimited profiles.conf format
+func parseLegacyConfig(data []byte) map[string]Profile {
+	profiles := make(map[string]Profile)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) >= 4 {
+			profiles[parts[0]] = Profile{
+				Name:      parts[0],
+				Proxy:     parts[1],
+				ProxyType: parts[2],
+				Flags:     parseFlagList(parts[3]),
+			}
+		}
+	}
+	return profiles
+}
+
+// configTable is one table of the config: its header without brackets,
+// such as "profiles.work", and its entries
+type configTable struct {
+	header string
+	fields []configField
+}
+
+// configTables returns the tables of the config in the order they are
+// written, with profiles sorted by name
+func configTables(profiles map[string]Profile, settings Settings) []configTable {
+	tables := []configTable{}
+	if fields := settings.fields(); len(fields) > 0 {
+		tables = append(tables, configTable{"settings", fields})
+	}
+	if len(settings.Keys) > 0 {
+		actions := make([]string, 0, len(settings.Keys))
+		for action := range settings.Keys {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		fields := []configField{}
+		for _, action := range actions {
+			fields = append(fields, configField{action, quoteStringArray(settings.Keys[action])})
+		}
+		tables = append(tables, configTable{"keys", fields})
+	}
+	themeNames := make([]string, 0, len(settings.Themes))
+	for name := range settings.Themes {
+		themeNames = append(themeNames, name)
+	}
+	sort.Strings(themeNames)
+	for _, name := range themeNames {
+		tables = append(tables, configTable{"themes." + formatKey(name), settings.Themes[name].fields()})
+	}
+	hookNames := make([]string, 0, len(settings.Webhooks))
+	for name := range settings.Webhooks {
+		hookNames = append(hookNames, name)
+	}
+	sort.Strings(hookNames)
+	for _, name := range hookNames {
+		tables = append(tables, configTable{"webhooks." + formatKey(name), settings.Webhooks[name].fields()})
+	}
+	for _, name := range settings.firstRunNames() {
+		tables = append(tables, configTable{"first_run." + formatKey(name), settings.FirstRun[name].fields()})
+	}
+	for _, name := range settings.appNames() {
+		tables = append(tables, configTable{"apps." + formatKey(name), settings.Apps[name].fields()})
+	}
+	for _, name := range sortedProfileNames(profiles) {
+		p := profiles[name]
+		tables = append(tables, configTable{"profiles." + formatKey(p.Name), p.fields()})
+		if p.Flags.structured() {
+			tables = append(tables, configTable{"profiles." + formatKey(p.Name) + ".flags", p.Flags.fields()})
+		}
+		for _, session := range p.Sessions.names() {
+			tables = append(tables, configTable{"profiles." + formatKey(p.Name) + ".sessions." + formatKey(session), p.Sessions[session].fields()})
+		}
+	}
+	return tables
+}
+
+// formatConfig renders settings and profiles in the TOML config format,
+// with profiles sorted by name
+func formatConfig(profiles map[string]Profile, settings Settings) []byte {
+	var b strings.Builder
+	b.WriteString("# Launchium profiles\n")
+	for _, table := range configTables(profiles, settings) {
+		b.WriteString("\n[" + table.header + "]\n")
+		for _, field := range table.fields {
+			fmt.Fprintf(&b, "%s = %s\n", field.key, field.value)
+		}
+	}
+	return []byte(b.String())
+}
+
+// configField is a single rendered key/value pair of a profile table
+type configField struct {
+	key, value string
+}
+
+// fields returns the profile's config entries in the order they are written
+func (p Profile) fields() []configField {
+	fields := []configField{}
+	if p.Description != "" {
+		fields = append(fields, configField{"description", quoteString(p.Description)})
+	}
+	fields = append(fields, []configField{
+		{"proxy", quoteString(p.Proxy)},
+		{"proxy_type", quoteString(p.ProxyType)},
+	}...)
+	if !p.Flags.structured() {
+		fields = append(fields, configField{"flags", quoteString(p.Flags.String())})
+	}
+	if len(p.Presets) > 0 {
+		fields = append(fields, configField{"presets", quoteStringArray(p.Presets)})
+	}
+	if p.Browser != "" {
+		fields = append(fields, configField{"browser", quoteString(p.Browser)})
+	}
+	if p.Channel != channelAny {
+		fields = append(fields, configField{"channel", quoteString(p.Channel)})
+	}
+	if p.DataDir != "" {
+		fields = append(fields, configField{"data_dir", quoteString(p.DataDir)})
+	}
+	if p.RAMDisk != ramDiskOff {
+		fields = append(fields, configField{"ramdisk", quoteString(p.RAMDisk)})
+	}
+	if len(p.Tags) > 0 {
+		fields = append(fields, configField{"tags", quoteStringArray(p.Tags)})
+	}
+	if p.Color != "" {
+		fields = append(fields, configField{"color", quoteString(p.Color)})
+	}
+	if p.Icon != "" {
+		fields = append(fields, configField{"icon", quoteString(p.Icon)})
+	}
+	if p.CleanSchedule != "" {
+		fields = append(fields, configField{"clean_schedule", quoteString(p.CleanSchedule)})
+	}
+	if len(p.CleanKeep) > 0 {
+		fields = append(fields, configField{"clean_keep", quoteStringArray(p.CleanKeep)})
+	}
+	if p.Notify != notifyDefault {
+		fields = append(fields, configField{"notify", quoteString(p.Notify)})
+	}
+	if p.Fallback {
+		fields = append(fields, configField{"fallback", "true"})
+	}
+	if p.Singleton {
+		fields = append(fields, configField{"singleton", "true"})
+	}
+	if p.MemoryLimit != "" {
+		fields = append(fields, configField{"memory_limit", quoteString(p.MemoryLimit)})
+	}
+	if p.CPUWeight != 0 {
+		fields = append(fields, configField{"cpu_weight", strconv.Itoa(p.CPUWeight)})
+	}
+	if p.Nice != 0 {
+		fields = append(fields, configField{"nice", strconv.Itoa(p.Nice)})
+	}
+	if p.RunAs != "" {
+		fields = append(fields, configField{"run_as", quoteString(p.RunAs)})
+	}
+	if p.IdleTimeout != 0 {
+		fields = append(fields, configField{"idle_timeout", strconv.Itoa(p.IdleTimeout)})
+	}
+	if p.IdleClean {
+		fields = append(fields, configField{"idle_clean", "true"})
+	}
+	if p.SearchEngine != "" {
+		fields = append(fields, configField{"search_engine", quoteString(p.SearchEngine)})
+	}
+	if p.Homepage != "" {
+		fields = append(fields, configField{"homepage", quoteString(p.Homepage)})
+	}
+	if p.FirstRun != "" {
+		fields = append(fields, configField{"first_run", quoteString(p.FirstRun)})
+	}
+	if p.Locale != "" {
+		fields = append(fields, configField{"locale", quoteString(p.Locale)})
+	}
+	if len(p.SpellcheckLanguages) > 0 {
+		fields = append(fields, configField{"spellcheck_languages", quoteStringArray(p.SpellcheckLanguages)})
+	}
+	if len(p.CACerts) > 0 {
+		fields = append(fields, configField{"ca_certs", quoteStringArray(p.CACerts)})
+	}
+	if len(p.AutoSelectCerts) > 0 {
+		fields = append(fields, configField{"auto_select_certs", quoteStringArray(p.AutoSelectCerts)})
+	}
+	if p.WebRTCPolicy != webRTCUnchanged {
+		fields = append(fields, configField{"webrtc_policy", quoteString(p.WebRTCPolicy)})
+	}
+	if p.PasswordStore != passwordStoreUnchanged {
+		fields = append(fields, configField{"password_store", quoteString(p.PasswordStore)})
+	}
+	if p.DoNotTrack {
+		fields = append(fields, configField{"do_not_track", "true"})
+	}
+	if p.BlockThirdPartyCookies {
+		fields = append(fields, configField{"block_third_party_cookies", "true"})
+	}
+	if p.DisableHyperlinkAuditing {
+		fields = append(fields, configField{"disable_hyperlink_auditing", "true"})
+	}
+	if len(p.Permissions) > 0 {
+		fields = append(fields, configField{"permissions", quoteStringArray(p.Permissions)})
+	}
+	if p.Slot != 0 {
+		fields = append(fields, configField{"slot", strconv.Itoa(p.Slot)})
+	}
+	if p.Protected {
+		fields = append(fields, configField{"protected", "true"})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching profile field
+func (p *Profile) setField(key, value string) error {
+	switch key {
+	case "description":
+		return unquoteInto(&p.Description, value)
+	case "proxy":
+		return unquoteInto(&p.Proxy, value)
+	case "proxy_type":
+		return unquoteInto(&p.ProxyType, value)
+	case "flags":
+		var flags string
+		if err := unquoteInto(&flags, value); err != nil {
+			return err
+		}
+		p.Flags = append(p.Flags, parseFlagList(flags)...)
+		return nil
+	case "presets":
+		presets, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.Presets = presets
+		return validatePresets(p.Presets)
+	case "browser":
+		return unquoteInto(&p.Browser, value)
+	case "channel":
+		if err := unquoteInto(&p.Channel, value); err != nil {
+			return err
+		}
+		if !validChannel(p.Channel) {
+			return fmt.Errorf("channel must be one of %s, got %q", strings.Join(browserChannels, ", "), p.Channel)
+		}
+		return nil
+	case "data_dir":
+		return unquoteInto(&p.DataDir, value)
+	case "ramdisk":
+		if err := unquoteInto(&p.RAMDisk, value); err != nil {
+			return err
+		}
+		if !validRAMDiskMode(p.RAMDisk) {
+			return fmt.Errorf("ramdisk must be \"discard\" or \"persist\", got %q", p.RAMDisk)
+		}
+		return nil
+	case "color":
+		if err := unquoteInto(&p.Color, value); err != nil {
+			return err
+		}
+		if p.Color != "" {
+			if _, err := parseHexColor(p.Color); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "icon":
+		return unquoteInto(&p.Icon, value)
+	case "search_engine":
+		if err := unquoteInto(&p.SearchEngine, value); err != nil {
+			return err
+		}
+		if p.SearchEngine != "" {
+			if _, err := resolveSearchEngine(p.SearchEngine); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "homepage":
+		if err := unquoteInto(&p.Homepage, value); err != nil {
+			return err
+		}
+		if p.Homepage != "" {
+			return validHomepage(p.Homepage)
+		}
+		return nil
+	case "first_run":
+		return unquoteInto(&p.FirstRun, value)
+	case "locale":
+		if err := unquoteInto(&p.Locale, value); err != nil {
+			return err
+		}
+		if p.Locale != "" {
+			return validLanguage(p.Locale)
+		}
+		return nil
+	case "spellcheck_languages":
+		languages, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.SpellcheckLanguages = languages
+		return validLanguages(languages)
+	case "ca_certs":
+		paths, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.CACerts = paths
+		return nil
+	case "auto_select_certs":
+		entries, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.AutoSelectCerts = entries
+		return validAutoSelectCerts(entries)
+	case "webrtc_policy":
+		if err := unquoteInto(&p.WebRTCPolicy, value); err != nil {
+			return err
+		}
+		return validWebRTCPolicy(p.WebRTCPolicy)
+	case "password_store":
+		if err := unquoteInto(&p.PasswordStore, value); err != nil {
+			return err
+		}
+		return validPasswordStore(p.PasswordStore)
+	case "do_not_track":
+		return parseBoolInto(&p.DoNotTrack, value)
+	case "block_third_party_cookies":
+		return parseBoolInto(&p.BlockThirdPartyCookies, value)
+	case "disable_hyperlink_auditing":
+		return parseBoolInto(&p.DisableHyperlinkAuditing, value)
+	case "permissions":
+		entries, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.Permissions = entries
+		return validPermissions(entries)
+	case "slot":
+		if err := parseIntInto(&p.Slot, value); err != nil {
+			return err
+		}
+		return validSlot(p.Slot)
+	case "protected":
+		return parseBoolInto(&p.Protected, value)
+	case "clean_schedule":
+		if err := unquoteInto(&p.CleanSchedule, value); err != nil {
+			return err
+		}
+		if p.CleanSchedule != "" {
+			if _, err := parseCleanSchedule(p.CleanSchedule); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "clean_keep":
+		patterns, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.CleanKeep = patterns
+		return validCleanKeep(patterns)
+	case "notify":
+		if err := unquoteInto(&p.Notify, value); err != nil {
+			return err
+		}
+		if !validNotifyMode(p.Notify) {
+			return fmt.Errorf("notify must be \"on\" or \"off\", got %q", p.Notify)
+		}
+		return nil
+	case "fallback":
+		return parseBoolInto(&p.Fallback, value)
+	case "singleton":
+		return parseBoolInto(&p.Singleton, value)
+	case "memory_limit":
+		if err := unquoteInto(&p.MemoryLimit, value); err != nil {
+			return err
+		}
+		return validateLimits(*p)
+	case "cpu_weight":
+		if err := parseIntInto(&p.CPUWeight, value); err != nil {
+			return err
+		}
+		return validateLimits(*p)
+	case "nice":
+		if err := parseIntInto(&p.Nice, value); err != nil {
+			return err
+		}
+		return validateLimits(*p)
+	case "run_as":
+		return unquoteInto(&p.RunAs, value)
+	case "idle_timeout":
+		if err := parseIntInto(&p.IdleTimeout, value); err != nil {
+			return err
+		}
+		if p.IdleTimeout < 0 {
+			return fmt.Errorf("idle_timeout must be a number of minutes, got %d", p.IdleTimeout)
+		}
+		return nil
+	case "idle_clean":
+		return parseBoolInto(&p.IdleClean, value)
+	case "tags":
+		tags, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		p.Tags = tags
+		return nil
+	default:
+		return unknownKeyError{"profile key", key}
+	}
+}
+
+// fields returns the non-default settings in the order they are written
+func (s Settings) fields() []configField {
+	fields := []configField{}
+	if s.DefaultProfile != "" {
+		fields = append(fields, configField{"default_profile", quoteString(s.DefaultProfile)})
+	}
+	if s.Browser != "" {
+		fields = append(fields, configField{"browser", quoteString(s.Browser)})
+	}
+	if s.ProfileDir != "" {
+		fields = append(fields, configField{"profile_dir", quoteString(s.ProfileDir)})
+	}
+	if s.Theme != "" {
+		fields = append(fields, configField{"theme", quoteString(s.Theme)})
+	}
+	if s.Notifications {
+		fields = append(fields, configField{"notifications", "true"})
+	}
+	if s.NoUpdateCheck {
+		fields = append(fields, configField{"check_updates", "false"})
+	}
+	if s.SyncRemote != "" {
+		fields = append(fields, configField{"sync_remote", quoteString(s.SyncRemote)})
+	}
+	if len(s.SyncExclude) > 0 {
+		fields = append(fields, configField{"sync_exclude", quoteStringArray(s.SyncExclude)})
+	}
+	if s.ConfigHistory {
+		fields = append(fields, configField{"config_history", "true"})
+	}
+	if len(s.FallbackOrder) > 0 {
+		fields = append(fields, configField{"fallback_order", quoteStringArray(s.FallbackOrder)})
+	}
+	if s.LaunchCheck != 0 {
+		fields = append(fields, configField{"launch_check", strconv.Itoa(max(s.LaunchCheck, 0))})
+	}
+	if s.RecentProfiles != 0 {
+		fields = append(fields, configField{"recent_profiles", strconv.Itoa(max(s.RecentProfiles, 0))})
+	}
+	if s.ConfirmSize != "" {
+		fields = append(fields, configField{"confirm_size", quoteString(s.ConfirmSize)})
+	}
+	if s.Tile != "" {
+		fields = append(fields, configField{"tile", quoteString(s.Tile)})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching setting
+func (s *Settings) setField(key, value string) error {
+	switch key {
+	case "default_profile":
+		return unquoteInto(&s.DefaultProfile, value)
+	case "browser":
+		return unquoteInto(&s.Browser, value)
+	case "profile_dir":
+		return unquoteInto(&s.ProfileDir, value)
+	case "theme":
+		return unquoteInto(&s.Theme, value)
+	case "notifications":
+		return parseBoolInto(&s.Notifications, value)
+	case "check_updates":
+		check := true
+		err := parseBoolInto(&check, value)
+		s.NoUpdateCheck = !check
+		return err
+	case "sync_remote":
+		return unquoteInto(&s.SyncRemote, value)
+	case "sync_exclude":
+		excludes, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		s.SyncExclude = excludes
+		return nil
+	case "config_history":
+		return parseBoolInto(&s.ConfigHistory, value)
+	case "fallback_order":
+		order, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		s.FallbackOrder = order
+		return nil
+	case "launch_check":
+		if err := parseIntInto(&s.LaunchCheck, value); err != nil {
+			return err
+		}
+		if s.LaunchCheck == 0 {
+			s.LaunchCheck = -1 // Turned off
+		}
+		return nil
+	case "recent_profiles":
+		if err := parseIntInto(&s.RecentProfiles, value); err != nil {
+			return err
+		}
+		if err := validRecentProfiles(s.RecentProfiles); err != nil {
+			return err
+		}
+		if s.RecentProfiles == 0 {
+			s.RecentProfiles = -1 // Turned off
+		}
+		return nil
+	case "confirm_size":
+		if err := unquoteInto(&s.ConfirmSize, value); err != nil {
+			return err
+		}
+		return validConfirmSize(s.ConfirmSize)
+	case "tile":
+		if err := unquoteInto(&s.Tile, value); err != nil {
+			return err
+		}
+		return validTileLayout(s.Tile)
+	default:
+		return unknownKeyError{"setting", key}
+	}
+}
+
+// setKey assigns the keys for a TUI action from the [keys] table
+func (s *Settings) setKey(action, value string) error {
+	if !validKeyAction(action) {
+		return fmt.Errorf("unknown key action %q (expected one of %s)", action, strings.Join(keyActions(), ", "))
+	}
+	keys, err := unquoteStringArray(value)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("key action %q needs at least one key", action)
+	}
+	if s.Keys == nil {
+		s.Keys = map[string][]string{}
+	}
+	s.Keys[action] = keys
+	return nil
+}
+
+// unknownKeyError is returned by setField for a key it doesn't know
+type unknownKeyError struct {
+	kind, key string
+}
+
+func (e unknownKeyError) Error() string {
+	return fmt.Sprintf("unknown %s %q", e.kind, e.key)
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// parseKey accepts a bare or quoted TOML key
+func parseKey(key string) (string, error) {
+	if strings.HasPrefix(key, "\"") {
+		return unquoteString(key)
+	}
+	if key == "" {
+		return "", fmt.Errorf("empty key")
+	}
+	for _, r := range key {
+		if !isBareKeyRune(r) {
+			return "", fmt.Errorf("invalid key %q (quote it)", key)
+		}
+	}
+	return key, nil
+}
+
+// formatKey quotes a key when it cannot be written bare
+func formatKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if !isBareKeyRune(r) {
+			return quoteString(key)
+		}
+	}
+	return key
+}
+
+func isBareKeyRune(r rune) bool {
+	return r == '-' || r == '_' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// quoteString renders a TOML basic string
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteString parses a TOML basic string
+func unquoteString(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %s", s)
+	}
+	value, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return value, nil
+}
+
+// quoteStringArray renders a TOML array of basic strings
+func quoteStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// unquoteStringArray parses a single-line TOML array of basic strings
+func unquoteStringArray(s string) ([]string, error) {
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("expected an array of strings, got %s", s)
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+
+	values := []string{}
+	for body != "" {
+		// Find the closing quote of the next element, skipping escapes
+		if body[0] != '"' {
+			return nil, fmt.Errorf("expected a quoted string in array %s", s)
+		}
+		end := 1
+		for end < len(body) && body[end] != '"' {
+			if body[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(body) {
+			return nil, fmt.Errorf("unterminated string in array %s", s)
+		}
+		value, err := unquoteString(body[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		body = strings.TrimSpace(body[end+1:])
+		if strings.HasPrefix(body, ",") {
+			body = strings.TrimSpace(body[1:])
+		} else if body != "" {
+			return nil, fmt.Errorf("expected ',' in array %s", s)
+		}
+	}
+	return values, nil
+}
+
+// parseBoolInto parses a TOML boolean
+func parseBoolInto(dst *bool, value string) error {
+	switch value {
+	case "true":
+		*dst = true
+	case "false":
+		*dst = false
+	default:
+		return fmt.Errorf("expected true or false, got %s", value)
+	}
+	return nil
+}
+
+// parseIntInto parses a TOML integer
+func parseIntInto(dst *int, value string) error {
+	n, err := strconv.Atoi(strings.ReplaceAll(value, "_", ""))
+	if err != nil {
+		return fmt.Errorf("expected an integer, got %s", value)
+	}
+	*dst = n
+	return nil
+}
+
+func unquoteInto(dst *string, value string) error {
+	s, err := unquoteString(value)
+	if err != nil {
+		return err
+	}
+	*dst = s
+	return nil
+}
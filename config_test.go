@@ -0,0 +1,227 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"proxy", `
+[profiles.work]
+proxy = "127.0.0.1:8080"
+proxy_type = "http"
+flags = "--no-first-run"
+`},
+		{"settings", `
+[settings]
+launch_check = 5
+recent_profiles = 0
+confirm_size = "500M"
+tile = "grid"
+
+[profiles.home]
+proxy = "none"
+proxy_type = "none"
+tags = ["personal", "daily"]
+`},
+		{"labels and storage", `
+[profiles.research]
+proxy = "127.0.0.1:9050"
+proxy_type = "socks5"
+description = "Research, always through Tor"
+color = "#3366ff"
+icon = "R"
+channel = "beta"
+ramdisk = "discard"
+`},
+		{"start pages", `
+[profiles.home]
+proxy = "none"
+proxy_type = "none"
+search_engine = "duckduckgo"
+homepage = "https://news.example.com/"
+`},
+		{"first run", `
+[first_run.default]
+skip_welcome = true
+skip_default_browser = false
+disable_metrics = true
+master_preferences = "~/initial_preferences"
+
+[profiles.kiosk]
+proxy = "none"
+proxy_type = "none"
+first_run = "default"
+`},
+		{"languages", `
+[profiles.berlin]
+proxy = "none"
+proxy_type = "none"
+locale = "de-DE"
+spellcheck_languages = ["de-DE", "en-US"]
+`},
+		{"trusted CAs", `
+[profiles.staging]
+proxy = "none"
+proxy_type = "none"
+ca_certs = ["~/certs/staging-ca.pem", "/etc/ssl/corp root.der"]
+`},
+		{"client certificates", `
+[profiles.corp]
+proxy = "none"
+proxy_type = "none"
+auto_select_certs = ["https://[*.]corp.example.com issuer:CN=Corp CA", "{\"pattern\":\"https://vpn.example.com\",\"filter\":{\"SUBJECT\":{\"O\":\"Example\"}}}"]
+`},
+		{"webrtc", `
+[profiles.private]
+proxy = "127.0.0.1:9050"
+proxy_type = "socks5"
+webrtc_policy = "disable-non-proxied-udp"
+password_store = "basic"
+`},
+		{"privacy", `
+[profiles.private]
+proxy = "none"
+proxy_type = "none"
+description = "Research, nothing kept"
+do_not_track = true
+block_third_party_cookies = true
+disable_hyperlink_auditing = true
+`},
+		{"permissions", `
+[profiles.apps]
+proxy = "none"
+proxy_type = "none"
+permissions = ["notifications=block", "clipboard=allow https://app.example.com"]
+`},
+		{"slots", `
+[profiles.personal]
+proxy = "none"
+proxy_type = "none"
+slot = 2
+
+[profiles.work]
+proxy = "none"
+proxy_type = "none"
+slot = 1
+protected = true
+`},
+		{"clean keep", `
+[profiles.daily]
+proxy = "none"
+proxy_type = "none"
+clean_keep = ["Default/Bookmarks", "Default/Login Data*"]
+`},
+		{"sessions", `
+[profiles.work]
+proxy = "none"
+proxy_type = "none"
+
+[profiles.work.sessions.standup]
+urls = ["https://meet.example.com/standup", "https://jira.example.com/board"]
+window_size = "1280,800"
+`},
+		{"apps", `
+[apps.Mail]
+profile = "work"
+url = "https://mail.example.com"
+icon = "/icons/mail.png"
+
+[profiles.work]
+proxy = "none"
+proxy_type = "none"
+`},
+		{"flags table", `
+[profiles.demo]
+proxy = "none"
+proxy_type = "none"
+
+[profiles.demo.flags]
+--no-first-run = true
+--window-size = "1280,800"
+--incognito = { enabled = false, note = "only for demos" }
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, settings, err := parseConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatalf("parsing: %s", err)
+			}
+			formatted := formatConfig(profiles, settings)
+			again, againSettings, err := parseConfig(formatted)
+			if err != nil {
+				t.Fatalf("parsing the formatted config: %s\n%s", err, formatted)
+			}
+			if !reflect.DeepEqual(profiles, again) {
+				t.Errorf("profiles changed in a round trip:\n%#v\n%#v", profiles, again)
+			}
+			if !reflect.DeepEqual(settings, againSettings) {
+				t.Errorf("settings changed in a round trip:\n%#v\n%#v", settings, againSettings)
+			}
+			if twice := formatConfig(again, againSettings); string(twice) != string(formatted) {
+				t.Errorf("formatting isn't stable:\n%s\n%s", formatted, twice)
+			}
+		})
+	}
+}
+
+func TestFlagsTableKeepsRepeatedFeatures(t *testing.T) {
+	flags := FlagList{
+		parseFlag("--enable-features=A,B"),
+		{Switch: "--window-size", Value: "800,600", Enabled: true, Note: "small screen"},
+		parseFlag("--enable-features=C"),
+	}
+	profile := Profile{Name: "a", Proxy: "none", ProxyType: "none", Flags: flags}
+	profiles, _, err := parseConfig(formatConfig(map[string]Profile{"a": profile}, Settings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := composeFlags(profiles["a"].Flags.args())
+	want := composeFlags(flags.args())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flags after a round trip are %q, want %q", got, want)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"ramdisk", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nramdisk = \"keep\"\n", "ramdisk must be"},
+		{"channel", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nchannel = \"nightly\"\n", "channel must be"},
+		{"search engine", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nsearch_engine = \"https://example.com/\"\n", "search engine must be"},
+		{"homepage", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nhomepage = \"javascript:alert(1)\"\n", "homepage must be"},
+		{"first run table", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nfirst_run = \"kiosk\"\n", "has no [first_run.kiosk] table"},
+		{"locale", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nlocale = \"german\"\n", "is not a language code"},
+		{"client certificate filter", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nauto_select_certs = [\"https://a.example.com serial:1\"]\n", "the filter must be"},
+		{"webrtc", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nwebrtc_policy = \"off\"\n", "webrtc_policy must be"},
+		{"permission", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\npermissions = [\"usb=allow\"]\n", "unknown permission"},
+		{"slot", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nslot = 10\n", "slot must be between 1 and 9"},
+		{"slot twice", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nslot = 3\n\n[profiles.b]\nproxy = \"none\"\nproxy_type = \"none\"\nslot = 3\n", "both have slot 3"},
+		{"recent profiles", "[settings]\nrecent_profiles = 6\n", "recent_profiles must be between 0 and 5"},
+		{"confirm size", "[settings]\nconfirm_size = \"big\"\n", "confirm_size"},
+		{"clean keep", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nclean_keep = [\"/etc\"]\n", "should be a path in the data dir"},
+		{"session window", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\n\n[profiles.a.sessions.s]\nwindow_size = \"wide\"\n", "window_size must be two numbers"},
+		{"tile", "[settings]\ntile = \"spiral\"\n", "tile must be one of"},
+		{"app url", "[apps.Mail]\nprofile = \"work\"\nurl = \"mail.example.com\"\n", "http or https"},
+		{"app without profile", "[apps.Mail]\nurl = \"https://mail.example.com\"\n", "needs a profile and a url"},
+		{"password store", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\npassword_store = \"keychain\"\n", "password_store must be"},
+		{"flag twice", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\n\n[profiles.a.flags]\n--incognito = true\n--incognito = false\n", "listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseConfig([]byte(tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
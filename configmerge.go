@@ -0,0 +1,337 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// Tables of the config in the order launchium writes them. New tables are
+// placed after the existing ones of the same or an earlier kind.
+var tableKinds = []string{"settings", "keys", "themes", "webhooks", "apps", "profiles"}
+
+// configSection is a table of a config file as it was written: the
+// comments directly above its header, the header line, and the lines up to
+// the next table. The preamble before the first table has no header.
+type configSection struct {
+	leading []string
+	header  string
+	name    string // Header without brackets, with its key in canonical form
+	body    []string
+	added   bool // Not in the original file
+}
+
+// tableKind returns the kind of a table name, such as "profiles" for
+// "profiles.work", and its position in tableKinds, or -1 for tables
+// launchium doesn't know. A profile's flags and session tables are of kind
+// "flags" and "sessions" and go with the profiles.
+func tableKind(name string) (string, int) {
+	kind, rest, _ := strings.Cut(name, ".")
+	if _, _, ok := sessionTableName(rest); ok && kind == "profiles" {
+		_, rank := tableKind(kind)
+		return "sessions", rank
+	}
+	if kind == "profiles" && strings.HasSuffix(name, ".flags") {
+		_, rank := tableKind(kind)
+		return "flags", rank
+	}
+	for i, k := range tableKinds {
+		if k == kind {
+			return kind, i
+		}
+	}
+	return kind, -1
+}
+
+// canonicalTable normalizes a table header so that `[profiles."work"]`
+// and `[profiles.work]` match
+func canonicalTable(header string) string {
+	header = strings.TrimSpace(header)
+	kind, rest, ok := strings.Cut(header, ".")
+	if !ok {
+		return header
+	}
+	if kind == "profiles" {
+		if profile, session, ok := sessionTableName(strings.TrimSpace(rest)); ok {
+			return kind + "." + formatKey(profile) + ".sessions." + formatKey(session)
+		}
+		name, flags, err := profileTableName(strings.TrimSpace(rest))
+		if err == nil && flags {
+			return kind + "." + formatKey(name) + ".flags"
+		}
+	}
+	key, err := parseKey(strings.TrimSpace(rest))
+	if err != nil {
+		return header
+	}
+	return kind + "." + formatKey(key)
+}
+
+// splitConfig breaks a config file into its sections
+func splitConfig(data []byte) []*configSection {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	sections := []*configSection{{}}
+	for _, line := range lines {
+		code := strings.TrimSpace(stripComment(line))
+		if !strings.HasPrefix(code, "[") || !strings.HasSuffix(code, "]") {
+			last := sections[len(sections)-1]
+			last.body = append(last.body, line)
+			continue
+		}
+
+		// Comments right above the header belong to it
+		prev := sections[len(sections)-1]
+		cut := len(prev.body)
+		for cut > 0 && strings.HasPrefix(strings.TrimSpace(prev.body[cut-1]), "#") {
+			cut--
+		}
+		section := &configSection{
+			leading: append([]string{}, prev.body[cut:]...),
+			header:  line,
+			name:    canonicalTable(code[1 : len(code)-1]),
+		}
+		prev.body = prev.body[:cut]
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// knownKey reports whether launchium understands key in tables of kind
+func knownKey(kind, key string) bool {
+	var err error
+	switch kind {
+	case "settings":
+		err = (&Settings{}).setField(key, `""`)
+	case "themes":
+		err = (&Theme{}).setField(key, `""`)
+	case "webhooks":
+		err = (&Webhook{}).setField(key, `""`)
+	case "apps":
+		err = (&App{}).setField(key, `""`)
+	case "profiles":
+		err = (&Profile{}).setField(key, `""`)
+	case "sessions":
+		err = (&Session{}).setField(key, `""`)
+	case "keys", "flags":
+		return true
+	}
+	_, unknown := err.(unknownKeyError)
+	return !unknown
+}
+
+// mergeFields updates the body of a table to hold fields. Lines for keys
+// that stay are kept with their spacing and comments, only their value
+// changing; known keys that are no longer set are removed, and new ones
+// are added after the last key. Comments and keys launchium doesn't know
+// are kept. It reports whether any key remains.
+func mergeFields(kind string, body []string, fields []configField) ([]string, bool) {
+	values := map[string]string{}
+	for _, field := range fields {
+		values[field.key] = field.value
+	}
+	written := map[string]bool{}
+	merged := []string{}
+	lastKey := -1
+	for _, line := range body {
+		code := stripComment(line)
+		eq := strings.Index(code, "=")
+		if eq < 0 {
+			merged = append(merged, line)
+			continue
+		}
+		key, err := parseKey(strings.TrimSpace(code[:eq]))
+		if err != nil {
+			merged = append(merged, line)
+			lastKey = len(merged) - 1
+			continue
+		}
+		value, set := values[key]
+		switch {
+		case set && !written[key]:
+			if strings.TrimSpace(code[eq+1:]) != value {
+				comment := line[len(code):]
+				spacing := ""
+				if comment != "" {
+					spacing = code[len(strings.TrimRight(code, " \t")):]
+				}
+				line = code[:eq+1] + " " + value + spacing + comment
+			}
+			written[key] = true
+		case set || knownKey(kind, key):
+			continue // Repeated, or no longer set
+		}
+		merged = append(merged, line)
+		lastKey = len(merged) - 1
+	}
+
+	added := []string{}
+	for _, field := range fields {
+		if !written[field.key] {
+			added = append(added, field.key+" = "+field.value)
+		}
+	}
+	if len(added) > 0 {
+		at := lastKey + 1
+		merged = append(merged[:at], append(added, merged[at:]...)...)
+		lastKey = at + len(added) - 1
+	}
+	return merged, lastKey >= 0
+}
+
+// mergeConfig renders tables into the config file original, keeping its
+// comments, the order of its tables and keys, and anything launchium
+// doesn't know. Tables launchium no longer writes are removed with the
+// comments above them, and new ones go after the last table of their kind.
+func mergeConfig(original []byte, tables []configTable) []byte {
+	sections := splitConfig(original)
+	wanted := map[string][]configField{}
+	for _, table := range tables {
+		wanted[table.name()] = table.fields
+	}
+
+	renamed := renamedTables(sections, tables, wanted)
+
+	kept := []*configSection{sections[0]}
+	present := map[string]bool{}
+	for _, section := range sections[1:] {
+		if name, ok := renamed[section]; ok {
+			code := stripComment(section.header)
+			section.header = "[" + name + "]" + section.header[len(strings.TrimRight(code, " \t")):]
+			section.name = name
+		}
+		kind, rank := tableKind(section.name)
+		fields, ok := wanted[section.name]
+		switch {
+		case rank < 0:
+			// A table launchium doesn't know stays as it is
+		case ok && !present[section.name]:
+			section.body, _ = mergeFields(kind, section.body, fields)
+			present[section.name] = true
+		case kind == "settings" || kind == "keys":
+			// Nothing is set any more, but keys launchium doesn't know stay
+			var remains bool
+			if section.body, remains = mergeFields(kind, section.body, nil); !remains {
+				continue
+			}
+		default:
+			continue // Deleted, or a repeated table
+		}
+		kept = append(kept, section)
+	}
+
+	for _, table := range tables {
+		if present[table.name()] {
+			continue
+		}
+		kind, rank := tableKind(table.name())
+		var owner string
+		if kind == "sessions" {
+			profile, _, _ := sessionTableName(strings.TrimPrefix(table.name(), "profiles."))
+			owner = "profiles." + formatKey(profile)
+		}
+		at := 1
+		for i := 1; i < len(kept); i++ {
+			if kind == "flags" {
+				// Right after the profile's own table
+				if kept[i].name == strings.TrimSuffix(table.name(), ".flags") {
+					at = i + 1
+					break
+				}
+			} else if kind == "sessions" {
+				// After the profile's own table and the tables within it
+				if kept[i].name == owner || strings.HasPrefix(kept[i].name, owner+".") {
+					at = i + 1
+				}
+			} else if _, r := tableKind(kept[i].name); r >= 0 && r <= rank {
+				at = i + 1
+			}
+		}
+		section := &configSection{header: "[" + table.header + "]", name: table.name(), added: true}
+		for _, field := range table.fields {
+			section.body = append(section.body, field.key+" = "+field.value)
+		}
+		kept = append(kept[:at], append([]*configSection{section}, kept[at:]...)...)
+	}
+
+	lines := []string{}
+	blank := func() bool { return len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) == "" }
+	for i, section := range kept {
+		if section.added && !blank() {
+			lines = append(lines, "")
+		}
+		lines = append(lines, section.leading...)
+		if section.header != "" {
+			lines = append(lines, section.header)
+		}
+		lines = append(lines, section.body...)
+		if section.added && i+1 < len(kept) {
+			lines = append(lines, "")
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// renamedTables finds tables that were renamed: a table that is no longer
+// written whose keys and values are the same as one new table's. Those
+// keep their place and comments under the new name.
+func renamedTables(sections []*configSection, tables []configTable, wanted map[string][]configField) map[*configSection]string {
+	existing := map[string]bool{}
+	for _, section := range sections {
+		existing[section.name] = true
+	}
+	renamed := map[*configSection]string{}
+	for _, section := range sections[1:] {
+		kind, rank := tableKind(section.name)
+		if _, ok := wanted[section.name]; ok || rank < 0 || !strings.Contains(section.name, ".") {
+			continue
+		}
+		values := map[string]string{}
+		for _, line := range section.body {
+			code := stripComment(line)
+			if eq := strings.Index(code, "="); eq >= 0 {
+				if key, err := parseKey(strings.TrimSpace(code[:eq])); err == nil && knownKey(kind, key) {
+					values[key] = strings.TrimSpace(code[eq+1:])
+				}
+			}
+		}
+
+		matches := []string{}
+		for _, table := range tables {
+			if k, _ := tableKind(table.name()); k != kind || existing[table.name()] || len(table.fields) != len(values) {
+				continue
+			}
+			same := true
+			for _, field := range table.fields {
+				if values[field.key] != field.value {
+					same = false
+					break
+				}
+			}
+			if same {
+				matches = append(matches, table.name())
+			}
+		}
+		if len(matches) == 1 {
+			renamed[section] = matches[0]
+			existing[matches[0]] = true
+		}
+	}
+	return renamed
+}
+
+// renderConfig formats the profiles and settings for saving over the
+// config file, keeping what was written by hand in it
+func (cm *ChromiumManager) renderConfig() []byte {
+	original, err := ioutil.ReadFile(cm.configFile)
+	if err != nil || len(strings.TrimSpace(string(original))) == 0 {
+		return formatConfig(cm.mainProfiles(), cm.settings)
+	}
+	return mergeConfig(original, configTables(cm.mainProfiles(), cm.settings))
+}
+
+// name returns the table's header in canonical form
+func (t configTable) name() string {
+	return canonicalTable(t.header)
+}
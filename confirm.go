@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profiles holding at least this much data are only deleted or cleaned
+// once their name is typed, unless the confirm_size setting says otherwise
+const defaultConfirmSize = "1G"
+
+// confirmSizeOff is the confirm_size that never asks for a typed name
+const confirmSizeOff = "off"
+
+// validConfirmSize checks the confirm_size setting
+func validConfirmSize(value string) error {
+	if value == confirmSizeOff {
+		return nil
+	}
+	if _, err := parseByteSize(value); err != nil {
+		return fmt.Errorf("confirm_size: %s, or \"off\"", err)
+	}
+	return nil
+}
+
+// confirmThreshold returns the size from which deleting or cleaning a
+// profile needs a typed confirmation, 0 when it never does
+func (s Settings) confirmThreshold() int64 {
+	value := s.ConfirmSize
+	switch value {
+	case confirmSizeOff:
+		return 0
+	case "":
+		value = defaultConfirmSize
+	}
+	size, _ := parseByteSize(value)
+	return size
+}
+
+// confirmLarge asks on the terminal for the name of a profile holding more
+// than the confirm_size before the command line deletes or cleans it.
+// -force skips the question, and without a terminal to ask on the profile
+// is left alone.
+func (cm *ChromiumManager) confirmLarge(ctx context.Context, name, action string) error {
+	threshold := cm.settings.confirmThreshold()
+	if threshold == 0 || cm.unprotect {
+		return nil
+	}
+	size := cm.profileSizes(ctx, []string{name}, false)[0]
+	if size.err != nil || size.noData || size.size < threshold {
+		return nil
+	}
+	if !stdinIsTerminal() {
+		return fmt.Errorf("profile '%s' holds %s; %s it on a terminal to type its name, or pass -force", name, formatBytes(size.size), action)
+	}
+	fmt.Printf("Profile '%s' holds %s. Type its name to %s it: ", name, formatBytes(size.size), action)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != name {
+		return fmt.Errorf("the name didn't match; profile '%s' was left alone", name)
+	}
+	return nil
+}
+
+// newConfirmInput returns the input a large profile's name is typed into
+func newConfirmInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Width = formInputWidth
+	input.Focus()
+	return input
+}
+
+// typedConfirmation returns what has to be typed to confirm the delete or
+// clean being asked about, or "" if y is enough. pending is set while the
+// sizes that decide it are still being scanned.
+func (cm *ChromiumManager) typedConfirmation(action string) (expected string, total int64, pending bool) {
+	threshold := cm.settings.confirmThreshold()
+	if threshold == 0 {
+		return "", 0, false
+	}
+	names := cm.confirmingNames()
+	large := false
+	for _, name := range names {
+		size, ok := cm.sizes[name]
+		if !ok {
+			// No data, or a scan that failed, is taken as small
+			pending = pending || cm.sizing[name]
+			continue
+		}
+		total += size
+		large = large || size >= threshold
+	}
+	switch {
+	case pending || !large:
+		return "", total, pending
+	case len(names) == 1:
+		return names[0], total, false
+	}
+	return fmt.Sprintf("%s %d profiles", action, len(names)), total, false
+}
+
+// typingConfirmation reports whether a name is being typed to confirm a
+// delete or clean, so keys such as n and ? are part of it
+func (cm *ChromiumManager) typingConfirmation() bool {
+	if cm.currentView != "confirm_delete" && cm.currentView != "confirm_clean" {
+		return false
+	}
+	expected, _, _ := cm.typedConfirmation(confirmAction(cm.currentView))
+	return expected != ""
+}
+
+// confirmAction names what a confirmation view is about to do
+func confirmAction(view string) string {
+	return strings.TrimPrefix(view, "confirm_")
+}
+
+// answerConfirm handles a key in a delete or clean confirmation. yes is
+// set once it is confirmed: with y, twice for protected profiles, or with
+// the typed name of large ones. no is set when it is called off.
+func (cm *ChromiumManager) answerConfirm(msg tea.KeyMsg) (yes, no bool, cmd tea.Cmd) {
+	expected, _, pending := cm.typedConfirmation(confirmAction(cm.currentView))
+	switch {
+	case expected == "" && key.Matches(msg, cm.keys.Cancel):
+		return false, true, nil
+	case pending:
+		// The sizes decide whether y is enough
+		if key.Matches(msg, cm.keys.Confirm) {
+			cm.notify(levelInfo, "Still calculating sizes, try again in a moment")
+		}
+		return false, false, nil
+	case expected == "":
+		return key.Matches(msg, cm.keys.Confirm) && cm.confirmProtected(), false, nil
+	case key.Matches(msg, cm.keys.Select):
+		if strings.TrimSpace(cm.confirmInput.Value()) == expected {
+			return true, false, nil
+		}
+		cm.notify(levelWarn, "Type %s exactly to confirm, or press %s to cancel", expected, cm.keys.Back.Help().Key)
+		return false, false, nil
+	}
+	cm.confirmInput, cmd = cm.confirmInput.Update(msg)
+	return false, false, cmd
+}
+
+// confirmPrompt ends a delete or clean confirmation with what to answer
+func (cm *ChromiumManager) confirmPrompt() string {
+	action := confirmAction(cm.currentView)
+	expected, total, pending := cm.typedConfirmation(action)
+	switch {
+	case pending:
+		return "(calculating sizes...)"
+	case expected == "":
+		return "(y/n)" + cm.protectedWarning(action)
+	case len(cm.confirmingNames()) == 1:
+		return fmt.Sprintf("\n\nThis profile holds %s. Type its name to %s it:\n\n%s", formatBytes(total), action, cm.confirmInput.View())
+	}
+	return fmt.Sprintf("\n\nThese profiles hold %s. Type %s to confirm:\n\n%s", formatBytes(total), expected, cm.confirmInput.View())
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConfirmThreshold(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    int64
+	}{
+		{"", 1 << 30},
+		{"500M", 500 << 20},
+		{confirmSizeOff, 0},
+	}
+	for _, tt := range tests {
+		if got := (Settings{ConfirmSize: tt.setting}).confirmThreshold(); got != tt.want {
+			t.Errorf("confirm_size %q: threshold %d, want %d", tt.setting, got, tt.want)
+		}
+	}
+}
+
+func TestTypedConfirmation(t *testing.T) {
+	cm := &ChromiumManager{
+		settings: Settings{ConfirmSize: "1M"},
+		profiles: map[string]Profile{"big": {Name: "big"}, "small": {Name: "small"}, "new": {Name: "new"}},
+		sizes:    map[string]int64{"big": 5 << 20, "small": 1 << 10},
+		sizing:   map[string]bool{},
+	}
+	tests := []struct {
+		selected string
+		bulk     []string
+		want     string
+		pending  bool
+	}{
+		{"small", nil, "", false},
+		{"big", nil, "big", false},
+		{"new", nil, "", false},
+		{"", []string{"small", "big"}, "delete 2 profiles", false},
+	}
+	for _, tt := range tests {
+		cm.selected, cm.bulkNames = tt.selected, tt.bulk
+		got, _, pending := cm.typedConfirmation("delete")
+		if got != tt.want || pending != tt.pending {
+			t.Errorf("%s%v: typed confirmation %q, pending %v, want %q, %v", tt.selected, tt.bulk, got, pending, tt.want, tt.pending)
+		}
+	}
+
+	// A size still being scanned decides nothing yet
+	cm.selected, cm.bulkNames = "new", nil
+	cm.sizing["new"] = true
+	if got, _, pending := cm.typedConfirmation("clean"); got != "" || !pending {
+		t.Errorf("while scanning: %q, pending %v", got, pending)
+	}
+}
+
+func TestConfirmLarge(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	cm.settings.ConfirmSize = "1K"
+	cm.sizes = map[string]int64{}
+	cm.loadState()
+	m.MkdirAll("/profiles/work/Default", 0755)
+	m.WriteFile("/profiles/work/Default/History", make([]byte, 4096), 0644)
+
+	// Tests don't run on a terminal, so a large profile is left alone
+	err := cm.confirmLarge(context.Background(), "work", "clean")
+	if err == nil || !strings.Contains(err.Error(), "pass -force") {
+		t.Errorf("a large profile off a terminal gave %v", err)
+	}
+	if err := cm.confirmLarge(context.Background(), "home", "clean"); err != nil {
+		t.Errorf("a profile without data gave %v", err)
+	}
+	cm.unprotect = true
+	if err := cm.confirmLarge(context.Background(), "work", "clean"); err != nil {
+		t.Errorf("-force didn't skip the question: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Crashes kept in the state for each profile, newest last
+const maxCrashRecords = 20
+
+// crashDumpDirs are where Crashpad, and Breakpad before it, leave the
+// minidumps of a data dir's crashes
+var crashDumpDirs = []string{
+	filepath.Join("Crashpad", "reports"),
+	filepath.Join("Crashpad", "completed"),
+	filepath.Join("Crashpad", "pending"),
+	"Crash Reports",
+}
+
+// crashReporterFlag in a profile's flags turns crash dumps back on, by
+// leaving --disable-breakpad out of the standard flags
+const crashReporterFlag = "--enable-crash-reporter"
+
+// standardFlags returns the flags every browser is started with, less
+// --disable-breakpad for a profile that asks for crash dumps
+func (p Profile) standardFlags() []string {
+	if !p.Flags.has(crashReporterFlag) {
+		return standardFlags
+	}
+	flags := []string{}
+	for _, flag := range standardFlags {
+		if flag != "--disable-breakpad" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// crashRecord is a crash of a profile's browser found after the fact
+type crashRecord struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Dump   string    `json:"dump,omitempty"` // Minidump the crash left, if any
+}
+
+// Browsers launchium started itself that exited with an error, by
+// profile. Exits are seen in the background, so they are left here for
+// the next crash check.
+var (
+	crashExitsMu sync.Mutex
+	crashExits   = map[string][]crashRecord{}
+)
+
+// watchCrashExit notes the browser's exit if it was an error. Browsers
+// closed by `launchium stop` or `kill` don't count.
+func watchCrashExit(name string, exit *processExit) {
+	go func() {
+		<-exit.done
+		if exit.err == nil {
+			return
+		}
+		reason := exit.err.Error()
+		if reason == "signal: killed" || reason == "signal: terminated" {
+			return
+		}
+		crashExitsMu.Lock()
+		defer crashExitsMu.Unlock()
+		crashExits[name] = append(crashExits[name], crashRecord{Time: time.Now(), Reason: "exited with " + reason})
+	}()
+}
+
+// takeCrashExits returns and forgets the error exits noted for a profile
+func takeCrashExits(name string) []crashRecord {
+	crashExitsMu.Lock()
+	defer crashExitsMu.Unlock()
+	exits := crashExits[name]
+	delete(crashExits, name)
+	return exits
+}
+
+// crashProbe is what a crash check of one profile looks at. It holds no
+// reference to the manager, so the check can run in the background.
+type crashProbe struct {
+	name     string
+	dataDir  string
+	since    time.Time // The newest crash already recorded
+	launched time.Time // The profile's last launch
+}
+
+// crashProbe captures what checking the profile for new crashes needs
+func (cm *ChromiumManager) crashProbe(name string) crashProbe {
+	probe := crashProbe{name: name, dataDir: cm.profilePath(cm.profiles[name]), launched: cm.state.LastLaunch[name]}
+	if crashes := cm.state.Crashes[name]; len(crashes) > 0 {
+		probe.since = crashes[len(crashes)-1].Time
+	}
+	return probe
+}
+
+// scan finds the crashes since the last recorded one: minidumps written
+// since, error exits of browsers launchium started, and a browser that
+// has gone without marking its profile as cleanly closed. The last is
+// only counted when nothing else explains it, so one crash is one record.
+func (p crashProbe) scan() []crashRecord {
+	found := takeCrashExits(p.name)
+	for _, dump := range crashDumps(p.dataDir) {
+		if dump.Time.After(p.since) {
+			found = append(found, dump)
+		}
+	}
+	if _, running := runningPID(p.dataDir); len(found) == 0 && !running && p.launched.After(p.since) && uncleanExit(p.dataDir) {
+		found = append(found, crashRecord{Time: time.Now(), Reason: "closed without shutting down cleanly"})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Time.Before(found[j].Time) })
+	return found
+}
+
+// crashDumps lists the minidumps in a data dir, oldest first
+func crashDumps(dataDir string) []crashRecord {
+	dumps := []crashRecord{}
+	for _, dir := range crashDumpDirs {
+		entries, err := fsys.ReadDir(filepath.Join(dataDir, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".dmp") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			dumps = append(dumps, crashRecord{Time: info.ModTime(), Reason: "left a crash dump", Dump: filepath.Join(dataDir, dir, entry.Name())})
+		}
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].Time.Before(dumps[j].Time) })
+	return dumps
+}
+
+// uncleanExit reports whether the browser last using the data dir didn't
+// shut down cleanly. Chromium marks the profile as crashed while it runs
+// and as normal once it has closed properly.
+func uncleanExit(dataDir string) bool {
+	data, err := fsys.ReadFile(filepath.Join(dataDir, "Default", "Preferences"))
+	if err != nil {
+		return false
+	}
+	var prefs struct {
+		Profile struct {
+			ExitType string `json:"exit_type"`
+		} `json:"profile"`
+	}
+	if json.Unmarshal(data, &prefs) != nil {
+		return false
+	}
+	return prefs.Profile.ExitType == "Crashed"
+}
+
+// recordCrashes adds crashes to the profile's history in the state and
+// returns those it didn't have yet
+func (cm *ChromiumManager) recordCrashes(name string, crashes []crashRecord) []crashRecord {
+	if cm.state.Crashes == nil {
+		cm.state.Crashes = map[string][]crashRecord{}
+	}
+	added := []crashRecord{}
+	for _, crash := range crashes {
+		known := false
+		for _, c := range cm.state.Crashes[name] {
+			// A crash without a dump is seen once per launch
+			sameDump := crash.Dump != "" && c.Dump == crash.Dump
+			sameLaunch := crash.Dump == "" && c.Dump == "" && c.Reason == crash.Reason && c.Time.After(cm.state.LastLaunch[name])
+			if sameDump || sameLaunch {
+				known = true
+				break
+			}
+		}
+		if !known {
+			cm.state.Crashes[name] = append(cm.state.Crashes[name], crash)
+			added = append(added, crash)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if history := cm.state.Crashes[name]; len(history) > maxCrashRecords {
+		cm.state.Crashes[name] = history[len(history)-maxCrashRecords:]
+	}
+	cm.saveState()
+	return added
+}
+
+// detectCrashes checks the profile for crashes since the last check and
+// records them
+func (cm *ChromiumManager) detectCrashes(name string) []crashRecord {
+	if _, ok := cm.profiles[name]; !ok {
+		return nil
+	}
+	return cm.recordCrashes(name, cm.crashProbe(name).scan())
+}
+
+// crashSummary describes a crash in a line
+func crashSummary(name string, crash crashRecord) string {
+	return fmt.Sprintf("Profile '%s' crashed %s: %s; see `launchium crashes -profile %s`", name, formatAgo(crash.Time), crash.Reason, name)
+}
+
+// crashesMsg reports crashes found in the background, by profile
+type crashesMsg struct {
+	found map[string][]crashRecord
+}
+
+// watchCrashes checks the profile for crashes once its browser has exited
+func (cm *ChromiumManager) watchCrashes(name string) tea.Cmd {
+	probe := cm.crashProbe(name)
+	dirs := cm.runningDirs()[name]
+	return func() tea.Msg {
+		if _, started := waitForExit(dirs, runningRefresh); !started {
+			return nil
+		}
+		// Give the exit watch a moment to note how it exited
+		time.Sleep(time.Second)
+		return crashesMsg{found: map[string][]crashRecord{name: probe.scan()}}
+	}
+}
+
+// scanCrashes checks every launched profile for crashes made while the
+// TUI wasn't watching, such as those of command line launches
+func (cm *ChromiumManager) scanCrashes() tea.Cmd {
+	probes := []crashProbe{}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		if _, launched := cm.state.LastLaunch[name]; launched {
+			probes = append(probes, cm.crashProbe(name))
+		}
+	}
+	if len(probes) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		found := map[string][]crashRecord{}
+		for _, probe := range probes {
+			if crashes := probe.scan(); len(crashes) > 0 {
+				found[probe.name] = crashes
+			}
+		}
+		return crashesMsg{found: found}
+	}
+}
+
+// finishCrashScan records crashes found in the background and warns about
+// the new ones
+func (cm *ChromiumManager) finishCrashScan(msg crashesMsg) {
+	for _, name := range sortedProfileNames(cm.profiles) {
+		for _, crash := range cm.recordCrashes(name, msg.found[name]) {
+			cm.notify(levelWarn, "%s", crashSummary(name, crash))
+		}
+	}
+}
+
+// crashDumpLocations returns the dump directories of the profile's data
+// dir that exist
+func (cm *ChromiumManager) crashDumpLocations(name string) []string {
+	dataDir := cm.profilePath(cm.profiles[name])
+	dirs := []string{}
+	for _, dir := range crashDumpDirs {
+		if info, err := fsys.Stat(filepath.Join(dataDir, dir)); err == nil && info.IsDir() {
+			dirs = append(dirs, filepath.Join(dataDir, dir))
+		}
+	}
+	return dirs
+}
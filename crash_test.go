@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStandardFlagsWithCrashReporter(t *testing.T) {
+	if !containsString(Profile{}.standardFlags(), "--disable-breakpad") {
+		t.Error("crash dumps aren't turned off by default")
+	}
+	profile := Profile{Flags: parseFlagList(crashReporterFlag)}
+	if containsString(profile.standardFlags(), "--disable-breakpad") {
+		t.Errorf("%s left --disable-breakpad in", crashReporterFlag)
+	}
+}
+
+func TestCrashScan(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	dumps := map[string]time.Time{
+		"Crashpad/completed/old.dmp": since.Add(-time.Hour),
+		"Crashpad/completed/new.dmp": since.Add(time.Hour),
+		"Crash Reports/newer.dmp":    since.Add(2 * time.Hour),
+		"Crash Reports/notes.txt":    since.Add(2 * time.Hour),
+	}
+	for path, mtime := range dumps {
+		path = filepath.Join(dataDir, path)
+		m.MkdirAll(filepath.Dir(path), 0755)
+		m.WriteFile(path, []byte("dump"), 0644)
+		m.Chtimes(path, mtime, mtime)
+	}
+
+	probe := crashProbe{name: "work", dataDir: dataDir, since: since, launched: since.Add(time.Minute)}
+	found := probe.scan()
+	if len(found) != 2 || filepath.Base(found[0].Dump) != "new.dmp" || filepath.Base(found[1].Dump) != "newer.dmp" {
+		t.Errorf("scan found %+v, want the two dumps since the last crash, oldest first", found)
+	}
+	if locations := (&ChromiumManager{profileDir: "/profiles", profiles: map[string]Profile{"work": {Name: "work"}}}).crashDumpLocations("work"); len(locations) != 2 {
+		t.Errorf("crashDumpLocations = %q, want Crashpad/completed and Crash Reports", locations)
+	}
+}
+
+func TestCrashScanUncleanExit(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	m.MkdirAll(filepath.Join(dataDir, "Default"), 0755)
+	m.WriteFile(filepath.Join(dataDir, "Default", "Preferences"), []byte(`{"profile":{"exit_type":"Crashed"}}`), 0644)
+	since := time.Now().Add(-time.Hour)
+
+	probe := crashProbe{name: "work", dataDir: dataDir, since: since, launched: since.Add(time.Minute)}
+	if found := probe.scan(); len(found) != 1 || found[0].Dump != "" {
+		t.Errorf("scan found %+v, want one unclean exit", found)
+	}
+	probe.launched = since.Add(-time.Minute)
+	if found := probe.scan(); len(found) != 0 {
+		t.Errorf("an unclean exit from before the last crash was counted again: %+v", found)
+	}
+
+	m.WriteFile(filepath.Join(dataDir, "Default", "Preferences"), []byte(`{"profile":{"exit_type":"Normal"}}`), 0644)
+	probe.launched = since.Add(time.Minute)
+	if found := probe.scan(); len(found) != 0 {
+		t.Errorf("a clean exit was taken for a crash: %+v", found)
+	}
+}
+
+func TestCrashScanTakesExits(t *testing.T) {
+	useFakes(t)
+	exit := &processExit{done: make(chan struct{}), err: errors.New("exit status 139")}
+	watchCrashExit("work", exit)
+	close(exit.done)
+	deadline := time.Now().Add(2 * time.Second)
+	var found []crashRecord
+	for len(found) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		found = crashProbe{name: "work", dataDir: "/profiles/work"}.scan()
+	}
+	if len(found) != 1 || found[0].Reason != "exited with exit status 139" {
+		t.Fatalf("scan found %+v, want the error exit", found)
+	}
+	if again := takeCrashExits("work"); len(again) != 0 {
+		t.Errorf("the exit was kept after the scan took it: %+v", again)
+	}
+}
+
+func TestRecordCrashes(t *testing.T) {
+	useFakes(t)
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles:   map[string]Profile{"work": {Name: "work"}},
+	}
+	cm.loadState()
+	launched := time.Now().Add(-time.Hour)
+	cm.state.LastLaunch = map[string]time.Time{"work": launched}
+
+	dump := crashRecord{Time: launched.Add(time.Minute), Reason: "left a crash dump", Dump: "/profiles/work/Crash Reports/a.dmp"}
+	unclean := crashRecord{Time: launched.Add(2 * time.Minute), Reason: "closed without shutting down cleanly"}
+	if added := cm.recordCrashes("work", []crashRecord{dump, unclean}); len(added) != 2 {
+		t.Fatalf("recorded %+v, want both crashes", added)
+	}
+	unclean.Time = unclean.Time.Add(time.Minute)
+	if added := cm.recordCrashes("work", []crashRecord{dump, unclean}); len(added) != 0 {
+		t.Errorf("crashes already recorded were added again: %+v", added)
+	}
+
+	for i := 0; i < maxCrashRecords; i++ {
+		cm.recordCrashes("work", []crashRecord{{Time: launched, Reason: "dump", Dump: filepath.Join("/dumps", string(rune('a'+i)))}})
+	}
+	if got := len(cm.state.Crashes["work"]); got != maxCrashRecords {
+		t.Errorf("%d crashes kept, want %d", got, maxCrashRecords)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Prefix of the names import-csv gives rows without one
+const defaultImportPrefix = "proxy"
+
+// Most rows import-csv takes from a sheet, like generate's limit
+const maxImportRows = 1000
+
+// importRow is one row of an import-csv sheet, by column
+type importRow struct {
+	line   int // Row number in the sheet, for errors
+	values map[string]string
+}
+
+// readSheet reads the rows of a .csv file, or of the first sheet of an
+// Excel .xlsx workbook
+func readSheet(file string) ([][]string, error) {
+	if strings.EqualFold(filepath.Ext(file), ".xlsx") {
+		return readXLSX(file)
+	}
+	data, err := fsys.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	// Excel saves CSV with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
+
+// importRows takes the rows under the header row, which must name at
+// least a proxy or name column. Blank rows are skipped.
+func importRows(rows [][]string) ([]importRow, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the sheet is empty")
+	}
+	columns, _ := csvHeader(rows[0])
+	if !containsString(columns, "name") && !containsString(columns, "proxy") {
+		return nil, fmt.Errorf("the first row must name the columns, such as name,proxy,proxy_type,flags,tags")
+	}
+	result := []importRow{}
+	for i, row := range rows[1:] {
+		r := importRow{line: i + 2, values: map[string]string{}}
+		for col, value := range row {
+			if col < len(columns) && columns[col] != "" && strings.TrimSpace(value) != "" {
+				r.values[columns[col]] = strings.TrimSpace(value)
+			}
+		}
+		if len(r.values) > 0 {
+			result = append(result, r)
+		}
+	}
+	if len(result) > maxImportRows {
+		return nil, fmt.Errorf("the sheet has %d rows, more than the %d import-csv takes at once", len(result), maxImportRows)
+	}
+	return result, nil
+}
+
+// profile applies the row to a profile: the existing one of its name, or
+// a new one. Empty cells leave a setting as it is.
+func (r importRow) profile(name string, existing Profile, exists bool) (Profile, []string) {
+	p := existing
+	if !exists {
+		p = Profile{Name: name, Proxy: "none", ProxyType: "none", Flags: parseFlagList(defaultNewProfileFlags)}
+	}
+	notes := []string{}
+
+	proxyType := strings.ToLower(r.values["proxy_type"])
+	if proxy, ok := r.values["proxy"]; ok {
+		scheme, host, port, username, password := splitProxyURL(proxy)
+		if host == "" {
+			p.Proxy, p.ProxyType = "none", "none"
+		} else {
+			p.Proxy = joinHostPort(host, port)
+			if proxyType == "" {
+				proxyType = scheme
+			}
+		}
+		if username != "" || password != "" {
+			notes = append(notes, fmt.Sprintf("%s: dropped the proxy's login; the browser asks for it", name))
+		}
+	}
+	switch proxyType {
+	case "":
+	case "https":
+		p.ProxyType = "http"
+	case "socks":
+		p.ProxyType = "socks5"
+	default:
+		p.ProxyType = proxyType
+	}
+	if p.ProxyType == "none" {
+		p.Proxy = "none"
+	}
+
+	if flags, ok := r.values["flags"]; ok {
+		p.Flags = parseFlagText(flags)
+	}
+	if tags, ok := r.values["tags"]; ok {
+		p.Tags = strings.FieldsFunc(tags, func(c rune) bool { return c == ',' || c == ';' || c == '|' || c == ' ' })
+	}
+	if description, ok := r.values["description"]; ok {
+		p.Description = description
+	}
+	return p, notes
+}
+
+// importCSV creates or updates a profile per row of a sheet. Rows without
+// a name get prefix and their number among them, so a vendor's proxy list
+// makes one profile per proxy. Nothing is saved unless every row checks
+// out. It returns a line per change, or per profile with dryRun.
+func (cm *ChromiumManager) importCSV(file, prefix string, dryRun bool) ([]string, []string, error) {
+	sheet, err := readSheet(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := importRows(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profiles := map[string]Profile{}
+	notes := []string{}
+	unnamed := 0
+	for _, row := range rows {
+		name := row.values["name"]
+		if name == "" {
+			unnamed++
+			name = fmt.Sprintf("%s-%d", prefix, unnamed)
+		}
+		if _, repeated := profiles[name]; repeated {
+			return nil, nil, fmt.Errorf("row %d: profile '%s' is in the sheet twice", row.line, name)
+		}
+		existing, exists := cm.profiles[name]
+		profile, rowNotes := row.profile(name, existing, exists)
+		if profile, err = checkProfile(profile, cm.settings); err != nil {
+			return nil, nil, fmt.Errorf("row %d (%s): %s", row.line, name, err)
+		}
+		profiles[name] = profile
+		notes = append(notes, rowNotes...)
+	}
+
+	if dryRun {
+		changes := []string{}
+		for _, name := range sortedProfileNames(profiles) {
+			existing, exists := cm.profiles[name]
+			switch {
+			case !exists:
+				changes = append(changes, "add "+name)
+			case !sameDefinition(existing, profiles[name]):
+				changes = append(changes, "update "+name)
+			}
+		}
+		return changes, notes, nil
+	}
+	overwrite := func(string) (string, error) { return "overwrite", nil }
+	changes, err := cm.addImported(profiles, importMerge, overwrite)
+	for i, change := range changes {
+		if name, ok := strings.CutPrefix(change, "overwrote "); ok {
+			changes[i] = "updated " + name
+		}
+	}
+	return changes, notes, err
+}
+
+// Parts of an .xlsx workbook import-csv reads
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	xlsxText struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// String joins a rich text's runs
+func (t xlsxText) String() string {
+	s := t.Text
+	for _, run := range t.Runs {
+		s += run.Text
+	}
+	return s
+}
+
+// readXLSX reads the cells of the first sheet of a workbook as text
+func readXLSX(file string) ([][]string, error) {
+	data, err := fsys.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s is missing from the workbook", name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		return xml.NewDecoder(r).Decode(v)
+	}
+
+	// The first sheet in the workbook's order, not the first file
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if decode("xl/workbook.xml", &workbook) == nil && len(workbook.Sheets) > 0 && decode("xl/_rels/workbook.xml.rels", &rels) == nil {
+		for _, rel := range rels.Relationships {
+			// Targets are relative to xl/, or absolute within the archive
+			if rel.ID == workbook.Sheets[0].ID && strings.HasPrefix(rel.Target, "/") {
+				sheetPath = strings.TrimPrefix(rel.Target, "/")
+			} else if rel.ID == workbook.Sheets[0].ID {
+				sheetPath = path.Join("xl", rel.Target)
+			}
+		}
+	}
+	var shared xlsxSharedStrings
+	if err := decode("xl/sharedStrings.xml", &shared); err != nil && files["xl/sharedStrings.xml"] != nil {
+		return nil, err
+	}
+	var sheet xlsxSheet
+	if err := decode(sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, r := range sheet.Rows {
+		row := []string{}
+		for i, c := range r.Cells {
+			col := i
+			if ref := xlsxColumn(c.Ref); ref >= 0 {
+				col = ref
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared.Items) {
+					row[col] = shared.Items[n].String()
+				}
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// xlsxColumn returns the column index of a cell reference such as "AB12",
+// or -1 without one
+func xlsxColumn(ref string) int {
+	col := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
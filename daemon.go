@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Default socket name, kept next to the config so separate configs get
+// separate daemons
+const daemonSocketName = "launchium.sock"
+
+// daemonRequest is one line a client sends: a JSON object naming the
+// method and, where needed, the profile
+type daemonRequest struct {
+	ID      json.RawMessage `json:"id,omitempty"` // Echoed back in the response
+	Method  string          `json:"method"`
+	Profile string          `json:"profile,omitempty"`
+	Force   bool            `json:"force,omitempty"` // Clean a protected profile
+}
+
+// daemonResponse answers one request
+type daemonResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	OK     bool            `json:"ok"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// daemonEvent is pushed to clients that asked for events
+type daemonEvent struct {
+	Event   string    `json:"event"` // launched, cleaned, started, stopped or reloaded
+	Profile string    `json:"profile,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// profileInfo describes a profile in the list result
+type profileInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Default     bool       `json:"default,omitempty"`
+	Running     bool       `json:"running"`
+	LastLaunch  *time.Time `json:"last_launch,omitempty"`
+}
+
+// instanceInfo describes a running browser in the status result
+type instanceInfo struct {
+	Profile       string `json:"profile"`
+	PID           int    `json:"pid,omitempty"`
+	DataDir       string `json:"data_dir"`
+	UptimeSeconds int64  `json:"uptime_seconds,omitempty"`
+	MemoryBytes   int64  `json:"memory_bytes,omitempty"`
+	DevToolsPort  int    `json:"devtools_port,omitempty"`
+}
+
+// info converts a running browser for the API
+func (inst instance) info() instanceInfo {
+	return instanceInfo{
+		Profile:       inst.name,
+		PID:           inst.pid,
+		DataDir:       inst.dataDir,
+		UptimeSeconds: int64(inst.uptime.Seconds()),
+		MemoryBytes:   inst.memory,
+		DevToolsPort:  inst.devToolsPort,
+	}
+}
+
+// daemon serves the control API. Requests are handled one at a time
+// since the manager isn't safe for concurrent use.
+type daemon struct {
+	cm      *ChromiumManager
+	mu      sync.Mutex // Guards cm
+	subsMu  sync.Mutex
+	subs    map[chan daemonEvent]bool
+	running map[string]bool // Profiles seen running at the last poll
+}
+
+// daemonSocketPath returns the socket to listen on: path if given, else
+// the default next to the config
+func (cm *ChromiumManager) daemonSocketPath(path string) string {
+	if path != "" {
+		return expandPath(path)
+	}
+	return filepath.Join(filepath.Dir(cm.configFile), daemonSocketName)
+}
+
+// runDaemon listens on the socket until interrupted
+func (cm *ChromiumManager) runDaemon(socketPath string) error {
+	// A socket nobody answers on is left over from a daemon that died
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("a daemon is already listening on %s", socketPath)
+	}
+	os.Remove(socketPath)
+
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		return err
+	}
+
+	d := &daemon{cm: cm, subs: map[chan daemonEvent]bool{}}
+	d.running = d.runningNames()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		listener.Close()
+	}()
+	go d.watchRunning()
+	if changes, stopWatch, err := cm.watchConfig(); err != nil {
+		fmt.Printf("Not watching the config for changes: %s\n", err)
+	} else {
+		defer stopWatch()
+		go d.watchConfig(changes)
+	}
+
+	fmt.Printf("Listening on %s\n", socketPath)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			break
+		}
+		go d.serve(conn)
+	}
+	os.Remove(socketPath)
+	return nil
+}
+
+// listenPrivate listens on a socket only its owner can connect to. It is
+// bound in a new 0700 directory and made 0600 before being moved into
+// place, so it is never reachable with looser permissions.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".sock")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	bound := filepath.Join(dir, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: bound, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// The socket outlives the directory it was bound in
+	listener.SetUnlinkOnClose(false)
+	if err := os.Chmod(bound, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("restricting %s: %w", socketPath, err)
+	}
+	if err := os.Rename(bound, socketPath); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// serve answers requests from one client until it disconnects
+func (d *daemon) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// Responses and events share the connection
+	var writeMu sync.Mutex
+	enc := json.NewEncoder(conn)
+	send := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return enc.Encode(v)
+	}
+
+	var events chan daemonEvent
+	defer func() {
+		if events != nil {
+			d.unsubscribe(events)
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req daemonRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			send(daemonResponse{Error: fmt.Sprintf("invalid request: %s", err)})
+			continue
+		}
+
+		if req.Method == "events" {
+			if events == nil {
+				events = d.subscribe()
+				go func(events chan daemonEvent) {
+					for event := range events {
+						if send(event) != nil {
+							return
+						}
+					}
+				}(events)
+			}
+			send(daemonResponse{ID: req.ID, OK: true})
+			continue
+		}
+
+		result, err := d.handle(req)
+		resp := daemonResponse{ID: req.ID, OK: err == nil, Result: result}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if send(resp) != nil {
+			return
+		}
+	}
+}
+
+// handle runs one request against the manager
+func (d *daemon) handle(req daemonRequest) (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cm := d.cm
+	ctx, cancel := context.WithTimeout(context.Background(), apiRequestTimeout)
+	defer cancel()
+
+	if err := cm.reloadProfiles(ctx); err != nil {
+		return nil, err
+	}
+
+	switch req.Method {
+	case "list":
+		running := d.runningNames()
+		profiles := []profileInfo{}
+		for _, name := range sortedProfileNames(cm.profiles) {
+			profile := cm.profiles[name]
+			info := profileInfo{
+				Name:        name,
+				Description: profile.Description,
+				Tags:        profile.Tags,
+				Default:     name == cm.settings.DefaultProfile,
+				Running:     running[name],
+			}
+			if t, ok := cm.state.LastLaunch[name]; ok {
+				info.LastLaunch = &t
+			}
+			profiles = append(profiles, info)
+		}
+		return profiles, nil
+
+	case "status":
+		instances := []instanceInfo{}
+		for _, inst := range findInstances(cm.runningDirs()) {
+			if req.Profile != "" && inst.name != req.Profile {
+				continue
+			}
+			instances = append(instances, inst.info())
+		}
+		return instances, nil
+
+	case "launch":
+		name := req.Profile
+		if name == "" {
+			name = cm.defaultProfile()
+		}
+		message, err := cm.launchBrowser(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		d.publish("launched", name)
+		return message, nil
+
+	case "clean":
+		if req.Profile == "" {
+			return nil, fmt.Errorf("clean needs a profile")
+		}
+		if cm.profiles[req.Profile].Protected && !req.Force {
+			return nil, errProtected(req.Profile, "clean", `add "force": true`)
+		}
+		if err := cm.cleanProfile(ctx, req.Profile, nil); err != nil {
+			return nil, err
+		}
+		d.publish("cleaned", req.Profile)
+		return fmt.Sprintf("Profile '%s' completely cleared and reset", req.Profile), nil
+	}
+	return nil, fmt.Errorf("unknown method %q (expected list, launch, clean, status or events)", req.Method)
+}
+
+// runningNames returns the profiles with a browser running
+func (d *daemon) runningNames() map[string]bool {
+	running := map[string]bool{}
+	for _, inst := range findInstances(d.cm.runningDirs()) {
+		running[inst.name] = true
+	}
+	return running
+}
+
+// watchRunning polls for browsers starting and exiting, including ones not
+// launched through the daemon, and reports them as events
+func (d *daemon) watchRunning() {
+	for range time.Tick(runningRefresh) {
+		d.mu.Lock()
+		now := d.runningNames()
+		before := d.running
+		d.running = now
+		d.mu.Unlock()
+
+		for name := range now {
+			if !before[name] {
+				d.publish("started", name)
+			}
+		}
+		for name := range before {
+			if !now[name] {
+				d.publish("stopped", name)
+			}
+		}
+	}
+}
+
+// watchConfig reloads the profiles as soon as the config changes on disk,
+// rather than at the next request, and tells subscribers
+func (d *daemon) watchConfig(changes <-chan struct{}) {
+	for range changes {
+		d.mu.Lock()
+		changed := d.cm.configChanged()
+		var err error
+		if changed {
+			ctx, cancel := context.WithTimeout(context.Background(), apiRequestTimeout)
+			err = d.cm.reloadProfiles(ctx)
+			cancel()
+		}
+		count := len(d.cm.profiles)
+		d.mu.Unlock()
+
+		switch {
+		case err != nil:
+			fmt.Printf("Config changed but didn't load: %s\n", err)
+		case changed:
+			fmt.Printf("Config changed, reloaded %d profiles\n", count)
+			d.publish("reloaded", "")
+		}
+	}
+}
+
+// subscribe registers a client for events
+func (d *daemon) subscribe() chan daemonEvent {
+	events := make(chan daemonEvent, 16)
+	d.subsMu.Lock()
+	d.subs[events] = true
+	d.subsMu.Unlock()
+	return events
+}
+
+// unsubscribe drops a client's event channel
+func (d *daemon) unsubscribe(events chan daemonEvent) {
+	d.subsMu.Lock()
+	delete(d.subs, events)
+	d.subsMu.Unlock()
+	close(events)
+}
+
+// publish sends an event to every subscriber. Slow clients miss events
+// rather than holding up the daemon.
+func (d *daemon) publish(event, profile string) {
+	e := daemonEvent{Event: event, Profile: profile, Time: time.Now()}
+	d.subsMu.Lock()
+	defer d.subsMu.Unlock()
+	for events := range d.subs {
+		select {
+		case events <- e:
+		default:
+		}
+	}
+}
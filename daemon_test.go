@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestListenPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket permissions")
+	}
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, daemonSocketName)
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("left %d entries next to the socket, want only the socket", len(entries))
+	}
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("dialing the moved socket: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profiles not launched for this long count as unused on the dashboard
+const unusedAfter = 30 * 24 * time.Hour
+
+// How long the dashboard waits for a proxy to accept a connection
+const proxyProbeTimeout = 3 * time.Second
+
+// Rows of the dashboard, in the order they are shown
+const (
+	healthProfiles = "Profiles"
+	healthDisk     = "Disk Usage"
+	healthRunning  = "Running"
+	healthProxies  = "Unreachable Proxies"
+	healthUnused   = "Unused"
+	healthGC       = "Pending Cleans"
+)
+
+// healthMsg carries the result of the dashboard's background checks. gen
+// tells checks for an earlier visit to the view apart.
+type healthMsg struct {
+	gen         int
+	instances   []instance
+	unreachable map[string]string // Why each profile's proxy didn't answer
+}
+
+// proxyAddr returns the host:port of a profile's proxy, without the
+// scheme a -proxy style address may carry, and false for no proxy
+func proxyAddr(proxy string) (string, bool) {
+	if proxy == "" || proxy == "none" {
+		return "", false
+	}
+	if i := strings.Index(proxy, "://"); i >= 0 {
+		proxy = proxy[i+3:]
+	}
+	return strings.TrimSuffix(proxy, "/"), true
+}
+
+// probeProxies tries to connect to each proxy, all at once, and returns
+// why the ones that failed did
+func probeProxies(ctx context.Context, proxies map[string]string) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, proxyProbeTimeout)
+	defer cancel()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed := map[string]string{}
+	for name, addr := range proxies {
+		wg.Add(1)
+		go func(name, addr string) {
+			defer wg.Done()
+			conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err == nil {
+				conn.Close()
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			failed[name] = err.Error()
+		}(name, addr)
+	}
+	wg.Wait()
+	return failed
+}
+
+// checkHealth looks for running browsers and unreachable proxies in the
+// background, and starts measuring the profiles without a known size
+func (cm *ChromiumManager) checkHealth() tea.Cmd {
+	dirs := cm.runningDirs()
+	proxies := map[string]string{}
+	for name, profile := range cm.profiles {
+		if addr, ok := proxyAddr(profile.Proxy); ok {
+			proxies[name] = addr
+		}
+	}
+	gen, ctx := cm.healthGen, cm.ctx
+	cmds := []tea.Cmd{func() tea.Msg {
+		return healthMsg{gen: gen, instances: findInstances(dirs), unreachable: probeProxies(ctx, proxies)}
+	}}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		cmds = append(cmds, cm.requestSize(name))
+	}
+	return tea.Batch(cmds...)
+}
+
+// openDashboard shows the health summary and starts the checks behind it
+func (cm *ChromiumManager) openDashboard() tea.Cmd {
+	cm.healthGen++
+	cm.health = nil
+	width, height := cm.contentSize()
+	cm.dashboardList = list.New(nil, cm.listDelegate(2), width, height)
+	cm.dashboardList.Title = "Health Dashboard"
+	cm.dashboardList.SetShowStatusBar(false)
+	cm.dashboardList.SetFilteringEnabled(false)
+	cm.keys.configureList(&cm.dashboardList)
+	styleList(&cm.dashboardList)
+	cm.currentView = "dashboard"
+	cm.setDashboardItems()
+	return cm.checkHealth()
+}
+
+// unusedProfiles returns the profiles launched before, but not within
+// unusedAfter. Archived profiles have already been dealt with.
+func (cm *ChromiumManager) unusedProfiles(now time.Time) []string {
+	names := []string{}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		last, ok := cm.state.LastLaunch[name]
+		if ok && now.Sub(last) > unusedAfter && !cm.isArchived(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// unreachableProfiles returns the profiles whose proxy the last check
+// couldn't reach
+func (cm *ChromiumManager) unreachableProfiles() []string {
+	names := []string{}
+	if cm.health == nil {
+		return names
+	}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		if _, failed := cm.health.unreachable[name]; failed {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// listNames joins names for a dashboard row, cutting long lists short
+func listNames(names []string) string {
+	const shown = 3
+	if len(names) <= shown {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:shown], ", "), len(names)-shown)
+}
+
+// setDashboardItems fills the dashboard's rows from what is known so far,
+// keeping the cursor in place
+func (cm *ChromiumManager) setDashboardItems() {
+	k := cm.keys
+	rows := []item{}
+
+	archived := 0
+	for name := range cm.profiles {
+		if cm.isArchived(name) {
+			archived++
+		}
+	}
+	desc := fmt.Sprintf("%d profiles", len(cm.profiles))
+	if archived > 0 {
+		desc += fmt.Sprintf(", %d archived", archived)
+	}
+	rows = append(rows, item{title: healthProfiles, desc: desc})
+
+	var total int64
+	measuring := 0
+	for name := range cm.profiles {
+		if cm.sizing[name] {
+			measuring++
+		}
+		total += cm.sizes[name]
+	}
+	desc = formatBytes(total) + " in data dirs"
+	if measuring > 0 {
+		desc += fmt.Sprintf(" (measuring %d profiles)", measuring)
+	}
+	rows = append(rows, item{title: healthDisk, desc: desc})
+
+	running, proxies := "checking...", "checking..."
+	if cm.health != nil {
+		running = fmt.Sprintf("%d browsers", len(cm.health.instances))
+		if names := cm.unreachableProfiles(); len(names) > 0 {
+			proxies = fmt.Sprintf("%s; %s to fix", listNames(names), k.FixProxy.Help().Key)
+		} else {
+			proxies = "none"
+		}
+	}
+	rows = append(rows, item{title: healthRunning, desc: running})
+	rows = append(rows, item{title: healthProxies, desc: proxies})
+
+	desc = "none"
+	if names := cm.unusedProfiles(time.Now()); len(names) > 0 {
+		desc = fmt.Sprintf("%s not launched in 30 days; %s to clean", listNames(names), k.Unused.Help().Key)
+	}
+	rows = append(rows, item{title: healthUnused, desc: desc})
+
+	desc = "none due"
+	if names := cm.dueCleans(time.Now()); len(names) > 0 {
+		desc = fmt.Sprintf("%s due; %s to run", listNames(names), k.RunGC.Help().Key)
+	}
+	rows = append(rows, item{title: healthGC, desc: desc})
+
+	items := make([]list.Item, len(rows))
+	for i, row := range rows {
+		items[i] = row
+	}
+	cm.dashboardList.SetItems(items)
+}
+
+// finishHealthCheck shows the result of the dashboard's checks
+func (cm *ChromiumManager) finishHealthCheck(msg healthMsg) {
+	if msg.gen != cm.healthGen || cm.currentView != "dashboard" {
+		return
+	}
+	cm.health = &msg
+	cm.setDashboardItems()
+}
+
+// fixProxies opens the form of the first profile with an unreachable
+// proxy, naming any others still to fix
+func (cm *ChromiumManager) fixProxies() tea.Cmd {
+	names := cm.unreachableProfiles()
+	if len(names) == 0 {
+		cm.notify(levelInfo, "No unreachable proxies")
+		return nil
+	}
+	name := names[0]
+	others := ""
+	if len(names) > 1 {
+		others = "; also unreachable: " + strings.Join(names[1:], ", ")
+	}
+	cm.notify(levelWarn, "Proxy of '%s' unreachable: %s%s", name, cm.health.unreachable[name], others)
+	return cm.openProfileForm(name)
+}
+
+// cleanUnused opens the clean picker with the unused profiles marked
+func (cm *ChromiumManager) cleanUnused() {
+	names := cm.unusedProfiles(time.Now())
+	if len(names) == 0 {
+		cm.notify(levelInfo, "No profiles unused for 30 days")
+		return
+	}
+	cm.updateProfileList()
+	cm.currentView = "select_clean"
+	for index, li := range cm.profileList.Items() {
+		if i := li.(item); containsString(names, i.title) {
+			i.marked = true
+			cm.profileList.SetItem(index, i)
+		}
+	}
+}
+
+// runGC runs the due scheduled cleans, as `launchium gc` would
+func (cm *ChromiumManager) runGC() tea.Cmd {
+	names := cm.dueCleans(time.Now())
+	if len(names) == 0 {
+		cm.notify(levelInfo, "No scheduled cleans are due")
+		return nil
+	}
+	return cm.startBulk("gc", names)
+}
+
+// gcProfile runs one profile's scheduled clean for a bulk gc. A running
+// browser holds its files, so it is left for the next time.
+func (cm *ChromiumManager) gcProfile(profile Profile) tea.Cmd {
+	name := profile.Name
+	schedule, err := parseCleanSchedule(profile.CleanSchedule)
+	path := cm.profilePath(profile)
+	return func() tea.Msg {
+		if err != nil {
+			return bulkResultMsg{name: name, err: err}
+		}
+		if _, running := runningPID(path); running {
+			return bulkResultMsg{name: name, err: fmt.Errorf("browser is running")}
+		}
+		started := time.Now()
+		if _, err := fsys.Stat(path); err == nil {
+			if schedule.scope == cleanScopeAll {
+				err = cleanDataDir(cm.ctx, path, profile.CleanKeep)
+			} else {
+				err = cleanCaches(path)
+			}
+			return bulkResultMsg{name: name, took: time.Since(started), err: err}
+		}
+		return bulkResultMsg{name: name}
+	}
+}
+
+// updateDashboard handles keys in the dashboard. Enter on a row jumps to
+// where it is dealt with.
+func (cm *ChromiumManager) updateDashboard(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, cm.keys.Refresh):
+		cm.healthGen++
+		cm.health = nil
+		for name := range cm.profiles {
+			delete(cm.sizes, name)
+		}
+		cm.setDashboardItems()
+		return cm.checkHealth()
+	case key.Matches(msg, cm.keys.FixProxy):
+		return cm.fixProxies()
+	case key.Matches(msg, cm.keys.Unused):
+		cm.cleanUnused()
+		return nil
+	case key.Matches(msg, cm.keys.RunGC):
+		return cm.runGC()
+	case key.Matches(msg, cm.keys.Select):
+		i, ok := cm.dashboardList.SelectedItem().(item)
+		if !ok {
+			return nil
+		}
+		switch i.title {
+		case healthProfiles:
+			cm.updateManageList()
+			cm.currentView = "manage"
+		case healthDisk:
+			cm.updateProfileList()
+			cm.currentView = "select_clean"
+		case healthRunning:
+			return cm.openRunningView()
+		case healthProxies:
+			return cm.fixProxies()
+		case healthUnused:
+			cm.cleanUnused()
+		case healthGC:
+			return cm.runGC()
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	cm.dashboardList, cmd = cm.dashboardList.Update(msg)
+	return cmd
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProxyAddr(t *testing.T) {
+	tests := []struct {
+		proxy, addr string
+		ok          bool
+	}{
+		{"", "", false},
+		{"none", "", false},
+		{"127.0.0.1:8080", "127.0.0.1:8080", true},
+		{"socks5://proxy.example.com:1080/", "proxy.example.com:1080", true},
+	}
+	for _, tt := range tests {
+		if addr, ok := proxyAddr(tt.proxy); addr != tt.addr || ok != tt.ok {
+			t.Errorf("proxyAddr(%q) = %q, %v; want %q, %v", tt.proxy, addr, ok, tt.addr, tt.ok)
+		}
+	}
+}
+
+func TestProbeProxies(t *testing.T) {
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	downAddr := down.Addr().String()
+	down.Close()
+
+	failed := probeProxies(context.Background(), map[string]string{"work": up.Addr().String(), "home": downAddr})
+	if _, ok := failed["work"]; ok {
+		t.Errorf("a listening proxy was reported unreachable: %s", failed["work"])
+	}
+	if _, ok := failed["home"]; !ok || len(failed) != 1 {
+		t.Errorf("failed = %v, want only home", failed)
+	}
+
+	// Quitting abandons probes still waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if failed := probeProxies(ctx, map[string]string{"work": up.Addr().String()}); len(failed) != 1 {
+		t.Errorf("failed = %v after cancel, want work", failed)
+	}
+}
+
+func TestUnusedProfiles(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cm := &ChromiumManager{profiles: map[string]Profile{
+		"work": {Name: "work"}, "home": {Name: "home"}, "old": {Name: "old"}, "new": {Name: "new"},
+	}}
+	cm.state.LastLaunch = map[string]time.Time{
+		"work": now.Add(-time.Hour),
+		"home": now.Add(-2 * unusedAfter),
+		"old":  now.Add(-2 * unusedAfter),
+	}
+	cm.state.Archived = map[string]time.Time{"old": now}
+	if got := cm.unusedProfiles(now); !reflect.DeepEqual(got, []string{"home"}) {
+		t.Errorf("unusedProfiles = %q, want [home]", got)
+	}
+
+	cm.health = &healthMsg{unreachable: map[string]string{"work": "connection refused"}}
+	if got := cm.unreachableProfiles(); !reflect.DeepEqual(got, []string{"work"}) {
+		t.Errorf("unreachableProfiles = %q, want [work]", got)
+	}
+}
+
+func TestListNames(t *testing.T) {
+	if got := listNames([]string{"a", "b", "c"}); got != "a, b, c" {
+		t.Errorf("listNames = %q", got)
+	}
+	if got := listNames([]string{"a", "b", "c", "d", "e"}); got != "a, b, c and 2 more" {
+		t.Errorf("listNames = %q", got)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the profile detail pane, built from the theme by setStyles
+var (
+	detailStyle      lipgloss.Style
+	detailLabelStyle lipgloss.Style
+)
+
+// Minimum terminal width for showing the detail pane beside the list
+const detailMinWidth = 90
+
+// sizeMsg carries the result of a background disk usage scan
+type sizeMsg struct {
+	name    string
+	size    int64
+	scanned time.Time // When the scan started
+	err     error
+}
+
+// formatBytes renders a byte count in human units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatAgo renders how long ago t was in coarse units
+func formatAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d h ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	}
+}
+
+// requestSize starts a background size scan for the profile unless a
+// result is cached or a scan is already running
+func (cm *ChromiumManager) requestSize(name string) tea.Cmd {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		return nil
+	}
+	if _, cached := cm.sizes[name]; cached || cm.sizing[name] || cm.isArchived(name) {
+		return nil
+	}
+	if size, ok := cm.cachedSize(name); ok {
+		cm.sizes[name] = size
+		return nil
+	}
+	cm.sizing[name] = true
+
+	path := cm.profilePath(profile)
+	return tea.Batch(cm.startSpinner(), func() tea.Msg {
+		scanned := time.Now()
+		size, err := dirSize(cm.ctx, path)
+		return sizeMsg{name: name, size: size, scanned: scanned, err: err}
+	})
+}
+
+// invalidateSize drops the cached size after the profile's data changed
+func (cm *ChromiumManager) invalidateSize(name string) {
+	delete(cm.sizes, name)
+	if _, ok := cm.state.Sizes[name]; ok {
+		delete(cm.state.Sizes, name)
+		cm.saveState()
+	}
+}
+
+// requestSelectedSize scans the profile under the list cursor
+func (cm *ChromiumManager) requestSelectedSize() tea.Cmd {
+	if !strings.HasPrefix(cm.currentView, "select_") {
+		return nil
+	}
+	if i, ok := cm.profileList.SelectedItem().(item); ok {
+		return cm.requestSize(i.title)
+	}
+	return nil
+}
+
+// showDetailPane reports whether the terminal is wide enough for the
+// detail pane beside the profile list
+func (cm *ChromiumManager) showDetailPane() bool {
+	return cm.width >= detailMinWidth
+}
+
+// detailSize is the space beside the profile list for the detail pane
+func (cm *ChromiumManager) detailSize() (int, int) {
+	width, height := cm.contentSize()
+	listWidth, _ := cm.profileListSize()
+	// Leave a gap between the list and the pane
+	return width - listWidth - 2, height
+}
+
+// profileDetail renders the detail pane for the named profile
+func (cm *ChromiumManager) profileDetail(name string) string {
+	rows, flags, visible, offset := cm.detailLayout(name)
+	if rows == nil {
+		return ""
+	}
+	width, _ := cm.detailSize()
+
+	rows = append(rows, "", detailLabelStyle.Render("Flags:"))
+	if len(flags) == 0 {
+		rows = append(rows, "(none)")
+	}
+	rows = append(rows, flags[offset:offset+visible]...)
+	if hidden := len(flags) - visible; hidden > 0 {
+		rows = append(rows, detailLabelStyle.Render(fmt.Sprintf("%d/%d, %s/%s to scroll",
+			offset+visible, len(flags), cm.keys.ScrollDown.Help().Key, cm.keys.ScrollUp.Help().Key)))
+	}
+
+	return detailStyle.Width(width - detailStyle.GetHorizontalBorderSize()).Render(strings.Join(rows, "\n"))
+}
+
+// detailLayout renders the rows of the detail pane above the flags and
+// works out which flags fit below them. Flags are shown one per line,
+// starting at the clamped scroll offset. rows is nil for unknown profiles.
+func (cm *ChromiumManager) detailLayout(name string) (rows, flags []string, visible, offset int) {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		return nil, nil, 0, 0
+	}
+	width, height := cm.detailSize()
+
+	inner := width - detailStyle.GetHorizontalFrameSize()
+	wrap := lipgloss.NewStyle().Width(inner)
+	row := func(label, value string) string {
+		return wrap.Render(detailLabelStyle.Render(label+": ") + value)
+	}
+
+	rows = []string{lipgloss.NewStyle().Bold(true).Render(strings.TrimSpace(profile.label() + " " + profile.Name))}
+	if profile.Description != "" {
+		rows = append(rows, wrap.Render(profile.Description))
+	}
+	rows = append(rows, "")
+
+	proxy := "none"
+	if profile.Proxy != "none" && profile.Proxy != "" {
+		proxy = profile.Proxy
+		if profile.ProxyType != "none" && profile.ProxyType != "" {
+			proxy = profile.ProxyType + "://" + proxy
+		}
+	}
+	rows = append(rows, row("Proxy", proxy))
+	if profile.Slot != 0 {
+		rows = append(rows, row("Slot", fmt.Sprintf("%d (launchium %d)", profile.Slot, profile.Slot)))
+	}
+	if profile.SearchEngine != "" {
+		rows = append(rows, row("Search", profile.SearchEngine))
+	}
+	if profile.Homepage != "" {
+		rows = append(rows, row("Homepage", profile.Homepage))
+	}
+	if len(profile.Sessions) > 0 {
+		rows = append(rows, row("Sessions", strings.Join(profile.Sessions.names(), ", ")))
+	}
+	if profile.FirstRun != "" {
+		rows = append(rows, row("First Run", profile.FirstRun))
+	}
+	if profile.Locale != "" {
+		rows = append(rows, row("Locale", profile.Locale))
+	}
+	if len(profile.SpellcheckLanguages) > 0 {
+		rows = append(rows, row("Spellcheck", strings.Join(profile.SpellcheckLanguages, ", ")))
+	}
+	if len(profile.CACerts) > 0 {
+		rows = append(rows, row("CAs", strings.Join(profile.CACerts, ", ")))
+	}
+	if len(profile.AutoSelectCerts) > 0 {
+		patterns := []string{}
+		for _, entry := range profile.AutoSelectCerts {
+			if rule, err := parseAutoSelectRule(entry); err == nil {
+				patterns = append(patterns, rule.Pattern)
+			}
+		}
+		rows = append(rows, row("Client Certs", strings.Join(patterns, ", ")))
+	}
+	if profile.WebRTCPolicy != webRTCUnchanged {
+		rows = append(rows, row("WebRTC", profile.WebRTCPolicy))
+	}
+	if store := savedPasswordStore(cm.profilePath(profile)); profile.PasswordStore != passwordStoreUnchanged || store != "" {
+		rows = append(rows, row("Passwords", passwordStoreSummary(profile.PasswordStore, store)))
+	}
+	if privacy := profile.privacySummary(); privacy != "" {
+		rows = append(rows, row("Privacy", privacy))
+	}
+	if len(profile.Permissions) > 0 {
+		rows = append(rows, row("Permissions", strings.Join(profile.Permissions, ", ")))
+	}
+
+	path := cm.profilePath(profile)
+	rows = append(rows, row("Data dir", path))
+	if profile.RAMDisk != ramDiskOff {
+		rows = append(rows, row("RAM disk", profile.RAMDisk))
+	}
+
+	size := cm.spinner.View() + " calculating..."
+	if cm.isArchived(name) {
+		size = "archived " + formatAgo(cm.state.Archived[name])
+		if info, err := os.Stat(cm.archivePath(profile)); err == nil {
+			size += ", " + formatBytes(info.Size()) + " packed"
+		}
+	} else if s, ok := cm.sizes[name]; ok {
+		size = formatBytes(s)
+	} else if _, err := os.Stat(path); os.IsNotExist(err) {
+		size = "no data yet"
+	}
+	rows = append(rows, row("Disk usage", size))
+
+	status := "not running"
+	if pid, running := runningPID(path); running {
+		status = "running"
+		if pid > 0 {
+			status = fmt.Sprintf("running (PID %d)", pid)
+		}
+	}
+	rows = append(rows, row("Status", status))
+
+	lastLaunch := "never"
+	if t, ok := cm.state.LastLaunch[name]; ok {
+		lastLaunch = fmt.Sprintf("%s (%s)", t.Format("2006-01-02 15:04"), formatAgo(t))
+	}
+	rows = append(rows, row("Last launch", lastLaunch))
+
+	if crashes := cm.state.Crashes[name]; len(crashes) > 0 {
+		rows = append(rows, row("Crashes", fmt.Sprintf("%d, last %s", len(crashes), formatAgo(crashes[len(crashes)-1].Time))))
+	}
+
+	if schedule := cm.scheduleSummary(name); schedule != "" {
+		rows = append(rows, row("Auto clean", schedule))
+	}
+	if len(profile.CleanKeep) > 0 {
+		rows = append(rows, row("Clean keeps", strings.Join(profile.CleanKeep, ", ")))
+	}
+
+	if profile.Notify != notifyDefault {
+		rows = append(rows, row("Notify", profile.Notify))
+	}
+
+	if profile.Channel != channelAny {
+		rows = append(rows, row("Channel", profile.Channel))
+	}
+
+	if profile.Fallback {
+		rows = append(rows, row("Fallback", "on"))
+		if browser, ok := cm.state.LastBrowser[name]; ok {
+			rows = append(rows, row("Last browser", browser))
+		}
+	}
+
+	if profile.Singleton {
+		rows = append(rows, row("Singleton", "on"))
+	}
+
+	if profile.Protected {
+		rows = append(rows, row("Protected", "on"))
+	}
+
+	if saved, ok := cm.goldenSaved(name); ok {
+		rows = append(rows, row("Golden image", "saved "+formatAgo(saved)))
+	}
+
+	if limits := profile.limitsSummary(); limits != "" {
+		rows = append(rows, row("Limits", limits))
+	}
+
+	if profile.RunAs != "" {
+		rows = append(rows, row("Runs as", profile.RunAs))
+	}
+
+	if file, ok := cm.profileFiles[name]; ok {
+		rows = append(rows, row("Defined in", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))))
+	} else if _, ok := cm.projectProfiles[name]; ok {
+		rows = append(rows, row("Defined in", cm.projectFile))
+	}
+
+	if profile.IdleTimeout > 0 {
+		idle := fmt.Sprintf("close after %d min idle", profile.IdleTimeout)
+		if profile.IdleClean {
+			idle += ", then clean"
+		}
+		rows = append(rows, row("Idle", idle))
+	}
+
+	if len(profile.Tags) > 0 {
+		rows = append(rows, row("Tags", strings.Join(profile.Tags, ", ")))
+	}
+
+	if len(profile.Presets) > 0 {
+		rows = append(rows, row("Presets", strings.Join(profile.Presets, ", ")))
+	}
+
+	for _, flag := range profile.Flags {
+		// Long flags are cut rather than wrapped so each takes one row
+		line := flag.arg()
+		if flag.Note != "" {
+			line += detailLabelStyle.Render("  # " + flag.Note)
+		}
+		if !flag.Enabled {
+			line = detailLabelStyle.Render("off ") + detailLabelStyle.Strikethrough(true).Render(flag.arg())
+		}
+		flags = append(flags, lipgloss.NewStyle().MaxWidth(inner).Render(line))
+	}
+
+	// Room left after the rows above, the blank line and the Flags label
+	visible = height - detailStyle.GetVerticalFrameSize() - lipgloss.Height(strings.Join(rows, "\n")) - 2
+	if visible < len(flags) {
+		visible-- // for the scroll position line
+	}
+	visible = max(min(visible, len(flags)), min(len(flags), 1))
+	offset = min(cm.flagScroll, len(flags)-visible)
+	return rows, flags, visible, offset
+}
+
+// scrollFlags moves the flags in the detail pane by delta rows, keeping
+// the offset within the flags of the highlighted profile
+func (cm *ChromiumManager) scrollFlags(delta int) {
+	i, ok := cm.profileList.SelectedItem().(item)
+	if !ok || !cm.showDetailPane() {
+		cm.flagScroll = 0
+		return
+	}
+	_, flags, visible, _ := cm.detailLayout(i.title)
+	cm.flagScroll = max(min(cm.flagScroll+delta, len(flags)-visible), 0)
+}
+
+// profileListView renders the profile list with the detail pane beside it
+// when the terminal is wide enough, or just the description below it
+// otherwise
+func (cm *ChromiumManager) profileListView() string {
+	listView := cm.profileList.View()
+	i, ok := cm.profileList.SelectedItem().(item)
+	if !ok {
+		return listView
+	}
+
+	if cm.showDetailPane() {
+		return lipgloss.JoinHorizontal(lipgloss.Top, listView, "  ", cm.profileDetail(i.title))
+	}
+	if desc := cm.profiles[i.title].Description; desc != "" {
+		width, _ := cm.contentSize()
+		listView += "\n" + lipgloss.NewStyle().Width(width).Render(i.title+": "+desc) + "\n"
+	}
+	return listView
+}
+
+// Top-level entries listed in the clean confirmation before eliding
+const cleanPreviewEntries = 8
+
+// cleanConfirmView renders the confirmation shown before cleaning one or
+// more profiles, with their sizes and what will be removed
+func (cm *ChromiumManager) cleanConfirmView() string {
+	names := cm.bulkNames
+	if len(names) == 0 {
+		names = []string{cm.selected}
+	}
+
+	var s string
+	if len(names) == 1 {
+		s = fmt.Sprintf("Clean Profile\n\nThis removes all browsing data for profile '%s'.\n\n", names[0])
+	} else {
+		s = fmt.Sprintf("Clean Profiles\n\nThis removes all browsing data for %d profiles.\n\n", len(names))
+	}
+
+	var total int64
+	pending := false
+	for _, name := range names {
+		profile := cm.profiles[name]
+		path := cm.profilePath(profile)
+
+		size := "calculating..."
+		if n, ok := cm.sizes[name]; ok {
+			size = formatBytes(n)
+			total += n
+		} else if _, err := os.Stat(path); os.IsNotExist(err) {
+			size = "no data"
+		} else {
+			pending = true
+		}
+		s += fmt.Sprintf("  %s (%s)\n", name, size)
+		s += "    " + detailLabelStyle.Render(path) + "\n"
+
+		if _, running := runningPID(path); running {
+			s += "    " + errStyle.Render("Browser is running; close it first or the clean may fail") + "\n"
+		}
+		if len(profile.CleanKeep) > 0 {
+			s += "    Keeps " + strings.Join(profile.CleanKeep, ", ") + "\n"
+		}
+
+		// A single profile also lists what is inside the directory
+		if len(names) == 1 {
+			if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+				for i, e := range entries {
+					if i == cleanPreviewEntries {
+						s += fmt.Sprintf("      ... and %d more\n", len(entries)-i)
+						break
+					}
+					s += "      " + e.Name() + "\n"
+				}
+			}
+		}
+	}
+
+	if len(names) > 1 && !pending {
+		s += fmt.Sprintf("\nTotal: %s\n", formatBytes(total))
+	}
+	s += "\nProceed? " + cm.confirmPrompt()
+	return s
+}
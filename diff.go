@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// diffSection is one part of a profile comparison: each key with its
+// values for the two profiles
+type diffSection struct {
+	title string
+	a, b  map[string][]string
+}
+
+// seededPreferences returns every preference value launchium writes for
+// the profile, as JSON by dotted key
+func seededPreferences(profile Profile) map[string][]string {
+	seeded := map[string][]string{}
+	for _, values := range []map[string]interface{}{
+		labelPreferences(profile), startPreferences(profile), localePreferences(profile),
+		autoSelectPreferences(profile), webRTCPreferences(profile), privacyPreferences(profile),
+	} {
+		for key, value := range values {
+			data, _ := json.Marshal(value)
+			seeded[key] = []string{string(data)}
+		}
+	}
+	return seeded
+}
+
+// profileSettings returns a profile's config entries by key. The flags are
+// left to their own section.
+func profileSettings(profile Profile) map[string][]string {
+	settings := map[string][]string{}
+	for _, field := range profile.fields() {
+		if field.key != "flags" {
+			settings[field.key] = []string{field.value}
+		}
+	}
+	return settings
+}
+
+// profileLaunchFlags returns the switches a profile adds to the browser's
+// command line, those of its presets and settings included
+func profileLaunchFlags(profile Profile) map[string][]string {
+	flags := append(profile.localeFlags(), profile.webRTCFlags()...)
+	flags = append(flags, profile.privacyFlags()...)
+	flags = append(flags, profile.launchFlags()...)
+	return map[string][]string{"": composeFlags(flags)}
+}
+
+// installedExtensions returns the extensions in a data dir by ID, with
+// the name and version of each from its manifest
+func installedExtensions(dataDir string) map[string][]string {
+	extensions := map[string][]string{}
+	dirs, _ := filepath.Glob(filepath.Join(dataDir, "Default", "Extensions", "*", "*", "manifest.json"))
+	for _, manifestPath := range dirs {
+		id := filepath.Base(filepath.Dir(filepath.Dir(manifestPath)))
+		manifest := struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}{}
+		if data, err := fsys.ReadFile(manifestPath); err == nil {
+			json.Unmarshal(data, &manifest)
+		}
+		// Names starting with __MSG_ are looked up in the extension's
+		// locales; the ID tells them apart well enough
+		if strings.HasPrefix(manifest.Name, "__MSG_") {
+			manifest.Name = ""
+		}
+		extensions[id] = append(extensions[id], strings.TrimSpace(manifest.Name+" "+manifest.Version))
+	}
+	return extensions
+}
+
+// browserPreferences returns the current values in a data dir of the
+// preferences launchium seeds for either profile, which the browser or the
+// user may have changed since
+func browserPreferences(dataDir string, keys []string) map[string][]string {
+	values := map[string][]string{}
+	data, err := fsys.ReadFile(preferencesFile(dataDir))
+	if err != nil {
+		return values
+	}
+	prefs := map[string]interface{}{}
+	if decodePreferences(data, &prefs) != nil {
+		return values
+	}
+	for _, key := range keys {
+		var value interface{} = prefs
+		for _, part := range strings.Split(key, ".") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = m[part]
+		}
+		if value != nil {
+			encoded, _ := json.Marshal(value)
+			values[key] = []string{string(encoded)}
+		}
+	}
+	return values
+}
+
+// diffProfiles compares two profiles' settings, launch flags and the
+// preferences launchium seeds, and with data also what their data dirs
+// hold: the installed extensions and the seeded preferences as they are
+// now
+func (cm *ChromiumManager) diffProfiles(nameA, nameB string, data bool) ([]diffSection, error) {
+	a, ok := cm.profiles[nameA]
+	if !ok {
+		return nil, profileNotFound(nameA)
+	}
+	b, ok := cm.profiles[nameB]
+	if !ok {
+		return nil, profileNotFound(nameB)
+	}
+
+	sections := []diffSection{
+		{"Settings", profileSettings(a), profileSettings(b)},
+		{"Flags", profileLaunchFlags(a), profileLaunchFlags(b)},
+		{"Seeded preferences", seededPreferences(a), seededPreferences(b)},
+	}
+	if data {
+		keys := []string{}
+		for _, seeded := range []map[string][]string{seededPreferences(a), seededPreferences(b)} {
+			for key := range seeded {
+				keys = append(keys, key)
+			}
+		}
+		dirA, dirB := cm.profilePath(a), cm.profilePath(b)
+		sections = append(sections,
+			diffSection{"Extensions", installedExtensions(dirA), installedExtensions(dirB)},
+			diffSection{"Browser preferences", browserPreferences(dirA, keys), browserPreferences(dirB, keys)},
+		)
+	}
+	return sections, nil
+}
+
+// printProfileDiff writes the differences between two profiles, one
+// section after another, with the first profile's values marked - and
+// the second's +. It reports whether there were any.
+func printProfileDiff(w io.Writer, nameA, nameB string, sections []diffSection) bool {
+	differ := false
+	for _, section := range sections {
+		keys := []string{}
+		for key := range section.a {
+			keys = append(keys, key)
+		}
+		for key := range section.b {
+			if _, ok := section.a[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		lines := []string{}
+		for _, key := range keys {
+			onlyA := subtractStrings(section.a[key], section.b[key])
+			onlyB := subtractStrings(section.b[key], section.a[key])
+			if len(onlyA) == 0 && len(onlyB) == 0 {
+				continue
+			}
+			indent := "  "
+			if key != "" {
+				lines = append(lines, "  "+key)
+				indent = "    "
+			}
+			for _, value := range onlyA {
+				lines = append(lines, indent+"- "+value)
+			}
+			for _, value := range onlyB {
+				lines = append(lines, indent+"+ "+value)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if !differ {
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", nameA, nameB)
+			differ = true
+		}
+		fmt.Fprintf(w, "\n%s\n%s\n", section.title, strings.Join(lines, "\n"))
+	}
+	return differ
+}
+
+// subtractStrings returns the values of a that aren't in b, in order
+func subtractStrings(a, b []string) []string {
+	rest := []string{}
+	for _, value := range a {
+		if !containsString(b, value) {
+			rest = append(rest, value)
+		}
+	}
+	return rest
+}
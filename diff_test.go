@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubtractStrings(t *testing.T) {
+	got := subtractStrings([]string{"a", "b", "c"}, []string{"b"})
+	if !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("subtractStrings = %q, want [a c]", got)
+	}
+	if got := subtractStrings(nil, []string{"b"}); len(got) != 0 {
+		t.Errorf("subtractStrings of nothing = %q", got)
+	}
+}
+
+func TestPrintProfileDiff(t *testing.T) {
+	sections := []diffSection{
+		{"Settings", map[string][]string{"proxy": {"none"}, "color": {"red"}}, map[string][]string{"proxy": {"none"}, "color": {"blue"}, "icon": {"cat"}}},
+		{"Flags", map[string][]string{"": {"--incognito", "--no-pings"}}, map[string][]string{"": {"--no-pings"}}},
+		{"Extensions", map[string][]string{}, map[string][]string{}},
+	}
+	var out bytes.Buffer
+	if !printProfileDiff(&out, "work", "home", sections) {
+		t.Fatal("printProfileDiff found no differences")
+	}
+	want := `--- work
++++ home
+
+Settings
+  color
+    - red
+    + blue
+  icon
+    + cat
+
+Flags
+  - --incognito
+`
+	if out.String() != want {
+		t.Errorf("printProfileDiff wrote\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if printProfileDiff(&out, "work", "home", sections[2:]) || out.Len() != 0 {
+		t.Errorf("printProfileDiff of equal sections wrote %q", out.String())
+	}
+}
+
+func TestDiffProfiles(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		profiles: map[string]Profile{
+			"work": {Name: "work", Description: "Work", Flags: parseFlagList("--incognito")},
+			"home": {Name: "home", Description: "Home", Color: "#336699"},
+		},
+	}
+	m.MkdirAll("/profiles/home/Default", 0755)
+	m.WriteFile(preferencesFile("/profiles/home"), []byte(`{"extensions":{"theme":{"id":"abc"}}}`), 0644)
+
+	if _, err := cm.diffProfiles("work", "missing", false); err == nil {
+		t.Error("diffProfiles compared a profile that doesn't exist")
+	}
+	sections, err := cm.diffProfiles("work", "home", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, section := range sections {
+		titles = append(titles, section.title)
+	}
+	if strings.Join(titles, ",") != "Settings,Flags,Seeded preferences,Extensions,Browser preferences" {
+		t.Errorf("sections are %q", titles)
+	}
+	if got := sections[1].a[""]; !containsString(got, "--incognito") || containsString(sections[1].b[""], "--incognito") {
+		t.Errorf("flags are %q and %q", got, sections[1].b[""])
+	}
+	if got := sections[4].b["extensions.theme.id"]; !reflect.DeepEqual(got, []string{`"abc"`}) {
+		t.Errorf("home's browser preferences are %q", sections[4].b)
+	}
+}
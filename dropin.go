@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dropInDir is the directory of per-profile config files merged into the
+// config: profiles.d next to profiles.toml, or work.d next to work.toml
+func (cm *ChromiumManager) dropInDir() string {
+	base := filepath.Base(cm.configFile)
+	return filepath.Join(filepath.Dir(cm.configFile), strings.TrimSuffix(base, filepath.Ext(base))+".d")
+}
+
+// dropInFiles returns the *.toml files in the drop-in directory in name
+// order, skipping hidden ones such as editor backups
+func (cm *ChromiumManager) dropInFiles() []string {
+	paths, _ := filepath.Glob(filepath.Join(cm.dropInDir(), "*.toml"))
+	files := []string{}
+	for _, path := range paths {
+		if !strings.HasPrefix(filepath.Base(path), ".") {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// loadDropIns adds the profiles from the drop-in files to profiles and
+// remembers which file each came from. The files hold [profiles.<name>]
+// tables only, and a profile may be defined in one place. It returns what
+// the files had that this version doesn't know.
+func (cm *ChromiumManager) loadDropIns(profiles map[string]Profile) ([]string, error) {
+	cm.profileFiles = map[string]string{}
+	unknown := []string{}
+	for _, path := range cm.dropInFiles() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		more, settings, err := parseTables(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		if len(settings.fields()) > 0 || len(settings.Keys) > 0 || len(settings.Themes) > 0 || len(settings.Webhooks) > 0 {
+			return nil, fmt.Errorf("%s: only [profiles.<name>] tables belong in %s", path, filepath.Base(cm.dropInDir()))
+		}
+		for _, u := range settings.Unknown {
+			unknown = append(unknown, filepath.Base(path)+" "+u)
+		}
+		for name, profile := range more {
+			if _, ok := profiles[name]; ok {
+				where := cm.configFile
+				if other, ok := cm.profileFiles[name]; ok {
+					where = other
+				}
+				return nil, fmt.Errorf("%s: profile '%s' is already defined in %s", path, name, where)
+			}
+			profiles[name] = profile
+			cm.profileFiles[name] = path
+		}
+	}
+	return unknown, nil
+}
+
+// mainProfiles returns the profiles kept in the config file itself, leaving
+// out those from drop-in files and the project
+func (cm *ChromiumManager) mainProfiles() map[string]Profile {
+	if len(cm.profileFiles) == 0 && len(cm.projectProfiles) == 0 {
+		return cm.profiles
+	}
+	main := map[string]Profile{}
+	for name, profile := range cm.profiles {
+		_, dropIn := cm.profileFiles[name]
+		_, project := cm.projectProfiles[name]
+		if !dropIn && !project {
+			main[name] = profile
+		}
+	}
+	return main
+}
+
+// saveDropIns writes the profiles that came from drop-in files back to
+// their files, keeping what was written by hand in them. A file whose
+// profiles have all been deleted is removed.
+func (cm *ChromiumManager) saveDropIns() error {
+	byFile := map[string]map[string]Profile{}
+	for name, path := range cm.profileFiles {
+		if byFile[path] == nil {
+			byFile[path] = map[string]Profile{}
+		}
+		if profile, ok := cm.profiles[name]; ok {
+			byFile[path][name] = profile
+		} else {
+			delete(cm.profileFiles, name)
+		}
+	}
+
+	for path, profiles := range byFile {
+		if len(profiles) == 0 {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		original, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data := mergeConfig(original, configTables(profiles, Settings{}))
+		if bytes.Equal(data, original) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveDropIn keeps a renamed profile in the drop-in file it came from
+func (cm *ChromiumManager) moveDropIn(oldName, newName string) {
+	if path, ok := cm.profileFiles[oldName]; ok {
+		delete(cm.profileFiles, oldName)
+		cm.profileFiles[newName] = path
+	}
+}
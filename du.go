@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// duWorkers is how many directories a size scan reads at once, and how
+// many profiles `launchium du` scans at once
+const duWorkers = 8
+
+// diskUsage is the size of a profile's data dir as last scanned
+type diskUsage struct {
+	Bytes   int64     `json:"bytes"`
+	Scanned time.Time `json:"scanned"`
+}
+
+// dirSize returns the total size of the regular files below path, reading
+// several directories at once
+func dirSize(ctx context.Context, path string) (int64, error) {
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	fail := func(err error) {
+		errOnce.Do(func() { firstErr = err })
+	}
+	slots := make(chan struct{}, duWorkers)
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		defer wg.Done()
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			return
+		}
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			// Files vanish while a browser is running; skip them
+			if !os.IsNotExist(err) {
+				fail(err)
+			}
+			return
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				wg.Add(1)
+				go walk(filepath.Join(dir, e.Name()))
+				continue
+			}
+			if !e.Type().IsRegular() {
+				continue
+			}
+			if info, err := e.Info(); err == nil {
+				total.Add(info.Size())
+			}
+		}
+	}
+	wg.Add(1)
+	walk(path)
+	wg.Wait()
+	return total.Load(), firstErr
+}
+
+// cachedSize returns the size last scanned for the profile if nothing
+// can have changed it since: it hasn't been launched or cleaned since the
+// scan and isn't running now
+func (cm *ChromiumManager) cachedSize(name string) (int64, bool) {
+	usage, ok := cm.state.Sizes[name]
+	if !ok {
+		return 0, false
+	}
+	if usage.Scanned.Before(cm.state.LastLaunch[name]) || usage.Scanned.Before(cm.state.LastClean[name]) {
+		return 0, false
+	}
+	if _, running := runningPID(cm.profilePath(cm.profiles[name])); running {
+		return 0, false
+	}
+	return usage.Bytes, true
+}
+
+// rememberSize keeps a scanned size for later runs. Sizes of running
+// profiles go stale right away and are not kept.
+func (cm *ChromiumManager) rememberSize(name string, size int64, scanned time.Time) {
+	cm.sizes[name] = size
+	if _, running := runningPID(cm.profilePath(cm.profiles[name])); running {
+		return
+	}
+	cm.state.Sizes[name] = diskUsage{Bytes: size, Scanned: scanned}
+	cm.saveState()
+}
+
+// profileSize is one profile's line in `launchium du`
+type profileSize struct {
+	name     string
+	size     int64
+	cached   bool // Taken from the last scan rather than scanned now
+	noData   bool // Never launched, so there is no data dir yet
+	archived bool // size is that of the archive
+	err      error
+}
+
+// profileSizes returns the sizes of the named profiles, biggest first,
+// scanning those without a usable cached size several at a time. rescan
+// ignores the cache.
+func (cm *ChromiumManager) profileSizes(ctx context.Context, names []string, rescan bool) []profileSize {
+	sizes := make([]profileSize, len(names))
+	slots := make(chan struct{}, duWorkers)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		sizes[i].name = name
+		if cm.isArchived(name) {
+			sizes[i].archived = true
+			if info, err := fsys.Stat(cm.archivePath(cm.profiles[name])); err == nil {
+				sizes[i].size = info.Size()
+			}
+			continue
+		}
+		if size, ok := cm.cachedSize(name); ok && !rescan {
+			sizes[i].size, sizes[i].cached = size, true
+			continue
+		}
+		path := cm.profilePath(cm.profiles[name])
+		if _, err := fsys.Stat(path); os.IsNotExist(err) {
+			sizes[i].noData = true
+			continue
+		}
+		wg.Add(1)
+		go func(s *profileSize, path string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			s.size, s.err = dirSize(ctx, path)
+		}(&sizes[i], path)
+	}
+	wg.Wait()
+
+	// Scans share the state with each other, so they are kept afterwards
+	now := time.Now()
+	for _, s := range sizes {
+		if !s.cached && !s.noData && !s.archived && s.err == nil {
+			cm.rememberSize(s.name, s.size, now)
+		}
+	}
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].size > sizes[j].size })
+	return sizes
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirSize(t *testing.T) {
+	m, _ := useFakes(t)
+	if err := m.MkdirAll("/data/Default/Cache", 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.WriteFile("/data/Local State", []byte("12345"), 0644)
+	m.WriteFile("/data/Default/History", []byte("1234567890"), 0644)
+	m.WriteFile("/data/Default/Cache/data_0", []byte("123"), 0644)
+	m.Symlink("Default/History", "/data/Last")
+
+	size, err := dirSize(context.Background(), "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 18 {
+		t.Errorf("dirSize() = %d, want 18 without counting the link", size)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := dirSize(ctx, "/data"); err == nil {
+		t.Error("a canceled scan succeeded")
+	}
+}
+
+func TestProfileSizes(t *testing.T) {
+	m, _ := useFakes(t)
+	root := "/profiles"
+	cm := &ChromiumManager{
+		profileDir: root,
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles: map[string]Profile{
+			"big":    {Name: "big"},
+			"small":  {Name: "small"},
+			"cached": {Name: "cached"},
+			"new":    {Name: "new"},
+		},
+	}
+	cm.loadState()
+	for name, data := range map[string]string{"big": "0123456789", "small": "0"} {
+		m.MkdirAll(filepath.Join(root, name), 0755)
+		m.WriteFile(filepath.Join(root, name, "History"), []byte(data), 0644)
+	}
+	m.MkdirAll(filepath.Join(root, "cached"), 0755)
+	cm.state.LastLaunch["cached"] = time.Now().Add(-time.Hour)
+	cm.state.Sizes["cached"] = diskUsage{Bytes: 5, Scanned: time.Now()}
+
+	var got []string
+	for _, s := range cm.profileSizes(context.Background(), []string{"small", "new", "cached", "big"}, false) {
+		line := s.name
+		switch {
+		case s.noData:
+			line += " none"
+		case s.cached:
+			line += " cached"
+		}
+		got = append(got, line)
+		if s.name == "big" && s.size != 10 {
+			t.Errorf("big is %d bytes, want 10", s.size)
+		}
+	}
+	want := "big|cached cached|small|new none"
+	if strings.Join(got, "|") != want {
+		t.Errorf("sizes are %q, want %q", strings.Join(got, "|"), want)
+	}
+	if usage := cm.state.Sizes["big"]; usage.Bytes != 10 {
+		t.Errorf("scanned size wasn't remembered: %+v", usage)
+	}
+
+	// A launch since the scan makes the cached size stale
+	cm.state.LastLaunch["cached"] = time.Now().Add(time.Minute)
+	if _, ok := cm.cachedSize("cached"); ok {
+		t.Error("used a size scanned before the last launch")
+	}
+}
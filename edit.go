@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Formats `launchium edit -editor` can open a profile in
+const (
+	editTOML = "toml"
+	editYAML = "yaml"
+)
+
+// editHeader goes above the profile in the file the editor opens
+const editHeader = `# Editing profile '%s'. Save and close the editor to apply the changes;
+# renaming the profile renames it and its data directory. Empty the file
+# to cancel.
+`
+
+// editErrorPrefix starts the line that tells why an edit was rejected
+const editErrorPrefix = "# Error: "
+
+// userEditor returns the editor set in VISUAL or EDITOR, which may include
+// arguments such as "code --wait", or nil if neither is set
+func userEditor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// editorCommand returns the user's editor, or the system's usual one
+func editorCommand() []string {
+	if editor := userEditor(); editor != nil {
+		return editor
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// renderProfileDefinition writes a profile alone, as its table of the
+// config or as a YAML definitions file like config export's
+func renderProfileDefinition(profile Profile, format string) ([]byte, error) {
+	if format == editYAML {
+		return exportProfiles(map[string]Profile{profile.Name: profile}, "")
+	}
+	data := formatConfig(map[string]Profile{profile.Name: profile}, Settings{})
+	// Drop the file's own header; the edit header takes its place
+	return []byte(strings.TrimLeft(strings.TrimPrefix(string(data), "# Launchium profiles\n"), "\n")), nil
+}
+
+// parseProfileDefinition reads an edited definition back, checking it
+// like a profile sent to the API. It has to hold exactly one profile.
+func parseProfileDefinition(data []byte, format string, settings Settings) (Profile, error) {
+	var profiles map[string]Profile
+	var err error
+	if format == editYAML {
+		if profiles, err = readProfileDefinitions(data, settings); err != nil {
+			return Profile{}, err
+		}
+	} else {
+		var extra Settings
+		if profiles, extra, err = parseTables(data); err != nil {
+			return Profile{}, err
+		}
+		if len(extra.fields()) > 0 || len(extra.Keys) > 0 || len(extra.Themes) > 0 || len(extra.Webhooks) > 0 || len(extra.FirstRun) > 0 {
+			return Profile{}, fmt.Errorf("only the [profiles.<name>] table can be edited here")
+		}
+		for name, profile := range profiles {
+			if profiles[name], err = checkProfile(profile, settings); err != nil {
+				return Profile{}, fmt.Errorf("profile '%s': %s", name, err)
+			}
+		}
+	}
+	if len(profiles) != 1 {
+		return Profile{}, fmt.Errorf("expected one profile, found %d", len(profiles))
+	}
+	for _, profile := range profiles {
+		return profile, nil
+	}
+	return Profile{}, nil
+}
+
+// withoutEditErrors drops the error line an earlier attempt added
+func withoutEditErrors(data []byte) string {
+	lines := []string{}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if !strings.HasPrefix(line, editErrorPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// blankDefinition reports whether an edited file has nothing but comments
+func blankDefinition(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// editProfile opens a profile's definition in the user's editor and puts
+// the edited profile in its place. An edit that doesn't check out is
+// opened again with the error at the top, until it is fixed, the file is
+// emptied or the editor is closed without changing it. It returns what
+// happened.
+func (cm *ChromiumManager) editProfile(name, format string) (string, error) {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		return "", profileNotFound(name)
+	}
+	if cm.configSource != "" {
+		return "", cm.errRemoteConfig()
+	}
+	if _, project := cm.projectProfiles[name]; project {
+		return "", fmt.Errorf("profile '%s' comes from %s; change it there", name, cm.projectFile)
+	}
+
+	definition, err := renderProfileDefinition(profile, format)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "launchium-edit-*."+format)
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	content := fmt.Sprintf(editHeader, name) + "\n" + string(definition)
+	editor := editorCommand()
+	for {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return "", err
+		}
+		cmd := exec.Command(editor[0], append(editor[1:], path)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("running %s: %w", editor[0], err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if blankDefinition(data) {
+			return "Edit cancelled; nothing changed", nil
+		}
+
+		edited, err := parseProfileDefinition(data, format, cm.settings)
+		if err == nil {
+			if format == editYAML {
+				// Data dirs aren't part of YAML definitions
+				edited.DataDir = profile.DataDir
+			}
+			if sameDefinition(profile, edited) {
+				return fmt.Sprintf("No changes to profile '%s'", name), nil
+			}
+			if err = cm.replaceProfile(name, edited); err == nil {
+				if edited.Name != name {
+					return fmt.Sprintf("Profile '%s' updated and renamed to '%s'", name, edited.Name), nil
+				}
+				return fmt.Sprintf("Profile '%s' updated", name), nil
+			}
+		}
+		if string(data) == content {
+			// Closed without fixing it
+			return "", err
+		}
+		content = editErrorPrefix + strings.ReplaceAll(err.Error(), "\n", " ") + "\n" + withoutEditErrors(data)
+	}
+}
+
+// replaceProfile saves an edited profile in place of the named one,
+// renaming the profile and its data directory when the name changed
+func (cm *ChromiumManager) replaceProfile(name string, edited Profile) error {
+	if other := slotHolder(cm.profiles, edited.Slot, name); other != "" && other != edited.Name {
+		return fmt.Errorf("profile '%s' already has slot %d", other, edited.Slot)
+	}
+	if edited.Name != name {
+		if err := cm.renameProfile(name, edited.Name); err != nil {
+			return err
+		}
+	}
+	previous := cm.profiles[edited.Name]
+	cm.profiles[edited.Name] = edited
+	if err := cm.saveProfiles(); err != nil {
+		cm.profiles[edited.Name] = previous
+		return fmt.Errorf("saving config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProfileDefinitionRoundTrip(t *testing.T) {
+	profile, err := checkProfile(Profile{Name: "work", Description: "Work", Tags: []string{"daily"}, Flags: parseFlagList("--incognito")}, Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{editTOML, editYAML} {
+		data, err := renderProfileDefinition(profile, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if strings.Contains(string(data), "# Launchium profiles") {
+			t.Errorf("%s: the config's header was kept:\n%s", format, data)
+		}
+		got, err := parseProfileDefinition(data, format, Settings{})
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		if !sameDefinition(got, profile) {
+			t.Errorf("%s: read back %+v", format, got)
+		}
+	}
+}
+
+func TestParseProfileDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"two profiles", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\n\n[profiles.b]\nproxy = \"none\"\nproxy_type = \"none\"\n", "expected one profile, found 2"},
+		{"settings", "[settings]\nlaunch_check = 5\n\n[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\n", "only the [profiles.<name>] table"},
+		{"bad value", "[profiles.a]\nproxy = \"none\"\nproxy_type = \"none\"\nchannel = \"nightly\"\n", "channel must be"},
+	}
+	for _, tt := range tests {
+		if _, err := parseProfileDefinition([]byte(tt.data), editTOML, Settings{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEditHelpers(t *testing.T) {
+	if got := withoutEditErrors([]byte(editErrorPrefix + "bad\n# Editing\n[profiles.a]\n")); got != "# Editing\n[profiles.a]\n" {
+		t.Errorf("withoutEditErrors = %q", got)
+	}
+	if !blankDefinition([]byte("# Editing\n\n  # more\n")) {
+		t.Error("a file of comments isn't blank")
+	}
+	if blankDefinition([]byte("# Editing\n[profiles.a]\n")) {
+		t.Error("a file with a table is blank")
+	}
+
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorCommand(); strings.Join(got, " ") != "code --wait" {
+		t.Errorf("editorCommand = %q", got)
+	}
+}
+
+func TestEditProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor is a shell script")
+	}
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+
+	// An editor that changes the description
+	editor := filepath.Join(t.TempDir(), "editor")
+	os.WriteFile(editor, []byte("#!/bin/sh\nsed 's/\"Work\"/\"Office\"/' \"$1\" > \"$1.new\" && mv \"$1.new\" \"$1\"\n"), 0755)
+	t.Setenv("VISUAL", editor)
+	message, err := cm.editProfile("work", editTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message != "Profile 'work' updated" || cm.profiles["work"].Description != "Office" {
+		t.Errorf("edit said %q and left %+v", message, cm.profiles["work"])
+	}
+
+	// Closing the editor without changes
+	t.Setenv("VISUAL", "true")
+	if message, err := cm.editProfile("work", editYAML); err != nil || message != "No changes to profile 'work'" {
+		t.Errorf("an unchanged edit said %q, %v", message, err)
+	}
+
+	if _, err := cm.editProfile("missing", editTOML); err == nil {
+		t.Error("edited a profile that doesn't exist")
+	}
+}
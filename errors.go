@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure callers may want to tell apart with errors.Is. The
+// errors returned keep their own wording and wrap one of these.
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrBrowserNotFound = errors.New("browser not found")
+	ErrLaunchFailed    = errors.New("launch failed")
+	ErrProtected       = errors.New("profile protected")
+)
+
+// Exit codes of the command line for each kind of failure, after 1 for
+// anything else and 2 for usage errors
+const (
+	exitProfileNotFound = 3
+	exitBrowserNotFound = 4
+	exitLaunchFailed    = 5
+	exitProtected       = 6
+)
+
+// kindError is an error of one of the kinds above
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// errorOf formats an error, which may wrap its cause with %w, as one of
+// the kinds above
+func errorOf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// profileNotFound reports that no profile has the name
+func profileNotFound(name string) error {
+	return errorOf(ErrProfileNotFound, "profile '%s' not found", name)
+}
+
+// exitCode returns the command line's exit status for an error
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrProfileNotFound):
+		return exitProfileNotFound
+	case errors.Is(err, ErrBrowserNotFound):
+		return exitBrowserNotFound
+	case errors.Is(err, ErrLaunchFailed):
+		return exitLaunchFailed
+	case errors.Is(err, ErrProtected):
+		return exitProtected
+	}
+	return 1
+}
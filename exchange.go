@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v3"
+)
+
+// Ways config import treats the profiles of the config
+const (
+	importMerge   = "merge"   // add the file's profiles, asking about ones that exist
+	importReplace = "replace" // the file's profiles take the place of the config's
+)
+
+// profileDefinitions is the file config export writes and config import
+// reads: profile settings by name, without any browser data
+type profileDefinitions struct {
+	Profiles map[string]map[string]interface{} `json:"profiles" yaml:"profiles"`
+}
+
+// exportableProfiles returns the profiles config export shares: all but
+// those of a project, which its repository shares already
+func (cm *ChromiumManager) exportableProfiles() map[string]Profile {
+	profiles := map[string]Profile{}
+	for name, profile := range cm.profiles {
+		if _, project := cm.projectProfiles[name]; !project {
+			profiles[name] = profile
+		}
+	}
+	return profiles
+}
+
+// exportProfiles renders profile definitions as YAML, or as JSON for a
+// .json path. Data dirs are left out, as they belong to this machine.
+func exportProfiles(profiles map[string]Profile, path string) ([]byte, error) {
+	defs := profileDefinitions{Profiles: map[string]map[string]interface{}{}}
+	for name, profile := range profiles {
+		profile.DataDir = ""
+		data, err := json.Marshal(profile)
+		if err != nil {
+			return nil, err
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		delete(fields, "name")
+		delete(fields, "data_dir")
+		if !profile.Flags.structured() {
+			fields["flags"] = profile.Flags.String()
+		}
+		defs.Profiles[name] = fields
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(defs, "", "  ")
+		return append(data, '\n'), err
+	}
+	return yaml.Marshal(defs)
+}
+
+// readProfileDefinitions parses a file written by config export. YAML is
+// a superset of JSON, so either is read the same way.
+func readProfileDefinitions(data []byte, settings Settings) (map[string]Profile, error) {
+	defs := profileDefinitions{}
+	if err := yaml.Unmarshal(data, &defs); err != nil {
+		return nil, err
+	}
+	if len(defs.Profiles) == 0 {
+		return nil, fmt.Errorf("no profiles in it")
+	}
+	profiles := map[string]Profile{}
+	for name, fields := range defs.Profiles {
+		fields["name"] = name
+		delete(fields, "data_dir")
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("profile '%s': %s", name, err)
+		}
+		var profile Profile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return nil, fmt.Errorf("profile '%s': %s", name, err)
+		}
+		if profile, err = checkProfile(profile, settings); err != nil {
+			return nil, fmt.Errorf("profile '%s': %s", name, err)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// importConflict decides about a profile that exists with other settings:
+// "keep", "overwrite" or a new name to import it as
+type importConflict func(name string) (string, error)
+
+// importProfiles adds the profiles of a definitions file to the config.
+// With importMerge, profiles already there with other settings go to
+// resolve; with importReplace, the config's profiles that aren't in the
+// file are removed, leaving their data behind. It returns a line per
+// change.
+func (cm *ChromiumManager) importProfiles(data []byte, mode string, resolve importConflict) ([]string, error) {
+	if cm.configSource != "" {
+		return nil, cm.errRemoteConfig()
+	}
+	imported, err := readProfileDefinitions(data, cm.settings)
+	if err != nil {
+		return nil, err
+	}
+	return cm.addImported(imported, mode, resolve)
+}
+
+// addImported adds checked profiles to the config the way importProfiles
+// describes. Imported profiles with a data dir, such as those of a
+// directory of data dirs, keep it; the others keep the local one.
+func (cm *ChromiumManager) addImported(imported map[string]Profile, mode string, resolve importConflict) ([]string, error) {
+	if cm.configSource != "" {
+		return nil, cm.errRemoteConfig()
+	}
+
+	changes := []string{}
+	before := map[string]Profile{}
+	for name, profile := range cm.profiles {
+		before[name] = profile
+	}
+	restore := func() { cm.profiles = before }
+
+	if mode == importReplace {
+		for _, name := range sortedProfileNames(cm.exportableProfiles()) {
+			if _, ok := imported[name]; !ok {
+				if cm.profiles[name].Protected && !cm.unprotect {
+					changes = append(changes, fmt.Sprintf("kept %s: it is protected (-force to remove it)", name))
+					continue
+				}
+				delete(cm.profiles, name)
+				changes = append(changes, fmt.Sprintf("removed %s (its data is kept)", name))
+			}
+		}
+	}
+
+	for _, name := range sortedProfileNames(imported) {
+		profile := imported[name]
+		if _, project := cm.projectProfiles[name]; project {
+			changes = append(changes, fmt.Sprintf("skipped %s: it comes from %s", name, cm.projectFile))
+			continue
+		}
+		existing, exists := cm.profiles[name]
+		importedDir := profile.DataDir
+		if importedDir == "" {
+			profile.DataDir = existing.DataDir
+		}
+		switch {
+		case !exists:
+			changes = append(changes, "added "+name)
+		case sameDefinition(existing, profile):
+			continue
+		case existing.Protected && !cm.unprotect:
+			changes = append(changes, fmt.Sprintf("kept %s: it is protected (-force to overwrite it)", name))
+			continue
+		case mode == importReplace:
+			changes = append(changes, "replaced "+name)
+		default:
+			choice, err := resolve(name)
+			if err != nil {
+				restore()
+				return nil, err
+			}
+			switch choice {
+			case "keep":
+				changes = append(changes, "kept "+name)
+				continue
+			case "overwrite":
+				changes = append(changes, "overwrote "+name)
+			default:
+				if err := validProfileName(choice); err != nil {
+					restore()
+					return nil, err
+				}
+				if _, taken := cm.profiles[choice]; taken {
+					restore()
+					return nil, fmt.Errorf("profile '%s' already exists", choice)
+				}
+				changes = append(changes, fmt.Sprintf("added %s as %s", name, choice))
+				profile.Name = choice
+				profile.DataDir = importedDir
+				name = choice
+			}
+		}
+		cm.profiles[name] = profile
+	}
+	if err := checkSlots(cm.profiles); err != nil {
+		restore()
+		return nil, err
+	}
+
+	if err := cm.saveProfiles(); err != nil {
+		restore()
+		return nil, err
+	}
+	return changes, nil
+}
+
+// sameDefinition reports whether two profiles of a name would be written
+// to the config the same way
+func sameDefinition(a, b Profile) bool {
+	config := func(p Profile) string {
+		return string(formatConfig(map[string]Profile{p.Name: p}, Settings{}))
+	}
+	return config(a) == config(b)
+}
+
+// askImportConflict asks on the terminal what to do about each profile
+// that exists with other settings. "all" answers stick for the rest of
+// the import. Without a terminal to ask on, conflicting profiles are kept.
+func askImportConflict(in io.Reader, out io.Writer, interactive bool) importConflict {
+	reader := bufio.NewReader(in)
+	always := ""
+	return func(name string) (string, error) {
+		if always != "" {
+			return always, nil
+		}
+		if !interactive {
+			return "keep", nil
+		}
+		for {
+			fmt.Fprintf(out, "Profile '%s' exists with other settings: [k]eep, [o]verwrite, [r]ename, keep [a]ll, overwrite a[l]l? ", name)
+			answer, err := reader.ReadString('\n')
+			if err != nil && answer == "" {
+				return "", fmt.Errorf("import stopped: %w", err)
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "k", "keep":
+				return "keep", nil
+			case "o", "overwrite":
+				return "overwrite", nil
+			case "a":
+				always = "keep"
+				return always, nil
+			case "l":
+				always = "overwrite"
+				return always, nil
+			case "r", "rename":
+				fmt.Fprintf(out, "Import '%s' as: ", name)
+				newName, err := reader.ReadString('\n')
+				if newName = strings.TrimSpace(newName); newName != "" {
+					return newName, nil
+				}
+				if err != nil {
+					return "", fmt.Errorf("import stopped: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// stdinIsTerminal reports whether config import can ask questions
+func stdinIsTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
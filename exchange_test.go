@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exchangeManager returns a manager with a work and a home profile whose
+// config is saved to the fake filesystem
+func exchangeManager(m *memFS) *ChromiumManager {
+	m.MkdirAll("/config", 0755)
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: "/config/profiles.toml",
+		profiles:   map[string]Profile{},
+	}
+	for _, profile := range []Profile{
+		{Name: "work", Description: "Work", Flags: parseFlagList("--incognito")},
+		{Name: "home", Description: "Home", DataDir: "/data/home"},
+	} {
+		cm.profiles[profile.Name], _ = checkProfile(profile, Settings{})
+	}
+	return cm
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	for _, path := range []string{"profiles.yaml", "profiles.JSON"} {
+		data, err := exportProfiles(cm.profiles, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if strings.Contains(string(data), "/data/home") {
+			t.Errorf("%s: export kept a data dir:\n%s", path, data)
+		}
+		if isJSON := bytes.HasPrefix(data, []byte("{")); isJSON != strings.HasSuffix(path, ".JSON") {
+			t.Errorf("%s: exported\n%s", path, data)
+		}
+		profiles, err := readProfileDefinitions(data, Settings{})
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		home := cm.profiles["home"]
+		home.DataDir = ""
+		if !sameDefinition(profiles["home"], home) || !sameDefinition(profiles["work"], cm.profiles["work"]) {
+			t.Errorf("%s: read back %+v", path, profiles)
+		}
+	}
+
+	if _, err := readProfileDefinitions([]byte("profiles: {}\n"), Settings{}); err == nil {
+		t.Error("read a file without profiles")
+	}
+}
+
+func TestImportProfiles(t *testing.T) {
+	file := []byte(`profiles:
+  work:
+    description: Office
+  play:
+    description: Games
+`)
+	tests := []struct {
+		name    string
+		mode    string
+		choice  string
+		want    []string
+		changes []string
+	}{
+		{"merge keeps", importMerge, "keep", []string{"home", "play", "work"}, []string{"added play", "kept work"}},
+		{"merge overwrites", importMerge, "overwrite", []string{"home", "play", "work"}, []string{"added play", "overwrote work"}},
+		{"merge renames", importMerge, "office", []string{"home", "office", "play", "work"}, []string{"added play", "added work as office"}},
+		{"replace", importReplace, "", []string{"play", "work"}, []string{"removed home (its data is kept)", "added play", "replaced work"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := useFakes(t)
+			cm := exchangeManager(m)
+			changes, err := cm.importProfiles(file, tt.mode, func(string) (string, error) { return tt.choice, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("changes are %q, want %q", changes, tt.changes)
+			}
+			if got := sortedProfileNames(cm.profiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profiles are %q, want %q", got, tt.want)
+			}
+			if _, err := m.Stat(cm.configFile); err != nil {
+				t.Errorf("the config wasn't saved: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportProfilesNameTaken(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	_, err := cm.importProfiles([]byte("profiles:\n  work:\n    description: Office\n"), importMerge,
+		func(string) (string, error) { return "home", nil })
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("importProfiles error = %v", err)
+	}
+	if cm.profiles["work"].Description != "Work" {
+		t.Error("a failed import changed the profiles")
+	}
+}
+
+func TestAskImportConflict(t *testing.T) {
+	var out bytes.Buffer
+	ask := askImportConflict(strings.NewReader("x\nr\nother\nl\n"), &out, true)
+	for _, want := range []string{"other", "overwrite", "overwrite"} {
+		if got, err := ask("work"); err != nil || got != want {
+			t.Errorf("ask = %q, %v, want %q", got, err, want)
+		}
+	}
+	if !strings.Contains(out.String(), "Import 'work' as: ") {
+		t.Errorf("asked %q", out.String())
+	}
+
+	if got, _ := askImportConflict(strings.NewReader(""), &out, false)("work"); got != "keep" {
+		t.Errorf("without a terminal the answer was %q, want keep", got)
+	}
+	if _, err := askImportConflict(strings.NewReader(""), &out, true)("work"); err == nil {
+		t.Error("an empty answer at end of input didn't stop the import")
+	}
+}
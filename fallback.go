@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// The browser each profile's latest launch ran with. Launches run in the
+// background, so they leave it here for recordLaunch to put in the state.
+var (
+	launchedBrowsersMu sync.Mutex
+	launchedBrowsers   = map[string]string{}
+)
+
+// noteLaunchedBrowser remembers the browser a profile was just started with
+func noteLaunchedBrowser(name, browserPath string) {
+	launchedBrowsersMu.Lock()
+	defer launchedBrowsersMu.Unlock()
+	launchedBrowsers[name] = browserPath
+}
+
+// takeLaunchedBrowser returns and forgets the browser noted for a profile
+func takeLaunchedBrowser(name string) (string, bool) {
+	launchedBrowsersMu.Lock()
+	defer launchedBrowsersMu.Unlock()
+	browserPath, ok := launchedBrowsers[name]
+	delete(launchedBrowsers, name)
+	return browserPath, ok
+}
+
+// fallbackBrowsers returns the browsers a fallback profile tries after
+// failed, in the order of the fallback_order setting: channel names stand
+// for the installed browsers of that channel, anything else is a path.
+// Without the setting every installed browser is tried in the order
+// `launchium browsers` lists them.
+func (cm *ChromiumManager) fallbackBrowsers(failed string) []string {
+	installed := installedBrowsers()
+	candidates := []string{}
+	if len(cm.settings.FallbackOrder) == 0 {
+		for _, b := range installed {
+			candidates = append(candidates, b.Path)
+		}
+	}
+	for _, entry := range cm.settings.FallbackOrder {
+		if entry != channelFetched && !containsString(browserChannels, entry) {
+			candidates = append(candidates, expandPath(entry))
+			continue
+		}
+		for _, b := range installed {
+			if b.Channel == entry {
+				candidates = append(candidates, b.Path)
+			}
+		}
+	}
+
+	// Skip the browser that failed and repeats, such as a path listed
+	// again by its channel
+	real := func(path string) string {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return resolved
+		}
+		return path
+	}
+	seen := map[string]bool{real(failed): true}
+	browsers := []string{}
+	for _, path := range candidates {
+		if _, err := fsys.Stat(path); err != nil || seen[real(path)] {
+			continue
+		}
+		seen[real(path)] = true
+		browsers = append(browsers, path)
+	}
+	return browsers
+}
+
+// startBrowser does the work of launching a profile without touching the
+// model, so the TUI can run it in the background. The new window opens
+// urls, or a blank page without any. A fallback profile whose browser
+// fails to start is tried with the other installed browsers.
+func (cm *ChromiumManager) startBrowser(ctx context.Context, profile Profile, urls ...string) (string, error) {
+	if cm.isArchived(profile.Name) {
+		return "", errArchived(profile.Name)
+	}
+	profile = cm.override.profile(profile)
+	// Unreadable CAs fail with every browser, so don't try the others
+	if _, err := profile.trustedCAs(); err != nil {
+		return "", err
+	}
+	browserPath, err := cm.browserFor(profile)
+	if err == nil {
+		var message string
+		if message, err = cm.startBrowserWith(ctx, profile, browserPath, urls); err == nil {
+			noteLaunchedBrowser(profile.Name, browserPath)
+			return message, nil
+		}
+	}
+	if !profile.Fallback || ctx.Err() != nil {
+		return "", err
+	}
+
+	fallbacks := cm.fallbackBrowsers(browserPath)
+	for _, fallback := range fallbacks {
+		message, retryErr := cm.startBrowserWith(ctx, profile, fallback, urls)
+		if retryErr == nil {
+			noteLaunchedBrowser(profile.Name, fallback)
+			return fmt.Sprintf("%s, using %s since its browser failed: %s", message, fallback, err), nil
+		}
+		if ctx.Err() != nil {
+			return "", retryErr
+		}
+	}
+	if len(fallbacks) > 0 {
+		return "", fmt.Errorf("%w; the other installed browsers failed too", err)
+	}
+	return "", err
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Chrome for Testing is the Chromium build Google publishes for every
+// platform at fixed versions, without auto-updates. fetch-browser installs
+// this version unless told otherwise.
+const (
+	pinnedBrowserVersion = "131.0.6778.85"
+	browserDownloadURL   = "https://storage.googleapis.com/chrome-for-testing-public/%s/%s/chrome-%s.zip"
+)
+
+// Chrome for Testing platform names by GOOS/GOARCH
+var browserPlatforms = map[string]string{
+	"linux/amd64":   "linux64",
+	"darwin/amd64":  "mac-x64",
+	"darwin/arm64":  "mac-arm64",
+	"windows/amd64": "win64",
+	"windows/386":   "win32",
+}
+
+// Executables fetchedBrowser looks for in an unpacked build, relative to
+// any directory in it
+var browserExecutables = []string{
+	"chrome",
+	"chrome.exe",
+	"chromium",
+	"chromium.exe",
+	filepath.Join("Google Chrome for Testing.app", "Contents", "MacOS", "Google Chrome for Testing"),
+	filepath.Join("Chromium.app", "Contents", "MacOS", "Chromium"),
+}
+
+// browsersDir is where fetched browsers are kept, one directory per build
+func browsersDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "launchium", "browsers"), nil
+	case "windows":
+		if local := os.Getenv("LocalAppData"); local != "" {
+			return filepath.Join(local, "launchium", "browsers"), nil
+		}
+	}
+	if data := os.Getenv("XDG_DATA_HOME"); data != "" {
+		return filepath.Join(data, "launchium", "browsers"), nil
+	}
+	return filepath.Join(homeDir, ".local", "share", "launchium", "browsers"), nil
+}
+
+// fetchedBrowsers returns the executables of the browsers fetch-browser
+// installed, newest directory name last
+func fetchedBrowsers() []string {
+	dir, err := browsersDir()
+	if err != nil {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	found := []string{}
+	for _, name := range names {
+		if exe := fetchedBrowser(filepath.Join(dir, name)); exe != "" {
+			found = append(found, exe)
+		}
+	}
+	return found
+}
+
+// fetchedBrowser finds the browser executable in an unpacked build
+func fetchedBrowser(root string) string {
+	found := ""
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		for _, exe := range browserExecutables {
+			candidate := filepath.Join(path, exe)
+			if st, err := os.Stat(candidate); err == nil && !st.IsDir() {
+				found = candidate
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// browserDownload returns the URL of the pinned build, or of version, for
+// this platform and the directory name to install it as
+func browserDownload(version string) (string, string, error) {
+	if version == "" {
+		version = pinnedBrowserVersion
+	}
+	platform, ok := browserPlatforms[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok {
+		return "", "", fmt.Errorf("no Chrome for Testing build for %s/%s; pass -url with a zip of another build", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf(browserDownloadURL, version, platform, platform), "chrome-" + version, nil
+}
+
+// fetchBrowser downloads and unpacks a browser build into the browsers
+// dir and returns its executable. progress is told about the download.
+func fetchBrowser(ctx context.Context, url, name string, progress func(done, total int64)) (string, error) {
+	root, err := browsersDir()
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(root, name)
+	if exe := fetchedBrowser(dest); exe != "" {
+		return exe, nil
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+
+	archive, err := ioutil.TempFile(root, ".download-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	if _, err := io.Copy(archive, &progressReader{r: resp.Body, total: resp.ContentLength, report: progress}); err != nil {
+		return "", fmt.Errorf("downloading %s: %w", url, err)
+	}
+
+	// Unpack next to the final location so a failed unzip leaves nothing
+	// that looks installed
+	staging := filepath.Join(root, ".unpack-"+name)
+	os.RemoveAll(staging)
+	if err := unzip(archive.Name(), staging); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("unpacking: %w", err)
+	}
+	if fetchedBrowser(staging) == "" {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("no Chrome or Chromium executable in %s", url)
+	}
+	os.RemoveAll(dest)
+	if err := os.Rename(staging, dest); err != nil {
+		return "", err
+	}
+	return fetchedBrowser(dest), nil
+}
+
+// progressReader reports how much of a download has been read
+type progressReader struct {
+	r      io.Reader
+	done   int64
+	total  int64 // -1 when the server didn't say
+	report func(done, total int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.done += int64(n)
+	if p.report != nil {
+		p.report(p.done, p.total)
+	}
+	return n, err
+}
+
+// unzip extracts archive into dir, keeping executable bits and the
+// symlinks macOS app bundles rely on
+func unzip(archive, dir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		path := filepath.Join(dir, f.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s points outside the archive", f.Name)
+		}
+		mode := f.Mode()
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		if mode&os.ModeSymlink != 0 {
+			target, err := ioutil.ReadAll(src)
+			src.Close()
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(string(target), path); err != nil {
+				return err
+			}
+			continue
+		}
+		dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+		if err == nil {
+			_, err = io.Copy(dst, src)
+			if cerr := dst.Close(); err == nil {
+				err = cerr
+			}
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// firstRunDefault is the [first_run.<name>] table used for browsers whose
+// channel has no table of its own
+const firstRunDefault = "default"
+
+// FirstRun says how a new data dir is set up before the browser first
+// runs in it. A [first_run.<name>] table of the config is one; profiles
+// pick one by name, or get the one named after their browser's channel.
+type FirstRun struct {
+	Name               string
+	SkipWelcome        bool   // No welcome page, first run tabs or sign-in promo
+	SkipDefaultBrowser bool   // Don't ask to become the default browser
+	DisableMetrics     bool   // Opt out of usage statistics and crash reports
+	MasterPreferences  string // initial_preferences JSON seeded into the data dir
+}
+
+// defaultFirstRun is the setup used when the config has no table for a
+// profile: the prompts are skipped and metrics are left as they are
+func defaultFirstRun(name string) FirstRun {
+	return FirstRun{Name: name, SkipWelcome: true, SkipDefaultBrowser: true}
+}
+
+// fields returns the table's config entries in the order they are written
+func (f FirstRun) fields() []configField {
+	fields := []configField{
+		{"skip_welcome", strconv.FormatBool(f.SkipWelcome)},
+		{"skip_default_browser", strconv.FormatBool(f.SkipDefaultBrowser)},
+		{"disable_metrics", strconv.FormatBool(f.DisableMetrics)},
+	}
+	if f.MasterPreferences != "" {
+		fields = append(fields, configField{"master_preferences", quoteString(f.MasterPreferences)})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching first run field
+func (f *FirstRun) setField(key, value string) error {
+	switch key {
+	case "skip_welcome":
+		return parseBoolInto(&f.SkipWelcome, value)
+	case "skip_default_browser":
+		return parseBoolInto(&f.SkipDefaultBrowser, value)
+	case "disable_metrics":
+		return parseBoolInto(&f.DisableMetrics, value)
+	case "master_preferences":
+		return unquoteInto(&f.MasterPreferences, value)
+	default:
+		return unknownKeyError{"first_run key", key}
+	}
+}
+
+// firstRunNames returns the names of the config's first run tables, sorted
+func (s Settings) firstRunNames() []string {
+	names := []string{}
+	for name := range s.FirstRun {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validFirstRun checks that a profile's first_run names a table, or is
+// empty to go by the browser
+func (s Settings) validFirstRun(name string) error {
+	if _, ok := s.FirstRun[name]; ok || name == "" {
+		return nil
+	}
+	if names := s.firstRunNames(); len(names) > 0 {
+		return fmt.Errorf("first_run %q has no [first_run.%s] table (have %s)", name, name, strings.Join(names, ", "))
+	}
+	return fmt.Errorf("first_run %q has no [first_run.%s] table", name, name)
+}
+
+// firstRunFor returns the first run setup of a profile launched with
+// browserPath: the table the profile names, else the one for the
+// browser's channel, else [first_run.default], else the built-in one
+func (cm *ChromiumManager) firstRunFor(profile Profile, browserPath string) FirstRun {
+	if f, ok := cm.settings.FirstRun[profile.FirstRun]; ok {
+		return f
+	}
+	for _, b := range installedBrowsers() {
+		if b.Path != browserPath {
+			continue
+		}
+		if f, ok := cm.settings.FirstRun[b.Channel]; ok {
+			return f
+		}
+	}
+	if f, ok := cm.settings.FirstRun[firstRunDefault]; ok {
+		return f
+	}
+	return defaultFirstRun(firstRunDefault)
+}
+
+// flags returns the switches the setup adds to every launch. They only
+// matter on a first run but are harmless later, and a cleaned profile is
+// new again.
+func (f FirstRun) flags() []string {
+	flags := []string{}
+	if f.SkipWelcome {
+		flags = append(flags, "--no-first-run")
+	}
+	if f.SkipDefaultBrowser {
+		flags = append(flags, "--no-default-browser-check")
+	}
+	return flags
+}
+
+// seed writes the setup into a data dir the browser hasn't run in yet
+func (f FirstRun) seed(dataDir string) error {
+	localStatePath := filepath.Join(dataDir, "Local State")
+
+	// Like Chromium's own initial_preferences, the distribution part goes
+	// to Local State and the rest is the profile's preferences. The setup's
+	// own switches are merged over them.
+	if f.MasterPreferences != "" {
+		initial, err := readMasterPreferences(expandPath(f.MasterPreferences))
+		if err != nil {
+			return err
+		}
+		if distribution, ok := initial["distribution"]; ok {
+			delete(initial, "distribution")
+			if err := mergePreferences(localStatePath, map[string]interface{}{"distribution": distribution}); err != nil {
+				return err
+			}
+		}
+		if len(initial) > 0 {
+			if err := mergePreferences(preferencesFile(dataDir), initial); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Always wanted: launchium turns the GPU off and runs without API keys
+	localState := map[string]interface{}{
+		"browser.enabled_labs_experiments":       []string{"ignore-gpu-blocklist@1"},
+		"distribution.suppress_api_keys_warning": true,
+	}
+	prefs := map[string]interface{}{}
+	if f.SkipWelcome {
+		localState["distribution.suppress_first_run_bubble"] = true
+		localState["distribution.skip_first_run_ui"] = true
+		prefs["browser.has_seen_welcome_page"] = true
+	}
+	if f.SkipDefaultBrowser {
+		localState["distribution.suppress_first_run_default_browser_prompt"] = true
+		prefs["browser.check_default_browser"] = false
+	}
+	if f.DisableMetrics {
+		localState["user_experience_metrics.reporting_enabled"] = false
+	}
+
+	if err := mergePreferences(localStatePath, localState); err != nil {
+		return err
+	}
+	if len(prefs) == 0 {
+		return nil
+	}
+	return mergePreferences(preferencesFile(dataDir), prefs)
+}
+
+// readMasterPreferences reads an initial_preferences (formerly
+// master_preferences) file
+func readMasterPreferences(path string) (map[string]interface{}, error) {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading master preferences: %w", err)
+	}
+	values := map[string]interface{}{}
+	if err := decodePreferences(data, &values); err != nil {
+		return nil, fmt.Errorf("reading master preferences %s: %w", path, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFirstRunFor(t *testing.T) {
+	useFakes(t)
+	browserSource = fakeBrowsers{
+		{Product: "Google Chrome", Channel: "stable", Path: "/usr/bin/google-chrome"},
+		{Product: "Google Chrome", Channel: "beta", Path: "/usr/bin/google-chrome-beta"},
+	}
+	cm := &ChromiumManager{settings: Settings{FirstRun: map[string]FirstRun{
+		"beta":    {Name: "beta", DisableMetrics: true},
+		"kiosk":   {Name: "kiosk", SkipWelcome: true},
+		"default": {Name: "default", SkipDefaultBrowser: true},
+	}}}
+	tests := []struct {
+		name    string
+		profile Profile
+		browser string
+		want    string
+	}{
+		{"named table", Profile{FirstRun: "kiosk"}, "/usr/bin/google-chrome-beta", "kiosk"},
+		{"browser's channel", Profile{}, "/usr/bin/google-chrome-beta", "beta"},
+		{"default table", Profile{}, "/usr/bin/google-chrome", "default"},
+		{"unknown browser", Profile{}, "/opt/chromium/chrome", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cm.firstRunFor(tt.profile, tt.browser); got.Name != tt.want {
+				t.Errorf("firstRunFor() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+
+	delete(cm.settings.FirstRun, "default")
+	if got := cm.firstRunFor(Profile{}, "/usr/bin/google-chrome"); !reflect.DeepEqual(got, defaultFirstRun(firstRunDefault)) {
+		t.Errorf("without tables got %+v, want the built-in setup", got)
+	}
+}
+
+func TestFirstRunFlags(t *testing.T) {
+	tests := []struct {
+		firstRun FirstRun
+		want     []string
+	}{
+		{FirstRun{}, []string{}},
+		{defaultFirstRun("default"), []string{"--no-first-run", "--no-default-browser-check"}},
+		{FirstRun{SkipDefaultBrowser: true, DisableMetrics: true}, []string{"--no-default-browser-check"}},
+	}
+	for _, tt := range tests {
+		if got := tt.firstRun.flags(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("flags() of %+v = %q, want %q", tt.firstRun, got, tt.want)
+		}
+	}
+}
+
+func TestFirstRunSeed(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/etc/chromium", 0755)
+	m.WriteFile("/etc/chromium/initial_preferences", []byte(`{
+		"distribution": {"import_bookmarks": false},
+		"bookmark_bar": {"show_on_all_tabs": true}
+	}`), 0644)
+	f := FirstRun{SkipWelcome: true, DisableMetrics: true, MasterPreferences: "/etc/chromium/initial_preferences"}
+	if err := f.seed("/profiles/work"); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(path string) map[string]interface{} {
+		t.Helper()
+		data, err := m.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			t.Fatal(err)
+		}
+		return values
+	}
+	localState := read(filepath.Join("/profiles/work", "Local State"))
+	distribution, _ := localState["distribution"].(map[string]interface{})
+	if distribution["import_bookmarks"] != false || distribution["skip_first_run_ui"] != true || distribution["suppress_api_keys_warning"] != true {
+		t.Errorf("Local State distribution is %v", distribution)
+	}
+	if metrics, _ := localState["user_experience_metrics"].(map[string]interface{}); metrics["reporting_enabled"] != false {
+		t.Errorf("metrics weren't turned off: %v", localState)
+	}
+	prefs := read(preferencesFile("/profiles/work"))
+	if _, ok := prefs["distribution"]; ok {
+		t.Error("the distribution part went to Preferences")
+	}
+	if bar, _ := prefs["bookmark_bar"].(map[string]interface{}); bar["show_on_all_tabs"] != true {
+		t.Errorf("master preferences weren't seeded: %v", prefs)
+	}
+	if browser, _ := prefs["browser"].(map[string]interface{}); browser["has_seen_welcome_page"] != true {
+		t.Errorf("welcome page wasn't skipped: %v", prefs)
+	}
+}
+
+func TestFirstRunSeedMissingMasterPreferences(t *testing.T) {
+	useFakes(t)
+	f := FirstRun{MasterPreferences: "/etc/chromium/initial_preferences"}
+	if err := f.seed("/profiles/work"); err == nil {
+		t.Error("seeding with missing master preferences succeeded")
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Flag is one browser switch of a profile
+type Flag struct {
+	Switch  string `json:"switch"`          // Such as --window-size; arguments without dashes are kept as they are
+	Value   string `json:"value,omitempty"` // What follows the '=', if anything
+	Enabled bool   `json:"enabled"`
+	Note    string `json:"note,omitempty"` // Why the flag is there
+}
+
+// FlagList holds a profile's browser switches in the order given. Simple
+// lists are written to the config as a string, as before; once a flag is
+// turned off or has a note, they get a [profiles.<name>.flags] table:
+//
+//	[profiles.work.flags]
+//	--no-first-run = true
+//	--window-size = "1280,800"
+//	--incognito = { enabled = false, note = "only for demos" }
+type FlagList []Flag
+
+// parseFlag splits a command line switch such as --window-size=1280,800
+func parseFlag(arg string) Flag {
+	name, value, _ := strings.Cut(arg, "=")
+	return Flag{Switch: name, Value: value, Enabled: true}
+}
+
+// parseFlagList reads flags separated by spaces, the way they are typed on
+// a command line
+func parseFlagList(s string) FlagList {
+	var flags FlagList
+	for _, arg := range strings.Fields(s) {
+		flags = append(flags, parseFlag(arg))
+	}
+	return flags
+}
+
+// arg renders the flag as a command line argument
+func (f Flag) arg() string {
+	if f.Value == "" {
+		return f.Switch
+	}
+	return f.Switch + "=" + f.Value
+}
+
+// args returns the enabled flags as command line arguments
+func (l FlagList) args() []string {
+	args := []string{}
+	for _, f := range l {
+		if f.Enabled {
+			args = append(args, f.arg())
+		}
+	}
+	return args
+}
+
+// String returns the enabled flags separated by spaces
+func (l FlagList) String() string {
+	return strings.Join(l.args(), " ")
+}
+
+// has reports whether an enabled flag sets the switch
+func (l FlagList) has(name string) bool {
+	for _, f := range l {
+		if f.Enabled && f.Switch == name {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns a copy of the list with more enabled flags at the end
+func (l FlagList) with(args ...string) FlagList {
+	flags := append(FlagList{}, l...)
+	for _, arg := range args {
+		flags = append(flags, parseFlag(arg))
+	}
+	return flags
+}
+
+// structured reports whether the list needs a flags table to be written,
+// having a flag turned off, with a note, or with a value holding spaces,
+// such as a user agent
+func (l FlagList) structured() bool {
+	for _, f := range l {
+		if !f.Enabled || f.Note != "" || strings.ContainsAny(f.Value, " \t") {
+			return true
+		}
+	}
+	return false
+}
+
+// text renders the flags for the profile editor, one per line. A flag
+// that is off starts with "# " and a note follows "  # ".
+func (l FlagList) text() string {
+	lines := []string{}
+	for _, f := range l {
+		line := f.arg()
+		if !f.Enabled {
+			line = "# " + line
+		}
+		if f.Note != "" {
+			line += "  # " + f.Note
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseFlagText reads flags as written by text, dropping blank lines and
+// repeats of the same flag. A line may also hold several flags separated
+// by spaces, as pasted from a command line; a note then belongs to the
+// last one. A "#" line that doesn't start with a switch is a comment.
+func parseFlagText(s string) FlagList {
+	var flags FlagList
+	seen := map[string]bool{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		enabled := true
+		if rest, ok := strings.CutPrefix(line, "#"); ok {
+			enabled = false
+			line = strings.TrimSpace(rest)
+		}
+		note := ""
+		if i := strings.Index(line, " #"); i >= 0 {
+			note = strings.TrimSpace(line[i+2:])
+			line = line[:i]
+		}
+		args := strings.Fields(line)
+		if !enabled && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			continue
+		}
+		for i, arg := range args {
+			f := parseFlag(arg)
+			f.Enabled = enabled
+			if i == len(args)-1 {
+				f.Note = note
+			}
+			if key := f.arg(); !seen[key] {
+				seen[key] = true
+				flags = append(flags, f)
+			}
+		}
+	}
+	return flags
+}
+
+// fields returns the entries of the profile's flags table. A switch can
+// only be listed once there, so repeated --enable-features and
+// --disable-features are combined into one at the place of the first, as
+// composeFlags does at launch, and of any other repeated switch only the
+// last is kept, which is the one the browser would use.
+func (l FlagList) fields() []configField {
+	kept := map[string]int{}
+	for i, f := range l {
+		if _, seen := kept[f.Switch]; !seen || !isFeatureList(f.Switch) {
+			kept[f.Switch] = i
+		}
+	}
+	fields := []configField{}
+	for i, f := range l {
+		if kept[f.Switch] != i {
+			continue
+		}
+		if isFeatureList(f.Switch) {
+			f = l.mergedFeatures(f.Switch)
+		}
+		value := "true"
+		switch {
+		case f.Note != "" || (!f.Enabled && f.Value != ""):
+			entries := []string{}
+			if f.Value != "" {
+				entries = append(entries, "value = "+quoteString(f.Value))
+			}
+			if !f.Enabled {
+				entries = append(entries, "enabled = false")
+			}
+			if f.Note != "" {
+				entries = append(entries, "note = "+quoteString(f.Note))
+			}
+			value = "{ " + strings.Join(entries, ", ") + " }"
+		case f.Value != "":
+			value = quoteString(f.Value)
+		case !f.Enabled:
+			value = "false"
+		}
+		fields = append(fields, configField{formatKey(f.Switch), value})
+	}
+	return fields
+}
+
+// mergedFeatures combines the repeats of a feature list switch into one
+// flag. The features of the enabled repeats are kept; when all of them
+// are off, those of every repeat are, turned off.
+func (l FlagList) mergedFeatures(name string) Flag {
+	merged := Flag{Switch: name}
+	for _, f := range l {
+		if f.Switch == name && f.Enabled {
+			merged.Enabled = true
+		}
+	}
+	notes := []string{}
+	for _, f := range l {
+		if f.Switch != name || f.Enabled != merged.Enabled {
+			continue
+		}
+		merged.Value = joinFeatures(merged.Value, f.Value)
+		if f.Note != "" && !containsString(notes, f.Note) {
+			notes = append(notes, f.Note)
+		}
+	}
+	merged.Note = strings.Join(notes, "; ")
+	return merged
+}
+
+// setFlagField adds an entry of the profile's flags table: true or false
+// to turn a switch on or off, a string for its value, or an inline table
+// with value, enabled and note
+func (p *Profile) setFlagField(key, value string) error {
+	for _, f := range p.Flags {
+		if f.Switch == key {
+			return fmt.Errorf("flag %s is listed twice", key)
+		}
+	}
+	f := Flag{Switch: key, Enabled: true}
+	switch {
+	case value == "true" || value == "false":
+		f.Enabled = value == "true"
+	case strings.HasPrefix(value, "{"):
+		entries, err := parseInlineTable(value)
+		if err != nil {
+			return err
+		}
+		for k, v := range entries {
+			switch k {
+			case "value":
+				err = unquoteInto(&f.Value, v)
+			case "enabled":
+				err = parseBoolInto(&f.Enabled, v)
+			case "note":
+				err = unquoteInto(&f.Note, v)
+			default:
+				err = fmt.Errorf("unknown key %q for flag %s, expected value, enabled or note", k, key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if err := unquoteInto(&f.Value, value); err != nil {
+			return err
+		}
+	}
+	p.Flags = append(p.Flags, f)
+	return nil
+}
+
+// parseInlineTable reads a single-line TOML inline table of strings and
+// booleans, returning the raw value of each key
+func parseInlineTable(s string) (map[string]string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("expected an inline table, got %s", s)
+	}
+	entries := map[string]string{}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	for body != "" {
+		eq := strings.Index(body, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("expected key = value in %s", s)
+		}
+		key, err := parseKey(strings.TrimSpace(body[:eq]))
+		if err != nil {
+			return nil, err
+		}
+		body = strings.TrimSpace(body[eq+1:])
+
+		// The value runs to the next comma outside a string
+		end := 0
+		for quoted := false; end < len(body) && (quoted || body[end] != ','); end++ {
+			switch {
+			case body[end] == '\\' && quoted:
+				end++
+			case body[end] == '"':
+				quoted = !quoted
+			}
+		}
+		entries[key] = strings.TrimSpace(body[:end])
+		body = strings.TrimSpace(strings.TrimPrefix(body[end:], ","))
+	}
+	return entries, nil
+}
+
+// MarshalJSON writes a profile without flags as an empty list
+func (l FlagList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Flag(l))
+}
+
+// UnmarshalJSON accepts flags as a list of objects or, as older clients
+// send them, a single string
+func (l *FlagList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = parseFlagList(s)
+		return nil
+	}
+	var flags []Flag
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return err
+	}
+	*l = flags
+	return nil
+}
+
+// UnmarshalJSON turns a flag on unless it says otherwise
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	type plain Flag
+	flag := plain{Enabled: true}
+	if err := json.Unmarshal(data, &flag); err != nil {
+		return err
+	}
+	*f = Flag(flag)
+	return nil
+}
+
+// profileTableName parses what follows "profiles." in a table header: the
+// name of a [profiles.<name>] table, or of the profile a
+// [profiles.<name>.flags] table belongs to
+func profileTableName(rest string) (name string, flags bool, err error) {
+	if name, err = parseKey(rest); err == nil {
+		return name, false, nil
+	}
+	if key, ok := strings.CutSuffix(rest, ".flags"); ok {
+		if name, err := parseKey(strings.TrimSpace(key)); err == nil {
+			return name, true, nil
+		}
+	}
+	return "", false, err
+}
+
+// flagConflicts reports switches set more than once with different
+// values by the profile and its presets. The browser only reads the last.
+func (p Profile) flagConflicts() []string {
+	type setting struct{ value, by string }
+	set := map[string]setting{}
+	conflicts := []string{}
+	add := func(args []string, by string) {
+		for _, arg := range args {
+			f := parseFlag(arg)
+			if f.Switch == "--enable-features" || f.Switch == "--disable-features" {
+				continue // Combined at launch
+			}
+			if earlier, ok := set[f.Switch]; ok && earlier.value != f.Value {
+				if earlier.by == by {
+					conflicts = append(conflicts, fmt.Sprintf("%s is set twice by %s; only the last is used", f.Switch, by))
+				} else {
+					conflicts = append(conflicts, fmt.Sprintf("%s is set by %s and again by %s; only the last is used", f.Switch, earlier.by, by))
+				}
+			}
+			set[f.Switch] = setting{f.Value, by}
+		}
+	}
+	for _, name := range p.Presets {
+		if preset, ok := findPreset(name); ok {
+			add(preset.flags, "preset "+name)
+		}
+	}
+	add(p.Flags.args(), "the profile")
+	return conflicts
+}
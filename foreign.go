@@ -0,0 +1,504 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Other profile managers' formats config import and export convert.
+// Launchium's own YAML or JSON is used when no format is given.
+const (
+	formatDirs       = "dirs"       // A directory of user-data-dirs, one per profile
+	formatGoLogin    = "gologin"    // GoLogin's JSON export
+	formatMultilogin = "multilogin" // Multilogin's JSON export
+	formatCSV        = "csv"        // One profile per row: name, proxy, user agent
+)
+
+// foreignFormats lists the formats -format accepts
+var foreignFormats = []string{formatDirs, formatGoLogin, formatMultilogin, formatCSV}
+
+// validForeignFormat checks -format, which export can't use with dirs
+func validForeignFormat(format string, export bool) error {
+	if format == "" {
+		return nil
+	}
+	if !containsString(foreignFormats, format) {
+		return fmt.Errorf("format must be one of %s, got %q", strings.Join(foreignFormats, ", "), format)
+	}
+	if export && format == formatDirs {
+		return fmt.Errorf("profiles can't be exported as dirs; their data dirs are already directories")
+	}
+	return nil
+}
+
+// foreignProfile is what launchium takes from another manager's profile
+type foreignProfile struct {
+	name, notes         string
+	proxyType           string // As the other manager names it, such as "HTTP" or "socks5"
+	host, port          string
+	username, password  string
+	userAgent, language string
+	resolution          string // Screen size such as "1920x1080"
+	tags                []string
+}
+
+// foreignImport is the result of reading another manager's export
+type foreignImport struct {
+	profiles map[string]Profile
+	notes    []string // What didn't come across, such as proxy passwords
+}
+
+// readForeignProfiles reads the profiles of another manager's export at
+// path, checking them as any import
+func readForeignProfiles(format, path string, settings Settings) (foreignImport, error) {
+	var found []foreignProfile
+	dataDirs := map[string]string{}
+	var err error
+	switch format {
+	case formatDirs:
+		found, dataDirs, err = readDataDirs(path)
+	case formatCSV:
+		var data []byte
+		if data, err = fsys.ReadFile(path); err == nil {
+			found, err = readProfileCSV(data)
+		}
+	default:
+		var data []byte
+		if data, err = fsys.ReadFile(path); err == nil {
+			found, err = readProfileJSON(data, format)
+		}
+	}
+	if err != nil {
+		return foreignImport{}, err
+	}
+	if len(found) == 0 {
+		return foreignImport{}, fmt.Errorf("no profiles in it")
+	}
+
+	result := foreignImport{profiles: map[string]Profile{}}
+	for i, fp := range found {
+		name := uniqueName(profileNameFrom(fp.name, i+1), result.profiles)
+		profile, notes := fp.profile(name)
+		profile.DataDir = dataDirs[fp.name]
+		if profile, err = checkProfile(profile, settings); err != nil {
+			return foreignImport{}, fmt.Errorf("profile '%s': %s", name, err)
+		}
+		result.profiles[name] = profile
+		result.notes = append(result.notes, notes...)
+	}
+	return result, nil
+}
+
+// profileNameFrom makes a launchium profile name of another manager's the
+// way generate names variants, falling back to a numbered one for profiles
+// without a usable name
+func profileNameFrom(name string, n int) string {
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if name == "" {
+		return fmt.Sprintf("imported-%d", n)
+	}
+	return name
+}
+
+// uniqueName numbers a name already taken in profiles
+func uniqueName(name string, profiles map[string]Profile) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, taken := profiles[unique]; !taken {
+			return unique
+		}
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// profile converts the other manager's profile, noting what launchium
+// can't keep
+func (fp foreignProfile) profile(name string) (Profile, []string) {
+	p := Profile{Name: name, Description: fp.notes, Tags: fp.tags, Proxy: "none", ProxyType: "none"}
+	notes := []string{}
+
+	proxyType := strings.ToLower(fp.proxyType)
+	switch {
+	case fp.host == "" || proxyType == "none" || proxyType == "":
+	case proxyType == "http" || proxyType == "https":
+		p.Proxy, p.ProxyType = joinHostPort(fp.host, fp.port), "http"
+	case proxyType == "socks5" || proxyType == "socks":
+		p.Proxy, p.ProxyType = joinHostPort(fp.host, fp.port), "socks5"
+	default:
+		notes = append(notes, fmt.Sprintf("%s: dropped its %s proxy, launchium supports http and socks5", name, fp.proxyType))
+	}
+	if p.ProxyType != "none" && (fp.username != "" || fp.password != "") {
+		notes = append(notes, fmt.Sprintf("%s: dropped the proxy's login; the browser asks for it", name))
+	}
+
+	if fp.userAgent != "" {
+		p.Flags = append(p.Flags, Flag{Switch: "--user-agent", Value: fp.userAgent, Enabled: true})
+	}
+	if w, h, ok := strings.Cut(strings.ToLower(fp.resolution), "x"); ok {
+		if _, err := strconv.Atoi(w); err == nil {
+			if _, err := strconv.Atoi(h); err == nil {
+				p.Flags = p.Flags.with("--window-size=" + w + "," + h)
+			}
+		}
+	}
+	// Accept-Language lists such as "en-US,en;q=0.9" start with the locale
+	if language := strings.TrimSpace(strings.Split(strings.Split(fp.language, ",")[0], ";")[0]); language != "" {
+		if validLanguage(language) == nil {
+			p.Locale = language
+		} else {
+			notes = append(notes, fmt.Sprintf("%s: dropped language %q", name, fp.language))
+		}
+	}
+	return p, notes
+}
+
+// joinHostPort joins a proxy's host and port
+func joinHostPort(host, port string) string {
+	if port == "" {
+		return host
+	}
+	return host + ":" + port
+}
+
+// readDataDirs takes each directory in dir that holds a browser's data,
+// as told by its Local State file, as a profile using it where it is
+func readDataDirs(dir string) ([]foreignProfile, map[string]string, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := []foreignProfile{}
+	dataDirs := map[string]string{}
+	for _, entry := range entries {
+		path := filepath.Join(abs, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := fsys.Stat(filepath.Join(path, "Local State")); err != nil {
+			continue
+		}
+		found = append(found, foreignProfile{name: entry.Name()})
+		dataDirs[entry.Name()] = path
+	}
+	return found, dataDirs, nil
+}
+
+// Where GoLogin and Multilogin keep each setting, tried in order. Both
+// have changed their exports over the years, so older layouts are kept.
+var foreignFields = map[string]map[string][]string{
+	formatGoLogin: {
+		"name":       {"name"},
+		"notes":      {"notes"},
+		"proxyType":  {"proxy.mode", "proxyType"},
+		"host":       {"proxy.host"},
+		"port":       {"proxy.port"},
+		"username":   {"proxy.username"},
+		"password":   {"proxy.password"},
+		"userAgent":  {"navigator.userAgent"},
+		"language":   {"navigator.language"},
+		"resolution": {"navigator.resolution"},
+	},
+	formatMultilogin: {
+		"name":       {"name"},
+		"notes":      {"notes"},
+		"proxyType":  {"network.proxy.type", "parameters.proxy.type", "proxy.type"},
+		"host":       {"network.proxy.host", "parameters.proxy.host", "proxy.host"},
+		"port":       {"network.proxy.port", "parameters.proxy.port", "proxy.port"},
+		"username":   {"network.proxy.username", "parameters.proxy.username", "proxy.username"},
+		"password":   {"network.proxy.password", "parameters.proxy.password", "proxy.password"},
+		"userAgent":  {"navigator.userAgent", "parameters.fingerprint.navigator.user_agent"},
+		"language":   {"navigator.language", "parameters.fingerprint.navigator.language"},
+		"resolution": {"navigator.resolution", "parameters.fingerprint.screen.resolution"},
+	},
+}
+
+// readProfileJSON reads a GoLogin or Multilogin export: a list of
+// profiles, or an object holding one under "profiles" or "data"
+func readProfileJSON(data []byte, format string) ([]foreignProfile, error) {
+	var list []map[string]interface{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		var wrapped struct {
+			Profiles []map[string]interface{} `json:"profiles"`
+			Data     []map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, err
+		}
+		list = append(wrapped.Profiles, wrapped.Data...)
+	}
+
+	fields := foreignFields[format]
+	get := func(obj map[string]interface{}, field string) string {
+		for _, path := range fields[field] {
+			if value := jsonPath(obj, path); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	found := []foreignProfile{}
+	for _, obj := range list {
+		found = append(found, foreignProfile{
+			name:       get(obj, "name"),
+			notes:      get(obj, "notes"),
+			proxyType:  get(obj, "proxyType"),
+			host:       get(obj, "host"),
+			port:       get(obj, "port"),
+			username:   get(obj, "username"),
+			password:   get(obj, "password"),
+			userAgent:  get(obj, "userAgent"),
+			language:   get(obj, "language"),
+			resolution: get(obj, "resolution"),
+			tags:       jsonTags(obj["tags"]),
+		})
+	}
+	return found, nil
+}
+
+// jsonPath returns the string or number at a dotted path in a JSON object
+func jsonPath(obj map[string]interface{}, path string) string {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		obj = next
+	}
+	switch value := obj[keys[len(keys)-1]].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+// jsonTags reads tags given as strings or as objects with a name or title
+func jsonTags(value interface{}) []string {
+	items, _ := value.([]interface{})
+	tags := []string{}
+	for _, item := range items {
+		switch tag := item.(type) {
+		case string:
+			tags = append(tags, tag)
+		case map[string]interface{}:
+			if name := jsonPath(tag, "name"); name != "" {
+				tags = append(tags, name)
+			} else if title := jsonPath(tag, "title"); title != "" {
+				tags = append(tags, title)
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// Headers the CSV columns are recognized by, lowercased without spaces,
+// dashes or underscores. -format csv reads name, proxy and user agent;
+// import-csv the rest too.
+var csvColumns = map[string]string{
+	"name":        "name",
+	"profile":     "name",
+	"proxy":       "proxy",
+	"useragent":   "user_agent",
+	"ua":          "user_agent",
+	"proxytype":   "proxy_type",
+	"flags":       "flags",
+	"tags":        "tags",
+	"description": "description",
+	"notes":       "description",
+}
+
+// readProfileCSV reads one profile per row. A header row names the
+// columns; without one they are name, proxy and user agent in that order.
+func readProfileCSV(data []byte) ([]foreignProfile, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := []string{"name", "proxy", "user_agent"}
+	if header, ok := csvHeader(rows[0]); ok {
+		columns, rows = header, rows[1:]
+	}
+	found := []foreignProfile{}
+	for _, row := range rows {
+		fp := foreignProfile{}
+		for i, value := range row {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "name":
+				fp.name = value
+			case "proxy":
+				fp.proxyType, fp.host, fp.port, fp.username, fp.password = splitProxyURL(value)
+			case "user_agent":
+				fp.userAgent = value
+			}
+		}
+		if fp.name != "" || fp.host != "" || fp.userAgent != "" {
+			found = append(found, fp)
+		}
+	}
+	return found, nil
+}
+
+// csvHeader returns the columns a header row names, and false when the
+// row doesn't look like a header
+func csvHeader(row []string) ([]string, bool) {
+	columns := make([]string, len(row))
+	known := false
+	for i, cell := range row {
+		key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(cell)))
+		columns[i] = csvColumns[key]
+		known = known || columns[i] != ""
+	}
+	return columns, known
+}
+
+// splitProxyURL reads a proxy as written in proxy lists: scheme://host:port,
+// user:pass@host:port or host:port:user:pass. Without a scheme it is HTTP.
+func splitProxyURL(s string) (scheme, host, port, username, password string) {
+	if s == "" || strings.EqualFold(s, "none") {
+		return "", "", "", "", ""
+	}
+	scheme = "http"
+	if i := strings.Index(s, "://"); i >= 0 {
+		scheme, s = strings.ToLower(s[:i]), s[i+3:]
+	}
+	s = strings.TrimSuffix(s, "/")
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		username, password, _ = strings.Cut(s[:i], ":")
+		s = s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) == 4 {
+		username, password = parts[2], parts[3]
+		parts = parts[:2]
+	}
+	host = parts[0]
+	if len(parts) > 1 {
+		port = parts[1]
+	}
+	return scheme, host, port, username, password
+}
+
+// foreignProfileOf converts a launchium profile for another manager
+func foreignProfileOf(p Profile) foreignProfile {
+	fp := foreignProfile{name: p.Name, notes: p.Description, language: p.Locale, tags: p.Tags}
+	if p.ProxyType != "none" && p.Proxy != "none" {
+		fp.proxyType = p.ProxyType
+		fp.host, fp.port, _ = strings.Cut(p.Proxy, ":")
+	}
+	for _, f := range p.Flags {
+		switch {
+		case !f.Enabled:
+		case f.Switch == "--user-agent":
+			fp.userAgent = strings.Trim(f.Value, `'"`)
+		case f.Switch == "--window-size":
+			fp.resolution = strings.Replace(f.Value, ",", "x", 1)
+		}
+	}
+	return fp
+}
+
+// exportForeign renders profiles in another manager's format. Only what
+// they have in common is kept: name, notes, tags, proxy, user agent,
+// language and window size.
+func exportForeign(profiles map[string]Profile, format string) ([]byte, error) {
+	names := sortedProfileNames(profiles)
+	if format == formatCSV {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"name", "proxy", "user_agent"})
+		for _, name := range names {
+			fp := foreignProfileOf(profiles[name])
+			proxy := ""
+			if fp.host != "" {
+				proxy = fp.proxyType + "://" + joinHostPort(fp.host, fp.port)
+			}
+			w.Write([]string{name, proxy, fp.userAgent})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+
+	list := []map[string]interface{}{}
+	for _, name := range names {
+		fp := foreignProfileOf(profiles[name])
+		port, _ := strconv.Atoi(fp.port)
+		obj := map[string]interface{}{"name": fp.name}
+		if fp.notes != "" {
+			obj["notes"] = fp.notes
+		}
+		if len(fp.tags) > 0 {
+			obj["tags"] = fp.tags
+		}
+		navigator := map[string]interface{}{}
+		if fp.userAgent != "" {
+			navigator["userAgent"] = fp.userAgent
+		}
+		if fp.language != "" {
+			navigator["language"] = fp.language
+		}
+		if fp.resolution != "" {
+			navigator["resolution"] = fp.resolution
+		}
+		if len(navigator) > 0 {
+			obj["navigator"] = navigator
+		}
+		switch format {
+		case formatGoLogin:
+			proxy := map[string]interface{}{"mode": "none"}
+			if fp.host != "" {
+				proxy = map[string]interface{}{"mode": fp.proxyType, "host": fp.host, "port": port}
+			}
+			obj["proxy"] = proxy
+		case formatMultilogin:
+			proxy := map[string]interface{}{"type": "NONE"}
+			if fp.host != "" {
+				proxy = map[string]interface{}{"type": strings.ToUpper(fp.proxyType), "host": fp.host, "port": port}
+			}
+			obj["network"] = map[string]interface{}{"proxy": proxy}
+		}
+		list = append(list, obj)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	return append(data, '\n'), err
+}
+
+// writeImportNotes prints what an import of another manager's profiles
+// left behind, grouped so a large fleet doesn't flood the terminal
+func writeImportNotes(w io.Writer, notes []string) {
+	sort.Strings(notes)
+	const shown = 20
+	for i, note := range notes {
+		if i == shown {
+			fmt.Fprintf(w, "note: ... and %d more\n", len(notes)-shown)
+			break
+		}
+		fmt.Fprintf(w, "note: %s\n", note)
+	}
+}
@@ -0,0 +1,693 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Kinds of profile form fields
+const (
+	fieldText = iota
+	fieldSelect
+	fieldArea
+	fieldMulti
+)
+
+// Flags a new profile starts with
+const defaultNewProfileFlags = "--no-first-run --disable-features=RendererCodeIntegrity"
+
+// Styles for the profile form, built from the theme by setStyles
+var (
+	formLabelStyle   lipgloss.Style
+	formFocusStyle   lipgloss.Style
+	formSelectStyle  lipgloss.Style
+	formHintStyle    lipgloss.Style
+	formInvalidStyle lipgloss.Style
+)
+
+// formField is one labeled input of the profile form
+type formField struct {
+	key     string
+	label   string
+	kind    int
+	hint    string
+	input   textinput.Model
+	area    textarea.Model
+	options []string // Values for select and multi fields
+	labels  []string // Display names for select options, descriptions for multi options
+	choice  int
+	picked  []string // Options turned on in a multi field, in the order picked
+}
+
+// value returns the field's current value
+func (f *formField) value() string {
+	switch f.kind {
+	case fieldSelect:
+		return f.options[f.choice]
+	case fieldArea:
+		return f.area.Value()
+	case fieldMulti:
+		return strings.Join(f.picked, ", ")
+	default:
+		return f.input.Value()
+	}
+}
+
+// toggle turns the option under the cursor of a multi field on or off
+func (f *formField) toggle() {
+	option := f.options[f.choice]
+	for i, p := range f.picked {
+		if p == option {
+			f.picked = append(f.picked[:i:i], f.picked[i+1:]...)
+			return
+		}
+	}
+	f.picked = append(f.picked, option)
+}
+
+// profileForm edits a new or existing profile
+type profileForm struct {
+	original   string  // Name of the profile being edited, "" when adding
+	base       Profile // Profile as opened; fields not on the form are kept
+	fields     []*formField
+	focus      int
+	errors     map[string]string
+	touched    map[string]bool // Fields edited so far; only these show errors
+	submitted  bool            // A save was attempted, so show every error
+	confirming bool            // Asking whether to discard unsaved changes
+	initial    map[string]string
+}
+
+// newTextField builds a single-line text field
+func newTextField(key, label, value, hint string) *formField {
+	input := textinput.New()
+	input.SetValue(value)
+	input.Width = formInputWidth
+	input.Prompt = ""
+	return &formField{key: key, label: label, kind: fieldText, hint: hint, input: input}
+}
+
+// newSelectField builds a field cycling through fixed options
+func newSelectField(key, label, value string, options, labels []string, hint string) *formField {
+	f := &formField{key: key, label: label, kind: fieldSelect, hint: hint, options: options, labels: labels}
+	for i, o := range options {
+		if o == value {
+			f.choice = i
+		}
+	}
+	return f
+}
+
+// newMultiField builds a field turning any of several options on, each
+// described by the matching label
+func newMultiField(key, label string, values, options, labels []string, hint string) *formField {
+	return &formField{key: key, label: label, kind: fieldMulti, hint: hint, options: options, labels: labels, picked: append([]string{}, values...)}
+}
+
+// newProfileForm creates the form for profile, or a blank profile when
+// original is ""
+func (cm *ChromiumManager) newProfileForm(original string, profile Profile) *profileForm {
+	// Flags are edited one per line
+	flags := textarea.New()
+	flags.SetValue(profile.Flags.text())
+	flags.SetWidth(formInputWidth)
+	flags.SetHeight(5)
+	flags.ShowLineNumbers = false
+	flags.Prompt = ""
+
+	proxyType := profile.ProxyType
+	if proxyType == "" {
+		proxyType = "none"
+	}
+	proxy := profile.Proxy
+	if proxy == "none" {
+		proxy = ""
+	}
+
+	browsers := append([]string{""}, browserCandidates()...)
+	if profile.Browser != "" && !containsString(browsers, profile.Browser) {
+		browsers = append(browsers, profile.Browser)
+	}
+	browserLabels := make([]string, len(browsers))
+	for i, b := range browsers {
+		browserLabels[i] = b
+		if b == "" {
+			browserLabels[i] = "auto-detect"
+		}
+	}
+
+	presetLabels := make([]string, len(flagPresets))
+	for i, preset := range flagPresets {
+		presetLabels[i] = preset.description
+	}
+
+	notifyLabel := "setting (off)"
+	if cm.settings.Notifications {
+		notifyLabel = "setting (on)"
+	}
+
+	form := &profileForm{
+		original: original,
+		base:     profile,
+		errors:   map[string]string{},
+		touched:  map[string]bool{},
+		fields: []*formField{
+			newTextField("name", "Name", profile.Name, "Unique profile name"),
+			newTextField("description", "Description", profile.Description, "What this profile is for"),
+			newSelectField("proxy_type", "Proxy Type", proxyType,
+				[]string{"none", "http", "socks5"}, []string{"none", "http", "socks5"}, "←/→ to choose"),
+			newTextField("proxy", "Proxy", proxy, "host:port, e.g. 127.0.0.1:8080"),
+			newSelectField("browser", "Browser", profile.Browser, browsers, browserLabels, "←/→ to choose"),
+			newSelectField("channel", "Channel", profile.Channel,
+				append([]string{channelAny}, browserChannels...), append([]string{"any"}, browserChannels...),
+				"←/→ to choose; used when Browser is auto-detect"),
+			newSelectField("fallback", "Fallback", strconv.FormatBool(profile.Fallback),
+				[]string{"false", "true"}, []string{"off", "on"}, "←/→ to choose; try the other installed browsers if this one fails to start"),
+			newMultiField("presets", "Presets", profile.Presets, presetNames(), presetLabels, "←/→ to choose, space to turn on or off"),
+			{key: "flags", label: "Flags", kind: fieldArea, area: flags, hint: "One flag per line; # in front turns it off, # after it adds a note"},
+			newTextField("data_dir", "Data Dir", profile.DataDir, "Leave empty for "+cm.profileDir),
+			newSelectField("ramdisk", "RAM Disk", profile.RAMDisk,
+				[]string{ramDiskOff, ramDiskDiscard, ramDiskPersist}, []string{"off", "discard", "persist"}, "←/→ to choose"),
+			newTextField("memory_limit", "Memory Limit", profile.MemoryLimit, "e.g. 2G; empty for no limit"),
+			newTextField("cpu_weight", "CPU Weight", intFieldValue(profile.CPUWeight), "1-10000, 100 is normal; empty for the default"),
+			newTextField("nice", "Nice", intFieldValue(profile.Nice), "-20 to 19, higher yields to other programs"),
+			newTextField("run_as", "Run As", profile.RunAs, "Local user to run the browser as; empty for you"),
+			newTextField("idle_timeout", "Idle Close", intFieldValue(profile.IdleTimeout), "Minutes without input before closing; empty never closes"),
+			newSelectField("idle_clean", "Idle Clean", strconv.FormatBool(profile.IdleClean),
+				[]string{"false", "true"}, []string{"off", "on"}, "←/→ to choose; clean the profile after an idle close"),
+			newTextField("clean_schedule", "Auto Clean", profile.CleanSchedule, "e.g. cache weekly or all monthly; run by launchium gc"),
+			newTextField("clean_keep", "Clean Keep", strings.Join(profile.CleanKeep, ", "), "Comma separated paths a clean leaves, e.g. Bookmarks, Extensions"),
+			newSelectField("notify", "Notify", profile.Notify,
+				[]string{notifyDefault, notifyOn, notifyOff}, []string{notifyLabel, "on", "off"}, "←/→ to choose; desktop notifications"),
+			newSelectField("singleton", "Singleton", strconv.FormatBool(profile.Singleton),
+				[]string{"false", "true"}, []string{"off", "on"}, "←/→ to choose; raise the running browser instead of launching another"),
+			newTextField("tags", "Tags", strings.Join(profile.Tags, ", "), "Comma separated"),
+			newTextField("color", "Color", profile.Color, "Hex color such as #e8710a"),
+			newTextField("icon", "Icon", profile.Icon, "Emoji or short label"),
+			newTextField("slot", "Slot", intFieldValue(profile.Slot), "1-9: its number key in the main menu and launchium <n> launch it"),
+			newSelectField("protected", "Protected", strconv.FormatBool(profile.Protected),
+				[]string{"false", "true"}, []string{"off", "on"}, "←/→ to choose; ask again before deleting or cleaning it"),
+			newTextField("search_engine", "Search Engine", profile.SearchEngine, strings.Join(searchEngineNames(), ", ")+" or a URL with %s; set in new data dirs"),
+			newTextField("homepage", "Homepage", profile.Homepage, "Opened on startup; set in new data dirs"),
+			newTextField("first_run", "First Run", profile.FirstRun, "A [first_run.<name>] table; empty goes by the browser"),
+			newTextField("locale", "Locale", profile.Locale, "UI language such as de-DE; empty follows the system"),
+			newTextField("spellcheck_languages", "Spellcheck", strings.Join(profile.SpellcheckLanguages, ", "), "Comma separated, e.g. de-DE, en-US"),
+			newTextField("ca_certs", "CA Certs", strings.Join(profile.CACerts, ", "), "Comma separated PEM or DER files the browser trusts"),
+			newSelectField("webrtc_policy", "WebRTC", profile.WebRTCPolicy,
+				append([]string{webRTCUnchanged}, webRTCPolicies...), []string{"browser's own", webRTCDefault, webRTCNoUDP, webRTCDisable}, "←/→ to choose; disable-non-proxied-udp keeps a proxied profile's IP hidden"),
+			newSelectField("password_store", "Passwords", passwordStoreName(profile.PasswordStore),
+				[]string{passwordStoreUnchanged, passwordStoreBasic, passwordStoreGnome, passwordStoreKWallet, passwordStoreKWallet5, passwordStoreKWallet6},
+				[]string{"as first launched", passwordStoreBasic, passwordStoreGnome, passwordStoreKWallet, passwordStoreKWallet5, passwordStoreKWallet6}, "←/→ to choose; Linux keyring saved logins are encrypted with"),
+			newSelectField("do_not_track", "Do Not Track", strconv.FormatBool(profile.DoNotTrack),
+				[]string{"false", "true"}, []string{"off", "on"}, "←/→ to choose; ask sites not to track you"),
+			newSelectField("block_third_party_cookies", "3P Cookies", strconv.FormatBool(profile.BlockThirdPartyCookies),
+				[]string{"false", "true"}, []string{"allowed", "blocked"}, "←/→ to choose; blocked in every window, not only incognito"),
+			newSelectField("disable_hyperlink_auditing", "Link Pings", strconv.FormatBool(profile.DisableHyperlinkAuditing),
+				[]string{"false", "true"}, []string{"browser's own", "off"}, "←/→ to choose; off stops <a ping> click tracking"),
+			newTextField("permissions", "Permissions", strings.Join(profile.Permissions, ", "), "Comma separated, e.g. notifications=block, clipboard=allow https://app.example.com"),
+			newTextField("auto_select_certs", "Client Certs", strings.Join(profile.AutoSelectCerts, "; "), "Semicolon separated, e.g. https://[*.]example.com issuer:CN=Corp CA"),
+		},
+	}
+	form.initial = form.values()
+	form.focusField(0)
+	form.validate(cm)
+	return form
+}
+
+// values snapshots every field's value by key
+func (f *profileForm) values() map[string]string {
+	values := map[string]string{}
+	for _, field := range f.fields {
+		values[field.key] = field.value()
+	}
+	return values
+}
+
+// dirty reports whether anything changed since the form opened
+func (f *profileForm) dirty() bool {
+	for key, value := range f.values() {
+		if f.initial[key] != value {
+			return true
+		}
+	}
+	return false
+}
+
+// focusField moves keyboard focus to the field at index i
+func (f *profileForm) focusField(i int) tea.Cmd {
+	for _, field := range f.fields {
+		field.input.Blur()
+		field.area.Blur()
+	}
+	f.focus = (i + len(f.fields)) % len(f.fields)
+	field := f.fields[f.focus]
+	switch field.kind {
+	case fieldText:
+		return field.input.Focus()
+	case fieldArea:
+		return field.area.Focus()
+	}
+	return nil
+}
+
+// validate checks every field and records errors for inline display
+func (f *profileForm) validate(cm *ChromiumManager) bool {
+	f.errors = map[string]string{}
+	v := f.values()
+
+	name := strings.TrimSpace(v["name"])
+	if name == "" {
+		f.errors["name"] = "Name is required"
+	} else if err := validProfileName(name); err != nil {
+		f.errors["name"] = err.Error()
+	} else if _, exists := cm.profiles[name]; exists && name != f.original {
+		f.errors["name"] = fmt.Sprintf("Profile '%s' already exists", name)
+	}
+
+	if v["proxy_type"] != "none" {
+		proxy := strings.TrimSpace(v["proxy"])
+		if proxy == "" {
+			f.errors["proxy"] = "Proxy address is required for " + v["proxy_type"]
+		} else if !strings.Contains(proxy, ":") {
+			f.errors["proxy"] = "Expected host:port"
+		}
+	}
+
+	if schedule := strings.TrimSpace(v["clean_schedule"]); schedule != "" {
+		if _, err := parseCleanSchedule(schedule); err != nil {
+			f.errors["clean_schedule"] = err.Error()
+		}
+	}
+	if err := validCleanKeep(parsePathList(v["clean_keep"])); err != nil {
+		f.errors["clean_keep"] = err.Error()
+	}
+
+	limits := Profile{MemoryLimit: strings.TrimSpace(v["memory_limit"])}
+	if limits.MemoryLimit != "" {
+		if _, err := parseByteSize(limits.MemoryLimit); err != nil {
+			f.errors["memory_limit"] = err.Error()
+		}
+	}
+	for key, dst := range map[string]*int{"cpu_weight": &limits.CPUWeight, "nice": &limits.Nice} {
+		if value := strings.TrimSpace(v[key]); value != "" {
+			if n, err := strconv.Atoi(value); err != nil {
+				f.errors[key] = "Expected a whole number"
+			} else {
+				*dst = n
+			}
+		}
+	}
+	if runAs := strings.TrimSpace(v["run_as"]); runAs != "" {
+		if _, err := user.Lookup(runAs); err != nil {
+			f.errors["run_as"] = fmt.Sprintf("No local user named '%s'", runAs)
+		}
+	}
+	if value := strings.TrimSpace(v["idle_timeout"]); value != "" {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			f.errors["idle_timeout"] = "Expected a number of minutes"
+		}
+	}
+	if limits.CPUWeight != 0 && (limits.CPUWeight < minCPUWeight || limits.CPUWeight > maxCPUWeight) {
+		f.errors["cpu_weight"] = fmt.Sprintf("Must be between %d and %d", minCPUWeight, maxCPUWeight)
+	}
+	if limits.Nice < minNice || limits.Nice > maxNice {
+		f.errors["nice"] = fmt.Sprintf("Must be between %d and %d", minNice, maxNice)
+	}
+
+	if value := strings.TrimSpace(v["slot"]); value != "" {
+		n, err := strconv.Atoi(value)
+		switch {
+		case err != nil || n < 1 || n > maxSlot:
+			f.errors["slot"] = fmt.Sprintf("Must be between 1 and %d", maxSlot)
+		case slotHolder(cm.profiles, n, f.original) != "":
+			f.errors["slot"] = fmt.Sprintf("'%s' already has slot %d", slotHolder(cm.profiles, n, f.original), n)
+		}
+	}
+
+	if color := strings.TrimSpace(v["color"]); color != "" {
+		if _, err := parseHexColor(color); err != nil {
+			f.errors["color"] = err.Error()
+		}
+	}
+	if engine := strings.TrimSpace(v["search_engine"]); engine != "" {
+		if _, err := resolveSearchEngine(engine); err != nil {
+			f.errors["search_engine"] = err.Error()
+		}
+	}
+	if homepage := strings.TrimSpace(v["homepage"]); homepage != "" {
+		if err := validHomepage(homepage); err != nil {
+			f.errors["homepage"] = err.Error()
+		}
+	}
+	if err := cm.settings.validFirstRun(strings.TrimSpace(v["first_run"])); err != nil {
+		f.errors["first_run"] = err.Error()
+	}
+	if locale := strings.TrimSpace(v["locale"]); locale != "" {
+		if err := validLanguage(locale); err != nil {
+			f.errors["locale"] = err.Error()
+		}
+	}
+	if err := validLanguages(parseTagList(v["spellcheck_languages"])); err != nil {
+		f.errors["spellcheck_languages"] = err.Error()
+	}
+	if _, err := (Profile{CACerts: parsePathList(v["ca_certs"])}).trustedCAs(); err != nil {
+		f.errors["ca_certs"] = err.Error()
+	}
+	if err := validPermissions(parsePathList(v["permissions"])); err != nil {
+		f.errors["permissions"] = err.Error()
+	}
+	if err := validAutoSelectCerts(parseAutoSelectList(v["auto_select_certs"])); err != nil {
+		f.errors["auto_select_certs"] = err.Error()
+	}
+
+	return len(f.errors) == 0
+}
+
+// profile builds the Profile described by the form
+func (f *profileForm) profile() Profile {
+	v := f.values()
+	proxy := strings.TrimSpace(v["proxy"])
+	if v["proxy_type"] == "none" || proxy == "" {
+		proxy = "none"
+	}
+	p := f.base
+	p.Name = strings.TrimSpace(v["name"])
+	p.Description = strings.TrimSpace(v["description"])
+	p.Proxy = proxy
+	p.ProxyType = v["proxy_type"]
+	p.Presets = parseTagList(v["presets"])
+	p.Flags = parseFlagText(v["flags"])
+	p.Browser = v["browser"]
+	p.Channel = v["channel"]
+	p.DataDir = strings.TrimSpace(v["data_dir"])
+	p.RAMDisk = v["ramdisk"]
+	p.MemoryLimit = strings.TrimSpace(v["memory_limit"])
+	p.CPUWeight, _ = strconv.Atoi(strings.TrimSpace(v["cpu_weight"]))
+	p.Nice, _ = strconv.Atoi(strings.TrimSpace(v["nice"]))
+	p.RunAs = strings.TrimSpace(v["run_as"])
+	p.IdleTimeout, _ = strconv.Atoi(strings.TrimSpace(v["idle_timeout"]))
+	p.IdleClean = v["idle_clean"] == "true"
+	p.CleanSchedule = strings.Join(strings.Fields(v["clean_schedule"]), " ")
+	p.CleanKeep = parsePathList(v["clean_keep"])
+	p.Notify = v["notify"]
+	p.Fallback = v["fallback"] == "true"
+	p.Singleton = v["singleton"] == "true"
+	p.Tags = parseTagList(v["tags"])
+	p.Color = strings.TrimSpace(v["color"])
+	p.Icon = strings.TrimSpace(v["icon"])
+	p.Slot, _ = strconv.Atoi(strings.TrimSpace(v["slot"]))
+	p.Protected = v["protected"] == "true"
+	p.SearchEngine = strings.TrimSpace(v["search_engine"])
+	p.Homepage = strings.TrimSpace(v["homepage"])
+	p.FirstRun = strings.TrimSpace(v["first_run"])
+	p.Locale = strings.TrimSpace(v["locale"])
+	p.SpellcheckLanguages = parseTagList(v["spellcheck_languages"])
+	p.CACerts = parsePathList(v["ca_certs"])
+	p.AutoSelectCerts = parseAutoSelectList(v["auto_select_certs"])
+	p.WebRTCPolicy = v["webrtc_policy"]
+	p.PasswordStore = v["password_store"]
+	p.Permissions = parsePathList(v["permissions"])
+	p.DoNotTrack = v["do_not_track"] == "true"
+	p.BlockThirdPartyCookies = v["block_third_party_cookies"] == "true"
+	p.DisableHyperlinkAuditing = v["disable_hyperlink_auditing"] == "true"
+	return p
+}
+
+// intFieldValue shows a number in a text field, leaving 0 empty
+func intFieldValue(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// openProfileForm switches to the form for the named profile, or a new one
+func (cm *ChromiumManager) openProfileForm(name string) tea.Cmd {
+	profile := Profile{Proxy: "none", ProxyType: "none", Flags: parseFlagList(defaultNewProfileFlags)}
+	if name != "" {
+		profile = cm.profiles[name]
+	}
+	return cm.showProfileForm(name, profile)
+}
+
+// showProfileForm switches to the form filled in from profile
+func (cm *ChromiumManager) showProfileForm(original string, profile Profile) tea.Cmd {
+	cm.form = cm.newProfileForm(original, profile)
+	width, _ := cm.contentSize()
+	cm.form.setWidth(width)
+	cm.clearMessage()
+	cm.currentView = "profile_form"
+	return textinput.Blink
+}
+
+// saveProfileForm validates the form and stores the profile, renaming the
+// original (and its data directory) if the name changed
+func (cm *ChromiumManager) saveProfileForm() bool {
+	form := cm.form
+	form.submitted = true
+	if !form.validate(cm) {
+		cm.notify(levelWarn, "Fix the highlighted fields before saving")
+		return false
+	}
+
+	profile := form.profile()
+	if form.original != "" && form.original != profile.Name {
+		if err := cm.renameProfile(form.original, profile.Name); err != nil {
+			cm.notify(levelError, "renaming profile: %s", err)
+			return false
+		}
+	}
+
+	cm.profiles[profile.Name] = profile
+	if err := cm.saveProfiles(); err != nil {
+		cm.notify(levelError, "saving config: %s", err)
+		return false
+	}
+	switch {
+	case cm.setup != nil:
+		cm.setup = nil
+		cm.firstRun = false
+		cm.notify(levelInfo, "Setup complete. Profile '%s' is ready to launch", profile.Name)
+	case len(profile.lint()) > 0:
+		cm.notify(levelWarn, "Profile '%s' saved, but %s", profile.Name, lintSummary(profile.lint()))
+	case form.original == "":
+		cm.notify(levelInfo, "Profile '%s' created", profile.Name)
+	default:
+		cm.notify(levelInfo, "Profile '%s' updated", profile.Name)
+	}
+	cm.form = nil
+	cm.currentView = "main"
+	return true
+}
+
+// closeProfileForm leaves the form without saving, going back to setup if
+// the form is its last step
+func (cm *ChromiumManager) closeProfileForm() tea.Cmd {
+	cm.form = nil
+	cm.clearMessage()
+	cm.currentView = "main"
+	if cm.setup != nil {
+		cm.currentView = "setup"
+		return textinput.Blink
+	}
+	return nil
+}
+
+// updateProfileForm handles input while the profile form is shown
+func (cm *ChromiumManager) updateProfileForm(msg tea.KeyMsg) tea.Cmd {
+	form := cm.form
+
+	// Discard confirmation after Esc with unsaved changes
+	if form.confirming {
+		switch {
+		case key.Matches(msg, cm.keys.Confirm):
+			return cm.closeProfileForm()
+		case key.Matches(msg, cm.keys.Cancel, cm.keys.Back):
+			form.confirming = false
+		}
+		return nil
+	}
+
+	field := form.fields[form.focus]
+
+	// Arrow keys move between lines inside the flags editor and only leave
+	// it from the first or last line
+	if field.kind == fieldArea {
+		if key.Matches(msg, field.area.KeyMap.LinePrevious) && field.area.Line() > 0 ||
+			key.Matches(msg, field.area.KeyMap.LineNext) && field.area.Line() < field.area.LineCount()-1 {
+			var cmd tea.Cmd
+			field.area, cmd = field.area.Update(msg)
+			return cmd
+		}
+	}
+
+	switch {
+	case key.Matches(msg, cm.keys.Back):
+		if form.dirty() {
+			form.confirming = true
+			return nil
+		}
+		return cm.closeProfileForm()
+	case key.Matches(msg, cm.keys.Save):
+		cm.saveProfileForm()
+		return nil
+	case key.Matches(msg, cm.keys.NextField):
+		return form.focusField(form.focus + 1)
+	case key.Matches(msg, cm.keys.PrevField):
+		return form.focusField(form.focus - 1)
+	case key.Matches(msg, cm.keys.Select):
+		// Enter adds a line in the flags editor and saves elsewhere
+		if field.kind != fieldArea {
+			cm.saveProfileForm()
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch field.kind {
+	case fieldSelect:
+		switch {
+		case key.Matches(msg, cm.keys.PrevOption):
+			field.choice = (field.choice + len(field.options) - 1) % len(field.options)
+		case key.Matches(msg, cm.keys.NextOption):
+			field.choice = (field.choice + 1) % len(field.options)
+		}
+	case fieldMulti:
+		switch {
+		case key.Matches(msg, cm.keys.Mark):
+			field.toggle()
+		case key.Matches(msg, cm.keys.PrevOption):
+			field.choice = (field.choice + len(field.options) - 1) % len(field.options)
+		case key.Matches(msg, cm.keys.NextOption):
+			field.choice = (field.choice + 1) % len(field.options)
+		}
+	case fieldArea:
+		field.area, cmd = field.area.Update(msg)
+	default:
+		field.input, cmd = field.input.Update(msg)
+	}
+
+	form.touched[field.key] = true
+	form.validate(cm)
+	cm.clearMessage()
+	return cmd
+}
+
+// Widest the form's inputs get on large terminals
+const formInputWidth = 50
+
+// setWidth fits the inputs beside the labels in width columns
+func (f *profileForm) setWidth(width int) {
+	w := max(min(formInputWidth, width-formLabelStyle.GetWidth()-1), 10)
+	for _, field := range f.fields {
+		switch field.kind {
+		case fieldText:
+			field.input.Width = w
+		case fieldArea:
+			field.area.SetWidth(w)
+		}
+	}
+}
+
+// view renders the profile form, scrolled to keep the focused field
+// visible when it is taller than height
+func (f *profileForm) view(height int) string {
+	title := "New Profile"
+	if f.original != "" {
+		title = "Edit Profile: " + f.original
+	}
+	header := lipgloss.NewStyle().Bold(true).Render(title) + "\n\n"
+
+	var s string
+	focusEnd := 0
+	for i, field := range f.fields {
+		labelStyle := formLabelStyle
+		if i == f.focus {
+			labelStyle = formFocusStyle
+		}
+
+		var value string
+		switch field.kind {
+		case fieldSelect:
+			value = field.labels[field.choice]
+			if i == f.focus {
+				value = "‹ " + formSelectStyle.Render(value) + " ›"
+			}
+		case fieldArea:
+			value = field.area.View()
+		case fieldMulti:
+			value = field.multiView(i == f.focus)
+		default:
+			value = field.input.View()
+		}
+		s += lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(field.label), value) + "\n"
+
+		hint := field.hint
+		if field.kind == fieldMulti {
+			hint = field.labels[field.choice] + "; " + hint
+		}
+		if err, ok := f.errors[field.key]; ok && (f.submitted || f.touched[field.key]) {
+			s += formLabelStyle.Render("") + formInvalidStyle.Render(err) + "\n"
+		} else if i == f.focus && hint != "" {
+			s += formLabelStyle.Render("") + formHintStyle.Render(hint) + "\n"
+		}
+		if i == f.focus {
+			focusEnd = lipgloss.Height(s) - 1
+		}
+	}
+
+	footer := ""
+	if f.confirming {
+		footer = "\n" + errStyle.Render("Discard unsaved changes? (y/n)")
+	}
+
+	// Scroll the fields when the whole form doesn't fit
+	room := height - lipgloss.Height(header) - lipgloss.Height(footer) + 1
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if room > 0 && len(lines) > room {
+		start := max(focusEnd-room, 0)
+		lines = lines[start : start+room]
+	}
+	return header + strings.Join(lines, "\n") + "\n" + footer
+}
+
+// multiView renders a multi field: the options turned on, or every option
+// with a checkbox while the field has focus
+func (f *formField) multiView(focused bool) string {
+	if !focused {
+		if len(f.picked) == 0 {
+			return formHintStyle.Render("none")
+		}
+		return f.value()
+	}
+	lines := []string{}
+	for i, option := range f.options {
+		box := "[ ] "
+		if containsString(f.picked, option) {
+			box = "[x] "
+		}
+		if i == f.choice {
+			lines = append(lines, "‹ "+formSelectStyle.Render(box+option)+" ›")
+		} else {
+			lines = append(lines, "  "+box+option)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
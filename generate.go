@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Most profiles one spec may generate, so a typo in an axis can't flood
+// the config
+const maxGenerated = 1000
+
+// matrixSpec describes a fleet of profiles: every combination of one
+// variant from each axis of the matrix, on top of the base settings
+//
+//	name: scrape-{proxy}-{agent}-{locale}
+//	base:
+//	  proxy_type: socks5
+//	  tags: [scraping]
+//	matrix:
+//	  proxy:
+//	    - {name: us, proxy: "us.example.net:1080"}
+//	    - {name: eu, proxy: "eu.example.net:1080"}
+//	  agent:
+//	    - {name: win, flags: "--user-agent='Mozilla/5.0 (Windows NT 10.0; Win64; x64)'"}
+//	    - {name: mac, flags: "--user-agent='Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)'"}
+//	  locale: [en-US, de-DE]
+//
+// A variant is a map of profile settings with an optional name for the
+// template, or a single value for the setting the axis is named after.
+type matrixSpec struct {
+	Name   string                   `yaml:"name"`
+	Base   map[string]interface{}   `yaml:"base"`
+	Matrix map[string][]interface{} `yaml:"matrix"`
+}
+
+// matrixVariant is one value of an axis: its name in the template and
+// the settings it adds
+type matrixVariant struct {
+	name     string
+	settings map[string]interface{}
+}
+
+// templateAxis matches the {axis} placeholders of a name template
+var templateAxis = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// unsafeNameChars are replaced in variant names put into profile names
+var unsafeNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// variants reads the values of an axis
+func (s matrixSpec) variants(axis string) ([]matrixVariant, error) {
+	variants := []matrixVariant{}
+	for i, value := range s.Matrix[axis] {
+		variant := matrixVariant{settings: map[string]interface{}{}}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, setting := range v {
+				if key == "name" {
+					variant.name = fmt.Sprint(setting)
+					continue
+				}
+				variant.settings[key] = setting
+			}
+			if variant.name == "" && len(variant.settings) == 1 {
+				for _, setting := range variant.settings {
+					variant.name = fmt.Sprint(setting)
+				}
+			}
+		case []interface{}, nil:
+			return nil, fmt.Errorf("matrix axis %s: value %d must be a setting or a map of settings", axis, i+1)
+		default:
+			variant.name = fmt.Sprint(v)
+			variant.settings[axis] = v
+		}
+		variant.name = strings.Trim(unsafeNameChars.ReplaceAllString(strings.ToLower(variant.name), "-"), "-")
+		if variant.name == "" {
+			return nil, fmt.Errorf("matrix axis %s: value %d needs a name", axis, i+1)
+		}
+		variants = append(variants, variant)
+	}
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("matrix axis %s has no values", axis)
+	}
+	return variants, nil
+}
+
+// mergeSetting adds a variant's setting to a profile's: flags are added
+// after the ones there, lists such as tags are joined, and anything else
+// replaces what was there
+func mergeSetting(settings map[string]interface{}, key string, value interface{}) {
+	switch existing := settings[key].(type) {
+	case string:
+		if added, ok := value.(string); ok && key == "flags" {
+			settings[key] = strings.TrimSpace(existing + " " + added)
+			return
+		}
+	case []interface{}:
+		if added, ok := value.([]interface{}); ok {
+			settings[key] = append(append([]interface{}{}, existing...), added...)
+			return
+		}
+	}
+	settings[key] = value
+}
+
+// expand returns the definition of every profile of the spec, by name
+func (s matrixSpec) expand() (map[string]map[string]interface{}, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("the spec needs a name template such as qa-{proxy}-{locale}")
+	}
+	axes := make([]string, 0, len(s.Matrix))
+	for axis := range s.Matrix {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+	for _, match := range templateAxis.FindAllStringSubmatch(s.Name, -1) {
+		if _, ok := s.Matrix[match[1]]; !ok {
+			return nil, fmt.Errorf("name template uses {%s}, which isn't a matrix axis", match[1])
+		}
+	}
+
+	// Every combination, one variant of each axis
+	combinations := [][]matrixVariant{{}}
+	for _, axis := range axes {
+		variants, err := s.variants(axis)
+		if err != nil {
+			return nil, err
+		}
+		if len(combinations)*len(variants) > maxGenerated {
+			return nil, fmt.Errorf("the matrix makes more than %d profiles", maxGenerated)
+		}
+		next := [][]matrixVariant{}
+		for _, combination := range combinations {
+			for _, variant := range variants {
+				next = append(next, append(append([]matrixVariant{}, combination...), variant))
+			}
+		}
+		combinations = next
+	}
+
+	profiles := map[string]map[string]interface{}{}
+	for _, combination := range combinations {
+		settings := map[string]interface{}{}
+		for key, value := range s.Base {
+			settings[key] = value
+		}
+		name := s.Name
+		for i, variant := range combination {
+			name = strings.ReplaceAll(name, "{"+axes[i]+"}", variant.name)
+			for key, value := range variant.settings {
+				mergeSetting(settings, key, value)
+			}
+		}
+		if _, taken := profiles[name]; taken {
+			return nil, fmt.Errorf("the name template makes '%s' more than once; give it every axis with more than one value", name)
+		}
+		profiles[name] = settings
+	}
+	return profiles, nil
+}
+
+// generateProfiles adds the profiles of a matrix spec to the config, or
+// updates them when they were generated before. With dryRun the config
+// is left alone and the profiles are only listed.
+func (cm *ChromiumManager) generateProfiles(data []byte, dryRun bool) ([]string, error) {
+	spec := matrixSpec{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	profiles, err := spec.expand()
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := yaml.Marshal(profileDefinitions{Profiles: profiles})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		generated, err := readProfileDefinitions(definitions, cm.settings)
+		if err != nil {
+			return nil, err
+		}
+		return sortedProfileNames(generated), nil
+	}
+	overwrite := func(string) (string, error) { return "overwrite", nil }
+	return cm.importProfiles(definitions, importMerge, overwrite)
+}
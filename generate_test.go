@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMatrix = `
+name: scrape-{proxy}-{locale}
+base:
+  proxy_type: socks5
+  tags: [scraping]
+  flags: --no-first-run
+matrix:
+  proxy:
+    - {name: us, proxy: "us.example.net:1080", tags: [us]}
+    - {name: eu, proxy: "eu.example.net:1080", flags: --lang=de}
+  locale: [en-US, de-DE]
+`
+
+func TestGenerateDryRun(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	names, err := cm.generateProfiles([]byte(testMatrix), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"scrape-eu-de-de", "scrape-eu-en-us", "scrape-us-de-de", "scrape-us-en-us"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("dry run lists %q, want %q", names, want)
+	}
+	if len(cm.profiles) != 2 {
+		t.Errorf("a dry run changed the profiles: %q", sortedProfileNames(cm.profiles))
+	}
+}
+
+func TestGenerateProfiles(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	if _, err := cm.generateProfiles([]byte(testMatrix), false); err != nil {
+		t.Fatal(err)
+	}
+	us := cm.profiles["scrape-us-en-us"]
+	if us.Proxy != "us.example.net:1080" || us.ProxyType != "socks5" || us.Locale != "en-US" {
+		t.Errorf("scrape-us-en-us is %+v", us)
+	}
+	if !reflect.DeepEqual(us.Tags, []string{"scraping", "us"}) {
+		t.Errorf("tags are %q, want the base's and the variant's", us.Tags)
+	}
+	if got := cm.profiles["scrape-eu-de-de"].Flags.String(); got != "--no-first-run --lang=de" {
+		t.Errorf("flags are %q, want the base's then the variant's", got)
+	}
+
+	// Generating again updates the profiles rather than adding more
+	changes, err := cm.generateProfiles([]byte(strings.Replace(testMatrix, "socks5", "http", 1)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 || !strings.HasPrefix(changes[0], "overwrote ") || cm.profiles["scrape-us-en-us"].ProxyType != "http" {
+		t.Errorf("generating again made %q", changes)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{"matrix: {locale: [en-US]}", "needs a name template"},
+		{"name: a-{proxy}\nmatrix: {locale: [en-US]}", "isn't a matrix axis"},
+		{"name: a\nmatrix: {locale: [en-US, de-DE]}", "more than once"},
+		{"name: a-{locale}\nmatrix: {locale: []}", "has no values"},
+		{"name: a-{locale}\nmatrix: {locale: [[en-US]]}", "must be a setting"},
+		{"name: a-{proxy}\nmatrix: {proxy: [{proxy: a, proxy_type: http}]}", "needs a name"},
+	}
+	for _, tt := range tests {
+		m, _ := useFakes(t)
+		_, err := exchangeManager(m).generateProfiles([]byte(tt.spec), true)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q: error = %v, want %q", tt.spec, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// goldenExt is added to a data dir's path for its golden image: a copy of
+// the data dir kept beside it, which launch -fresh starts from every time
+const goldenExt = ".golden"
+
+// goldenPath returns where the profile's golden image is kept
+func (cm *ChromiumManager) goldenPath(profile Profile) string {
+	return cm.profilePath(profile) + goldenExt
+}
+
+// goldenSaved returns when the profile's golden image was saved, and
+// whether it has one
+func (cm *ChromiumManager) goldenSaved(name string) (time.Time, bool) {
+	info, err := os.Stat(cm.goldenPath(cm.profiles[name]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// errNoGolden is returned for a profile without a golden image
+func errNoGolden(name string) error {
+	return fmt.Errorf("profile '%s' has no golden image; save one with launchium golden save -profile %s", name, name)
+}
+
+// saveGolden makes a closed profile's data dir, as it is now, its golden
+// image, replacing the one saved before. The copy is forked like a clone's
+// so it takes little space where the filesystem can share blocks, and
+// leaves out the caches.
+func (cm *ChromiumManager) saveGolden(ctx context.Context, name string) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if cm.isArchived(name) {
+		return "", errArchived(name)
+	}
+	path := cm.profilePath(profile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("profile '%s' has no data yet; launch it and set it up first", name)
+	}
+	if _, running := runningPID(path); running {
+		return "", fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	if err := reclaimDataDir(path); err != nil {
+		return "", err
+	}
+
+	// Copy next to the old image, so a failed save keeps it
+	golden := cm.goldenPath(profile)
+	staging := golden + ".new"
+	os.RemoveAll(staging)
+	stats, err := forkDir(ctx, path, staging)
+	if err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("copying data directory: %w", err)
+	}
+	if err := os.RemoveAll(golden); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("removing the old golden image: %w", err)
+	}
+	if err := os.Rename(staging, golden); err != nil {
+		return "", fmt.Errorf("saving golden image: %w", err)
+	}
+	return fmt.Sprintf("Saved the golden image of '%s' (%s)", name, stats), nil
+}
+
+// dropGolden deletes a profile's golden image
+func (cm *ChromiumManager) dropGolden(name string) error {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return profileNotFound(name)
+	}
+	if _, ok := cm.goldenSaved(name); !ok {
+		return errNoGolden(name)
+	}
+	if err := os.RemoveAll(cm.goldenPath(profile)); err != nil {
+		return fmt.Errorf("deleting golden image: %w", err)
+	}
+	return nil
+}
+
+// restoreGolden replaces a closed profile's data dir with a fresh copy of
+// its golden image
+func (cm *ChromiumManager) restoreGolden(ctx context.Context, name string) error {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return profileNotFound(name)
+	}
+	if _, ok := cm.goldenSaved(name); !ok {
+		return errNoGolden(name)
+	}
+	if cm.isArchived(name) {
+		return errArchived(name)
+	}
+	path := cm.profilePath(profile)
+	if _, running := runningPID(path); running {
+		return fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	if err := reclaimDataDir(path); err != nil {
+		return err
+	}
+
+	defer cm.invalidateSize(name)
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("removing data directory: %w", err)
+	}
+	if _, err := forkDir(ctx, cm.goldenPath(profile), path); err != nil {
+		return fmt.Errorf("restoring golden image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoldenImage(t *testing.T) {
+	cm := &ChromiumManager{
+		profileDir: filepath.Join(t.TempDir(), "profiles"),
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		sizes:      map[string]int64{},
+		profiles:   map[string]Profile{"demo": {Name: "demo"}},
+	}
+	cm.loadState()
+	ctx := context.Background()
+	dataDir := cm.profilePath(cm.profiles["demo"])
+
+	if _, err := cm.saveGolden(ctx, "demo"); err == nil || !strings.Contains(err.Error(), "no data yet") {
+		t.Errorf("saving a profile without data gave %v", err)
+	}
+	if err := cm.restoreGolden(ctx, "demo"); err == nil || !strings.Contains(err.Error(), "no golden image") {
+		t.Errorf("restoring without a golden image gave %v", err)
+	}
+
+	os.MkdirAll(filepath.Join(dataDir, "Default", "Cache"), 0755)
+	os.WriteFile(filepath.Join(dataDir, "Default", "Bookmarks"), []byte("set up"), 0644)
+	os.WriteFile(filepath.Join(dataDir, "Default", "Cache", "data_0"), []byte("cached"), 0644)
+	if _, err := cm.saveGolden(ctx, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.goldenSaved("demo"); !ok {
+		t.Fatal("the golden image wasn't saved")
+	}
+	if _, err := os.Stat(filepath.Join(cm.goldenPath(cm.profiles["demo"]), "Default", "Cache", "data_0")); err == nil {
+		t.Error("the golden image kept the cache")
+	}
+
+	// A session changes the data; restoring brings back the image
+	os.WriteFile(filepath.Join(dataDir, "Default", "Bookmarks"), []byte("changed"), 0644)
+	os.WriteFile(filepath.Join(dataDir, "Default", "History"), []byte("visited"), 0644)
+	if err := cm.restoreGolden(ctx, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dataDir, "Default", "Bookmarks")); string(data) != "set up" {
+		t.Errorf("Bookmarks after restoring is %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "Default", "History")); err == nil {
+		t.Error("restoring kept the session's history")
+	}
+
+	if err := cm.dropGolden("demo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.goldenSaved("demo"); ok {
+		t.Error("the golden image is still there after dropping it")
+	}
+	if err := cm.dropGolden("demo"); err == nil {
+		t.Error("dropped a golden image twice")
+	}
+}
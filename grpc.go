@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/mlinton/launchium/launchiumpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Default address for `launchium serve -grpc`
+const defaultGRPCAddr = "127.0.0.1:7778"
+
+// grpcServer implements the gRPC API on top of the REST server's operations
+type grpcServer struct {
+	launchiumpb.UnimplementedLaunchiumServer
+	s *apiServer
+}
+
+// serveGRPC serves the gRPC API on listener
+func (s *apiServer) serveGRPC(listener net.Listener) error {
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.intercept))
+	launchiumpb.RegisterLaunchiumServer(server, &grpcServer{s: s})
+	return server.Serve(listener)
+}
+
+// intercept gives gRPC calls the same authentication, locking and config
+// reloads as REST requests, and maps API errors to gRPC status codes
+func (s *apiServer) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := md.Get("authorization")
+	if len(auth) == 0 || !s.authorized(auth[0]) {
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiRequestTimeout)
+	defer cancel()
+	var resp interface{}
+	err := s.call(ctx, func() (err error) {
+		resp, err = handler(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+// grpcError converts an error to a gRPC status
+func grpcError(err error) error {
+	e, ok := err.(*apiError)
+	if !ok {
+		if errors.Is(err, ErrProfileNotFound) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch e.status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, e.msg)
+}
+
+// toProto converts a profile for the gRPC API
+func toProto(p Profile) *launchiumpb.Profile {
+	return &launchiumpb.Profile{
+		Name:                     p.Name,
+		Description:              p.Description,
+		Proxy:                    p.Proxy,
+		ProxyType:                p.ProxyType,
+		Flags:                    p.Flags.String(),
+		DataDir:                  p.DataDir,
+		Ramdisk:                  p.RAMDisk,
+		Tags:                     p.Tags,
+		Browser:                  p.Browser,
+		Color:                    p.Color,
+		Icon:                     p.Icon,
+		CleanSchedule:            p.CleanSchedule,
+		Notify:                   p.Notify,
+		Channel:                  p.Channel,
+		Fallback:                 p.Fallback,
+		Singleton:                p.Singleton,
+		MemoryLimit:              p.MemoryLimit,
+		CpuWeight:                int32(p.CPUWeight),
+		Nice:                     int32(p.Nice),
+		IdleTimeout:              int32(p.IdleTimeout),
+		IdleClean:                p.IdleClean,
+		RunAs:                    p.RunAs,
+		Presets:                  p.Presets,
+		FlagList:                 flagsToProto(p.Flags),
+		SearchEngine:             p.SearchEngine,
+		Homepage:                 p.Homepage,
+		FirstRun:                 p.FirstRun,
+		Locale:                   p.Locale,
+		SpellcheckLanguages:      p.SpellcheckLanguages,
+		CaCerts:                  p.CACerts,
+		AutoSelectCerts:          p.AutoSelectCerts,
+		WebrtcPolicy:             p.WebRTCPolicy,
+		DoNotTrack:               p.DoNotTrack,
+		BlockThirdPartyCookies:   p.BlockThirdPartyCookies,
+		DisableHyperlinkAuditing: p.DisableHyperlinkAuditing,
+		Permissions:              p.Permissions,
+		Slot:                     int32(p.Slot),
+		Protected:                p.Protected,
+		CleanKeep:                p.CleanKeep,
+		Sessions:                 sessionsToProto(p.Sessions),
+		PasswordStore:            p.PasswordStore,
+	}
+}
+
+// flagsToProto converts a profile's flags for the gRPC API
+func flagsToProto(flags FlagList) []*launchiumpb.Flag {
+	list := []*launchiumpb.Flag{}
+	for _, f := range flags {
+		list = append(list, &launchiumpb.Flag{Switch: f.Switch, Value: f.Value, Enabled: f.Enabled, Note: f.Note})
+	}
+	return list
+}
+
+// flagsFromProto reads a profile's flags from flag_list, or from the flags
+// string when a client only sets that
+func flagsFromProto(p *launchiumpb.Profile) FlagList {
+	if len(p.GetFlagList()) == 0 {
+		return parseFlagList(p.GetFlags())
+	}
+	var flags FlagList
+	for _, f := range p.GetFlagList() {
+		flags = append(flags, Flag{Switch: f.GetSwitch(), Value: f.GetValue(), Enabled: f.GetEnabled(), Note: f.GetNote()})
+	}
+	return flags
+}
+
+// sessionsToProto converts a profile's sessions for the gRPC API, sorted
+// by name
+func sessionsToProto(sessions Sessions) []*launchiumpb.Session {
+	list := []*launchiumpb.Session{}
+	for _, name := range sessions.names() {
+		s := sessions[name]
+		list = append(list, &launchiumpb.Session{Name: name, Urls: s.URLs, WindowSize: s.WindowSize, WindowPosition: s.WindowPosition, Maximized: s.Maximized})
+	}
+	return list
+}
+
+// sessionsFromProto reads a profile's sessions sent over gRPC
+func sessionsFromProto(list []*launchiumpb.Session) Sessions {
+	if len(list) == 0 {
+		return nil
+	}
+	sessions := Sessions{}
+	for _, s := range list {
+		sessions[s.GetName()] = Session{Name: s.GetName(), URLs: s.GetUrls(), WindowSize: s.GetWindowSize(), WindowPosition: s.GetWindowPosition(), Maximized: s.GetMaximized()}
+	}
+	return sessions
+}
+
+// fromProto converts a profile sent over gRPC
+func fromProto(p *launchiumpb.Profile) Profile {
+	return Profile{
+		Name:                     p.GetName(),
+		Description:              p.GetDescription(),
+		Proxy:                    p.GetProxy(),
+		ProxyType:                p.GetProxyType(),
+		Flags:                    flagsFromProto(p),
+		DataDir:                  p.GetDataDir(),
+		RAMDisk:                  p.GetRamdisk(),
+		Tags:                     p.GetTags(),
+		Browser:                  p.GetBrowser(),
+		Color:                    p.GetColor(),
+		Icon:                     p.GetIcon(),
+		CleanSchedule:            p.GetCleanSchedule(),
+		Notify:                   p.GetNotify(),
+		Channel:                  p.GetChannel(),
+		Fallback:                 p.GetFallback(),
+		Singleton:                p.GetSingleton(),
+		MemoryLimit:              p.GetMemoryLimit(),
+		CPUWeight:                int(p.GetCpuWeight()),
+		Nice:                     int(p.GetNice()),
+		IdleTimeout:              int(p.GetIdleTimeout()),
+		IdleClean:                p.GetIdleClean(),
+		RunAs:                    p.GetRunAs(),
+		Presets:                  p.GetPresets(),
+		SearchEngine:             p.GetSearchEngine(),
+		Homepage:                 p.GetHomepage(),
+		FirstRun:                 p.GetFirstRun(),
+		Locale:                   p.GetLocale(),
+		SpellcheckLanguages:      p.GetSpellcheckLanguages(),
+		CACerts:                  p.GetCaCerts(),
+		AutoSelectCerts:          p.GetAutoSelectCerts(),
+		WebRTCPolicy:             p.GetWebrtcPolicy(),
+		DoNotTrack:               p.GetDoNotTrack(),
+		BlockThirdPartyCookies:   p.GetBlockThirdPartyCookies(),
+		DisableHyperlinkAuditing: p.GetDisableHyperlinkAuditing(),
+		Permissions:              p.GetPermissions(),
+		Slot:                     int(p.GetSlot()),
+		Protected:                p.GetProtected(),
+		CleanKeep:                p.GetCleanKeep(),
+		Sessions:                 sessionsFromProto(p.GetSessions()),
+		PasswordStore:            p.GetPasswordStore(),
+	}
+}
+
+func (g *grpcServer) ListProfiles(ctx context.Context, req *launchiumpb.ListProfilesRequest) (*launchiumpb.ListProfilesResponse, error) {
+	resp := &launchiumpb.ListProfilesResponse{}
+	for _, profile := range g.s.listProfiles(req.GetTag()) {
+		resp.Profiles = append(resp.Profiles, toProto(profile))
+	}
+	return resp, nil
+}
+
+func (g *grpcServer) GetProfile(ctx context.Context, req *launchiumpb.GetProfileRequest) (*launchiumpb.Profile, error) {
+	profile, err := g.s.profile(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return toProto(profile), nil
+}
+
+func (g *grpcServer) CreateProfile(ctx context.Context, req *launchiumpb.CreateProfileRequest) (*launchiumpb.Profile, error) {
+	profile, err := g.s.createProfile(fromProto(req.GetProfile()))
+	if err != nil {
+		return nil, err
+	}
+	return toProto(profile), nil
+}
+
+func (g *grpcServer) UpdateProfile(ctx context.Context, req *launchiumpb.UpdateProfileRequest) (*launchiumpb.Profile, error) {
+	profile, err := g.s.updateProfile(req.GetName(), fromProto(req.GetProfile()))
+	if err != nil {
+		return nil, err
+	}
+	return toProto(profile), nil
+}
+
+func (g *grpcServer) DeleteProfile(ctx context.Context, req *launchiumpb.DeleteProfileRequest) (*launchiumpb.DeleteProfileResponse, error) {
+	if err := g.s.deleteProfile(req.GetName(), req.GetPurge(), req.GetForce()); err != nil {
+		return nil, err
+	}
+	return &launchiumpb.DeleteProfileResponse{}, nil
+}
+
+func (g *grpcServer) LaunchProfile(ctx context.Context, req *launchiumpb.LaunchProfileRequest) (*launchiumpb.LaunchProfileResponse, error) {
+	message, err := g.s.launchProfile(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &launchiumpb.LaunchProfileResponse{Message: message}, nil
+}
+
+func (g *grpcServer) CleanProfile(ctx context.Context, req *launchiumpb.CleanProfileRequest) (*launchiumpb.CleanProfileResponse, error) {
+	message, err := g.s.cleanProfile(ctx, req.GetName(), req.GetForce())
+	if err != nil {
+		return nil, err
+	}
+	return &launchiumpb.CleanProfileResponse{Message: message}, nil
+}
+
+func (g *grpcServer) ListRunning(ctx context.Context, req *launchiumpb.ListRunningRequest) (*launchiumpb.ListRunningResponse, error) {
+	resp := &launchiumpb.ListRunningResponse{}
+	for _, inst := range g.s.running() {
+		resp.Instances = append(resp.Instances, &launchiumpb.Instance{
+			Profile:       inst.Profile,
+			Pid:           int32(inst.PID),
+			DataDir:       inst.DataDir,
+			UptimeSeconds: inst.UptimeSeconds,
+			MemoryBytes:   inst.MemoryBytes,
+			DevtoolsPort:  int32(inst.DevToolsPort),
+		})
+	}
+	return resp, nil
+}
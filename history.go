@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// How many changes `launchium config log` shows by default
+const defaultHistoryLength = 20
+
+// historyDir is the git directory holding the config's history. It sits
+// next to the config, named after it, so configs sharing a directory keep
+// separate histories and a dotfiles repository around it is left alone.
+func (cm *ChromiumManager) historyDir() string {
+	dir, base := filepath.Split(cm.configFile)
+	return filepath.Join(dir, "."+base+".history")
+}
+
+// historyGit runs git on the config's history repository
+func (cm *ChromiumManager) historyGit(args ...string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("config history needs git")
+	}
+	base := []string{
+		"--git-dir=" + cm.historyDir(), "--work-tree=" + filepath.Dir(cm.configFile),
+		"-c", "user.name=launchium", "-c", "user.email=launchium@localhost", "-c", "commit.gpgsign=false",
+	}
+	out, err := exec.Command("git", append(base, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// hasHistory reports whether the config has a history repository yet
+func (cm *ChromiumManager) hasHistory() bool {
+	_, err := os.Stat(cm.historyDir())
+	return err == nil
+}
+
+// commitConfig records the config file in its history with message, if it
+// changed since the last commit
+func (cm *ChromiumManager) commitConfig(message string) error {
+	if !cm.hasHistory() {
+		if _, err := cm.historyGit("init", "--quiet"); err != nil {
+			return err
+		}
+	}
+	base := filepath.Base(cm.configFile)
+	if _, err := cm.historyGit("add", "--", base); err != nil {
+		return err
+	}
+	if _, err := cm.historyGit("diff", "--cached", "--quiet"); err == nil {
+		return nil // Nothing changed
+	}
+	_, err := cm.historyGit("commit", "--quiet", "-m", message)
+	return err
+}
+
+// recordHandEdits commits the config as it is on disk before launchium
+// overwrites it, so edits made outside launchium stay in the history
+func (cm *ChromiumManager) recordHandEdits() error {
+	if _, err := os.Stat(cm.configFile); err != nil {
+		return nil
+	}
+	message := "edited outside launchium"
+	if _, err := cm.historyGit("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		message = "config before history was turned on"
+	}
+	return cm.commitConfig(message)
+}
+
+// saveWithHistory writes the config and records the change in its history.
+// A failure to record it doesn't stop the save.
+func (cm *ChromiumManager) saveWithHistory() error {
+	var oldProfiles map[string]Profile
+	var oldSettings Settings
+	if data, err := ioutil.ReadFile(cm.configFile); err == nil {
+		oldProfiles, oldSettings, _ = parseConfig(data)
+	}
+	historyErr := cm.recordHandEdits()
+
+	if err := ioutil.WriteFile(cm.configFile, cm.renderConfig(), 0644); err != nil {
+		return err
+	}
+	if historyErr == nil {
+		historyErr = cm.commitConfig(describeConfigChange(oldProfiles, oldSettings, cm.mainProfiles(), cm.settings))
+	}
+	if historyErr != nil {
+		return fmt.Errorf("config saved, but not recorded in its history: %s", historyErr)
+	}
+	return nil
+}
+
+// describeConfigChange summarizes what changed between two versions of the
+// config for a history message, such as "edited profile work"
+func describeConfigChange(oldProfiles map[string]Profile, oldSettings Settings, newProfiles map[string]Profile, newSettings Settings) string {
+	var added, edited, deleted []string
+	for name, profile := range newProfiles {
+		old, ok := oldProfiles[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case !reflect.DeepEqual(old, profile):
+			edited = append(edited, name)
+		}
+	}
+	for name := range oldProfiles {
+		if _, ok := newProfiles[name]; !ok {
+			deleted = append(deleted, name)
+		}
+	}
+
+	// A single add and delete of the same profile is a rename
+	if len(added) == 1 && len(deleted) == 1 && len(edited) == 0 {
+		renamed := newProfiles[added[0]]
+		renamed.Name = deleted[0]
+		if reflect.DeepEqual(renamed, oldProfiles[deleted[0]]) {
+			return finishChange([]string{fmt.Sprintf("renamed profile %s to %s", deleted[0], added[0])}, oldSettings, newSettings)
+		}
+	}
+
+	parts := []string{}
+	for _, change := range []struct {
+		verb  string
+		names []string
+	}{{"added", added}, {"edited", edited}, {"deleted", deleted}} {
+		if len(change.names) == 0 {
+			continue
+		}
+		sort.Strings(change.names)
+		noun := "profile"
+		if len(change.names) > 1 {
+			noun = "profiles"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", change.verb, noun, strings.Join(change.names, ", ")))
+	}
+	return finishChange(parts, oldSettings, newSettings)
+}
+
+// finishChange adds a settings change to the profile changes and joins
+// them into one message
+func finishChange(parts []string, oldSettings, newSettings Settings) string {
+	if !reflect.DeepEqual(oldSettings, newSettings) {
+		parts = append(parts, "edited settings")
+	}
+	if len(parts) == 0 {
+		return "saved config"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// configLog returns the config's recorded changes, newest first
+func (cm *ChromiumManager) configLog(n int) ([]string, error) {
+	if !cm.hasHistory() {
+		return nil, fmt.Errorf("the config has no history yet; set config_history = true in [settings]")
+	}
+	out, err := cm.historyGit("log", fmt.Sprintf("-n%d", n), "--date=format:%Y-%m-%d %H:%M", "--format=%h  %ad  %s", "--", filepath.Base(cm.configFile))
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// revertConfig puts the config back as it was at the given change from
+// `launchium config log`, or before the latest change when rev is empty,
+// and records that as a change of its own. It returns the change reverted
+// to.
+func (cm *ChromiumManager) revertConfig(rev string) (string, error) {
+	if cm.configSource != "" {
+		return "", cm.errRemoteConfig()
+	}
+	if !cm.hasHistory() {
+		return "", fmt.Errorf("the config has no history yet; set config_history = true in [settings]")
+	}
+	if err := cm.recordHandEdits(); err != nil {
+		return "", err
+	}
+	if rev == "" {
+		rev = "HEAD~1"
+	}
+	base := filepath.Base(cm.configFile)
+	summary, err := cm.historyGit("log", "-n1", "--format=%h (%s)", rev, "--")
+	if err != nil {
+		return "", fmt.Errorf("no change %q in the config's history", rev)
+	}
+	data, err := cm.historyGit("show", rev+":"+base)
+	if err != nil {
+		return "", fmt.Errorf("the config didn't exist at %s", summary)
+	}
+	if _, _, err := parseConfig([]byte(data)); err != nil {
+		return "", fmt.Errorf("the config at %s doesn't parse: %s", summary, err)
+	}
+	if err := ioutil.WriteFile(cm.configFile, []byte(data+"\n"), 0644); err != nil {
+		return "", err
+	}
+	if err := cm.commitConfig("reverted to " + summary); err != nil {
+		return "", err
+	}
+	cm.loadProfiles()
+	return summary, nil
+}
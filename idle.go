@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How often an idle watch checks how long the machine has been idle
+const idleCheck = 30 * time.Second
+
+// idleWatches tracks browsers waiting to be closed when the machine goes
+// idle, so the process can keep watching them before it quits
+var (
+	idleWatches    sync.WaitGroup
+	idleWatchCount int32
+)
+
+// Idle counters printed by ioreg on macOS and gdbus on GNOME
+var (
+	hidIdleTime    = regexp.MustCompile(`"HIDIdleTime" = (\d+)`)
+	mutterIdleTime = regexp.MustCompile(`uint64 (\d+)`)
+)
+
+// watchIdle closes the profile's browser once the machine has had no
+// keyboard or mouse input for the profile's idle timeout, then cleans it
+// if the profile asks for that. It stops watching when the browser exits.
+func (cm *ChromiumManager) watchIdle(profile Profile) {
+	timeout := time.Duration(profile.IdleTimeout) * time.Minute
+	dirs := cm.runningDirs()[profile.Name]
+	path := cm.profilePath(profile)
+
+	idleWatches.Add(1)
+	atomic.AddInt32(&idleWatchCount, 1)
+	go func() {
+		defer idleWatches.Done()
+		defer atomic.AddInt32(&idleWatchCount, -1)
+
+		// Give the browser time to take its lock
+		time.Sleep(time.Second)
+		for {
+			running := findInstances(map[string][]string{profile.Name: dirs})
+			if len(running) == 0 {
+				return
+			}
+			idle, err := systemIdle()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Idle timeout for profile '%s' is off: %s\n", profile.Name, err)
+				return
+			}
+			if idle < timeout {
+				time.Sleep(min(idleCheck, timeout-idle))
+				continue
+			}
+
+			if _, err := stopBrowser(running[0], defaultStopTimeout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing idle profile '%s': %s\n", profile.Name, err)
+				return
+			}
+			message := fmt.Sprintf("Closed '%s' after %s idle", profile.Name, formatUptime(idle))
+			if profile.IdleClean {
+				// RAM disk sessions finish saving once the browser is gone
+				ramSessions.Wait()
+				started := time.Now()
+				err := cleanDataDir(context.Background(), path, profile.CleanKeep)
+				cm.reportClean(profile.Name, "cleaned after being idle", time.Since(started), err)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error cleaning idle profile '%s': %s\n", profile.Name, err)
+				} else {
+					message += " and cleaned it"
+				}
+			}
+			cm.notifyDesktop(profile.Name, message)
+			return
+		}
+	}()
+}
+
+// waitForIdleWatches blocks until every browser with an idle timeout has
+// exited or been closed
+func waitForIdleWatches() {
+	if atomic.LoadInt32(&idleWatchCount) > 0 {
+		fmt.Println("Watching for inactivity to close idle browsers...")
+	}
+	idleWatches.Wait()
+}
+
+// systemIdle returns how long it has been since the last keyboard or mouse
+// input anywhere in the session
+func systemIdle() (time.Duration, error) {
+	switch runtime.GOOS {
+	case "linux":
+		// xprintidle covers X11; GNOME's idle monitor covers Wayland
+		if out, err := exec.Command("xprintidle").Output(); err == nil {
+			if ms, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+				return time.Duration(ms) * time.Millisecond, nil
+			}
+		}
+		out, err := exec.Command("gdbus", "call", "--session",
+			"--dest", "org.gnome.Mutter.IdleMonitor",
+			"--object-path", "/org/gnome/Mutter/IdleMonitor/Core",
+			"--method", "org.gnome.Mutter.IdleMonitor.GetIdletime").Output()
+		if m := mutterIdleTime.FindSubmatch(out); err == nil && m != nil {
+			ms, _ := strconv.ParseInt(string(m[1]), 10, 64)
+			return time.Duration(ms) * time.Millisecond, nil
+		}
+		return 0, fmt.Errorf("idle detection needs xprintidle (X11) or GNOME")
+
+	case "darwin":
+		out, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
+		if err != nil {
+			return 0, fmt.Errorf("ioreg: %w", err)
+		}
+		m := hidIdleTime.FindSubmatch(out)
+		if m == nil {
+			return 0, fmt.Errorf("ioreg reported no HIDIdleTime")
+		}
+		ns, _ := strconv.ParseInt(string(m[1]), 10, 64)
+		return time.Duration(ns), nil
+
+	case "windows":
+		return windowsIdleTime()
+	}
+	return 0, fmt.Errorf("idle detection is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// windowsIdleTime is only available on Windows
+func windowsIdleTime() (time.Duration, error) {
+	return 0, fmt.Errorf("not running on Windows")
+}
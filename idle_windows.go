@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procGetLastInputInfo = user32.NewProc("GetLastInputInfo")
+	procGetTickCount     = kernel32.NewProc("GetTickCount")
+)
+
+// windowsIdleTime asks Windows how long ago the last input was
+func windowsIdleTime() (time.Duration, error) {
+	info := struct {
+		size uint32
+		time uint32
+	}{size: 8}
+	if ok, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0, fmt.Errorf("GetLastInputInfo: %w", err)
+	}
+	now, _, _ := procGetTickCount.Call()
+	// Both are milliseconds since boot and wrap together after 49 days
+	return time.Duration(uint32(now)-info.time) * time.Millisecond, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// isolatedSessions tracks browsers running from an isolated copy so the
+// process can wait for them to exit and remove the copy
+var (
+	isolatedSessions     sync.WaitGroup
+	isolatedSessionCount int32
+)
+
+// isolateDataDir forks a data dir, which may be in use by a running
+// browser, into a new directory beside it and returns its path. Being on
+// the same filesystem, the fork is reflinked where it can be, and it has
+// no SingletonLock, so a second browser can run from it alongside the
+// first.
+func isolateDataDir(ctx context.Context, profilePath string) (string, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(profilePath), filepath.Base(profilePath)+isolatedPrefix)
+	if err != nil {
+		return "", err
+	}
+	if _, err := forkDir(ctx, profilePath, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// isolatedPrefix starts the name of a data dir's isolated copies
+const isolatedPrefix = ".isolated-"
+
+// instanceCopy says which copy of its profile's data dir a browser runs
+// from: "isolated copy", "RAM disk", or "" for the data dir itself
+func (cm *ChromiumManager) instanceCopy(inst instance) string {
+	dir := cm.profilePath(cm.profiles[inst.name])
+	switch {
+	case strings.HasPrefix(inst.dataDir, dir+isolatedPrefix):
+		return "isolated copy"
+	case inst.dataDir != dir:
+		return "RAM disk"
+	}
+	return ""
+}
+
+// watchIsolatedSession removes an isolated copy once its browser exits.
+// Nothing is merged back into the profile.
+func watchIsolatedSession(exit *processExit, path string) {
+	isolatedSessions.Add(1)
+	atomic.AddInt32(&isolatedSessionCount, 1)
+	go func() {
+		defer isolatedSessions.Done()
+		defer atomic.AddInt32(&isolatedSessionCount, -1)
+		<-exit.done
+		// Files a run_as browser wrote belong to its user
+		err := reclaimDataDir(path)
+		if err == nil {
+			err = os.RemoveAll(path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing isolated copy %s: %s\n", path, err)
+		}
+	}()
+}
+
+// waitForIsolatedSessions blocks until every isolated browser has exited
+// and its copy has been removed
+func waitForIsolatedSessions() {
+	if atomic.LoadInt32(&isolatedSessionCount) > 0 {
+		fmt.Println("Waiting for isolated browser sessions to exit...")
+	}
+	isolatedSessions.Wait()
+}
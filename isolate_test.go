@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsolatedSession(t *testing.T) {
+	profilePath := filepath.Join(t.TempDir(), "work")
+	os.MkdirAll(filepath.Join(profilePath, "Default"), 0755)
+	os.WriteFile(filepath.Join(profilePath, "Default", "History"), []byte("visits"), 0644)
+	// The running session's lock
+	if err := os.Symlink("myhost-4242", filepath.Join(profilePath, "SingletonLock")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := isolateDataDir(context.Background(), profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(dir) != filepath.Dir(profilePath) || !strings.HasPrefix(filepath.Base(dir), "work.isolated-") {
+		t.Errorf("isolated copy is at %s", dir)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Default", "History")); err != nil || string(data) != "visits" {
+		t.Errorf("the copy's History is %q, %v", data, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "SingletonLock")); err == nil {
+		t.Error("the copy kept the running browser's lock")
+	}
+
+	// The copy goes once its browser exits, and the profile stays
+	exit := &processExit{done: make(chan struct{})}
+	watchIsolatedSession(exit, dir)
+	close(exit.done)
+	waitForIsolatedSessions()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("the isolated copy is still there: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(profilePath, "Default", "History")); err != nil {
+		t.Errorf("the profile lost its data: %v", err)
+	}
+}
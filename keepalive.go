@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Relaunch timing for `launchium launch -keep-alive`. The wait before a
+// relaunch doubles after each short run, and a run lasting keepAliveStable
+// counts as healthy and resets it along with the restart count.
+const (
+	keepAliveMinBackoff  = time.Second
+	keepAliveMaxBackoff  = time.Minute
+	keepAliveStable      = 5 * time.Minute
+	defaultMaxRestarts   = 5
+	keepAliveExitPolling = time.Second
+)
+
+// waitForExit blocks until the browser using one of dirs has exited. It
+// first waits up to exitWatchStartup for the browser to appear, and returns
+// false if it never does. ran is how long it was seen running.
+func waitForExit(dirs []string, poll time.Duration) (ran time.Duration, started bool) {
+	running := func() bool {
+		for _, dir := range dirs {
+			if _, ok := runningPID(dir); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	waiting := time.Now()
+	for !running() {
+		if time.Since(waiting) > exitWatchStartup {
+			return 0, false
+		}
+		time.Sleep(time.Second)
+	}
+	since := time.Now()
+	for running() {
+		time.Sleep(poll)
+	}
+	return time.Since(since), true
+}
+
+// keepAlive launches the profile and relaunches it whenever its browser
+// exits or fails to start, until it is interrupted or the browser has
+// stopped early maxRestarts times in a row (0 for no limit). Progress is
+// written to out.
+func (cm *ChromiumManager) keepAlive(ctx context.Context, name string, maxRestarts int, out io.Writer) error {
+	if _, ok := cm.profiles[name]; !ok {
+		return profileNotFound(name)
+	}
+
+	exited := make(chan time.Duration, 1)
+
+	backoff := keepAliveMinBackoff
+	failures := 0
+	for {
+		message, err := cm.launchBrowser(ctx, name)
+		if err != nil {
+			exited <- 0
+		} else {
+			fmt.Fprintln(out, message)
+			dirs := cm.runningDirs()[name]
+			go func() {
+				ran, _ := waitForExit(dirs, keepAliveExitPolling)
+				exited <- ran
+			}()
+		}
+
+		var ran time.Duration
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out, "Stopped watching; the browser is left running")
+			return nil
+		case ran = <-exited:
+		}
+
+		if ran >= keepAliveStable {
+			backoff, failures = keepAliveMinBackoff, 0
+		} else {
+			failures++
+			if maxRestarts > 0 && failures > maxRestarts {
+				return fmt.Errorf("'%s' stopped %d times in a row, each within %.0f minutes of launching; giving up", name, failures, keepAliveStable.Minutes())
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(out, "Launch failed: %s; retrying in %s\n", err, backoff)
+		} else {
+			fmt.Fprintf(out, "Browser exited after %s; relaunching in %s\n", formatUptime(ran), backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if ran < keepAliveStable {
+			backoff = min(backoff*2, keepAliveMaxBackoff)
+		}
+	}
+}
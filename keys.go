@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyMap holds the TUI key bindings. Each binding can be remapped in the
+// [keys] table of the config file using the action names from bindings().
+type keyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	Select     key.Binding
+	Back       key.Binding
+	Quit       key.Binding
+	ForceQuit  key.Binding
+	Help       key.Binding
+	Mark       key.Binding
+	Filter     key.Binding
+	TagFilter  key.Binding
+	LaunchMode key.Binding
+	Sessions   key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+	Focus      key.Binding
+	Kill       key.Binding
+	OpenURL    key.Binding
+	Refresh    key.Binding
+	History    key.Binding
+	FixProxy   key.Binding
+	Unused     key.Binding
+	RunGC      key.Binding
+	Confirm    key.Binding
+	Cancel     key.Binding
+	Save       key.Binding
+	NextField  key.Binding
+	PrevField  key.Binding
+	NextOption key.Binding
+	PrevOption key.Binding
+	FormHelp   key.Binding
+}
+
+// defaultKeyMap returns the bindings used when the config doesn't remap them
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:         key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:       key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Select:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Back:       key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		Quit:       key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		ForceQuit:  key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+		Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Mark:       key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark")),
+		Filter:     key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		TagFilter:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "filter by tag")),
+		LaunchMode: key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "incognito/guest")),
+		Sessions:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sessions")),
+		ScrollUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "scroll flags up")),
+		ScrollDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "scroll flags down")),
+		Focus:      key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "raise window")),
+		Kill:       key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "close browser")),
+		OpenURL:    key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open URL")),
+		Refresh:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		History:    key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "messages")),
+		FixProxy:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "fix proxy")),
+		Unused:     key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "clean unused")),
+		RunGC:      key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "run due cleans")),
+		Confirm:    key.NewBinding(key.WithKeys("y", "Y"), key.WithHelp("y", "yes")),
+		Cancel:     key.NewBinding(key.WithKeys("n", "N"), key.WithHelp("n", "no")),
+		Save:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save")),
+		NextField:  key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab/↓", "next field")),
+		PrevField:  key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab/↑", "previous field")),
+		NextOption: key.NewBinding(key.WithKeys("right", "l", " "), key.WithHelp("→/l/space", "next option")),
+		PrevOption: key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "previous option")),
+		FormHelp:   key.NewBinding(key.WithKeys("f1"), key.WithHelp("f1", "help")),
+	}
+}
+
+// bindings maps the action names accepted in the [keys] table to the
+// bindings they replace
+func (k *keyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":          &k.Up,
+		"down":        &k.Down,
+		"select":      &k.Select,
+		"back":        &k.Back,
+		"quit":        &k.Quit,
+		"force_quit":  &k.ForceQuit,
+		"help":        &k.Help,
+		"mark":        &k.Mark,
+		"filter":      &k.Filter,
+		"tag_filter":  &k.TagFilter,
+		"launch_mode": &k.LaunchMode,
+		"sessions":    &k.Sessions,
+		"scroll_up":   &k.ScrollUp,
+		"scroll_down": &k.ScrollDown,
+		"focus":       &k.Focus,
+		"kill":        &k.Kill,
+		"open_url":    &k.OpenURL,
+		"refresh":     &k.Refresh,
+		"history":     &k.History,
+		"fix_proxy":   &k.FixProxy,
+		"unused":      &k.Unused,
+		"run_gc":      &k.RunGC,
+		"confirm":     &k.Confirm,
+		"cancel":      &k.Cancel,
+		"save":        &k.Save,
+		"next_field":  &k.NextField,
+		"prev_field":  &k.PrevField,
+		"next_option": &k.NextOption,
+		"prev_option": &k.PrevOption,
+		"form_help":   &k.FormHelp,
+	}
+}
+
+// keyActions returns the action names accepted in the [keys] table, sorted
+func keyActions() []string {
+	k := defaultKeyMap()
+	actions := []string{}
+	for action := range k.bindings() {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// validKeyAction reports whether action can be remapped in the [keys] table
+func validKeyAction(action string) bool {
+	k := defaultKeyMap()
+	_, ok := k.bindings()[action]
+	return ok
+}
+
+// apply replaces the bindings named in overrides. Actions are checked when
+// the config is parsed, so unknown ones are ignored here.
+func (k *keyMap) apply(overrides map[string][]string) {
+	bindings := k.bindings()
+	for action, names := range overrides {
+		b, ok := bindings[action]
+		if !ok || len(names) == 0 {
+			continue
+		}
+		keys := make([]string, len(names))
+		for i, name := range names {
+			// "space" reads better in the config than a quoted blank
+			if name == "space" {
+				name = " "
+			}
+			keys[i] = name
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(names, "/"), b.Help().Desc)
+	}
+}
+
+// configureList makes a list use the shared bindings. The list's own help
+// and quit keys are turned off since the manager handles both.
+func (k keyMap) configureList(l *list.Model) {
+	l.SetShowHelp(false)
+	l.KeyMap.CursorUp = k.Up
+	l.KeyMap.CursorDown = k.Down
+	l.KeyMap.Filter = k.Filter
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+}
+
+// Views that show one of the menus or profile lists
+func isListView(view string) bool {
+	return view == "main" || view == "manage" || view == "running" || view == "sessions" || view == "dashboard" || strings.HasPrefix(view, "select_")
+}
+
+// Views that handle the back key themselves instead of returning to the
+// main menu
+func handlesBack(view string) bool {
+	return view == "bulk_progress" || view == "profile_form" || view == "open_url" || view == "messages" ||
+		view == "setup" || view == "sessions"
+}
+
+// helpKey is the key that opens the help overlay. Views with text fields
+// use F1, since ? can be typed into them.
+func (cm *ChromiumManager) helpKey() key.Binding {
+	if cm.currentView == "profile_form" || cm.currentView == "setup" || cm.typingConfirmation() {
+		return cm.keys.FormHelp
+	}
+	return cm.keys.Help
+}
+
+// withDesc returns a copy of b described differently in the help
+func withDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// viewKeys returns the bindings that apply to the current view, grouped
+// into columns for the help overlay. The first group of list views holds
+// the navigation keys, which the short help leaves out.
+func (cm *ChromiumManager) viewKeys() [][]key.Binding {
+	k := cm.keys
+	nav := list.DefaultKeyMap()
+	navigation := []key.Binding{k.Up, k.Down, nav.PrevPage, nav.NextPage, nav.GoToStart, nav.GoToEnd}
+	if strings.HasPrefix(cm.currentView, "select_") && cm.showDetailPane() {
+		navigation = append(navigation, k.ScrollDown, k.ScrollUp)
+	}
+
+	switch cm.currentView {
+	case "main":
+		return [][]key.Binding{navigation, {k.Select, slotKeys, k.History, k.Quit, k.ForceQuit, k.Help}}
+	case "manage":
+		return [][]key.Binding{navigation, {k.Select, k.History, k.Quit, k.ForceQuit, k.Help}}
+	case "select_profile":
+		return [][]key.Binding{navigation, {withDesc(k.Select, "launch"), slotKeys, k.Mark, k.Filter, k.TagFilter, k.LaunchMode, k.Sessions}, {k.Back, k.History, k.Quit, k.Help}}
+	case "sessions":
+		return [][]key.Binding{navigation, {withDesc(k.Select, "launch")}, {k.Back, k.History, k.Quit, k.Help}}
+	case "select_clean", "select_delete":
+		return [][]key.Binding{navigation, {k.Select, k.Mark, k.Filter}, {k.Back, k.History, k.Quit, k.Help}}
+	case "select_edit", "select_default":
+		return [][]key.Binding{navigation, {k.Select, k.Filter}, {k.Back, k.History, k.Quit, k.Help}}
+	case "running":
+		return [][]key.Binding{navigation, {k.Focus, k.OpenURL, k.Kill, k.Refresh}, {k.Back, k.History, k.Quit, k.Help}}
+	case "dashboard":
+		return [][]key.Binding{navigation, {withDesc(k.Select, "go to fix"), k.FixProxy, k.Unused, k.RunGC, k.Refresh}, {k.Back, k.History, k.Quit, k.Help}}
+	case "open_url":
+		return [][]key.Binding{{withDesc(k.Select, "open"), withDesc(k.Back, "cancel")}}
+	case "messages":
+		vp := viewport.DefaultKeyMap()
+		return [][]key.Binding{{vp.Up, vp.Down, vp.PageUp, vp.PageDown}, {k.Back, k.ForceQuit, k.Help}}
+	case "confirm_delete", "confirm_clean":
+		if cm.typingConfirmation() {
+			return [][]key.Binding{{withDesc(k.Select, "confirm"), withDesc(k.Back, "cancel")}, {k.ForceQuit, k.FormHelp}}
+		}
+		return [][]key.Binding{{k.Confirm, k.Cancel}, {k.Back, k.ForceQuit, k.Help}}
+	case "profile_form":
+		return [][]key.Binding{
+			{k.NextField, k.PrevField, k.NextOption, k.PrevOption, withDesc(k.Mark, "toggle preset")},
+			{withDesc(k.Select, "save"), k.Save, withDesc(k.Back, "cancel"), k.FormHelp},
+		}
+	case "setup":
+		if cm.setup != nil && cm.setup.step == setupBrowser {
+			return [][]key.Binding{
+				{withDesc(k.NextField, "next browser"), withDesc(k.PrevField, "previous browser")},
+				{withDesc(k.Select, "next"), withDesc(k.Back, "skip setup"), k.ForceQuit, k.FormHelp},
+			}
+		}
+		return [][]key.Binding{{withDesc(k.Select, "next"), k.Back, k.ForceQuit, k.FormHelp}}
+	default:
+		return [][]key.Binding{{k.ForceQuit}}
+	}
+}
+
+// shortHelp renders the one-line key summary shown below every view
+func (cm *ChromiumManager) shortHelp() string {
+	groups := cm.viewKeys()
+	if isListView(cm.currentView) {
+		groups = groups[1:]
+	}
+	bindings := []key.Binding{}
+	for _, group := range groups {
+		bindings = append(bindings, group...)
+	}
+	return cm.help.ShortHelpView(bindings)
+}
+
+// helpView renders the full key overlay for the current view
+func (cm *ChromiumManager) helpView() string {
+	title := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Keys: %s", cm.currentView))
+	hint := helpStyle.Render(fmt.Sprintf("Press %s or %s to close", cm.helpKey().Help().Key, cm.keys.Back.Help().Key))
+	return detailStyle.Render(title + "\n\n" + cm.help.FullHelpView(cm.viewKeys()) + "\n\n" + hint)
+}
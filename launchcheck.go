@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long a launched browser has to stay up to count as started, unless
+// the launch_check setting says otherwise
+const defaultLaunchCheck = 2 * time.Second
+
+// launchLogName is the file in a profile's data dir that gets the
+// browser's error output, so a failed launch can say why
+const launchLogName = "launchium-stderr.log"
+
+// launchCheck returns how long to watch a launched browser, 0 when the
+// check is turned off
+func (s Settings) launchCheck() time.Duration {
+	switch {
+	case s.LaunchCheck < 0:
+		return 0
+	case s.LaunchCheck == 0:
+		return defaultLaunchCheck
+	}
+	return time.Duration(s.LaunchCheck) * time.Second
+}
+
+// openLaunchLog empties the launch log of a data dir for a new launch. It
+// returns nil when the file can't be written, such as in the data dir of a
+// run_as profile, and the browser's errors are then dropped as before.
+func openLaunchLog(dataDir string) *os.File {
+	f, err := os.Create(filepath.Join(dataDir, launchLogName))
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// processExit tells when a started process exits. Waiting on it also
+// reaps the process, so it doesn't linger as a zombie that still looks
+// like a running browser.
+type processExit struct {
+	done chan struct{} // Closed once the process has exited
+	err  error         // How it exited, set before done is closed
+}
+
+// watchExit waits for a started command in the background
+func watchExit(cmd *exec.Cmd) *processExit {
+	exit := &processExit{done: make(chan struct{})}
+	go func() {
+		exit.err = procs.Wait(cmd)
+		close(exit.done)
+	}()
+	return exit
+}
+
+// waitForStart watches a just started browser, or the launcher that
+// starts it, until it has stayed up for the check period or, when remote
+// debugging is on, until DevTools answers. A browser that exits 0 in that
+// time handed its window to one already running; a non-zero exit is a
+// failed launch, reported with the last lines the browser wrote.
+func waitForStart(ctx context.Context, exit *processExit, dataDir string, args []string, within time.Duration) error {
+	if within == 0 {
+		return nil
+	}
+	started := time.Now()
+	devTools := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--remote-debugging-port") {
+			devTools = true
+		}
+	}
+
+	deadline := time.After(within)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-exit.done:
+			if exit.err == nil {
+				return nil
+			}
+			if output := lastLines(filepath.Join(dataDir, launchLogName), 3); output != "" {
+				return errorOf(ErrLaunchFailed, "browser exited right after starting (%w): %s", exit.err, output)
+			}
+			return errorOf(ErrLaunchFailed, "browser exited right after starting (%w)", exit.err)
+		case <-deadline:
+			return nil
+		case <-ctx.Done():
+			// The browser is left to finish starting
+			return fmt.Errorf("stopped waiting for the browser to start: %w", ctx.Err())
+		case <-tick.C:
+			if devTools && devToolsAnswers(dataDir, started) {
+				return nil
+			}
+		}
+	}
+}
+
+// devToolsAnswers reports whether the browser using dataDir wrote its
+// DevTools port since it was started and accepts connections on it
+func devToolsAnswers(dataDir string, started time.Time) bool {
+	info, err := os.Stat(filepath.Join(dataDir, "DevToolsActivePort"))
+	if err != nil || info.ModTime().Before(started.Add(-time.Second)) {
+		return false // Left over from an earlier run
+	}
+	_, ok := devToolsPort(dataDir)
+	return ok
+}
+
+// devToolsPort returns the DevTools port the browser using dataDir wrote,
+// if it accepts connections on it
+func devToolsPort(dataDir string) (int, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(dataDir, "DevToolsActivePort"))
+	if err != nil {
+		return 0, false
+	}
+	lines := strings.Fields(string(data))
+	if len(lines) == 0 {
+		return 0, false
+	}
+	port, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return 0, false
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", lines[0]), 500*time.Millisecond)
+	if err != nil {
+		return 0, false
+	}
+	conn.Close()
+	return port, true
+}
+
+// lastLines returns the last n non-empty lines of a file joined by "; "
+func lastLines(path string, n int) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "; ")
+}
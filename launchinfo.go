@@ -0,0 +1,85 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long `launch -print-pid` waits for a launched browser to write its
+// lock and, with remote debugging, its DevTools port
+const launchInfoWait = 5 * time.Second
+
+// launchInfo is what `launch -print-pid` and `-json` report of a launch,
+// for wrapper scripts that supervise the browser themselves
+type launchInfo struct {
+	Profile      string `json:"profile"`
+	PID          int    `json:"pid"`                     // 0 when it couldn't be found
+	DevToolsPort int    `json:"devtools_port,omitempty"` // Set when the browser runs with remote debugging
+}
+
+// The process each profile's latest launch started, for launchInfo to fall
+// back on where the data dir's lock doesn't record it
+var (
+	launchedPIDsMu sync.Mutex
+	launchedPIDs   = map[string]int{}
+)
+
+// noteLaunchedPID remembers the process a profile's browser was started as
+func noteLaunchedPID(name string, pid int) {
+	launchedPIDsMu.Lock()
+	defer launchedPIDsMu.Unlock()
+	launchedPIDs[name] = pid
+}
+
+// takeLaunchedPID returns and forgets the process noted for a profile
+func takeLaunchedPID(name string) int {
+	launchedPIDsMu.Lock()
+	defer launchedPIDsMu.Unlock()
+	pid := launchedPIDs[name]
+	delete(launchedPIDs, name)
+	return pid
+}
+
+// launchInfo finds the browser of a profile that was just launched, or was
+// found running. The PID comes from the data dir's lock on Linux and macOS,
+// which names the browser even when a launcher or wrapper started it; on
+// Windows it is the started process's.
+func (cm *ChromiumManager) launchInfo(name string) launchInfo {
+	info := launchInfo{Profile: name}
+	started := takeLaunchedPID(name)
+	profile := cm.override.profile(cm.profiles[name])
+	devTools := false
+	for _, arg := range cm.override.args(profileLaunchFlags(profile)[""]) {
+		if strings.HasPrefix(arg, "--remote-debugging-port") {
+			devTools = true
+		}
+	}
+
+	deadline := time.Now().Add(launchInfoWait)
+	for {
+		for _, dir := range cm.runningDirs()[name] {
+			pid, running := runningPID(dir)
+			if !running {
+				continue
+			}
+			if pid > 0 {
+				info.PID = pid
+			}
+			if devTools {
+				info.DevToolsPort, _ = devToolsPort(dir)
+			}
+			break
+		}
+		found := info.PID > 0 || runtime.GOOS == "windows"
+		if found && (!devTools || info.DevToolsPort > 0) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if info.PID == 0 {
+		info.PID = started
+	}
+	return info
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestDevToolsPort(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := devToolsPort(dir); ok {
+		t.Error("found a port without a DevToolsActivePort file")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	file := filepath.Join(dir, "DevToolsActivePort")
+	os.WriteFile(file, []byte(strconv.Itoa(port)+"\n/devtools/browser/1234\n"), 0644)
+	if got, ok := devToolsPort(dir); !ok || got != port {
+		t.Errorf("devToolsPort = %d, %v, want %d", got, ok, port)
+	}
+
+	// A port left behind by a browser that has quit
+	ln.Close()
+	if _, ok := devToolsPort(dir); ok {
+		t.Error("found a port nothing listens on")
+	}
+	os.WriteFile(file, []byte("not a port\n"), 0644)
+	if _, ok := devToolsPort(dir); ok {
+		t.Error("found a port in a garbled file")
+	}
+}
+
+func TestLaunchedPID(t *testing.T) {
+	noteLaunchedPID("work", 1234)
+	noteLaunchedPID("work", 5678)
+	if pid := takeLaunchedPID("work"); pid != 5678 {
+		t.Errorf("took PID %d, want the latest launch's 5678", pid)
+	}
+	if pid := takeLaunchedPID("work"); pid != 0 {
+		t.Errorf("took PID %d twice", pid)
+	}
+}
@@ -0,0 +1,382 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.27.1
+// source: launchium.proto
+
+// Launchium manages Chromium profiles. The service mirrors the REST API of
+// `launchium serve`; every call needs an "authorization: Bearer <token>"
+// metadata entry with the API token.
+
+package launchiumpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Launchium_ListProfiles_FullMethodName  = "/launchium.v1.Launchium/ListProfiles"
+	Launchium_GetProfile_FullMethodName    = "/launchium.v1.Launchium/GetProfile"
+	Launchium_CreateProfile_FullMethodName = "/launchium.v1.Launchium/CreateProfile"
+	Launchium_UpdateProfile_FullMethodName = "/launchium.v1.Launchium/UpdateProfile"
+	Launchium_DeleteProfile_FullMethodName = "/launchium.v1.Launchium/DeleteProfile"
+	Launchium_LaunchProfile_FullMethodName = "/launchium.v1.Launchium/LaunchProfile"
+	Launchium_CleanProfile_FullMethodName  = "/launchium.v1.Launchium/CleanProfile"
+	Launchium_ListRunning_FullMethodName   = "/launchium.v1.Launchium/ListRunning"
+)
+
+// LaunchiumClient is the client API for Launchium service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LaunchiumClient interface {
+	ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error)
+	GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (*Profile, error)
+	CreateProfile(ctx context.Context, in *CreateProfileRequest, opts ...grpc.CallOption) (*Profile, error)
+	// Replaces a profile; a different name in the profile renames it
+	UpdateProfile(ctx context.Context, in *UpdateProfileRequest, opts ...grpc.CallOption) (*Profile, error)
+	DeleteProfile(ctx context.Context, in *DeleteProfileRequest, opts ...grpc.CallOption) (*DeleteProfileResponse, error)
+	LaunchProfile(ctx context.Context, in *LaunchProfileRequest, opts ...grpc.CallOption) (*LaunchProfileResponse, error)
+	CleanProfile(ctx context.Context, in *CleanProfileRequest, opts ...grpc.CallOption) (*CleanProfileResponse, error)
+	ListRunning(ctx context.Context, in *ListRunningRequest, opts ...grpc.CallOption) (*ListRunningResponse, error)
+}
+
+type launchiumClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLaunchiumClient(cc grpc.ClientConnInterface) LaunchiumClient {
+	return &launchiumClient{cc}
+}
+
+func (c *launchiumClient) ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProfilesResponse)
+	err := c.cc.Invoke(ctx, Launchium_ListProfiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (*Profile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Profile)
+	err := c.cc.Invoke(ctx, Launchium_GetProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) CreateProfile(ctx context.Context, in *CreateProfileRequest, opts ...grpc.CallOption) (*Profile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Profile)
+	err := c.cc.Invoke(ctx, Launchium_CreateProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) UpdateProfile(ctx context.Context, in *UpdateProfileRequest, opts ...grpc.CallOption) (*Profile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Profile)
+	err := c.cc.Invoke(ctx, Launchium_UpdateProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) DeleteProfile(ctx context.Context, in *DeleteProfileRequest, opts ...grpc.CallOption) (*DeleteProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProfileResponse)
+	err := c.cc.Invoke(ctx, Launchium_DeleteProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) LaunchProfile(ctx context.Context, in *LaunchProfileRequest, opts ...grpc.CallOption) (*LaunchProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LaunchProfileResponse)
+	err := c.cc.Invoke(ctx, Launchium_LaunchProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) CleanProfile(ctx context.Context, in *CleanProfileRequest, opts ...grpc.CallOption) (*CleanProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CleanProfileResponse)
+	err := c.cc.Invoke(ctx, Launchium_CleanProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *launchiumClient) ListRunning(ctx context.Context, in *ListRunningRequest, opts ...grpc.CallOption) (*ListRunningResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRunningResponse)
+	err := c.cc.Invoke(ctx, Launchium_ListRunning_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LaunchiumServer is the server API for Launchium service.
+// All implementations must embed UnimplementedLaunchiumServer
+// for forward compatibility
+type LaunchiumServer interface {
+	ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error)
+	GetProfile(context.Context, *GetProfileRequest) (*Profile, error)
+	CreateProfile(context.Context, *CreateProfileRequest) (*Profile, error)
+	// Replaces a profile; a different name in the profile renames it
+	UpdateProfile(context.Context, *UpdateProfileRequest) (*Profile, error)
+	DeleteProfile(context.Context, *DeleteProfileRequest) (*DeleteProfileResponse, error)
+	LaunchProfile(context.Context, *LaunchProfileRequest) (*LaunchProfileResponse, error)
+	CleanProfile(context.Context, *CleanProfileRequest) (*CleanProfileResponse, error)
+	ListRunning(context.Context, *ListRunningRequest) (*ListRunningResponse, error)
+	mustEmbedUnimplementedLaunchiumServer()
+}
+
+// UnimplementedLaunchiumServer must be embedded to have forward compatible implementations.
+type UnimplementedLaunchiumServer struct {
+}
+
+func (UnimplementedLaunchiumServer) ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfiles not implemented")
+}
+func (UnimplementedLaunchiumServer) GetProfile(context.Context, *GetProfileRequest) (*Profile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) CreateProfile(context.Context, *CreateProfileRequest) (*Profile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) UpdateProfile(context.Context, *UpdateProfileRequest) (*Profile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) DeleteProfile(context.Context, *DeleteProfileRequest) (*DeleteProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) LaunchProfile(context.Context, *LaunchProfileRequest) (*LaunchProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LaunchProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) CleanProfile(context.Context, *CleanProfileRequest) (*CleanProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CleanProfile not implemented")
+}
+func (UnimplementedLaunchiumServer) ListRunning(context.Context, *ListRunningRequest) (*ListRunningResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRunning not implemented")
+}
+func (UnimplementedLaunchiumServer) mustEmbedUnimplementedLaunchiumServer() {}
+
+// UnsafeLaunchiumServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LaunchiumServer will
+// result in compilation errors.
+type UnsafeLaunchiumServer interface {
+	mustEmbedUnimplementedLaunchiumServer()
+}
+
+func RegisterLaunchiumServer(s grpc.ServiceRegistrar, srv LaunchiumServer) {
+	s.RegisterService(&Launchium_ServiceDesc, srv)
+}
+
+func _Launchium_ListProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).ListProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_ListProfiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).ListProfiles(ctx, req.(*ListProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_GetProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).GetProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_GetProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).GetProfile(ctx, req.(*GetProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_CreateProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).CreateProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_CreateProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).CreateProfile(ctx, req.(*CreateProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_UpdateProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).UpdateProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_UpdateProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).UpdateProfile(ctx, req.(*UpdateProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_DeleteProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).DeleteProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_DeleteProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).DeleteProfile(ctx, req.(*DeleteProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_LaunchProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LaunchProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).LaunchProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_LaunchProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).LaunchProfile(ctx, req.(*LaunchProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_CleanProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CleanProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).CleanProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_CleanProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).CleanProfile(ctx, req.(*CleanProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Launchium_ListRunning_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaunchiumServer).ListRunning(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Launchium_ListRunning_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaunchiumServer).ListRunning(ctx, req.(*ListRunningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Launchium_ServiceDesc is the grpc.ServiceDesc for Launchium service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Launchium_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "launchium.v1.Launchium",
+	HandlerType: (*LaunchiumServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListProfiles",
+			Handler:    _Launchium_ListProfiles_Handler,
+		},
+		{
+			MethodName: "GetProfile",
+			Handler:    _Launchium_GetProfile_Handler,
+		},
+		{
+			MethodName: "CreateProfile",
+			Handler:    _Launchium_CreateProfile_Handler,
+		},
+		{
+			MethodName: "UpdateProfile",
+			Handler:    _Launchium_UpdateProfile_Handler,
+		},
+		{
+			MethodName: "DeleteProfile",
+			Handler:    _Launchium_DeleteProfile_Handler,
+		},
+		{
+			MethodName: "LaunchProfile",
+			Handler:    _Launchium_LaunchProfile_Handler,
+		},
+		{
+			MethodName: "CleanProfile",
+			Handler:    _Launchium_CleanProfile_Handler,
+		},
+		{
+			MethodName: "ListRunning",
+			Handler:    _Launchium_ListRunning_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "launchium.proto",
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Rows kept below every view for the status message and the key help
+const footerRows = 4
+
+// Smallest terminal the TUI draws in; anything smaller gets a notice
+const (
+	minWidth  = 40
+	minHeight = 12
+)
+
+// Below this the lists drop item descriptions and spacing
+const (
+	compactWidth  = 80
+	compactHeight = 24
+)
+
+// contentSize is the space inside the document margins left for a view,
+// assuming 80x24 until the first WindowSizeMsg arrives
+func (cm *ChromiumManager) contentSize() (int, int) {
+	width, height := 80, 24
+	if cm.width > 0 {
+		width, height = cm.width, cm.height
+	}
+	width -= docStyle.GetHorizontalFrameSize()
+	height -= docStyle.GetVerticalFrameSize() + footerRows
+	return max(width, 1), max(height, 1)
+}
+
+// tooSmall reports whether the terminal is below the supported minimum
+func (cm *ChromiumManager) tooSmall() bool {
+	return cm.width > 0 && (cm.width < minWidth || cm.height < minHeight)
+}
+
+// compactLayout reports whether the lists should use single-row items
+func (cm *ChromiumManager) compactLayout() bool {
+	return cm.width > 0 && (cm.width < compactWidth || cm.height < compactHeight)
+}
+
+// listDelegate returns the item delegate for a list whose items take
+// height rows on a roomy terminal
+func (cm *ChromiumManager) listDelegate(height int) list.DefaultDelegate {
+	if cm.compactLayout() {
+		d := newListDelegate(1, 0)
+		d.ShowDescription = false
+		return d
+	}
+	return newListDelegate(height, 1)
+}
+
+// resize fits every part of the UI to the current terminal size
+func (cm *ChromiumManager) resize() {
+	width, height := cm.contentSize()
+	if cm.mainList.Items() != nil {
+		cm.mainList.SetDelegate(cm.listDelegate(3))
+		cm.mainList.SetSize(width, height)
+	}
+	if cm.manageList.Items() != nil {
+		cm.manageList.SetDelegate(cm.listDelegate(2))
+		cm.manageList.SetSize(width, height)
+	}
+	if cm.profileList.Items() != nil {
+		cm.profileList.SetDelegate(cm.listDelegate(2))
+		cm.profileList.SetSize(cm.profileListSize())
+	}
+	if cm.sessionList.Items() != nil {
+		cm.sessionList.SetDelegate(cm.listDelegate(2))
+		cm.sessionList.SetSize(width, height)
+	}
+	if cm.runningList.Items() != nil {
+		cm.runningList.SetDelegate(cm.listDelegate(2))
+		cm.runningList.SetSize(width, height)
+	}
+	if cm.dashboardList.Items() != nil {
+		cm.dashboardList.SetDelegate(cm.listDelegate(2))
+		cm.dashboardList.SetSize(width, height)
+	}
+	cm.help.Width = width
+	if cm.currentView == "messages" {
+		cm.history.Width, cm.history.Height = width, height-2
+		cm.history.SetContent(cm.historyContent(width))
+	}
+	if cm.form != nil {
+		cm.form.setWidth(width)
+	}
+	if cm.bulk != nil {
+		cm.bulk.bar.Width = min(width, 60)
+	}
+	cm.scrollFlags(0)
+}
+
+// tooSmallView is shown instead of the UI on tiny terminals
+func (cm *ChromiumManager) tooSmallView() string {
+	return lipgloss.NewStyle().Width(cm.width).Render(
+		fmt.Sprintf("Terminal too small (%dx%d). Launchium needs at least %dx%d.",
+			cm.width, cm.height, minWidth, minHeight))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Ranges of the per-profile resource limits. CPU weight is the cgroup v2
+// cpu.weight scale, where 100 is an ordinary process.
+const (
+	minCPUWeight = 1
+	maxCPUWeight = 10000
+	minNice      = -20
+	maxNice      = 19
+)
+
+// hasLimits reports whether the profile sets any resource limit
+func (p Profile) hasLimits() bool {
+	return p.MemoryLimit != "" || p.CPUWeight != 0 || p.Nice != 0
+}
+
+// limitsSummary describes the profile's resource limits, or "" for none
+func (p Profile) limitsSummary() string {
+	parts := []string{}
+	if p.MemoryLimit != "" {
+		parts = append(parts, "memory "+p.MemoryLimit)
+	}
+	if p.CPUWeight != 0 {
+		parts = append(parts, fmt.Sprintf("CPU weight %d", p.CPUWeight))
+	}
+	if p.Nice != 0 {
+		parts = append(parts, fmt.Sprintf("nice %d", p.Nice))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// validateLimits checks the profile's resource limits are in range
+func validateLimits(p Profile) error {
+	if p.MemoryLimit != "" {
+		if _, err := parseByteSize(p.MemoryLimit); err != nil {
+			return fmt.Errorf("memory_limit: %w", err)
+		}
+	}
+	if p.CPUWeight != 0 && (p.CPUWeight < minCPUWeight || p.CPUWeight > maxCPUWeight) {
+		return fmt.Errorf("cpu_weight must be between %d and %d, got %d", minCPUWeight, maxCPUWeight, p.CPUWeight)
+	}
+	if p.Nice < minNice || p.Nice > maxNice {
+		return fmt.Errorf("nice must be between %d and %d, got %d", minNice, maxNice, p.Nice)
+	}
+	return nil
+}
+
+// parseByteSize reads a size such as "512M" or "2G". Suffixes are powers of
+// 1024, as in systemd, and a bare number is bytes.
+func parseByteSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	shift := 0
+	if s != "" {
+		if i := strings.IndexByte("KMGT", s[len(s)-1]); i >= 0 {
+			shift = 10 * (i + 1)
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a size such as 512M or 2G")
+	}
+	return n << shift, nil
+}
+
+// limitCommand wraps the browser command line so it runs under the
+// profile's limits on Linux and macOS. On Linux, systemd-run puts it in a
+// transient cgroup v2 scope and then execs it, so the PID is still the
+// browser's. Windows applies limits after starting, with applyJobLimits.
+func limitCommand(profile Profile, browserPath string, args []string) (string, []string, error) {
+	if !profile.hasLimits() || runtime.GOOS == "windows" {
+		return browserPath, args, nil
+	}
+
+	cgroupLimits := profile.MemoryLimit != "" || profile.CPUWeight != 0
+	if runtime.GOOS == "linux" && cgroupLimits {
+		if _, err := exec.LookPath("systemd-run"); err != nil {
+			return "", nil, fmt.Errorf("memory and CPU limits need systemd-run")
+		}
+		// systemd-run fails after it has started without a user manager
+		// to create the scope, so check for one first
+		if err := systemctl("show-environment"); err != nil {
+			return "", nil, fmt.Errorf("memory and CPU limits need a systemd user session: %w", err)
+		}
+		wrapped := []string{"--user", "--scope", "--quiet", "--collect",
+			"--description=Launchium profile " + profile.Name}
+		if profile.MemoryLimit != "" {
+			wrapped = append(wrapped, "--property=MemoryMax="+strings.ToUpper(profile.MemoryLimit))
+		}
+		if profile.CPUWeight != 0 {
+			wrapped = append(wrapped, fmt.Sprintf("--property=CPUWeight=%d", profile.CPUWeight))
+		}
+		if profile.Nice != 0 {
+			wrapped = append(wrapped, fmt.Sprintf("--nice=%d", profile.Nice))
+		}
+		wrapped = append(append(wrapped, "--", browserPath), args...)
+		return "systemd-run", wrapped, nil
+	}
+	if cgroupLimits {
+		return "", nil, fmt.Errorf("memory and CPU limits are not supported on %s", runtime.GOOS)
+	}
+
+	// Only a priority: nice execs the browser with it
+	return "nice", append([]string{"-n", strconv.Itoa(profile.Nice), browserPath}, args...), nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// applyJobLimits is a no-op outside Windows, where limitCommand has
+// already applied the limits
+func applyJobLimits(profile Profile, pid int) error {
+	return nil
+}
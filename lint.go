@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// flagNotice describes a browser switch that no longer does what a
+// profile expects
+type flagNotice struct {
+	flag   string // Switch name, or name=value when only that value is affected
+	use    string // Replacement to suggest, if there is one
+	reason string
+	onlyOn string // GOOS the switch works on; empty when the notice applies everywhere
+}
+
+// Switches Chromium removed or renamed, and ones that only work on some
+// platforms. Keep this in step with Chromium releases; newest changes go
+// at the end.
+var flagNotices = []flagNotice{
+	{flag: "--enable-npapi", reason: "NPAPI plugins were removed in Chrome 45"},
+	{flag: "--disable-plugins", reason: "NPAPI plugins were removed in Chrome 45, so there is nothing left to disable"},
+	{flag: "--disable-translate", use: "--disable-features=Translate", reason: "removed in Chrome 59"},
+	{flag: "--disable-infobars", reason: "no longer has any effect since Chrome 76"},
+	{flag: "--ignore-gpu-blacklist", use: "--ignore-gpu-blocklist", reason: "renamed in Chrome 86"},
+	{flag: "--disable-gpu-blacklist", use: "--ignore-gpu-blocklist", reason: "removed in Chrome 86"},
+	{flag: "--ppapi-flash-path", reason: "Flash was removed in Chrome 88"},
+	{flag: "--ppapi-flash-version", reason: "Flash was removed in Chrome 88"},
+	{flag: "--disable-bundled-ppapi-flash", reason: "Flash was removed in Chrome 88"},
+	{flag: "--headless=old", use: "chrome-headless-shell", reason: "the old headless mode was removed from Chrome in 132"},
+	{flag: "--load-extension", reason: "ignored by branded Chrome since 137; use Chromium or Chrome for Testing"},
+	{flag: "--silent-launch", reason: "only has an effect on Windows", onlyOn: "windows"},
+	{flag: "--user-data-dir", use: "data_dir", reason: "launchium sets it from the profile"},
+	{flag: "--proxy-server", use: "the profile's proxy and proxy_type", reason: "launchium sets it from the profile"},
+	{flag: "--password-store", use: "password_store", reason: "launchium keeps the store the data dir's logins were saved with"},
+}
+
+// lintFlags returns a message for each flag a notice applies to, in the
+// order the flags are given
+func lintFlags(flags []string) []string {
+	problems := []string{}
+	for _, flag := range flags {
+		name, _, _ := strings.Cut(flag, "=")
+		for _, notice := range flagNotices {
+			if notice.flag != flag && notice.flag != name {
+				continue
+			}
+			if notice.onlyOn == runtime.GOOS {
+				continue
+			}
+			problem := notice.flag + ": " + notice.reason
+			if notice.use != "" {
+				problem += "; use " + notice.use + " instead"
+			}
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// flagContradiction is a pair of switches where one undoes or defeats the
+// other
+type flagContradiction struct {
+	flag, other string
+	reason      string
+}
+
+// Switches that don't make sense together. launchium's standard flags
+// count too: every browser is started with --disable-gpu.
+var contradictingFlags = []flagContradiction{
+	{"--disable-gpu", "--ignore-gpu-blocklist", "the GPU is turned off, so its blocklist doesn't matter"},
+	{"--disable-gpu", "--enable-gpu-rasterization", "the GPU is turned off, so nothing is rasterized on it"},
+	{"--disable-extensions", "--load-extension", "extensions are turned off, so none are loaded"},
+	{"--incognito", "--guest", "a window is either incognito or a guest session"},
+	{"--no-proxy-server", "--proxy-server", "the proxy is turned off and set at the same time"},
+	{"--headless", "--kiosk", "a headless browser has no window to show full screen"},
+}
+
+// flagContradictions returns a message for each pair of switches on the
+// command line that work against each other, and for each feature both
+// enabled and disabled
+func flagContradictions(args []string) []string {
+	set := map[string]bool{}
+	features := map[string][]string{}
+	for _, arg := range args {
+		f := parseFlag(arg)
+		set[f.Switch] = true
+		if f.Switch == "--enable-features" || f.Switch == "--disable-features" {
+			features[f.Switch] = append(features[f.Switch], strings.Split(f.Value, ",")...)
+		}
+	}
+
+	problems := []string{}
+	for _, c := range contradictingFlags {
+		if set[c.flag] && set[c.other] {
+			problems = append(problems, fmt.Sprintf("%s and %s: %s", c.flag, c.other, c.reason))
+		}
+	}
+	for _, feature := range features["--enable-features"] {
+		if feature != "" && containsString(features["--disable-features"], feature) {
+			problems = append(problems, fmt.Sprintf("feature %s is both enabled and disabled; Chromium keeps it disabled", feature))
+		}
+	}
+	return problems
+}
+
+// lint checks the flags of the profile and its presets, that they don't set
+// a switch twice, and that they don't contradict each other or the flags
+// launchium adds
+func (p Profile) lint() []string {
+	problems := append(lintFlags(p.launchFlags()), p.flagConflicts()...)
+	args := append(p.launchFlags(), p.standardFlags()...)
+	if p.Proxy != "none" && p.Proxy != "" {
+		args = append(args, "--proxy-server="+p.Proxy)
+	}
+	return append(problems, flagContradictions(composeFlags(args))...)
+}
+
+// lintSummary describes the problems found in a profile in one line for a
+// status message
+func lintSummary(problems []string) string {
+	summary := problems[0]
+	if len(problems) > 1 {
+		summary += fmt.Sprintf(" (and %d more, see launchium lint)", len(problems)-1)
+	}
+	return summary
+}
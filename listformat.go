@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// tablePrefix starts a -format that is printed as aligned columns under a
+// header row. On its own it prints defaultTableFormat.
+const tablePrefix = "table"
+
+// Columns of `launchium list -format table`
+const defaultTableFormat = "{{.Name}}\t{{if .Slot}}{{.Slot}}{{end}}\t{{.Proxy}}\t{{join .Tags \",\"}}\t{{.Description}}"
+
+// listEntry is what a `launchium list -format` template gets for each
+// profile: its settings, such as {{.Name}} and {{.Proxy}}, and its state
+type listEntry struct {
+	Profile
+	Archived   bool      // Data packed away by `launchium archive`
+	LastLaunch time.Time // Zero if never launched
+}
+
+// listFuncs are the functions -format templates can use besides the
+// built-in ones
+var listFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// The first field a template cell prints, which names its column
+var templateFieldPattern = regexp.MustCompile(`{{[^}]*?\.(\w+)`)
+
+// listFormat is a parsed -format
+type listFormat struct {
+	tmpl    *template.Template
+	headers []string // Column headers of a table, nil otherwise
+}
+
+// parseListFormat parses a -format template. Like printf, \t and \n stand
+// for a tab and a newline, so they can be typed in a shell. A template
+// starting with "table" is printed as columns split at tabs.
+func parseListFormat(format string) (listFormat, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	table := format == tablePrefix || strings.HasPrefix(format, tablePrefix+" ")
+	if table {
+		format = strings.TrimSpace(strings.TrimPrefix(format, tablePrefix))
+		if format == "" {
+			format = defaultTableFormat
+		}
+	}
+	tmpl, err := template.New("format").Funcs(listFuncs).Parse(format)
+	if err != nil {
+		return listFormat{}, fmt.Errorf("invalid -format: %s", err)
+	}
+	f := listFormat{tmpl: tmpl}
+	if table {
+		for _, cell := range strings.Split(format, "\t") {
+			header := ""
+			if m := templateFieldPattern.FindStringSubmatch(cell); m != nil {
+				header = strings.ToUpper(snakeCase(m[1]))
+			}
+			f.headers = append(f.headers, header)
+		}
+	}
+	return f, nil
+}
+
+// snakeCase turns a field name such as LastLaunch into last_launch
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		// Break before a capital that starts a word, so CACerts is ca_certs
+		if upper && i > 0 && (runes[i-1] < 'A' || runes[i-1] > 'Z' || i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z') {
+			b.WriteByte('_')
+		}
+		b.WriteString(strings.ToLower(string(r)))
+	}
+	return b.String()
+}
+
+// printProfiles writes a line for each named profile using the format,
+// with the header row and aligned columns of a table
+func (cm *ChromiumManager) printProfiles(w io.Writer, names []string, f listFormat) error {
+	out := w
+	var tw *tabwriter.Writer
+	if f.headers != nil {
+		tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		out = tw
+		fmt.Fprintln(tw, strings.Join(f.headers, "\t"))
+	}
+	for _, name := range names {
+		entry := listEntry{Profile: cm.profiles[name], Archived: cm.isArchived(name), LastLaunch: cm.state.LastLaunch[name]}
+		if err := f.tmpl.Execute(out, entry); err != nil {
+			return fmt.Errorf("-format: %s", err)
+		}
+		fmt.Fprintln(out)
+	}
+	if tw != nil {
+		return tw.Flush()
+	}
+	return nil
+}
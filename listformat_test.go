@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"Name":       "name",
+		"LastLaunch": "last_launch",
+		"CACerts":    "ca_certs",
+		"DataDir":    "data_dir",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPrintProfiles(t *testing.T) {
+	cm := &ChromiumManager{profiles: map[string]Profile{
+		"work":     {Name: "work", Proxy: "127.0.0.1:8080", Tags: []string{"client-a", "daily"}, Slot: 1},
+		"personal": {Name: "personal", Proxy: "none", Description: "Home"},
+	}}
+	names := []string{"personal", "work"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{`{{.Name}}\t{{.Proxy}}`, "personal\tnone\nwork\t127.0.0.1:8080\n"},
+		{`{{upper .Name}} {{.Archived}}`, "PERSONAL false\nWORK false\n"},
+		{"table", "NAME      SLOT  PROXY           TAGS            DESCRIPTION\n" +
+			"personal        none                            Home\n" +
+			"work      1     127.0.0.1:8080  client-a,daily  \n"},
+		{`table {{.Name}}\t{{join .Tags "+"}}`, "NAME      TAGS\npersonal  \nwork      client-a+daily\n"},
+	}
+	for _, tt := range tests {
+		f, err := parseListFormat(tt.format)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.format, err)
+		}
+		var out bytes.Buffer
+		if err := cm.printProfiles(&out, names, f); err != nil {
+			t.Fatalf("%q: %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%q printed\n%q\nwant\n%q", tt.format, out.String(), tt.want)
+		}
+	}
+}
+
+func TestListFormatErrors(t *testing.T) {
+	if _, err := parseListFormat("{{.Name"); err == nil || !strings.Contains(err.Error(), "invalid -format") {
+		t.Errorf("an unclosed action gave %v", err)
+	}
+	f, err := parseListFormat("{{.Nmae}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := &ChromiumManager{profiles: map[string]Profile{"work": {Name: "work"}}}
+	if err := cm.printProfiles(&bytes.Buffer{}, []string{"work"}, f); err == nil {
+		t.Error("a misspelled field printed without an error")
+	}
+}
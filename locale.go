@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// languageTag matches the BCP 47 style codes Chromium names its UI
+// languages and dictionaries by, such as de, pt-BR or es-419
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// validLanguage checks a locale or spellcheck language
+func validLanguage(tag string) error {
+	if !languageTag.MatchString(tag) {
+		return fmt.Errorf("%q is not a language code such as en-US or de", tag)
+	}
+	return nil
+}
+
+// validLanguages checks each of a list of spellcheck languages
+func validLanguages(tags []string) error {
+	for _, tag := range tags {
+		if err := validLanguage(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acceptLanguages returns the languages pages are asked for, the locale
+// first and then the spellcheck languages, without repeats
+func (p Profile) acceptLanguages() []string {
+	seen := map[string]bool{}
+	languages := []string{}
+	for _, tag := range append([]string{p.Locale}, p.SpellcheckLanguages...) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			languages = append(languages, tag)
+		}
+	}
+	return languages
+}
+
+// localePreferences returns the preference values that set the profile's
+// languages: what pages are asked for and which dictionaries spellcheck
+// uses
+func localePreferences(profile Profile) map[string]interface{} {
+	values := map[string]interface{}{}
+	if languages := profile.acceptLanguages(); len(languages) > 0 {
+		values["intl.accept_languages"] = strings.Join(languages, ",")
+		values["intl.selected_languages"] = strings.Join(languages, ",")
+	}
+	if len(profile.SpellcheckLanguages) > 0 {
+		values["browser.enable_spellchecking"] = true
+		values["spellcheck.dictionaries"] = profile.SpellcheckLanguages
+		values["spellcheck.dictionary"] = profile.SpellcheckLanguages[0]
+	}
+	return values
+}
+
+// seedLocale writes the profile's languages into a data dir whose
+// browser is closed. Unlike the first run setup they are applied on every
+// launch, so changing them in launchium changes the browser.
+func seedLocale(dataDir string, profile Profile) error {
+	if values := localePreferences(profile); len(values) > 0 {
+		if err := mergePreferences(preferencesFile(dataDir), values); err != nil {
+			return err
+		}
+	}
+	if profile.Locale == "" {
+		return nil
+	}
+	// Windows and ChromeOS take the UI language from Local State
+	return mergePreferences(filepath.Join(dataDir, "Local State"), map[string]interface{}{
+		"intl.app_locale": profile.Locale,
+	})
+}
+
+// localeFlags returns the switch that sets the browser's UI language
+func (p Profile) localeFlags() []string {
+	if p.Locale == "" {
+		return nil
+	}
+	return []string{"--lang=" + p.Locale}
+}
+
+// localeEnv returns the environment the browser needs for the profile's
+// UI language: on Linux Chromium ignores --lang and goes by LANGUAGE
+func (p Profile) localeEnv() []string {
+	if p.Locale == "" || runtime.GOOS != "linux" {
+		return nil
+	}
+	return []string{"LANGUAGE=" + strings.ReplaceAll(p.Locale, "-", "_")}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidLanguage(t *testing.T) {
+	for _, tag := range []string{"de", "en-US", "pt-BR", "es-419", "zh-Hant-TW", "fil"} {
+		if err := validLanguage(tag); err != nil {
+			t.Errorf("validLanguage(%q) = %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "german", "e", "en_US", "en-", "de DE"} {
+		if err := validLanguage(tag); err == nil {
+			t.Errorf("validLanguage(%q) accepted it", tag)
+		}
+	}
+}
+
+func TestAcceptLanguages(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		want    []string
+	}{
+		{Profile{}, []string{}},
+		{Profile{Locale: "de-DE"}, []string{"de-DE"}},
+		{Profile{Locale: "de-DE", SpellcheckLanguages: []string{"de-DE", "en-US"}}, []string{"de-DE", "en-US"}},
+		{Profile{SpellcheckLanguages: []string{"fr", "fr"}}, []string{"fr"}},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.acceptLanguages(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("acceptLanguages() of %+v = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestSeedLocale(t *testing.T) {
+	m, _ := useFakes(t)
+	profile := Profile{Name: "berlin", Locale: "de-DE", SpellcheckLanguages: []string{"de-DE", "en-US"}}
+	if err := seedLocale("/profiles/berlin", profile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.ReadFile(preferencesFile("/profiles/berlin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prefs struct {
+		Intl struct {
+			AcceptLanguages string `json:"accept_languages"`
+		} `json:"intl"`
+		Spellcheck struct {
+			Dictionaries []string `json:"dictionaries"`
+			Dictionary   string   `json:"dictionary"`
+		} `json:"spellcheck"`
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		t.Fatal(err)
+	}
+	if prefs.Intl.AcceptLanguages != "de-DE,en-US" {
+		t.Errorf("accept_languages = %q", prefs.Intl.AcceptLanguages)
+	}
+	if prefs.Spellcheck.Dictionary != "de-DE" || !reflect.DeepEqual(prefs.Spellcheck.Dictionaries, profile.SpellcheckLanguages) {
+		t.Errorf("spellcheck = %+v", prefs.Spellcheck)
+	}
+	if data, err := m.ReadFile(filepath.Join("/profiles/berlin", "Local State")); err != nil || string(data) != `{"intl":{"app_locale":"de-DE"}}` {
+		t.Errorf("Local State is %s, %v", data, err)
+	}
+	if got := profile.localeFlags(); !reflect.DeepEqual(got, []string{"--lang=de-DE"}) {
+		t.Errorf("localeFlags() = %q", got)
+	}
+}
+
+func TestSeedLocaleWithoutLanguages(t *testing.T) {
+	m, _ := useFakes(t)
+	if err := seedLocale("/profiles/work", Profile{Name: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat("/profiles/work"); err == nil {
+		t.Error("a profile without languages had its data dir written")
+	}
+}
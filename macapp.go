@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// appBundle returns the macOS .app bundle a browser path points to: the
+// path itself when it names a bundle, or the bundle holding a binary in
+// its Contents/MacOS. It returns "" for anything else.
+func appBundle(path string) string {
+	if isAppBundle(path) {
+		return strings.TrimSuffix(path, "/")
+	}
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "MacOS" && filepath.Base(filepath.Dir(dir)) == "Contents" {
+		if bundle := filepath.Dir(filepath.Dir(dir)); strings.HasSuffix(bundle, ".app") {
+			return bundle
+		}
+	}
+	return ""
+}
+
+// isAppBundle reports whether a browser path names a macOS .app bundle
+// rather than a binary
+func isAppBundle(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), ".app")
+}
+
+// openCommand starts a new instance of a macOS app with the arguments
+// passed through as they are, even when the app is already running with
+// another profile
+func openCommand(bundle string, args []string) *exec.Cmd {
+	return exec.Command("open", append([]string{"-n", "-a", bundle, "--args"}, args...)...)
+}
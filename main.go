@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime" //added for platform detection
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -18,149 +29,846 @@ import (
 
 // Profile represents a Chromium browser profile
 type Profile struct {
-	Name      string
-	Proxy     string
-	ProxyType string
-	Flags     string
+	Name                     string   `json:"name"`
+	Description              string   `json:"description,omitempty"` // Free-form note on what the profile is for
+	Proxy                    string   `json:"proxy"`
+	ProxyType                string   `json:"proxy_type"`
+	Flags                    FlagList `json:"flags"`
+	Presets                  []string `json:"presets,omitempty"`  // Flag presets expanded before Flags at launch
+	DataDir                  string   `json:"data_dir,omitempty"` // Optional user-data-dir override; defaults to <profileDir>/<name>
+	RAMDisk                  string   `json:"ramdisk,omitempty"`  // "", "discard" or "persist"
+	Tags                     []string `json:"tags,omitempty"`
+	Browser                  string   `json:"browser,omitempty"`                    // Browser binary; empty uses the detected one
+	Color                    string   `json:"color,omitempty"`                      // Label color as #rrggbb, also used as the browser theme
+	Icon                     string   `json:"icon,omitempty"`                       // Emoji or short label shown with the profile name
+	CleanSchedule            string   `json:"clean_schedule,omitempty"`             // "<cache|all> <daily|weekly|monthly>", run by `launchium gc`
+	Notify                   string   `json:"notify,omitempty"`                     // "", "on" or "off"; empty follows the notifications setting
+	Channel                  string   `json:"channel,omitempty"`                    // Pinned release channel: "", "stable", "beta", "dev" or "canary"
+	Fallback                 bool     `json:"fallback,omitempty"`                   // Retry with the other installed browsers when the browser fails to start
+	Singleton                bool     `json:"singleton,omitempty"`                  // Raise the running browser instead of launching a second one
+	MemoryLimit              string   `json:"memory_limit,omitempty"`               // Most memory the browser may use, e.g. "2G"
+	CPUWeight                int      `json:"cpu_weight,omitempty"`                 // cgroup v2 CPU weight, 1-10000; 0 leaves the default of 100
+	Nice                     int      `json:"nice,omitempty"`                       // Scheduling priority, -20 (highest) to 19 (lowest)
+	RunAs                    string   `json:"run_as,omitempty"`                     // Local user to run the browser as
+	IdleTimeout              int      `json:"idle_timeout,omitempty"`               // Minutes without input before the browser is closed; 0 never
+	IdleClean                bool     `json:"idle_clean,omitempty"`                 // Clean the profile after closing it for being idle
+	SearchEngine             string   `json:"search_engine,omitempty"`              // Default search engine of new data dirs: a known name or a URL with %s
+	Homepage                 string   `json:"homepage,omitempty"`                   // Page new data dirs open on startup and from the home button
+	FirstRun                 string   `json:"first_run,omitempty"`                  // [first_run.<name>] table new data dirs are set up with; empty goes by the browser
+	Locale                   string   `json:"locale,omitempty"`                     // UI language of the browser and the first language asked of pages, such as de-DE
+	SpellcheckLanguages      []string `json:"spellcheck_languages,omitempty"`       // Dictionaries spellcheck uses, also asked of pages after the locale
+	CACerts                  []string `json:"ca_certs,omitempty"`                   // PEM or DER files of certificate authorities the browser trusts
+	AutoSelectCerts          []string `json:"auto_select_certs,omitempty"`          // Sites that get a client certificate without the picker, as AutoSelectCertificateForUrls entries
+	WebRTCPolicy             string   `json:"webrtc_policy,omitempty"`              // "", "default", "disable-non-proxied-udp" or "disable"
+	DoNotTrack               bool     `json:"do_not_track,omitempty"`               // Send Do Not Track with requests
+	BlockThirdPartyCookies   bool     `json:"block_third_party_cookies,omitempty"`  // Block third-party cookies in normal windows too
+	DisableHyperlinkAuditing bool     `json:"disable_hyperlink_auditing,omitempty"` // Don't send <a ping> requests
+	Permissions              []string `json:"permissions,omitempty"`                // Permission defaults and site settings such as "notifications=block"
+	Slot                     int      `json:"slot,omitempty"`                       // Quick-launch number from 1 to 9, launched by its key in the main menu and by `launchium <n>`
+	Protected                bool     `json:"protected,omitempty"`                  // Refuse to remove, clean or overwrite the profile without -force or a second confirmation
+	CleanKeep                []string `json:"clean_keep,omitempty"`                 // Paths in the data dir a clean leaves, matched like sync_exclude
+	Sessions                 Sessions `json:"sessions,omitempty"`                   // Named sets of pages and window layouts, from [profiles.<name>.sessions.<session>] tables
+	PasswordStore            string   `json:"password_store,omitempty"`             // Linux keyring saved logins are encrypted with: "", "basic", "gnome", "gnome-libsecret", "kwallet", "kwallet5" or "kwallet6"
 }
 
 // ChromiumManager handles the application state
 type ChromiumManager struct {
-	profiles     map[string]Profile
-	configFile   string
-	chromePath   string
-	profileDir   string
-	currentView  string
-	mainList     list.Model
-	profileList  list.Model
-	manageList   list.Model
-	message      string
-	selected     string
-	profileName  string
-	profileProxy string
-	profileType  string
-	profileFlags string
-	err          error
+	ctx             context.Context // Background work of the TUI; canceled when it quits
+	profiles        map[string]Profile
+	settings        Settings
+	state           State
+	configFile      string
+	configSource    string             // URL the config is fetched from; configFile is then the cached copy
+	profileFiles    map[string]string  // Drop-in file each profile came from, by name
+	projectFile     string             // Project config of the working directory, if any
+	projectProfiles map[string]Profile // Profiles from projectFile as loaded, by prefixed name
+	unknownKeys     []string           // What the config files had that this version doesn't know
+	chromePath      string
+	profileDir      string
+	currentView     string
+	mainList        list.Model
+	profileList     list.Model
+	manageList      list.Model
+	toast           *toast         // Status message shown below the view
+	forceLaunch     bool           // Launch singleton profiles even when running
+	unprotect       bool           // -force: remove, clean and overwrite protected profiles
+	protectedAsked  bool           // The first y of a delete or clean of protected profiles was pressed
+	foreground      bool           // Keep launched browsers attached to the terminal
+	override        launchOverride // Changes to profiles for this run's launches
+	toastSeq        int
+	messages        []toast // Message history, oldest first
+	history         viewport.Model
+	historyFrom     string // View to go back to from the history
+	selected        string
+	form            *profileForm
+	tagFilter       string
+	launchMode      string // Incognito or guest, as picked in the launch picker
+	width           int
+	height          int
+	sizes           map[string]int64         // Cached disk usage per profile
+	sizing          map[string]bool          // Profiles with a size scan in flight
+	cleaning        map[string]cleanProgress // Progress of the cleans running in the background
+	bulk            *bulkOp
+	bulkNames       []string
+	keys            keyMap
+	help            help.Model
+	showHelp        bool // Key overlay shown over the current view
+	flagScroll      int  // First flag shown in the detail pane
+	runningList     list.Model
+	instances       []instance // Browsers shown in the running view
+	runningGen      int        // Visit to the running view, to drop stale scans
+	sessionList     list.Model // Sessions of the profile picked to launch
+	dashboardList   list.Model
+	health          *healthMsg // Result of the dashboard's last checks
+	healthGen       int        // Visit to the dashboard, to drop stale checks
+	urlInput        textinput.Model
+	confirmInput    textinput.Model // Where a large profile's name is typed to confirm deleting or cleaning it
+	tasks           []string        // Background operations in progress
+	spinner         spinner.Model
+	spinning        bool
+	firstRun        bool         // No config yet; the TUI starts with setup
+	setup           *setupWizard // First-run setup in progress
+	err             error
+
+	loadedStamp   string          // configStamp of the config as last loaded or saved
+	configChanges <-chan struct{} // Settled changes to the config files
+
+	remoteChecked time.Time // When the remote config was last fetched
+	remoteChanged bool      // The last fetch brought a new version
+	remoteErr     error     // Why the last fetch failed
+}
+
+// cliOptions holds the parsed command line
+type cliOptions struct {
+	command    string
+	profile    string
+	profiles   []string       // Profiles for launch to start together
+	force      bool           // Launch singleton profiles even when running; remove, clean or overwrite protected ones
+	foreground bool           // Keep launched browsers attached to the terminal
+	quiet      bool           // Launch prints nothing but errors, to stderr
+	printPID   bool           // Launch prints only the browser's PID
+	jsonOut    bool           // Launch prints the PID and DevTools port as JSON
+	keepAlive  bool           // Relaunch the browser whenever it exits
+	maxRestart int            // Early exits in a row before keep-alive gives up
+	override   launchOverride // Flags and proxy changed for this launch only
+	all        bool           // Stop every running browser
+	orLaunch   bool           // Focus launches the profile when it isn't running
+	timeout    time.Duration  // How long stop waits before killing a browser
+	configPath string
+	purge      bool
+	shred      bool   // Overwrite files before clean deletes them
+	rescan     bool   // Ignore the sizes du cached
+	data       bool   // Diff also compares what the data dirs hold
+	output     string // File config export writes
+	merge      bool   // Config import adds to the profiles
+	replace    bool   // Config import replaces the profiles
+	foreign    string // Other profile manager's format config import and export convert
+	spec       string // Matrix spec generate expands
+	prefix     string // Import-csv names rows without a name after it
+	dryRun     bool   // Generate only lists the profiles
+	slot       int    // Quick-launch slot given as the command, as in `launchium 3`
+	editor     bool   // Edit opens the profile in $EDITOR rather than the form
+	open       bool   // Dir opens the directory rather than printing it
+	yamlEdit   bool   // Edit -editor opens the profile as YAML
+	tag        string
+	format     string   // Template list prints each profile with
+	cleanKeep  []string // Clean -keep paths in place of the profile's clean_keep; nil when not given
+	socket     string   // Control socket for the daemon
+	listen     string   // Address for the REST API server
+	grpcListen string   // Address for the gRPC API server, off if empty
+	menu       string   // Menu program for pick to run
+	fromStdin  bool     // Pick reads the profile to launch from stdin
+	scriptJSON bool     // List as launcher app Script Filter JSON
+	version    string   // Browser version for fetch-browser
+	url        string   // Browser zip for fetch-browser to download instead
+	remote     string   // Sync remote overriding the sync_remote setting
+	count      int      // Changes for config log to show
+	args       []string // Positional arguments after the command's flags
+	app        App      // Web app for app add to create
+	noShortcut bool     // App add makes no shortcut
 }
 
 // Parse command line arguments and handle direct commands
-func parseCommandLine() (string, string, bool) {
+func parseCommandLine() (cliOptions, bool) {
+    opts := cliOptions{}
+
+    // Global flags may appear before the command
+    globalFlags := flag.NewFlagSet("launchium", flag.ExitOnError)
+    globalFlags.StringVar(&opts.configPath, "config", "", "Path to the profiles config file")
+    globalFlags.Parse(os.Args[1:])
+    args := globalFlags.Args()
+
     // Define commands
     launchCmd := flag.NewFlagSet("launch", flag.ExitOnError)
-    launchProfile := launchCmd.String("profile", "default", "Profile name to launch")
+    launchProfile := launchCmd.String("profile", "", "Profile name to launch (default: the default profile)")
+    launchProfiles := launchCmd.String("profiles", "", "Comma-separated profiles to launch in parallel")
+    launchCmd.BoolVar(&opts.force, "force", false, "Launch singleton profiles even if they are already running")
+    launchCmd.BoolVar(&opts.foreground, "foreground", false, "Keep the browser attached to this terminal, so it closes with it")
+    launchCmd.BoolVar(&opts.keepAlive, "keep-alive", false, "Stay running and relaunch the browser whenever it crashes or is closed")
+    launchCmd.IntVar(&opts.maxRestart, "max-restarts", defaultMaxRestarts, "With -keep-alive, give up after this many early exits in a row (0 for no limit)")
+    var addFlags, removeFlags flagList
+    launchCmd.Var(&addFlags, "add-flag", "Add a browser flag for this launch only (repeatable)")
+    launchCmd.Var(&removeFlags, "remove-flag", "Leave out a browser flag for this launch only (repeatable)")
+    launchProxy := launchCmd.String("proxy", "", "Use this proxy for this launch only: socks5://host:port, http://host:port or none")
+    incognito := launchCmd.Bool("incognito", false, "Open an incognito window with the profile's proxy and flags")
+    guest := launchCmd.Bool("guest", false, "Open a guest session with the profile's proxy and flags")
+    launchCmd.BoolVar(&opts.override.fresh, "fresh", false, "Restore the profile's golden image before launching")
+    launchCmd.BoolVar(&opts.override.isolated, "isolated", false, "Run from a temporary copy of the profile, beside any running session, and discard it on exit")
+    launchCmd.StringVar(&opts.override.session, "session", "", "Open one of the profile's named sessions: its pages and window layout")
+    launchCmd.StringVar(&opts.override.tile, "tile", "", "With -profiles, lay the windows out as "+strings.Join(tileLayouts, ", ")+" (default: the tile setting)")
     
     cleanCmd := flag.NewFlagSet("clean", flag.ExitOnError)
-    cleanProfile := cleanCmd.String("profile", "default", "Profile name to clean")
+    cleanProfile := cleanCmd.String("profile", "default", "Profile name, glob or /regex/ to clean")
+    cleanCmd.BoolVar(&opts.shred, "shred", false, "Overwrite files with random data before deleting them")
+    cleanCmd.BoolVar(&opts.force, "force", false, "Clean protected profiles too")
+    cleanKeep := cleanCmd.String("keep", "", "Comma separated paths to leave, in place of the profile's clean_keep; empty keeps nothing")
+    
+    removeCmd := flag.NewFlagSet("remove", flag.ExitOnError)
+    removeProfile := removeCmd.String("profile", "", "Profile name, glob or /regex/ to remove")
+    removePurge := removeCmd.Bool("purge", false, "Also delete the profiles' data directories")
+    removeCmd.BoolVar(&opts.force, "force", false, "Remove protected profiles too")
     
     listCmd := flag.NewFlagSet("list", flag.ExitOnError)
+    listCmd.StringVar(&opts.tag, "tag", "", "Only list profiles with this tag")
+    listCmd.BoolVar(&opts.scriptJSON, "script-filter", false, "Print Alfred/Raycast Script Filter JSON")
+    listCmd.StringVar(&opts.format, "format", "", "Print each profile with a Go template such as '{{.Name}}\\t{{.Proxy}}'; 'table' for columns with headers")
+    
+    renameCmd := flag.NewFlagSet("rename", flag.ExitOnError)
+    
+    cloneCmd := flag.NewFlagSet("clone", flag.ExitOnError)
+    
+    editCmd := flag.NewFlagSet("edit", flag.ExitOnError)
+    editCmd.StringVar(&opts.profile, "profile", "", "Profile to edit")
+    editCmd.BoolVar(&opts.editor, "editor", false, "Edit the profile's definition in $VISUAL or $EDITOR instead of the form")
+    editCmd.BoolVar(&opts.yamlEdit, "yaml", false, "With -editor, edit the profile as YAML instead of TOML")
+    
+    generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
+    generateCmd.StringVar(&opts.spec, "spec", "", "YAML matrix spec of the profiles to generate")
+    generateCmd.BoolVar(&opts.dryRun, "dry-run", false, "List the profiles the spec makes without adding them")
+    generateCmd.BoolVar(&opts.force, "force", false, "Overwrite protected profiles too")
+
+    importCSVCmd := flag.NewFlagSet("import-csv", flag.ExitOnError)
+    importCSVCmd.StringVar(&opts.prefix, "prefix", defaultImportPrefix, "Name rows without a name <prefix>-1, <prefix>-2 and so on")
+    importCSVCmd.BoolVar(&opts.dryRun, "dry-run", false, "List the profiles the sheet adds or updates without changing them")
+    importCSVCmd.BoolVar(&opts.force, "force", false, "Update protected profiles too")
+    
+    diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+    diffCmd.BoolVar(&opts.data, "data", false, "Also compare the installed extensions and the preferences in the data dirs")
+    
+    goCmd := flag.NewFlagSet("go", flag.ExitOnError)
+    goCmd.BoolVar(&opts.force, "force", false, "Launch a singleton profile even if it is already running")
+    for _, fs := range []*flag.FlagSet{launchCmd, goCmd} {
+        fs.BoolVar(&opts.quiet, "quiet", false, "Print nothing but errors, and those to stderr")
+        fs.BoolVar(&opts.printPID, "print-pid", false, "Print only the launched browser's PID")
+        fs.BoolVar(&opts.jsonOut, "json", false, "Print the profile, PID and DevTools port of the launched browser as JSON")
+    }
+    
+    pickCmd := flag.NewFlagSet("pick", flag.ExitOnError)
+    pickCmd.StringVar(&opts.menu, "menu", "", "Show the profiles in a menu and launch the choice: "+strings.Join(pickerMenuNames(), ", "))
+    pickCmd.BoolVar(&opts.fromStdin, "launch", false, "Launch the profile named on stdin")
+    pickCmd.StringVar(&opts.tag, "tag", "", "Only offer profiles with this tag")
+    
+    stopCmd := flag.NewFlagSet("stop", flag.ExitOnError)
+    stopCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to stop")
+    stopCmd.BoolVar(&opts.all, "all", false, "Stop every running profile")
+    stopCmd.DurationVar(&opts.timeout, "timeout", defaultStopTimeout, "How long to wait for a browser to quit before killing it")
+    
+    killCmd := flag.NewFlagSet("kill", flag.ExitOnError)
+    killCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to kill")
+    killCmd.BoolVar(&opts.all, "all", false, "Kill every running profile")
+    
+    focusCmd := flag.NewFlagSet("focus", flag.ExitOnError)
+    focusCmd.StringVar(&opts.profile, "profile", "", "Profile whose window to raise (default: the default profile)")
+    focusCmd.BoolVar(&opts.orLaunch, "launch", false, "Launch the profile if it isn't running")
+    
+    psCmd := flag.NewFlagSet("ps", flag.ExitOnError)
+    psCmd.BoolVar(&opts.jsonOut, "json", false, "Print the running browsers as JSON")
+    
+    gcCmd := flag.NewFlagSet("gc", flag.ExitOnError)
+    
+    serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
+    serveCmd.StringVar(&opts.listen, "listen", defaultListenAddr, "Address to serve the REST API on")
+    serveCmd.StringVar(&opts.grpcListen, "grpc", "", "Also serve the gRPC API on this address (e.g. "+defaultGRPCAddr+")")
+    
+    daemonCmd := flag.NewFlagSet("daemon", flag.ExitOnError)
+    daemonCmd.StringVar(&opts.socket, "socket", "", "Socket to listen on (default: launchium.sock next to the config)")
+    
+    schedulerCmd := flag.NewFlagSet("scheduler", flag.ExitOnError)
+    
+    urlCmd := flag.NewFlagSet("url", flag.ExitOnError)
+    
+    browsersCmd := flag.NewFlagSet("browsers", flag.ExitOnError)
+    
+    fetchCmd := flag.NewFlagSet("fetch-browser", flag.ExitOnError)
+    fetchCmd.StringVar(&opts.version, "version", pinnedBrowserVersion, "Chrome for Testing version to download")
+    fetchCmd.StringVar(&opts.url, "url", "", "Download this zip of a Chromium build (e.g. ungoogled-chromium) instead")
+    
+    autostartCmd := flag.NewFlagSet("autostart", flag.ExitOnError)
+    autostartProfile := autostartCmd.String("profile", "", "Profile to launch at login")
+    
+    refreshCmd := flag.NewFlagSet("refresh", flag.ExitOnError)
+    
+    syncCmd := flag.NewFlagSet("sync", flag.ExitOnError)
+    syncCmd.StringVar(&opts.profile, "profile", "", "Profile to sync (default: the default profile)")
+    syncCmd.StringVar(&opts.remote, "remote", "", "Sync with this remote instead of the sync_remote setting")
+    
+    configCmd := flag.NewFlagSet("config", flag.ExitOnError)
+    configCmd.IntVar(&opts.count, "n", defaultHistoryLength, "Number of changes for log to show")
+    configCmd.StringVar(&opts.output, "o", "", "File for export to write, .yaml or .json (default: standard output)")
+    configCmd.BoolVar(&opts.merge, "merge", false, "Import adds the file's profiles, asking about ones that exist (the default)")
+    configCmd.BoolVar(&opts.replace, "replace", false, "Import replaces the config's profiles with the file's")
+    configCmd.BoolVar(&opts.force, "force", false, "Import overwrites and, with -replace, removes protected profiles too")
+    configCmd.StringVar(&opts.foreign, "format", "", "Other profile manager's format to import or export: "+strings.Join(foreignFormats, ", "))
+    
+    crashesCmd := flag.NewFlagSet("crashes", flag.ExitOnError)
+    crashesCmd.StringVar(&opts.profile, "profile", "", "Profile whose crashes to list (default: all)")
+    crashesCmd.BoolVar(&opts.open, "open", false, "Open the profile's crash dump folder in Finder, Explorer or the file manager")
+
+    dirCmd := flag.NewFlagSet("dir", flag.ExitOnError)
+    dirCmd.StringVar(&opts.profile, "profile", "", "Profile whose data directory to print")
+    dirCmd.BoolVar(&opts.open, "open", false, "Open the directory in Finder, Explorer or the file manager instead")
+
+    presetsCmd := flag.NewFlagSet("presets", flag.ExitOnError)
+
+    lintCmd := flag.NewFlagSet("lint", flag.ExitOnError)
+    lintCmd.StringVar(&opts.profile, "profile", "", "Only check this profile")
+    
+    compactCmd := flag.NewFlagSet("compact", flag.ExitOnError)
+    compactCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to compact")
+    
+    resetCmd := flag.NewFlagSet("reset", flag.ExitOnError)
+    resetCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to reset")
+    resetCmd.BoolVar(&opts.force, "force", false, "Reset protected and large profiles without asking")
+    
+    appCmd := flag.NewFlagSet("app", flag.ExitOnError)
+    appCmd.StringVar(&opts.app.Profile, "profile", "", "Profile the app runs with")
+    appCmd.StringVar(&opts.app.URL, "url", "", "Page the app opens")
+    appCmd.StringVar(&opts.app.Name, "name", "", "Name of the app and its shortcut")
+    appCmd.StringVar(&opts.app.Icon, "icon", "", "Image for the shortcut (default: the site's icon, or one in the profile's color)")
+    appCmd.BoolVar(&opts.noShortcut, "no-shortcut", false, "Only add the app to the config, without a shortcut")
+    
+    goldenCmd := flag.NewFlagSet("golden", flag.ExitOnError)
+    goldenCmd.StringVar(&opts.profile, "profile", "", "Profile whose golden image to save or drop")
+    
+    archiveCmd := flag.NewFlagSet("archive", flag.ExitOnError)
+    archiveCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to archive")
+    
+    thawCmd := flag.NewFlagSet("thaw", flag.ExitOnError)
+    thawCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to restore")
+    
+    duCmd := flag.NewFlagSet("du", flag.ExitOnError)
+    duCmd.StringVar(&opts.profile, "profile", "", "Profile name, glob or /regex/ to measure (default: all)")
+    duCmd.BoolVar(&opts.rescan, "rescan", false, "Scan every profile again instead of using sizes from earlier scans")
     
     versionCmd := flag.NewFlagSet("version", flag.ExitOnError)
+
+    // Commands also accept -config after the command name
+    for _, fs := range []*flag.FlagSet{launchCmd, cleanCmd, removeCmd, stopCmd, killCmd, focusCmd, listCmd, goCmd, pickCmd, renameCmd, autostartCmd, gcCmd, schedulerCmd, daemonCmd, serveCmd, urlCmd, browsersCmd, fetchCmd, refreshCmd, syncCmd, configCmd, presetsCmd, lintCmd, duCmd, compactCmd, resetCmd, goldenCmd, appCmd, archiveCmd, thawCmd, cloneCmd, editCmd, dirCmd, crashesCmd, diffCmd, generateCmd, importCSVCmd} {
+        fs.StringVar(&opts.configPath, "config", opts.configPath, "Path to the profiles config file")
+    }
     
     // Check if any arguments were provided
-    if len(os.Args) < 2 {
-        return "", "", false
+    if len(args) < 1 {
+        return opts, false
     }
     
     // Parse the command
-    switch os.Args[1] {
+    opts.command = args[0]
+    switch args[0] {
     case "launch":
-        launchCmd.Parse(os.Args[2:])
-        return "launch", *launchProfile, true
+        launchCmd.Parse(args[1:])
+        opts.profile = *launchProfile
+        opts.profiles = parseProfileList(*launchProfiles)
+        if (opts.profile != "" || opts.keepAlive) && len(opts.profiles) > 0 {
+            fmt.Println("Usage: launchium launch [-profile <name> [-keep-alive] | -profiles <name,name,...>]")
+            os.Exit(2)
+        }
+        if opts.override.session != "" && len(opts.profiles) > 0 {
+            fmt.Println("Usage: launchium launch [-profile <name>] -session <name>")
+            os.Exit(2)
+        }
+        if err := validTileLayout(opts.override.tile); err != nil {
+            fmt.Printf("Error: -%s\n", err)
+            os.Exit(2)
+        }
+        if (opts.printPID || opts.jsonOut) && (opts.keepAlive || len(opts.profiles) > 0) {
+            fmt.Println("Usage: launchium launch [-profile <name>] [-print-pid | -json]")
+            os.Exit(2)
+        }
+        opts.override.addFlags, opts.override.removeFlags = addFlags, removeFlags
+        switch {
+        case *incognito && *guest:
+            fmt.Println("Usage: launchium launch [-incognito | -guest]")
+            os.Exit(2)
+        case *incognito:
+            opts.override.mode = launchIncognito
+        case *guest:
+            opts.override.mode = launchGuest
+        }
+        if *launchProxy != "" {
+            var err error
+            if opts.override.proxy, opts.override.proxyType, err = parseProxyOverride(*launchProxy); err != nil {
+                fmt.Printf("Error: -proxy: %s\n", err)
+                os.Exit(2)
+            }
+        }
+        return opts, true
     case "clean":
-        cleanCmd.Parse(os.Args[2:])
-        return "clean", *cleanProfile, true
+        cleanCmd.Parse(args[1:])
+        opts.profile = *cleanProfile
+        cleanCmd.Visit(func(f *flag.Flag) {
+            if f.Name == "keep" {
+                opts.cleanKeep = parsePathList(*cleanKeep)
+            }
+        })
+        if err := validCleanKeep(opts.cleanKeep); err != nil {
+            fmt.Printf("Error: %s\n", err)
+            os.Exit(2)
+        }
+        return opts, true
+    case "remove":
+        removeCmd.Parse(args[1:])
+        opts.profile = *removeProfile
+        opts.purge = *removePurge
+        if opts.profile == "" {
+            fmt.Println("Usage: launchium remove -profile <name|glob|/regex/> [-purge]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "stop":
+        stopCmd.Parse(args[1:])
+        if (opts.profile == "") == !opts.all {
+            fmt.Println("Usage: launchium stop [-profile <name|glob|/regex/> | -all] [-timeout 10s]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "kill":
+        killCmd.Parse(args[1:])
+        if (opts.profile == "") == !opts.all {
+            fmt.Println("Usage: launchium kill [-profile <name|glob|/regex/> | -all]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "ps":
+        psCmd.Parse(args[1:])
+        return opts, true
+    case "focus":
+        focusCmd.Parse(args[1:])
+        return opts, true
     case "list":
-        listCmd.Parse(os.Args[2:])
-        return "list", "", true
+        listCmd.Parse(args[1:])
+        if opts.format != "" && opts.scriptJSON {
+            fmt.Println("Usage: launchium list [-tag <tag>] [-format <template> | -script-filter]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "rename":
+        renameCmd.Parse(args[1:])
+        opts.args = renameCmd.Args()
+        if len(opts.args) != 2 {
+            fmt.Println("Usage: launchium rename <old-name> <new-name>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "clone":
+        cloneCmd.Parse(args[1:])
+        opts.args = cloneCmd.Args()
+        if len(opts.args) != 2 {
+            fmt.Println("Usage: launchium clone <name> <new-name>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "edit":
+        editCmd.Parse(args[1:])
+        if opts.profile == "" || editCmd.NArg() > 0 || opts.yamlEdit && !opts.editor {
+            fmt.Println("Usage: launchium edit -profile <name> [-editor [-yaml]]")
+            os.Exit(2)
+        }
+        // Without -editor the profile's form opens in the TUI
+        return opts, opts.editor
+    case "generate":
+        generateCmd.Parse(args[1:])
+        if opts.spec == "" || generateCmd.NArg() > 0 {
+            fmt.Println("Usage: launchium generate -spec matrix.yaml [-dry-run]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "import-csv":
+        usage := "Usage: launchium import-csv <file.csv|file.xlsx> [-prefix proxy] [-dry-run] [-force]"
+        importCSVCmd.Parse(args[1:])
+        opts.args = importCSVCmd.Args()
+        // Flags may follow the file too
+        if len(opts.args) > 0 {
+            importCSVCmd.Parse(opts.args[1:])
+            opts.args = append(opts.args[:1], importCSVCmd.Args()...)
+        }
+        if len(opts.args) != 1 || opts.prefix == "" {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        return opts, true
+    case "diff":
+        diffCmd.Parse(args[1:])
+        opts.args = diffCmd.Args()
+        if len(opts.args) != 2 {
+            fmt.Println("Usage: launchium diff [-data] <name> <other-name>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "autostart":
+        usage := "Usage: launchium autostart <enable|disable|status> [-profile <name>]"
+        if len(args) < 2 {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        opts.args = []string{args[1]}
+        autostartCmd.Parse(args[2:])
+        opts.profile = *autostartProfile
+        switch args[1] {
+        case "enable", "disable":
+            if opts.profile == "" {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        case "status":
+        default:
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        return opts, true
+    case "gc":
+        gcCmd.Parse(args[1:])
+        return opts, true
+    case "daemon":
+        daemonCmd.Parse(args[1:])
+        return opts, true
+    case "serve":
+        serveCmd.Parse(args[1:])
+        return opts, true
+    case "scheduler":
+        if len(args) < 2 || (args[1] != "install" && args[1] != "uninstall" && args[1] != "status") {
+            fmt.Println("Usage: launchium scheduler <install|uninstall|status>")
+            os.Exit(2)
+        }
+        opts.args = []string{args[1]}
+        schedulerCmd.Parse(args[2:])
+        return opts, true
+    case "pick":
+        pickCmd.Parse(args[1:])
+        return opts, true
+    case "url":
+        urlCmd.Parse(args[1:])
+        opts.args = urlCmd.Args()
+        if len(opts.args) != 1 {
+            fmt.Println("Usage: launchium url <register|unregister|" + urlScheme + "://...>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "browsers":
+        browsersCmd.Parse(args[1:])
+        return opts, true
+    case "fetch-browser":
+        fetchCmd.Parse(args[1:])
+        return opts, true
+    case "refresh":
+        refreshCmd.Parse(args[1:])
+        return opts, true
+    case "sync":
+        if len(args) < 2 || (args[1] != syncPush && args[1] != syncPull) {
+            fmt.Println("Usage: launchium sync <push|pull> [-profile <name>] [-remote <url>]")
+            os.Exit(2)
+        }
+        opts.args = []string{args[1]}
+        syncCmd.Parse(args[2:])
+        return opts, true
+    case "config":
+        usage := "Usage: launchium config <path | open | log [-n 20] | revert [change] | export [-o file] [-format f] | import <file> [-merge|-replace] [-format f]>"
+        if len(args) < 2 || !containsString([]string{"path", "open", "log", "revert", "export", "import"}, args[1]) {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        configCmd.Parse(args[2:])
+        opts.args = append([]string{args[1]}, configCmd.Args()...)
+        // import takes its flags after the file too
+        if args[1] == "import" && len(opts.args) > 1 {
+            configCmd.Parse(opts.args[2:])
+            opts.args = append(opts.args[:2], configCmd.Args()...)
+        }
+        if opts.foreign != "" && args[1] != "import" && args[1] != "export" {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        if err := validForeignFormat(opts.foreign, args[1] == "export"); err != nil {
+            fmt.Printf("Error: %s\n", err)
+            os.Exit(2)
+        }
+        switch {
+        case args[1] == "path" || args[1] == "open" || args[1] == "log" || args[1] == "export":
+            if len(opts.args) > 1 {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        case args[1] == "import":
+            if len(opts.args) != 2 || (opts.merge && opts.replace) {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        case len(opts.args) > 2:
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        return opts, true
+    case "crashes":
+        crashesCmd.Parse(args[1:])
+        if (opts.open && opts.profile == "") || crashesCmd.NArg() > 0 {
+            fmt.Println("Usage: launchium crashes [-profile <name> [-open]]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "dir":
+        dirCmd.Parse(args[1:])
+        if opts.profile == "" || dirCmd.NArg() > 0 {
+            fmt.Println("Usage: launchium dir -profile <name> [-open]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "presets":
+        usage := "Usage: launchium presets <list | show <name>>"
+        if len(args) < 2 || (args[1] != "list" && args[1] != "show") {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        presetsCmd.Parse(args[2:])
+        opts.args = append([]string{args[1]}, presetsCmd.Args()...)
+        if (args[1] == "list" && len(opts.args) != 1) || (args[1] == "show" && len(opts.args) != 2) {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        return opts, true
+    case "lint":
+        lintCmd.Parse(args[1:])
+        return opts, true
+    case "du":
+        duCmd.Parse(args[1:])
+        return opts, true
+    case "compact":
+        compactCmd.Parse(args[1:])
+        if opts.profile == "" {
+            fmt.Println("Usage: launchium compact -profile <name|glob|/regex/>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "reset":
+        resetCmd.Parse(args[1:])
+        if opts.profile == "" {
+            fmt.Println("Usage: launchium reset -profile <name|glob|/regex/> [-force]")
+            os.Exit(2)
+        }
+        return opts, true
+    case "golden":
+        if len(args) < 2 || (args[1] != "save" && args[1] != "drop") {
+            fmt.Println("Usage: launchium golden <save|drop> -profile <name>")
+            os.Exit(2)
+        }
+        opts.args = []string{args[1]}
+        goldenCmd.Parse(args[2:])
+        if opts.profile == "" {
+            fmt.Println("Usage: launchium golden <save|drop> -profile <name>")
+            os.Exit(2)
+        }
+        return opts, true
+    case "app":
+        usage := "Usage: launchium app <add -profile <name> -url <url> -name <name> [-icon <file>] [-no-shortcut] | launch <name> | remove <name> | list>"
+        if len(args) < 2 {
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        appCmd.Parse(args[2:])
+        opts.args = append([]string{args[1]}, appCmd.Args()...)
+        switch args[1] {
+        case "add":
+            if opts.app.Profile == "" || opts.app.URL == "" || opts.app.Name == "" || len(opts.args) != 1 {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        case "launch", "remove":
+            if len(opts.args) != 2 {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        case "list":
+            if len(opts.args) != 1 {
+                fmt.Println(usage)
+                os.Exit(2)
+            }
+        default:
+            fmt.Println(usage)
+            os.Exit(2)
+        }
+        return opts, true
+    case "archive", "thaw":
+        fs := map[string]*flag.FlagSet{"archive": archiveCmd, "thaw": thawCmd}[args[0]]
+        fs.Parse(args[1:])
+        if opts.profile == "" {
+            fmt.Printf("Usage: launchium %s -profile <name|glob|/regex/>\n", args[0])
+            os.Exit(2)
+        }
+        return opts, true
+    case "go", ".":
+        goCmd.Parse(args[1:])
+        opts.command = "go"
+        return opts, true
     case "version":
-        versionCmd.Parse(os.Args[2:])
-        return "version", "", true
+        versionCmd.Parse(args[1:])
+        return opts, true
     case "help":
         printHelp()
         os.Exit(0)
-    default:
-        return "", "", false
+    }
+
+    // A number launches the profile in that slot, as in `launchium 3`
+    if slot, ok := parseSlot(args[0]); ok {
+        goCmd.Parse(args[1:])
+        opts.command, opts.slot = "slot", slot
+        return opts, true
     }
     
-    return "", "", false
+    opts.command = ""
+    return opts, false
 }
 
 // Print help information
 func printHelp() {
     fmt.Println("Launchium - Chromium Profile Manager")
     fmt.Println("\nUsage:")
-    fmt.Println("  launchium [command] [options]")
+    fmt.Println("  launchium [-config path] [command] [options]")
     fmt.Println("\nCommands:")
     fmt.Println("  launch    Launch browser with specified profile")
     fmt.Println("  clean     Clean a specific profile")
+    fmt.Println("  go, .     Launch the default (or last-used) profile")
+    fmt.Println("  1-9       Launch the profile in that quick-launch slot")
     fmt.Println("  list      List all available profiles")
-    fmt.Println("  version   Show version information")
+    fmt.Println("  pick      Print profile names for a picker, or launch a choice (-launch, -menu rofi)")
+    fmt.Println("  remove    Remove profiles from the config (-purge also deletes their data)")
+    fmt.Println("  stop      Quit running browsers cleanly (-profile name or -all)")
+    fmt.Println("  kill      Kill running browsers at once, without letting them save their session")
+    fmt.Println("  ps        List the running browsers, also those started by earlier runs of launchium")
+    fmt.Println("  focus     Bring a running profile's window to the front (-launch starts it if needed)")
+    fmt.Println("  rename    Rename a profile and move its data directory")
+    fmt.Println("  clone     Copy a profile and its data under a new name")
+    fmt.Println("  edit      Edit a profile in the TUI form, or in $EDITOR with -editor")
+    fmt.Println("  generate  Add the profiles of a matrix spec, one per combination (-spec file, -dry-run)")
+    fmt.Println("  import-csv Add or update profiles from the rows of a CSV or Excel sheet (-prefix, -dry-run)")
+    fmt.Println("  diff      Compare two profiles' settings, flags and seeded preferences (-data for their data)")
+    fmt.Println("  autostart Launch a profile at login (enable, disable or status)")
+    fmt.Println("  gc        Run the scheduled cleans that are due")
+    fmt.Println("  daemon    Serve a JSON control API on a local socket (-socket path)")
+    fmt.Println("  serve     Serve an authenticated REST API (-listen 127.0.0.1:7777, -grpc addr)")
+    fmt.Println("  scheduler Run 'gc' regularly from the OS (install, uninstall or status)")
+    fmt.Println("  url       Open " + urlScheme + ":// links (register, unregister or a link)")
+    fmt.Println("  browsers  List installed browsers with their channels and versions")
+    fmt.Println("  fetch-browser Download a pinned Chromium build for profiles to use")
+    fmt.Println("  refresh   Fetch a new version of a remote config (-config https://...)")
+    fmt.Println("  sync      Copy a profile's data to or from S3, WebDAV or ssh (push or pull)")
+    fmt.Println("  config    Print the config's path (path), edit it (open), show its history (log),")
+    fmt.Println("            go back to an earlier version (revert),")
+    fmt.Println("            or share profile definitions (export -o file, import file -merge|-replace)")
+    fmt.Println("            -format dirs, gologin, multilogin or csv converts other profile managers' exports")
+    fmt.Println("  dir       Print a profile's data directory, or open it (-open)")
+    fmt.Println("  crashes   List the crashes found of profiles' browsers and where their dumps are (-profile, -open)")
+    fmt.Println("  presets   List the flag presets profiles can use, or show one's flags (list, show)")
+    fmt.Println("  lint      Check profile flags for switches Chromium removed or renamed")
+    fmt.Println("  du        Show how much disk space profiles use (-profile pattern, -rescan)")
+    fmt.Println("  compact   Vacuum a closed profile's databases and remove its caches")
+    fmt.Println("  reset     Wipe a profile's data and set it up again as on its first launch")
+    fmt.Println("  golden    Save or drop the golden image launch -fresh restores a profile from")
+    fmt.Println("  app       Open web apps in windows of their own, with shortcuts (add, launch, remove, list)")
+    fmt.Println("  archive   Pack a profile's data into a .tar.zst to free disk space")
+    fmt.Println("  thaw      Unpack an archived profile so it can be launched again")
+    fmt.Println("  version   Show version and build information, and check for updates")
     fmt.Println("  help      Show this help message")
     fmt.Println("\nOptions for 'launch' and 'clean':")
-    fmt.Println("  -profile  Specify the profile name (default: 'default')")
+    fmt.Println("  -profile  Specify the profile name (default: the default_profile setting, or 'default')")
+    fmt.Println("            'clean' and 'remove' also accept a glob (test-*) or /regex/")
+    fmt.Println("  -profiles Comma-separated profiles for 'launch' to start in parallel")
+    fmt.Println("\nGlobal options:")
+    fmt.Println("  -config   Path or https://, s3:// or git+ URL of the profiles config (or set " + configEnvVar + ")")
     fmt.Println("\nExamples:")
     fmt.Println("  launchium                    Start the interactive UI")
     fmt.Println("  launchium launch -profile=work  Launch browser with 'work' profile")
+    fmt.Println("  launchium launch -profiles work,personal  Launch several profiles at once")
+    fmt.Println("  launchium launch -profiles a,b,c,d -tile grid  Launch four profiles tiled 2x2 across the screen")
+    fmt.Println("  launchium launch -profile kiosk -keep-alive  Relaunch 'kiosk' whenever it exits")
+    fmt.Println("  launchium launch -profile work --add-flag=--start-maximized --proxy=none  Tweak 'work' for one launch")
+    fmt.Println("  launchium launch -profile work -guest   Open a guest session with 'work's proxy")
+    fmt.Println("  launchium launch -profile work -session standup   Open the pages and window layout of 'work's standup session")
+    fmt.Println("  launchium app add -profile work -url https://app.example.com -name Example   Make a shortcut opening the site as an app")
+    fmt.Println("  launchium app launch Example   Open the 'Example' app in its own window")
+    fmt.Println("  launchium focus -profile work -launch   Switch to 'work', launching it if it isn't running")
+    fmt.Println("  launchium launch -profile work -isolated   Run a second, throwaway session of 'work' beside the first")
+    fmt.Println("  launchium launch -profile scraper -print-pid   Print only the browser's PID, for a supervisor")
     fmt.Println("  launchium clean -profile=test   Clean the 'test' profile")
+    fmt.Println("  launchium clean -profile test -shred   Overwrite 'test's files before deleting them")
+    fmt.Println("  launchium clean -profile work -keep \"Default/Bookmarks,Default/Extensions\"   Clean 'work' but keep its bookmarks and extensions")
+    fmt.Println("  launchium reset -profile demo   Wipe 'demo' and seed it fresh, ready to launch")
+    fmt.Println("  launchium golden save -profile demo   Keep 'demo' as it is now as its golden image")
+    fmt.Println("  launchium launch -profile demo -fresh   Launch 'demo' from its golden image")
+    fmt.Println("  launchium remove -profile '/^tmp-/' -purge   Remove all tmp-* profiles and their data")
+    fmt.Println("  launchium .                  Launch the default or last-used profile")
+    fmt.Println("  launchium 3                  Launch the profile with slot = 3")
     fmt.Println("  launchium list               List all available profiles")
+    fmt.Println("  launchium list -tag client-a List profiles tagged client-a")
+    fmt.Println("  launchium list --script-filter  Profiles as JSON for Alfred or Raycast")
+    fmt.Println("  launchium list -format '{{.Name}}\\t{{.Proxy}}'   One line per profile for cut or awk")
+    fmt.Println("  launchium list -format table  Profiles as columns with headers")
+    fmt.Println("  launchium rename old new     Rename profile 'old' to 'new'")
+    fmt.Println("  launchium edit -profile work -editor   Edit 'work' as TOML in $EDITOR")
+    fmt.Println("  launchium diff -data work work-staging   Show why 'work' and 'work-staging' behave differently")
+    fmt.Println("  launchium pick | fzf | launchium pick -launch   Choose a profile with fzf")
+    fmt.Println("  launchium pick -menu rofi    Choose a profile with rofi")
+    fmt.Println("  launchium autostart enable -profile work   Launch 'work' at login")
+    fmt.Println("  launchium scheduler install  Clean profiles on their clean_schedule")
+    fmt.Println("  launchium url register       Open launchium://launch/work links with launchium")
+    fmt.Println("  launchium sync push -profile work   Copy 'work' to the sync_remote")
+    fmt.Println("  launchium config open        Edit the config in $EDITOR")
+    fmt.Println("  launchium config revert      Undo the last change to the config")
+    fmt.Println("  launchium dir -profile work -open   Show 'work's data in the file manager")
+    fmt.Println("  launchium config export -o profiles.yaml   Write the profiles for a teammate to import")
+    fmt.Println("  launchium presets show privacy   Print the flags of the privacy preset")
+    fmt.Println("  launchium -config ~/work.toml   Use a separate profiles config")
+    fmt.Println("  launchium -config https://it.example.com/launchium.toml refresh   Update a centrally managed config")
+}
+
+// Installed Chrome/Chromium binaries for this platform, in order of preference.
+// Builds from 'launchium fetch-browser' come after installed browsers.
+func browserCandidates() []string {
+    found := []string{}
+    for _, b := range installedBrowsers() {
+        found = append(found, b.Path)
+    }
+    return found
 }
 
-// Detect platform and set paths accordingly
+// Pick the browser: the one chosen at setup, else the first one installed
 func (cm *ChromiumManager) detectPlatform() {
-    // Set platform-specific paths
-    switch runtime.GOOS {
-    case "darwin": // macOS
-        cm.chromePath = "/Applications/Chromium.app/Contents/MacOS/Chromium"
-        if _, err := os.Stat(cm.chromePath); os.IsNotExist(err) {
-            cm.chromePath = "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"
-        }
-        
-    case "windows":
-        // Common paths for Windows
-        chromePaths := []string{
-            filepath.Join(os.Getenv("ProgramFiles"), "Chromium", "Application", "chrome.exe"),
-            filepath.Join(os.Getenv("ProgramFiles(x86)"), "Chromium", "Application", "chrome.exe"),
-            filepath.Join(os.Getenv("ProgramFiles"), "Google", "Chrome", "Application", "chrome.exe"),
-            filepath.Join(os.Getenv("ProgramFiles(x86)"), "Google", "Chrome", "Application", "chrome.exe"),
-            filepath.Join(os.Getenv("LocalAppData"), "Chromium", "Application", "chrome.exe"),
-            filepath.Join(os.Getenv("LocalAppData"), "Google", "Chrome", "Application", "chrome.exe"),
-        }
-        
-        // Try each path
-        for _, path := range chromePaths {
-            if _, err := os.Stat(path); err == nil {
-                cm.chromePath = path
-                break
-            }
-        }
-        
-    case "linux":
-        // Common paths for Linux
-        chromePaths := []string{
-            "/usr/bin/chromium",
-            "/usr/bin/chromium-browser",
-            "/usr/bin/google-chrome",
-            "/usr/bin/google-chrome-stable",
-            "/snap/bin/chromium",
-        }
-        
-        // Try each path
-        for _, path := range chromePaths {
-            if _, err := os.Stat(path); err == nil {
-                cm.chromePath = path
-                break
-            }
-        }
+    if cm.settings.Browser != "" {
+        cm.chromePath = expandPath(cm.settings.Browser)
+        return
+    }
+    if candidates := browserCandidates(); len(candidates) > 0 {
+        cm.chromePath = candidates[0]
+        return
     }
     
-    // If no browser found, set a default and log a warning
-    if cm.chromePath == "" {
-        cm.err = fmt.Errorf("Could not find Chrome or Chromium browser. Please specify path manually")
-        if runtime.GOOS == "windows" {
-            cm.chromePath = filepath.Join(os.Getenv("ProgramFiles"), "Google", "Chrome", "Application", "chrome.exe")
-        } else {
-            cm.chromePath = "/usr/bin/google-chrome"
-        }
+    // If no browser found, fall back to the usual location; launching
+    // reports the missing binary
+    switch runtime.GOOS {
+    case "darwin":
+        cm.chromePath = "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"
+    case "windows":
+        cm.chromePath = filepath.Join(os.Getenv("ProgramFiles"), "Google", "Chrome", "Application", "chrome.exe")
+    default:
+        cm.chromePath = "/usr/bin/google-chrome"
     }
 }
 
@@ -184,709 +892,2506 @@ func (cm *ChromiumManager) setProfilePath() {
     cm.configFile = filepath.Join(cm.profileDir, "profiles.conf")
 }
 
-// Helper styles for application UI
+// Helper styles for application UI, built from the theme by setStyles
 var (
-	docStyle  = lipgloss.NewStyle().Margin(1, 2)
-	errStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-	okStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
-	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Italic(true)
+	docStyle      lipgloss.Style
+	errStyle      lipgloss.Style
+	okStyle       lipgloss.Style
+	warnStyle     lipgloss.Style
+	helpStyle     lipgloss.Style
+	archivedStyle lipgloss.Style // Profiles whose data is archived
 )
 
-// Create a new model
-func initialModel() *ChromiumManager {
+// Create a new model. configPath overrides the default config file location
+// when non-empty.
+func initialModel(configPath string) *ChromiumManager {
 	cm := &ChromiumManager{
+		ctx:         context.Background(),
 		profiles:    make(map[string]Profile),
+		sizes:       make(map[string]int64),
+		sizing:      make(map[string]bool),
+		cleaning:    make(map[string]cleanProgress),
 		currentView: "main",
+		keys:        defaultKeyMap(),
+		help:        help.New(),
+		spinner:     newSpinner(),
 	}
 
 	// Set paths
 	homeDir, _ := os.UserHomeDir()
 	cm.profileDir = filepath.Join(homeDir, ".chrome_profiles")
-	cm.configFile = filepath.Join(cm.profileDir, "profiles.conf")
+	cm.configFile = resolveConfigPath(configPath, cm.profileDir)
 
-	// Find browser
-	cm.chromePath = "/Applications/Chromium.app/Contents/MacOS/Chromium"
-	if _, err := os.Stat(cm.chromePath); os.IsNotExist(err) {
-		cm.chromePath = "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"
+	// A remote config is fetched into a cache, which is used from then on
+	if isRemoteConfig(cm.configFile) {
+		cm.configSource = cm.configFile
+		cm.configFile = filepath.Join(remoteCacheDir(cm.profileDir, cm.configSource), configFileName)
+		cm.remoteChanged, cm.remoteErr = cm.refreshConfig(cm.ctx)
+		if _, err := os.Stat(cm.configFile); cm.remoteErr != nil && err == nil {
+			cm.notify(levelWarn, "Couldn't refresh the config from %s, using the copy fetched before: %s", cm.configSource, cm.remoteErr)
+		}
 	}
 
 	// Create directories & load profiles
 	os.MkdirAll(cm.profileDir, 0755)
+	os.MkdirAll(filepath.Dir(cm.configFile), 0755)
 	cm.loadProfiles()
+	cm.loadState()
+	instancesPath = cm.instancesFile()
+	if len(cm.unknownKeys) > 0 {
+		cm.notify(levelWarn, "Ignoring what this version doesn't know in the config (kept when saving): %s", strings.Join(cm.unknownKeys, "; "))
+	}
 
-	// Create main menu
-	delegate := list.NewDefaultDelegate()
-	delegate.SetHeight(3) // Increase item height for better visibility
-	delegate.SetSpacing(1) // Add spacing between items
-	
-	items := []list.Item{
-		item{title: "Launch Browser", desc: "Start with a profile"},
-		item{title: "Manage Profiles", desc: "Add, edit or remove profiles"},
-		item{title: "Clean Profile", desc: "Clear browsing data"},
-		item{title: "Quit", desc: "Exit application"},
+	// Settings may move the profile data and pick the browser
+	if cm.settings.ProfileDir != "" {
+		cm.profileDir = expandPath(cm.settings.ProfileDir)
+		os.MkdirAll(cm.profileDir, 0755)
 	}
+	cm.detectPlatform()
+
+	// Create menus
+	cm.updateMainList()
+	cm.updateManageList()
 
-	cm.mainList = list.New(items, delegate, 80, 24)
+	return cm
+}
+
+// Update the main menu
+func (cm *ChromiumManager) updateMainList() {
+	// Taller items for better visibility when there is room
+	delegate := cm.listDelegate(3)
+
+	width, height := cm.contentSize()
+	cm.mainList = list.New(cm.mainItems(), delegate, width, height)
 	cm.mainList.Title = "Launchium - Chromium Profile Manager"
 	cm.mainList.SetShowStatusBar(true)
 	cm.mainList.SetFilteringEnabled(false)
-	
-	// Create management menu
-	cm.updateManageList()
+	cm.keys.configureList(&cm.mainList)
+	styleList(&cm.mainList)
+}
 
-	return cm
+// Entries of the main menu: the recently launched profiles, so the last
+// one is a single Enter away, then the menu itself
+func (cm *ChromiumManager) mainItems() []list.Item {
+	return append(cm.recentItems(),
+		item{title: "Launch Browser", desc: "Start with a profile"},
+		item{title: "Manage Profiles", desc: "Add, edit or remove profiles"},
+		item{title: "Clean Profile", desc: "Clear browsing data"},
+		item{title: "Running Browsers", desc: "Raise, close or open URLs in running profiles"},
+		item{title: "Health Dashboard", desc: "Disk usage, proxies, unused profiles and due cleans"},
+		item{title: "Quit", desc: "Exit application"},
+	)
 }
 
 // Load profiles from config file
 func (cm *ChromiumManager) loadProfiles() {
-	// Create default profile if needed
-	if _, err := os.Stat(cm.configFile); os.IsNotExist(err) {
-		defaults := []Profile{
-			{Name: "default", Proxy: "none", ProxyType: "none", Flags: "--no-first-run --disable-features=RendererCodeIntegrity"},
-			{Name: "clean", Proxy: "none", ProxyType: "none", Flags: "--no-first-run --disable-features=RendererCodeIntegrity,UseChromeOSDirectVideoDecoder --disable-gpu-driver-bug-workarounds --ignore-gpu-blacklist --disable-gpu-compositing --disable-infobars"},
+	if cm.configSource != "" {
+		if err := checkConfigSource(cm.configSource); err != nil {
+			cm.err = err
+			return
 		}
-		
-		var content string
-		for _, p := range defaults {
-			content += fmt.Sprintf("%s|%s|%s|%s\n", p.Name, p.Proxy, p.ProxyType, p.Flags)
+	}
+	if _, err := fsys.Stat(cm.configFile); os.IsNotExist(err) {
+		if cm.configSource != "" {
+			cm.err = fmt.Errorf("fetching the config from %s: %s", cm.configSource, cm.remoteErr)
+			return
 		}
-		
-		ioutil.WriteFile(cm.configFile, []byte(content), 0644)
+
+		// Migrate the old pipe-delimited config if it sits next to the new one
+		legacyFile := filepath.Join(filepath.Dir(cm.configFile), legacyConfigFileName)
+		data, err := fsys.ReadFile(legacyFile)
+		if err != nil && len(cm.dropInFiles()) > 0 {
+			// Profiles can be provisioned as drop-in files alone
+			cm.loadConfigData(nil)
+			return
+		}
+		if err != nil {
+			// First run: nothing is written until setup is done, so the
+			// command line works with the default profiles meanwhile
+			cm.profiles = defaultProfiles()
+			cm.firstRun = true
+			cm.updateProfileList()
+			return
+		}
+		cm.profiles = parseLegacyConfig(data)
+		cm.saveProfiles()
 	}
 
 	// Read profiles
-	data, err := ioutil.ReadFile(cm.configFile)
+	data, err := fsys.ReadFile(cm.configFile)
 	if err != nil {
 		return
 	}
+	cm.loadConfigData(data)
+}
 
-	for _, line := range strings.Split(string(data), "\n") {
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		if len(parts) >= 4 {
-			cm.profiles[parts[0]] = Profile{
-				Name:      parts[0],
-				Proxy:     parts[1],
-				ProxyType: parts[2],
-				Flags:     parts[3],
-			}
-		}
+// loadConfigData takes the profiles and settings from the config file's
+// contents, adding the profiles from drop-in files
+func (cm *ChromiumManager) loadConfigData(data []byte) {
+	profiles, settings, err := parseConfig(data)
+	if err != nil {
+		cm.err = fmt.Errorf("%s: %s", cm.configFile, err)
+		return
+	}
+	unknown := []string{}
+	for _, u := range settings.Unknown {
+		unknown = append(unknown, filepath.Base(cm.configFile)+" "+u)
+	}
+	more, err := cm.loadDropIns(profiles)
+	if err != nil {
+		cm.err = err
+		return
+	}
+	project, err := cm.loadProjectProfiles(profiles)
+	if err != nil {
+		cm.err = err
+		return
+	}
+	more = append(more, project...)
+	if err := checkReferences(profiles, settings); err != nil {
+		cm.err = err
+		return
 	}
+	cm.profiles = profiles
+	cm.settings = settings
+	cm.unknownKeys = append(unknown, more...)
+	cm.keys.apply(settings.Keys)
+	cm.loadedStamp = cm.configStamp()
 
 	// Update profile list
 	cm.updateProfileList()
 }
 
+// reloadProfiles re-reads the config so long-running servers pick up edits
+// made in the TUI or by hand, and new versions of a remote config. ctx
+// bounds fetching the latter.
+func (cm *ChromiumManager) reloadProfiles(ctx context.Context) error {
+	cm.refreshIfStale(ctx)
+	cm.loadProfiles()
+	err := cm.err
+	cm.err = nil
+	return err
+}
+
 // Update the profile list
 func (cm *ChromiumManager) updateProfileList() {
 	items := []list.Item{}
-	for name := range cm.profiles {
-		items = append(items, item{title: name, desc: ""})
+	for _, name := range sortedProfileNames(cm.profiles) {
+		profile := cm.profiles[name]
+		if cm.tagFilter != "" && !profile.hasTag(cm.tagFilter) {
+			continue
+		}
+		items = append(items, item{title: name, desc: profile.summary(), label: profile.label(), archived: cm.isArchived(name), slot: profile.Slot})
 	}
 
-	delegate := list.NewDefaultDelegate()
-	delegate.SetHeight(2)
-	delegate.SetSpacing(1)
-	
-	cm.profileList = list.New(items, delegate, 80, 24)
-	cm.profileList.Title = "Select Profile"
+	delegate := cm.listDelegate(2)
+
+	width, height := cm.profileListSize()
+	cm.profileList = list.New(items, delegate, width, height)
+	cm.flagScroll = 0
+	cm.profileList.Title = cm.profileListTitle()
 	cm.profileList.SetShowStatusBar(true)
-	cm.profileList.SetFilteringEnabled(false)
+	cm.profileList.Filter = filterItems
+	cm.keys.configureList(&cm.profileList)
+	styleList(&cm.profileList)
+}
+
+// Title of the profile list with the tag filter and, in the launch picker,
+// the launch mode
+func (cm *ChromiumManager) profileListTitle() string {
+	title := "Select Profile"
+	if cm.tagFilter != "" {
+		title += " [tag: " + cm.tagFilter + "]"
+	}
+	if cm.currentView == "select_profile" && cm.launchMode != launchNormal {
+		title += " [" + cm.launchMode + "]"
+	}
+	return title
+}
+
+// Size of the profile list, leaving room for the detail pane on wide terminals
+func (cm *ChromiumManager) profileListSize() (int, int) {
+	width, height := cm.contentSize()
+	if cm.showDetailPane() {
+		width = width * 2 / 5
+	}
+	// Leave a row for the marking hint below the list
+	return width, height - 1
+}
+
+// typingFilter reports whether a filter is being typed into the profile list
+func (cm *ChromiumManager) typingFilter() bool {
+	return strings.HasPrefix(cm.currentView, "select_") && cm.profileList.SettingFilter()
+}
+
+// filterItems matches a profile list's filter against the profiles' names,
+// descriptions and tags. Matches aren't highlighted since most of what is
+// matched isn't in the title, and the title has the label's colors in it.
+func filterItems(term string, targets []string) []list.Rank {
+	ranks := list.DefaultFilter(term, targets)
+	for i := range ranks {
+		ranks[i].MatchedIndexes = nil
+	}
+	return ranks
+}
+
+// Forward a message to the profile list and scan the newly selected
+// profile's disk usage for the detail pane
+func (cm *ChromiumManager) forwardToProfileList(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	index := cm.profileList.Index()
+	cm.profileList, cmd = cm.profileList.Update(msg)
+	if cm.profileList.Index() != index {
+		cm.flagScroll = 0
+	}
+	return tea.Batch(cmd, cm.requestSelectedSize())
 }
 
 // Update the manage menu
 func (cm *ChromiumManager) updateManageList() {
-	delegate := list.NewDefaultDelegate()
-	delegate.SetHeight(2)
-	delegate.SetSpacing(1)
-	
+	delegate := cm.listDelegate(2)
+
 	items := []list.Item{
 		item{title: "Add New Profile", desc: "Create a new browser profile"},
 		item{title: "Edit Profile", desc: "Modify an existing profile"},
 		item{title: "Delete Profile", desc: "Remove a profile"},
+		item{title: "Set Default Profile", desc: "Choose the profile used by 'launchium launch' and 'launchium go'"},
 	}
 
-	cm.manageList = list.New(items, delegate, 80, 24)
+	width, height := cm.contentSize()
+	cm.manageList = list.New(items, delegate, width, height)
 	cm.manageList.Title = "Profile Management"
 	cm.manageList.SetShowStatusBar(true)
 	cm.manageList.SetFilteringEnabled(false)
+	cm.keys.configureList(&cm.manageList)
+	styleList(&cm.manageList)
 }
 
 // Save profiles to config file
-func (cm *ChromiumManager) saveProfiles() {
-	var content string
-	for _, profile := range cm.profiles {
-		content += fmt.Sprintf("%s|%s|%s|%s\n", 
-			profile.Name, profile.Proxy, profile.ProxyType, profile.Flags)
+func (cm *ChromiumManager) saveProfiles() error {
+	if cm.configSource != "" {
+		return cm.errRemoteConfig()
 	}
-	
-	ioutil.WriteFile(cm.configFile, []byte(content), 0644)
+	if err := cm.checkProjectProfiles(); err != nil {
+		for name, profile := range cm.projectProfiles {
+			cm.profiles[name] = profile
+		}
+		return err
+	}
+	var err error
+	if cm.settings.ConfigHistory {
+		err = cm.saveWithHistory()
+	} else {
+		err = fsys.WriteFile(cm.configFile, cm.renderConfig(), 0644)
+	}
+	if dropInErr := cm.saveDropIns(); err == nil {
+		err = dropInErr
+	}
+	cm.loadedStamp = cm.configStamp()
+	return err
 }
 
-// Launch browser with profile
-func (cm *ChromiumManager) launchBrowser(profileName string) string {
-	profile, exists := cm.profiles[profileName]
+// Rename a profile in the config and move its data directory along with it.
+// Profiles with a custom data_dir keep their directory where it is.
+func (cm *ChromiumManager) renameProfile(oldName, newName string) error {
+	profile, exists := cm.profiles[oldName]
 	if !exists {
-		return fmt.Sprintf("Profile '%s' not found", profileName)
+		return profileNotFound(oldName)
 	}
-
-	// Create profile directory
-	profilePath := filepath.Join(cm.profileDir, profile.Name)
-	os.MkdirAll(profilePath, 0755)
-	
-	// Create Local State file for API key warnings
-	prefsFile := filepath.Join(profilePath, "Local State")
-	if _, err := os.Stat(prefsFile); os.IsNotExist(err) {
-		prefsData := `{"browser":{"enabled_labs_experiments":["ignore-gpu-blocklist@1"]},"distribution":{"suppress_first_run_bubble":true,"suppress_api_keys_warning":true}}`
-		ioutil.WriteFile(prefsFile, []byte(prefsData), 0644)
+	if newName == "" {
+		return fmt.Errorf("new profile name is required")
 	}
-
-	// Build command line with all arguments
-	cmdArgs := []string{}
-	
-	// Add profile directory
-	cmdArgs = append(cmdArgs, "--user-data-dir="+profilePath)
-	
-	// Force new window
-	cmdArgs = append(cmdArgs, "--new-window")
-	cmdArgs = append(cmdArgs, "about:blank") // Open a blank page to ensure window opens
-	
-	// Add proxy if specified
-	if profile.Proxy != "none" {
-		proxyFlag := "--proxy-server="
-		if profile.ProxyType == "http" {
-			proxyFlag += "http://"
-		}
-		proxyFlag += profile.Proxy
-		cmdArgs = append(cmdArgs, proxyFlag)
+	if err := validProfileName(newName); err != nil {
+		return err
 	}
-	
-	// Add profile flags by splitting on spaces (proper handling)
-	if profile.Flags != "" {
-		for _, flag := range strings.Split(profile.Flags, " ") {
-			if flag != "" {
-				cmdArgs = append(cmdArgs, flag)
-			}
-		}
+	if cm.configSource != "" {
+		return cm.errRemoteConfig()
 	}
-	
-	// Add standard suppression flags
-	standardFlags := []string{
-		// Logging and notification suppression
-		"--disable-logging",
-		"--disable-breakpad",
-		"--disable-infobars",
-		"--disable-notifications",
-		"--no-default-browser-check",
-		"--silent-launch",
-		
-		// GPU artifact suppression
-		"--disable-gpu",
-		"--disable-gpu-compositing",
-		"--disable-gpu-sandbox",
-		"--disable-gpu-driver-bug-workarounds",
-		"--disable-features=UseChromeOSDirectVideoDecoder",
-		"--disable-accelerated-2d-canvas",
-		"--disable-accelerated-video-decode",
-		"--disable-accelerated-video-encode",
-		"--disable-webgl",
-		"--disable-threaded-animation",
-		"--disable-webgl-image-chromium",
-		"--force-dark-mode",
-		// Ignore Certificat errors
-		"--ignore-certificate-errors",
+	if _, exists := cm.profiles[newName]; exists {
+		return fmt.Errorf("profile '%s' already exists", newName)
 	}
-	
-	for _, flag := range standardFlags {
-		cmdArgs = append(cmdArgs, flag)
+
+	oldPath := cm.profilePath(profile)
+	if _, running := runningPID(oldPath); running {
+		return fmt.Errorf("profile '%s' is running, close the browser first", oldName)
 	}
-	
-	// Platform-specific browser launching
-	var err error
-	
-	switch runtime.GOOS {
-	case "darwin": // macOS
-		// First attempt: standard exec approach
-		cmd := exec.Command(cm.chromePath, cmdArgs...)
-		err = cmd.Start()
-		
-		// If that fails, try the open command on macOS
-		if err != nil {
-			// Create a shell script in temp directory
-			scriptPath := filepath.Join(os.TempDir(), "launch_chrome.sh")
-			scriptContent := "#!/bin/bash\n" + cm.chromePath + " " + strings.Join(cmdArgs, " ") + " &\n"
-			if err := ioutil.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
-				return fmt.Sprintf("Error creating launcher script: %s", err)
-			}
-			
-			// Execute the script
-			cmd = exec.Command("/bin/bash", scriptPath)
-			if err = cmd.Start(); err != nil {
-				// Last resort - use 'open' command on macOS
-				openArgs := []string{cm.chromePath, "--args"}
-				openArgs = append(openArgs, cmdArgs...)
-				cmd = exec.Command("open", openArgs...)
-				err = cmd.Start()
+
+	renamed := profile
+	renamed.Name = newName
+	newPath := cm.profilePath(renamed)
+
+	// Move the data directory first so a failure leaves the config untouched
+	moved := false
+	if oldPath != newPath {
+		if _, err := os.Stat(newPath); err == nil {
+			return fmt.Errorf("data directory %s already exists", newPath)
+		}
+		if err := os.Rename(oldPath, newPath); err == nil {
+			moved = true
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("moving data directory: %w", err)
+		}
+		if cm.isArchived(oldName) {
+			if err := os.Rename(oldPath+archiveExt, newPath+archiveExt); err != nil {
+				if moved {
+					os.Rename(newPath, oldPath)
+				}
+				return fmt.Errorf("moving archive: %w", err)
 			}
 		}
-		
-	case "linux": // Linux
-		// Try normal execution first
-		cmd := exec.Command(cm.chromePath, cmdArgs...)
-		err = cmd.Start()
-		
-		// If that fails, try using xdg-open
-		if err != nil {
-			// Try with nohup
-			cmd = exec.Command("nohup", cm.chromePath)
-			cmd.Args = append(cmd.Args, cmdArgs...)
-			err = cmd.Start()
-			
-			// If nohup fails, try with xdg-open via a temporary desktop file
-			if err != nil {
-				// Create a desktop file
-				desktopPath := filepath.Join(os.TempDir(), "launchium_chrome.desktop")
-				desktopContent := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=Launchium Chrome\nExec=%s %s\nTerminal=false", 
-											cm.chromePath, strings.Join(cmdArgs, " "))
-				
-				if err := ioutil.WriteFile(desktopPath, []byte(desktopContent), 0755); err == nil {
-					cmd = exec.Command("xdg-open", desktopPath)
-					err = cmd.Start()
+		if _, ok := cm.goldenSaved(oldName); ok {
+			if err := os.Rename(oldPath+goldenExt, newPath+goldenExt); err != nil {
+				if moved {
+					os.Rename(newPath, oldPath)
 				}
+				if cm.isArchived(oldName) {
+					os.Rename(newPath+archiveExt, oldPath+archiveExt)
+				}
+				return fmt.Errorf("moving golden image: %w", err)
 			}
 		}
-
-	default:
-        // Fallback for unsupported platforms
-        cmd := exec.Command(cm.chromePath, cmdArgs...)
-        err = cmd.Start()
-    }
-	
-	if err != nil {
-		return fmt.Sprintf("Error launching browser: %s", err)
 	}
-	
-	return fmt.Sprintf("Launched with profile: %s", profile.Name)
-}
 
-// Item for lists
-type item struct {
-	title, desc string
+	delete(cm.profiles, oldName)
+	cm.profiles[newName] = renamed
+	cm.moveDropIn(oldName, newName)
+	cm.renameAppProfile(oldName, newName)
+	if cm.settings.DefaultProfile == oldName {
+		cm.settings.DefaultProfile = newName
+	}
+	if err := cm.saveProfiles(); err != nil {
+		// Put everything back so config and disk stay in sync
+		delete(cm.profiles, newName)
+		cm.profiles[oldName] = profile
+		cm.moveDropIn(newName, oldName)
+		cm.renameAppProfile(newName, oldName)
+		if cm.settings.DefaultProfile == newName {
+			cm.settings.DefaultProfile = oldName
+		}
+		if moved {
+			os.Rename(newPath, oldPath)
+		}
+		if cm.isArchived(oldName) && oldPath != newPath {
+			os.Rename(newPath+archiveExt, oldPath+archiveExt)
+		}
+		if oldPath != newPath {
+			os.Rename(newPath+goldenExt, oldPath+goldenExt)
+		}
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	// Carry usage history over to the new name
+	if cm.state.LastUsed == oldName {
+		cm.state.LastUsed = newName
+	}
+	if t, ok := cm.state.LastLaunch[oldName]; ok {
+		cm.state.LastLaunch[newName] = t
+		delete(cm.state.LastLaunch, oldName)
+	}
+	if t, ok := cm.state.LastClean[oldName]; ok {
+		cm.state.LastClean[newName] = t
+		delete(cm.state.LastClean, oldName)
+	}
+	if browser, ok := cm.state.LastBrowser[oldName]; ok {
+		cm.state.LastBrowser[newName] = browser
+		delete(cm.state.LastBrowser, oldName)
+	}
+	if t, ok := cm.state.Archived[oldName]; ok {
+		cm.state.Archived[newName] = t
+		delete(cm.state.Archived, oldName)
+	}
+	if usage, ok := cm.state.Sizes[oldName]; ok {
+		cm.state.Sizes[newName] = usage
+		delete(cm.state.Sizes, oldName)
+	}
+	cm.saveState()
+
+	return nil
 }
 
-func (i item) Title() string       { return i.title }
-func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+// Profile used when no name is given on the command line
+func (cm *ChromiumManager) defaultProfile() string {
+	if cm.settings.DefaultProfile != "" {
+		return cm.settings.DefaultProfile
+	}
+	return "default"
+}
 
-// Init implements tea.Model
-func (cm *ChromiumManager) Init() tea.Cmd {
-	// Set initial size to show items
-	if cm.mainList.Items() != nil {
-		cm.mainList.SetSize(80, 20)
+// Profile for the quick-launch shortcut: the configured default, then the
+// last-used profile, then "default"
+func (cm *ChromiumManager) quickLaunchProfile() string {
+	if cm.settings.DefaultProfile != "" {
+		return cm.settings.DefaultProfile
 	}
-	if cm.profileList.Items() != nil {
-		cm.profileList.SetSize(80, 20)
+	if _, ok := cm.profiles[cm.state.LastUsed]; ok {
+		return cm.state.LastUsed
 	}
-	if cm.manageList.Items() != nil {
-		cm.manageList.SetSize(80, 20)
+	return "default"
+}
+
+// Color swatch and icon shown before the profile name in the TUI
+func (p Profile) label() string {
+	s := ""
+	if p.Color != "" {
+		s = lipgloss.NewStyle().Foreground(lipgloss.Color(p.Color)).Render("●")
 	}
-	return nil
+	if p.Icon != "" {
+		if s != "" {
+			s += " "
+		}
+		s += p.Icon
+	}
+	return s
 }
 
-// Update implements tea.Model
-func (cm *ChromiumManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+// One-line description and tags shown next to the profile name in lists
+func (p Profile) summary() string {
+	s := p.Description
+	if len(p.Tags) > 0 {
+		if s != "" {
+			s += " "
+		}
+		s += "[" + strings.Join(p.Tags, ", ") + "]"
+	}
+	return s
+}
 
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		// Update window sizes for all lists
-		if cm.manageList.Items() != nil {
-			cm.manageList.SetSize(msg.Width, msg.Height-6)
+// Resolve the user-data-dir for a profile
+func (cm *ChromiumManager) profilePath(profile Profile) string {
+	if profile.DataDir != "" {
+		return expandPath(profile.DataDir)
+	}
+	return filepath.Join(cm.profileDir, profile.Name)
+}
+
+// validProfileName refuses names that would put the data directory
+// somewhere other than its own folder under the profile directory
+func validProfileName(name string) error {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("profile name '%s' can't contain / or \\ or .., or start with a dot", name)
+	}
+	return nil
+}
+
+// Launch browser with profile, returning a message describing the launch
+func (cm *ChromiumManager) launchBrowser(ctx context.Context, profileName string, urls ...string) (string, error) {
+	profile, exists := cm.profiles[profileName]
+	if !exists {
+		return "", profileNotFound(profileName)
+	}
+	// A running singleton browser opens the session's pages, but keeps its window
+	if cm.override.session != "" {
+		session, err := profile.session(cm.override.session)
+		if err != nil {
+			return "", err
 		}
-		if cm.mainList.Items() != nil {
-			cm.mainList.SetSize(msg.Width, msg.Height-6)
+		profile = session.apply(profile)
+		urls = append(append([]string{}, session.URLs...), urls...)
+	}
+	// An app gets a window of its own even beside a running singleton
+	if cm.override.app != "" {
+		profile.Flags = profile.Flags.with("--app=" + cm.override.app)
+	}
+	// An isolated launch is a second session by design
+	if !cm.override.isolated && cm.override.app == "" {
+		if message, reused, err := cm.reuseRunning(profile, urls); reused {
+			return message, err
 		}
-		if cm.profileList.Items() != nil {
-			cm.profileList.SetSize(msg.Width, msg.Height-6)
+	}
+	if cm.override.fresh {
+		if err := cm.restoreGolden(ctx, profileName); err != nil {
+			return "", err
 		}
+	}
 
-	case tea.KeyMsg:
-		// Global keys
-		switch msg.Type {
-		case tea.KeyCtrlC:
-			return cm, tea.Quit
-		case tea.KeyEsc:
-			if cm.currentView != "main" {
-				cm.currentView = "main"
-				cm.message = ""
-				return cm, nil
-			}
-		}
+	message, err := cm.startBrowser(ctx, profile, urls...)
+	cm.reportLaunch(profile.Name, err)
+	if err != nil {
+		return "", err
+	}
+	cm.recordLaunch(profile.Name)
+	cm.invalidateSize(profile.Name)
+	return message, nil
+}
 
-		// View-specific handling
-		switch cm.currentView {
-		case "main":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.mainList.SelectedItem().(item)
-				if ok {
-					switch i.title {
-					case "Launch Browser":
-						cm.updateProfileList()
-						cm.currentView = "select_profile"
-					case "Manage Profiles":
-						cm.updateManageList()
-						cm.currentView = "manage"
-					case "Clean Profile":
-						cm.updateProfileList()
-						cm.currentView = "select_clean"
-					case "Quit":
-						return cm, tea.Quit
-					}
-				}
-			}
-			cm.mainList, cmd = cm.mainList.Update(msg)
-			return cm, cmd
+// Flags every browser is started with, after the profile's own
+var standardFlags = []string{
+	// Logging and notification suppression
+	"--disable-logging",
+	"--disable-breakpad",
+	"--disable-infobars",
+	"--disable-notifications",
+	"--silent-launch",
 
-		case "select_profile":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.profileList.SelectedItem().(item)
-				if ok {
-					cm.message = cm.launchBrowser(i.title)
-					cm.currentView = "main"
-				}
-			}
-			cm.profileList, cmd = cm.profileList.Update(msg)
-			return cm, cmd
-			
-		case "manage":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.manageList.SelectedItem().(item)
-				if ok {
-					switch i.title {
-					case "Add New Profile":
-						cm.currentView = "add_profile"
-						cm.profileName = ""
-						cm.profileProxy = "none"
-						cm.profileType = "none"
-						cm.profileFlags = "--no-first-run --disable-features=RendererCodeIntegrity"
-					case "Edit Profile":
-						cm.updateProfileList()
-						cm.currentView = "select_edit"
-					case "Delete Profile":
-						cm.updateProfileList()
-						cm.currentView = "select_delete"
-					}
-				}
-			}
-			cm.manageList, cmd = cm.manageList.Update(msg)
-			return cm, cmd
-			
-		case "select_edit":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.profileList.SelectedItem().(item)
-				if ok {
-					profile := cm.profiles[i.title]
-					cm.profileName = profile.Name
-					cm.profileProxy = profile.Proxy
-					cm.profileType = profile.ProxyType
-					cm.profileFlags = profile.Flags
-					cm.selected = i.title
-					cm.currentView = "edit_profile"
-				}
-			}
-			cm.profileList, cmd = cm.profileList.Update(msg)
-			return cm, cmd
-			
-		case "select_delete":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.profileList.SelectedItem().(item)
-				if ok {
-					cm.selected = i.title
-					cm.currentView = "confirm_delete"
-				}
-			}
-			cm.profileList, cmd = cm.profileList.Update(msg)
-			return cm, cmd
-			
-		case "confirm_delete":
-			switch msg.String() {
-			case "y", "Y":
-				delete(cm.profiles, cm.selected)
-				cm.saveProfiles()
-				cm.message = fmt.Sprintf("Profile '%s' deleted", cm.selected)
-				cm.currentView = "main"
-				return cm, nil
-			case "n", "N":
-				cm.currentView = "main"
-				return cm, nil
-			}
-			
-		case "select_clean":
-			if msg.Type == tea.KeyEnter {
-				i, ok := cm.profileList.SelectedItem().(item)
-				if ok {
-					profilePath := filepath.Join(cm.profileDir, i.title)
-					if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-						cm.message = "Profile directory does not exist"
-					} else {
-						// Clean the entire profile directory
-						files, err := ioutil.ReadDir(profilePath)
-						if err != nil {
-							cm.message = fmt.Sprintf("Error reading directory: %s", err)
-						} else {
-							// Remove all files in the directory
-							for _, file := range files {
-								filePath := filepath.Join(profilePath, file.Name())
-								if err := os.RemoveAll(filePath); err != nil {
-									cm.message = fmt.Sprintf("Error cleaning profile: %s", err)
-									cm.currentView = "main"
-									return cm, nil
-								}
-							}
-							cm.message = fmt.Sprintf("Profile '%s' completely cleared and reset", i.title)
-						}
-					}
-					cm.currentView = "main"
-				}
-			}
-			cm.profileList, cmd = cm.profileList.Update(msg)
-			return cm, cmd
-			
-		case "edit_profile", "add_profile":
-			// Handle field editing with number keys
-			switch msg.String() {
-			case "1":
-				cm.currentView = "edit_name"
-				return cm, nil
-			case "2":
-				cm.currentView = "edit_proxy"
-				return cm, nil
-			case "3":
-				cm.currentView = "edit_type"
-				return cm, nil
-			case "4":
-				cm.currentView = "edit_flags"
-				return cm, nil
-			}
-			
-			if msg.Type == tea.KeyEnter {
-				// Save the edited profile
-				oldName := cm.selected
-				
-				// Check if name is provided
-				if cm.profileName == "" {
-					cm.message = "Profile name is required"
-					return cm, nil
-				}
-				
-				// Check if name already exists (if changed)
-				if oldName != cm.profileName {
-					if _, exists := cm.profiles[cm.profileName]; exists {
-						cm.message = fmt.Sprintf("Profile '%s' already exists", cm.profileName)
-						return cm, nil
-					}
-				}
-				
-				// Remove the old profile if name changed
-				if oldName != cm.profileName {
-					delete(cm.profiles, oldName)
-				}
-				
-				// Add/update the profile
-				cm.profiles[cm.profileName] = Profile{
-					Name:      cm.profileName,
-					Proxy:     cm.profileProxy,
-					ProxyType: cm.profileType,
-					Flags:     cm.profileFlags,
-				}
-				
-				// Save profiles
-				cm.saveProfiles()
-				cm.message = fmt.Sprintf("Profile '%s' updated", cm.profileName)
-				cm.currentView = "main"
-				return cm, nil
-			}
-			
-		// Text input views
-		case "edit_name", "edit_proxy", "edit_type", "edit_flags":
-			if msg.Type == tea.KeyEnter {
-				// Return to the edit/add view
-				if strings.HasPrefix(cm.currentView, "edit_") {
-					if cm.selected != "" {
-						cm.currentView = "edit_profile"
-					} else {
-						cm.currentView = "add_profile"
-					}
-				}
-				return cm, nil
-			}
-			
-			// Handle text input
-			switch cm.currentView {
-			case "edit_name":
-				if msg.Type == tea.KeyBackspace && len(cm.profileName) > 0 {
-					cm.profileName = cm.profileName[:len(cm.profileName)-1]
-				} else if msg.Type == tea.KeyRunes {
-					cm.profileName += msg.String()
-				}
-			case "edit_proxy":
-				if msg.Type == tea.KeyBackspace && len(cm.profileProxy) > 0 {
-					cm.profileProxy = cm.profileProxy[:len(cm.profileProxy)-1]
-				} else if msg.Type == tea.KeyRunes {
-					cm.profileProxy += msg.String()
-				}
-			case "edit_type":
-				if msg.Type == tea.KeyBackspace && len(cm.profileType) > 0 {
-					cm.profileType = cm.profileType[:len(cm.profileType)-1]
-				} else if msg.Type == tea.KeyRunes {
-					cm.profileType += msg.String()
-				}
-			case "edit_flags":
-				if msg.Type == tea.KeyBackspace && len(cm.profileFlags) > 0 {
-					cm.profileFlags = cm.profileFlags[:len(cm.profileFlags)-1]
-				} else if msg.Type == tea.KeyRunes {
-					cm.profileFlags += msg.String()
-				}
-			}
+	// GPU artifact suppression
+	"--disable-gpu",
+	"--disable-gpu-compositing",
+	"--disable-gpu-sandbox",
+	"--disable-gpu-driver-bug-workarounds",
+	"--disable-features=UseChromeOSDirectVideoDecoder",
+	"--disable-accelerated-2d-canvas",
+	"--disable-accelerated-video-decode",
+	"--disable-accelerated-video-encode",
+	"--disable-webgl",
+	"--disable-threaded-animation",
+	"--disable-webgl-image-chromium",
+	"--force-dark-mode",
+	// Ignore Certificat errors
+	"--ignore-certificate-errors",
+}
+
+// startBrowserWith launches a profile with the given browser binary
+func (cm *ChromiumManager) startBrowserWith(ctx context.Context, profile Profile, browserPath string, urls []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("not launched: %w", err)
+	}
+	if _, err := procs.LookPath(browserPath); err != nil && !isAppBundle(browserPath) {
+		return "", errorOf(ErrBrowserNotFound, "no browser at %s", browserPath)
+	}
+	// Read the profile's CAs before setting anything up
+	caFlags, err := profile.caFlags()
+	if err != nil {
+		return "", err
+	}
+
+	// Create profile directory
+	profilePath := cm.profilePath(profile)
+	if err := fsys.MkdirAll(profilePath, 0755); err != nil {
+		return "", fmt.Errorf("creating profile directory: %w", err)
+	}
+	// A run_as profile's dir belongs to its user since the last launch
+	if _, running := runningPID(profilePath); !running {
+		if err := reclaimDataDir(profilePath); err != nil {
+			return "", err
 		}
 	}
 
-	return cm, nil
-}
+	// RAM disk profiles run from a tmpfs copy of the data dir, and isolated
+	// launches from a fork of it; both are scratch copies
+	scratch := false
+	switch {
+	case cm.override.isolated:
+		isolatedPath, err := isolateDataDir(ctx, profilePath)
+		if err != nil {
+			return "", fmt.Errorf("isolating data directory: %w", err)
+		}
+		profilePath, scratch = isolatedPath, true
+	case profile.RAMDisk != ramDiskOff:
+		ramPath, err := cm.prepareRAMDisk(profile)
+		if err != nil {
+			return "", fmt.Errorf("preparing RAM disk: %w", err)
+		}
+		profilePath, scratch = ramPath, true
+	}
+	
+	if err := cm.seedDataDir(profile, browserPath, profilePath); err != nil {
+		if scratch {
+			fsys.RemoveAll(profilePath)
+		}
+		return "", err
+	}
 
-// View renders the current UI
-func (cm *ChromiumManager) View() string {
-	// Handle errors
-	if cm.err != nil {
-		return errStyle.Render(fmt.Sprintf("Error: %s", cm.err))
+	// Build command line with all arguments
+	cmdArgs := []string{}
+	
+	// Add profile directory
+	cmdArgs = append(cmdArgs, "--user-data-dir="+profilePath)
+	
+	// Force new window
+	cmdArgs = append(cmdArgs, "--new-window")
+	cmdArgs = append(cmdArgs, "--window-name="+profile.windowName())
+	if len(urls) == 0 && !profile.Flags.has("--app") {
+		urls = []string{"about:blank"} // Open a blank page to ensure window opens
+	}
+	cmdArgs = append(cmdArgs, urls...)
+	
+	// Add proxy if specified
+	if profile.Proxy != "none" {
+		proxyFlag := "--proxy-server="
+		if (profile.ProxyType == "http" || profile.ProxyType == "socks5") && !strings.Contains(profile.Proxy, "://") {
+			proxyFlag += profile.ProxyType + "://"
+		}
+		proxyFlag += profile.Proxy
+		cmdArgs = append(cmdArgs, proxyFlag)
+	}
+	
+	// Add the profile's language, CAs, WebRTC policy, password store and
+	// privacy switches, the flags of its presets, then its own
+	passwordStore, storeWarning := seedPasswordStore(profile, profilePath)
+	cmdArgs = append(cmdArgs, profile.localeFlags()...)
+	cmdArgs = append(cmdArgs, caFlags...)
+	cmdArgs = append(cmdArgs, profile.webRTCFlags()...)
+	cmdArgs = append(cmdArgs, passwordStoreFlags(passwordStore)...)
+	cmdArgs = append(cmdArgs, profile.privacyFlags()...)
+	cmdArgs = append(cmdArgs, profile.launchFlags()...)
+	
+	// Add standard suppression flags and those of the first run setup
+	cmdArgs = append(cmdArgs, profile.standardFlags()...)
+	cmdArgs = append(cmdArgs, cm.firstRunFor(profile, browserPath).flags()...)
+	cmdArgs = composeFlags(cm.override.args(cmdArgs))
+	warnings := flagContradictions(cmdArgs)
+	if storeWarning != "" {
+		warnings = append([]string{storeWarning}, warnings...)
+	}
+	
+	// Run the browser under the profile's resource limits
+	browserPath, cmdArgs, err = limitCommand(profile, browserPath, cmdArgs)
+	if err != nil {
+		if scratch {
+			fsys.RemoveAll(profilePath)
+		}
+		return "", err
 	}
 
-	var s string
+	// Run it as another user if the profile asks for one
+	browserPath, cmdArgs, waitable, err := runAsCommand(profile, profilePath, browserPath, cmdArgs)
+	if err != nil {
+		if scratch {
+			fsys.RemoveAll(profilePath)
+		}
+		return "", err
+	}
 
-	// Render the appropriate view
-	switch cm.currentView {
-	case "main":
-		s = cm.mainList.View()
-		
-	case "select_profile", "select_edit", "select_delete", "select_clean":
-		s = cm.profileList.View()
-		
-	case "manage":
-		s = cm.manageList.View()
-		
-	case "confirm_delete":
-		s = fmt.Sprintf("Delete Profile\n\nAre you sure you want to delete profile '%s'? (y/n)", cm.selected)
-		
-	case "add_profile", "edit_profile":
-		s = "Profile Editor\n\n"
-		s += fmt.Sprintf("1. Name: %s\n", cm.profileName)
-		s += fmt.Sprintf("2. Proxy: %s\n", cm.profileProxy)
-		s += fmt.Sprintf("3. Proxy Type: %s\n", cm.profileType)
-		s += fmt.Sprintf("4. Flags: %s\n\n", cm.profileFlags)
-		s += "Press 1-4 to edit a field, Enter to save, Esc to cancel"
-		
-	case "edit_name":
-		s = "Edit Profile Name\n\n"
-		s += fmt.Sprintf("Name: %s\n\n", cm.profileName)
-		s += "Press Enter when done, Esc to cancel"
-		
-	case "edit_proxy":
-		s = "Edit Proxy Address\n\n"
-		s += fmt.Sprintf("Proxy: %s\n\n", cm.profileProxy)
-		s += "Enter 'none' for no proxy, or server address (e.g. 127.0.0.1:8080)"
-		s += "\nPress Enter when done, Esc to cancel"
+	// Platform-specific browser launching
+	var cmd *exec.Cmd
+	direct := waitable // cmd runs as long as the browser rather than being a launcher
+
+	// Detach the browser from launchium's terminal so it outlives it, unless
+	// asked not to. Windows' runas asks for the password on the console.
+	// Its errors go to the launch log.
+	stderr := openLaunchLog(profilePath)
+	start := func(cmd *exec.Cmd) error {
+		if !cm.foreground && !(runtime.GOOS == "windows" && profile.RunAs != "") {
+			detach(cmd)
+		}
+		if stderr != nil {
+			cmd.Stderr = stderr
+		}
+		if env := profile.localeEnv(); len(env) > 0 {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		return procs.Start(cmd)
+	}
+	
+	switch runtime.GOOS {
+	case "darwin": // macOS
+		bundle := appBundle(browserPath)
+		if isAppBundle(browserPath) {
+			// A bundle can't be run directly; open starts a separate
+			// instance of the app for each profile
+			direct = false
+			cmd = openCommand(bundle, cmdArgs)
+			err = start(cmd)
+			break
+		}
+
+		// Run the binary itself, so the browser can be waited on
+		cmd = exec.Command(browserPath, cmdArgs...)
+		err = start(cmd)
 		
-	case "edit_type":
-		s = "Edit Proxy Type\n\n"
-		s += fmt.Sprintf("Proxy Type: %s\n\n", cm.profileType)
-		s += "Enter 'none', 'http', or 'socks5'"
-		s += "\nPress Enter when done, Esc to cancel"
+		// If that fails, have open start a new instance of its app
+		if err != nil && bundle != "" {
+			direct = false
+			cmd = openCommand(bundle, cmdArgs)
+			err = start(cmd)
+		}
 		
-	case "edit_flags":
-		s = "Edit Browser Flags\n\n"
-		s += fmt.Sprintf("Flags: %s\n\n", cm.profileFlags)
-		s += "Enter the browser command-line flags"
-		s += "\nPress Enter when done, Esc to cancel"
+	case "linux": // Linux
+		// Try normal execution first
+		cmd = exec.Command(browserPath, cmdArgs...)
+		err = start(cmd)
 		
+		// If that fails, try with xdg-open via a temporary desktop file
+		if err != nil {
+			direct = false
+
+			// Create a desktop file
+			desktopPath := filepath.Join(os.TempDir(), "launchium_chrome.desktop")
+			desktopContent := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=Launchium Chrome\nExec=%s %s\nTerminal=false", 
+										browserPath, strings.Join(cmdArgs, " "))
+			
+			if err := ioutil.WriteFile(desktopPath, []byte(desktopContent), 0755); err == nil {
+				cmd = exec.Command("xdg-open", desktopPath)
+				err = start(cmd)
+			}
+		}
+
 	default:
-		s = "Unknown view: " + cm.currentView
+        // Fallback for unsupported platforms
+        cmd = exec.Command(browserPath, cmdArgs...)
+        err = start(cmd)
+    }
+	
+	if stderr != nil {
+		stderr.Close() // The browser has its own copy
 	}
 
-	// Add any messages
-	if cm.message != "" {
-		if strings.HasPrefix(cm.message, "Error") {
-			s += "\n\n" + errStyle.Render(cm.message)
-		} else {
-			s += "\n\n" + okStyle.Render(cm.message)
+	// Windows limits apply to the started process
+	if err == nil {
+		if err = applyJobLimits(profile, cmd.Process.Pid); err != nil {
+			cmd.Process.Kill()
 		}
 	}
+	
+	if err != nil {
+		if scratch {
+			fsys.RemoveAll(profilePath)
+		}
+		return "", errorOf(ErrLaunchFailed, "launching browser: %w", err)
+	}
+
+	// Make sure the browser came up instead of exiting with an error
+	exit := watchExit(cmd)
+	if err := waitForStart(ctx, exit, profilePath, cmdArgs, cm.settings.launchCheck()); err != nil {
+		if scratch {
+			fsys.RemoveAll(profilePath)
+		}
+		return "", err
+	}
+	if direct {
+		noteLaunchedPID(profile.Name, cmd.Process.Pid)
+		watchCrashExit(profile.Name, exit)
+	}
+	// Keep track of it for later runs of launchium, which may not look in
+	// this data dir or learn the PID from its lock
+	record := instanceRecord{Profile: profile.Name, DataDir: profilePath, Started: time.Now()}
+	if pid, _ := runningPID(profilePath); pid > 0 {
+		record.PID = pid
+	} else if direct {
+		record.PID = cmd.Process.Pid
+	}
+	registerInstance(record)
+
+	launched := "Launched with profile: " + profile.Name
+	if cm.override.isolated {
+		// Like a RAM disk, the copy is only removed if the browser can be
+		// waited on
+		if !direct {
+			launched += fmt.Sprintf(" (isolated copy at %s will not be cleaned up automatically)", profilePath)
+		} else {
+			watchIsolatedSession(exit, profilePath)
+			launched += " (isolated copy, discarded on exit)"
+		}
+	} else if profile.RAMDisk != ramDiskOff {
+		// Only a directly started browser can be waited on; a launcher
+		// exits immediately and the browser would lose its data dir
+		if !direct {
+			launched += fmt.Sprintf(" (RAM disk at %s will not be cleaned up automatically)", profilePath)
+		} else {
+			cm.watchRAMSession(exit, profile, profilePath)
+			launched += fmt.Sprintf(" (RAM disk, %s on exit)", profile.RAMDisk)
+		}
+	}
+
+	// The browser starts either way; say which flags work against each other
+	if len(warnings) > 0 {
+		launched += "; " + lintSummary(warnings)
+	}
+	return launched, nil
+}
+
+// seedDataDir gives a data dir the profile's settings before the browser
+// starts in it. A new one is set up for its first run.
+func (cm *ChromiumManager) seedDataDir(profile Profile, browserPath, profilePath string) error {
+	// A data dir without Local State is new: set it up for its first run,
+	// then give it the profile's search engine and homepage
+	firstRun := cm.firstRunFor(profile, browserPath)
+	prefsFile := filepath.Join(profilePath, "Local State")
+	if _, err := fsys.Stat(prefsFile); os.IsNotExist(err) {
+		if err := firstRun.seed(profilePath); err != nil {
+			return fmt.Errorf("seeding first run: %w", err)
+		}
+		if err := seedStartPreferences(profilePath, profile); err != nil {
+			return fmt.Errorf("seeding preferences: %w", err)
+		}
+	}
+
+	// Keep the browser's languages, permissions, client certificate
+	// choices, WebRTC policy and privacy settings those of the profile
+	if _, running := runningPID(profilePath); !running {
+		if err := seedLocale(profilePath, profile); err != nil {
+			return fmt.Errorf("seeding preferences: %w", err)
+		}
+		if err := seedPermissions(profilePath, profile); err != nil {
+			return fmt.Errorf("seeding preferences: %w", err)
+		}
+		for _, values := range []map[string]interface{}{autoSelectPreferences(profile), webRTCPreferences(profile), privacyPreferences(profile)} {
+			if len(values) == 0 {
+				continue
+			}
+			if err := mergePreferences(preferencesFile(profilePath), values); err != nil {
+				return fmt.Errorf("seeding preferences: %w", err)
+			}
+		}
+	}
+
+	// Theme the browser with the profile's label. Chromium rewrites these
+	// files while running, so only touch them when it is closed.
+	if profile.Color != "" || profile.Icon != "" {
+		if _, running := runningPID(profilePath); !running {
+			if values := labelPreferences(profile); len(values) > 0 {
+				if err := mergePreferences(preferencesFile(profilePath), values); err != nil {
+					return fmt.Errorf("seeding preferences: %w", err)
+				}
+			}
+			mergePreferences(prefsFile, map[string]interface{}{
+				"profile.info_cache.Default.name":                   profile.windowName(),
+				"profile.info_cache.Default.is_using_default_name": false,
+			})
+		}
+	}
+	return nil
+}
+
+// Remove everything inside a profile's data directory
+func (cm *ChromiumManager) cleanProfile(ctx context.Context, profileName string, report func(cleanProgress)) error {
+	profile, exists := cm.profiles[profileName]
+	if !exists {
+		return profileNotFound(profileName)
+	}
+	if cm.isArchived(profileName) {
+		return errArchived(profileName)
+	}
+
+	defer cm.invalidateSize(profileName)
+	started := time.Now()
+	err := cleanDataDirWith(ctx, cm.profilePath(profile), profile.CleanKeep, report)
+	cm.reportClean(profileName, "", time.Since(started), err)
+	return err
+}
+
+// cleanDataDir removes everything inside a profile's data directory but
+// the paths to keep
+func cleanDataDir(ctx context.Context, profilePath string, keep []string) error {
+	return cleanDataDirWith(ctx, profilePath, keep, nil)
+}
+
+// Remove a profile from the config, optionally deleting its data directory.
+// The caller saves the config.
+func (cm *ChromiumManager) removeProfile(profileName string, purge bool) error {
+	profile, exists := cm.profiles[profileName]
+	if !exists {
+		return profileNotFound(profileName)
+	}
+	if cm.configSource != "" {
+		return cm.errRemoteConfig()
+	}
+	if _, ok := cm.projectProfiles[profileName]; ok {
+		return fmt.Errorf("it comes from %s; remove it there", cm.projectFile)
+	}
+
+	if purge {
+		profilePath := cm.profilePath(profile)
+		if _, running := runningPID(profilePath); running {
+			return fmt.Errorf("profile is running, close the browser first")
+		}
+		if err := os.RemoveAll(profilePath); err != nil {
+			return fmt.Errorf("deleting data directory: %w", err)
+		}
+		if cm.isArchived(profileName) {
+			if err := os.Remove(cm.archivePath(profile)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("deleting archive: %w", err)
+			}
+			delete(cm.state.Archived, profileName)
+			cm.saveState()
+		}
+		if err := os.RemoveAll(cm.goldenPath(profile)); err != nil {
+			return fmt.Errorf("deleting golden image: %w", err)
+		}
+	}
+
+	delete(cm.profiles, profileName)
+	if cm.settings.DefaultProfile == profileName {
+		cm.settings.DefaultProfile = ""
+	}
+	return nil
+}
+
+// Item for lists
+type item struct {
+	title, desc string
+	label       string // Rendered color/icon prefix for profile items
+	marked      bool   // Selected for a bulk operation
+	archived    bool   // Profile's data is archived; drawn greyed out
+	slot        int    // Quick-launch slot, shown in front of the name
+	profile     string // Profile a main menu entry launches
+}
+
+func (i item) Title() string {
+	title := i.title
+	if i.archived {
+		title = archivedStyle.Render(title + " (archived)")
+	}
+	if i.label != "" {
+		title = i.label + " " + title
+	}
+	if i.slot != 0 {
+		title = helpStyle.Render(fmt.Sprint(i.slot)) + " " + title
+	}
+	if i.marked {
+		return "[x] " + title
+	}
+	return title
+}
+func (i item) Description() string { return i.desc }
+func (i item) FilterValue() string { return i.title + " " + i.desc }
+
+// Init implements tea.Model
+func (cm *ChromiumManager) Init() tea.Cmd {
+	// Lists are sized in resize once the terminal reports its size. Crashes
+	// of browsers launched since the last run are looked for meanwhile.
+	return tea.Batch(cm.scanCrashes(), cm.startConfigWatch())
+}
+
+// Update implements tea.Model
+func (cm *ChromiumManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Messages posted while handling msg expire on their own
+	seq := cm.toastSeq
+	model, cmd := cm.update(msg)
+	if cm.toastSeq != seq {
+		cmd = tea.Batch(cmd, cm.expireToast())
+	}
+	// Launches and profile changes made elsewhere show up in the recent
+	// profiles once the main menu is back
+	if cm.currentView == "main" {
+		cm.refreshRecent()
+	}
+	return model, cmd
+}
+
+// update handles a message for Update
+func (cm *ChromiumManager) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		cm.width, cm.height = msg.Width, msg.Height
+		cm.resize()
+
+	case sizeMsg:
+		cm.sizing[msg.name] = false
+		if msg.err == nil {
+			cm.rememberSize(msg.name, msg.size, msg.scanned)
+		}
+		if cm.currentView == "dashboard" {
+			cm.setDashboardItems()
+		}
+
+	case bulkResultMsg:
+		return cm, cm.stepBulk(msg)
+
+	case launchDoneMsg:
+		return cm, cm.finishLaunch(msg)
+
+	case crashesMsg:
+		cm.finishCrashScan(msg)
+
+	case healthMsg:
+		cm.finishHealthCheck(msg)
+
+	case configChangedMsg:
+		return cm, cm.reloadConfig()
+
+	case cleanDoneMsg:
+		cm.finishClean(msg)
+
+	case list.FilterMatchesMsg:
+		return cm, cm.forwardToProfileList(msg)
+
+	case cleanProgressMsg:
+		return cm, cm.updateCleanProgress(msg)
+
+	case spinner.TickMsg:
+		return cm, cm.updateSpinner(msg)
+
+	case expireMsg:
+		if cm.toast != nil && cm.toast.seq == msg.seq {
+			cm.clearMessage()
+		}
+
+	case runningMsg:
+		if msg.gen != cm.runningGen || (cm.currentView != "running" && cm.currentView != "open_url") {
+			return cm, nil
+		}
+		cm.setInstances(msg.instances)
+		gen := msg.gen
+		return cm, tea.Tick(runningRefresh, func(time.Time) tea.Msg { return runningTickMsg{gen: gen} })
+
+	case runningTickMsg:
+		if msg.gen == cm.runningGen && (cm.currentView == "running" || cm.currentView == "open_url") {
+			return cm, cm.scanRunning()
+		}
+
+	default:
+		// Cursor blinks and other internal messages for the text inputs
+		if cm.currentView == "open_url" {
+			cm.urlInput, cmd = cm.urlInput.Update(msg)
+			return cm, cmd
+		}
+		if cm.currentView == "setup" {
+			return cm, cm.setup.updateInputs(msg)
+		}
+		if cm.form != nil {
+			field := cm.form.fields[cm.form.focus]
+			switch field.kind {
+			case fieldText:
+				field.input, cmd = field.input.Update(msg)
+			case fieldArea:
+				field.area, cmd = field.area.Update(msg)
+			}
+			return cm, cmd
+		}
+
+	case tea.KeyMsg:
+		// Global keys
+		if key.Matches(msg, cm.keys.ForceQuit) {
+			return cm, tea.Quit
+		}
+
+		// Keys typed into a profile list's filter are the list's
+		if cm.typingFilter() {
+			return cm, cm.forwardToProfileList(msg)
+		}
+
+		// The help overlay swallows keys until it is closed
+		if cm.showHelp {
+			if key.Matches(msg, cm.keys.Help, cm.keys.FormHelp, cm.keys.Back) {
+				cm.showHelp = false
+			}
+			return cm, nil
+		}
+		if key.Matches(msg, cm.helpKey()) && cm.currentView != "bulk_progress" {
+			cm.showHelp = true
+			return cm, nil
+		}
+
+		if key.Matches(msg, cm.keys.Back) {
+			// A filtered profile list is cleared before it is left
+			if strings.HasPrefix(cm.currentView, "select_") && cm.profileList.IsFiltered() {
+				cm.profileList.ResetFilter()
+				return cm, nil
+			}
+			// Some views handle Esc themselves, e.g. so typed input isn't lost
+			if cm.currentView != "main" && !handlesBack(cm.currentView) {
+				cm.currentView = "main"
+				cm.clearMessage()
+				return cm, nil
+			}
+		}
+		if key.Matches(msg, cm.keys.Quit) && isListView(cm.currentView) {
+			return cm, tea.Quit
+		}
+		if key.Matches(msg, cm.keys.History) && isListView(cm.currentView) {
+			cm.openHistory()
+			return cm, nil
+		}
+
+		// Scroll long flag lists in the detail pane
+		if strings.HasPrefix(cm.currentView, "select_") {
+			switch {
+			case key.Matches(msg, cm.keys.ScrollDown):
+				cm.scrollFlags(1)
+				return cm, nil
+			case key.Matches(msg, cm.keys.ScrollUp):
+				cm.scrollFlags(-1)
+				return cm, nil
+			}
+		}
+
+		// Space marks profiles for bulk operations
+		if key.Matches(msg, cm.keys.Mark) && bulkSelectable(cm.currentView) {
+			return cm, cm.toggleMark()
+		}
+
+		// View-specific handling
+		switch cm.currentView {
+		case "main":
+			if key.Matches(msg, slotKeys) {
+				cm.launchMode = launchNormal
+				return cm, cm.launchSlot(msg)
+			}
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.mainList.SelectedItem().(item)
+				if ok && i.profile != "" {
+					cm.launchMode = launchNormal
+					return cm, cm.launchAsync(i.profile)
+				}
+				if ok {
+					switch i.title {
+					case "Launch Browser":
+						cm.tagFilter = ""
+						cm.launchMode = launchNormal
+						cm.updateProfileList()
+						cm.currentView = "select_profile"
+					case "Manage Profiles":
+						cm.updateManageList()
+						cm.currentView = "manage"
+					case "Clean Profile":
+						cm.updateProfileList()
+						cm.currentView = "select_clean"
+					case "Running Browsers":
+						return cm, cm.openRunningView()
+					case "Health Dashboard":
+						return cm, cm.openDashboard()
+					case "Quit":
+						return cm, tea.Quit
+					}
+				}
+			}
+			cm.mainList, cmd = cm.mainList.Update(msg)
+			return cm, tea.Batch(cmd, cm.requestSelectedSize())
+
+		case "select_profile":
+			// t cycles the tag filter
+			if key.Matches(msg, cm.keys.TagFilter) {
+				cm.tagFilter = nextTagFilter(cm.tagFilter, allTags(cm.profiles))
+				cm.updateProfileList()
+				return cm, nil
+			}
+			// i cycles between normal, incognito and guest launches
+			if key.Matches(msg, cm.keys.LaunchMode) {
+				for i, mode := range launchModes {
+					if mode == cm.launchMode {
+						cm.launchMode = launchModes[(i+1)%len(launchModes)]
+						break
+					}
+				}
+				cm.profileList.Title = cm.profileListTitle()
+				return cm, nil
+			}
+			// Number keys launch their slot in the chosen launch mode
+			if key.Matches(msg, slotKeys) {
+				return cm, cm.launchSlot(msg)
+			}
+			// s picks one of the profile's sessions to launch
+			if key.Matches(msg, cm.keys.Sessions) {
+				if i, ok := cm.profileList.SelectedItem().(item); ok {
+					cm.openSessionPicker(i.title)
+				}
+				return cm, nil
+			}
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.profileList.SelectedItem().(item)
+				if ok {
+					if names := cm.markedProfiles(); len(names) > 0 {
+						return cm, cm.startBulk("launch", names)
+					}
+					cm.currentView = "main"
+					return cm, cm.launchAsync(i.title)
+				}
+			}
+			return cm, cm.forwardToProfileList(msg)
+			
+		case "manage":
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.manageList.SelectedItem().(item)
+				if ok {
+					switch i.title {
+					case "Add New Profile":
+						return cm, cm.openProfileForm("")
+					case "Edit Profile":
+						cm.updateProfileList()
+						cm.currentView = "select_edit"
+					case "Delete Profile":
+						cm.updateProfileList()
+						cm.currentView = "select_delete"
+					case "Set Default Profile":
+						cm.updateProfileList()
+						cm.currentView = "select_default"
+					}
+				}
+			}
+			cm.manageList, cmd = cm.manageList.Update(msg)
+			return cm, tea.Batch(cmd, cm.requestSelectedSize())
+			
+		case "select_edit":
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.profileList.SelectedItem().(item)
+				if ok {
+					return cm, cm.openProfileForm(i.title)
+				}
+			}
+			return cm, cm.forwardToProfileList(msg)
+			
+		case "select_default":
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.profileList.SelectedItem().(item)
+				if ok {
+					cm.settings.DefaultProfile = i.title
+					if err := cm.saveProfiles(); err != nil {
+						cm.notify(levelError, "saving config: %s", err)
+						cm.loadProfiles()
+					} else {
+						cm.notify(levelInfo, "Default profile set to '%s'", i.title)
+					}
+					cm.currentView = "main"
+				}
+			}
+			return cm, cm.forwardToProfileList(msg)
+			
+		case "select_delete":
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.profileList.SelectedItem().(item)
+				if ok {
+					cm.selected = i.title
+					cm.bulkNames = cm.markedProfiles()
+					cm.protectedAsked = false
+					cm.confirmInput = newConfirmInput()
+					cm.currentView = "confirm_delete"
+
+					// Large profiles need their name typed, so their sizes are needed
+					cmds := []tea.Cmd{cm.requestSize(i.title)}
+					for _, name := range cm.bulkNames {
+						cmds = append(cmds, cm.requestSize(name))
+					}
+					return cm, tea.Batch(cmds...)
+				}
+			}
+			return cm, cm.forwardToProfileList(msg)
+			
+		case "confirm_delete":
+			yes, no, cmd := cm.answerConfirm(msg)
+			switch {
+			case yes:
+				if len(cm.bulkNames) > 0 {
+					return cm, cm.startBulk("delete", cm.bulkNames)
+				}
+				delete(cm.profiles, cm.selected)
+				if cm.settings.DefaultProfile == cm.selected {
+					cm.settings.DefaultProfile = ""
+				}
+				if err := cm.saveProfiles(); err != nil {
+					cm.notify(levelError, "saving config: %s", err)
+					cm.loadProfiles()
+				} else {
+					cm.notify(levelInfo, "Profile '%s' deleted", cm.selected)
+				}
+				cm.currentView = "main"
+				return cm, nil
+			case no:
+				cm.currentView = "main"
+				return cm, nil
+			}
+			return cm, cmd
+			
+		case "select_clean":
+			if key.Matches(msg, cm.keys.Select) {
+				i, ok := cm.profileList.SelectedItem().(item)
+				if ok {
+					cm.selected = i.title
+					cm.bulkNames = cm.markedProfiles()
+					cm.protectedAsked = false
+					cm.confirmInput = newConfirmInput()
+					cm.currentView = "confirm_clean"
+
+					// Make sure the sizes shown in the confirmation are known
+					cmds := []tea.Cmd{cm.requestSize(i.title)}
+					for _, name := range cm.bulkNames {
+						cmds = append(cmds, cm.requestSize(name))
+					}
+					return cm, tea.Batch(cmds...)
+				}
+			}
+			return cm, cm.forwardToProfileList(msg)
+			
+		case "confirm_clean":
+			yes, no, cmd := cm.answerConfirm(msg)
+			switch {
+			case yes:
+				if len(cm.bulkNames) > 0 {
+					return cm, cm.startBulk("clean", cm.bulkNames)
+				}
+				cm.currentView = "main"
+				return cm, cm.cleanAsync(cm.selected)
+			case no:
+				cm.bulkNames = nil
+				cm.currentView = "main"
+				return cm, nil
+			}
+			return cm, cmd
+			
+		case "profile_form":
+			return cm, cm.updateProfileForm(msg)
+
+		case "sessions":
+			return cm, cm.updateSessionPicker(msg)
+
+		case "running", "open_url":
+			return cm, cm.updateRunning(msg)
+
+		case "dashboard":
+			return cm, cm.updateDashboard(msg)
+
+		case "messages":
+			return cm, cm.updateHistory(msg)
+
+		case "setup":
+			return cm, cm.updateSetup(msg)
+		}
+	}
+
+	return cm, nil
+}
+
+// View renders the current UI
+func (cm *ChromiumManager) View() string {
+	// Handle errors
+	if cm.err != nil {
+		return errStyle.Render(fmt.Sprintf("Error: %s", cm.err))
+	}
+
+	if cm.tooSmall() {
+		return cm.tooSmallView()
+	}
+
+	// The key overlay replaces the view until it is closed
+	if cm.showHelp {
+		return docStyle.Render(cm.helpView())
+	}
+
+	var s string
+	width, height := cm.contentSize()
+	wrap := lipgloss.NewStyle().Width(width)
+
+	// Render the appropriate view
+	switch cm.currentView {
+	case "main":
+		s = cm.mainList.View()
+		
+	case "select_profile", "select_edit", "select_delete", "select_clean", "select_default":
+		s = cm.profileListView()
+		if bulkSelectable(cm.currentView) {
+			s += "\n" + helpStyle.Render(fmt.Sprintf("%s to mark several profiles, %s to apply to all marked",
+				cm.keys.Mark.Help().Key, cm.keys.Select.Help().Key))
+		}
+		
+	case "manage":
+		s = cm.manageList.View()
+		
+	case "confirm_delete":
+		if len(cm.bulkNames) > 0 {
+			s = fmt.Sprintf("Delete Profiles\n\nAre you sure you want to delete %d profiles?\n\n  %s\n\n%s",
+				len(cm.bulkNames), strings.Join(cm.bulkNames, "\n  "), cm.confirmPrompt())
+		} else {
+			s = fmt.Sprintf("Delete Profile\n\nAre you sure you want to delete profile '%s'? %s", cm.selected, cm.confirmPrompt())
+		}
+		s = wrap.Render(s)
+
+	case "confirm_clean":
+		s = wrap.Render(cm.cleanConfirmView())
+
+	case "bulk_progress":
+		s = wrap.Render(cm.bulkView())
+		
+	case "profile_form":
+		s = cm.form.view(height)
+
+	case "sessions":
+		s = cm.sessionList.View()
+
+	case "running", "open_url":
+		s = cm.runningView()
+
+	case "dashboard":
+		s = cm.dashboardList.View()
+
+	case "messages":
+		s = cm.historyView()
+
+	case "setup":
+		s = wrap.Render(cm.setupView())
+		
+	default:
+		s = "Unknown view: " + cm.currentView
+	}
+
+	// Cut off anything taller than the terminal so the footer stays visible
+	s = lipgloss.NewStyle().MaxHeight(height).Render(s)
+
+	// Add any messages
+	if len(cm.tasks) > 0 {
+		s += "\n\n" + cm.tasksView()
+	} else if cm.toast != nil {
+		s += "\n\n" + cm.toast.render(width)
+	}
 
 	// Add help at the bottom
-	s += "\n\n" + helpStyle.Render(fmt.Sprintf("View: %s | Press Esc to go back, Ctrl+C to quit", cm.currentView))
+	s += "\n\n" + cm.shortHelp()
 
 	return docStyle.Render(s)
 }
 
+// browserFor returns the browser binary for the profile: its own if it
+// names one, then the installed one of its pinned channel, otherwise the
+// detected one
+func (cm *ChromiumManager) browserFor(profile Profile) (string, error) {
+	if profile.Browser != "" {
+		return expandPath(profile.Browser), nil
+	}
+	if profile.Channel != channelAny {
+		return channelBrowser(profile.Channel)
+	}
+	return cm.chromePath, nil
+}
+
+// Resolve a -profile name or pattern, exiting if nothing matches
+func matchProfilesOrExit(cm *ChromiumManager, pattern string) []string {
+    names, err := cm.matchProfiles(pattern)
+    if err != nil {
+        fmt.Printf("Error: %s\n", err)
+        os.Exit(2)
+    }
+    if len(names) == 0 {
+        fmt.Printf("Error: No profiles match '%s'\n", pattern)
+        os.Exit(1)
+    }
+    return names
+}
+
+// interruptContext returns a context canceled by Ctrl+C, for commands
+// that stop cleanly when interrupted. A second Ctrl+C exits right away.
+func interruptContext() context.Context {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    go func() {
+        <-ctx.Done()
+        stop()
+    }()
+    return ctx
+}
+
 func main() {
-    // Define application version
-    const VERSION = "0.1.0"
-    
     // Check for command-line arguments
-    cmd, profileName, hasCmdArgs := parseCommandLine()
+    opts, hasCmdArgs := parseCommandLine()
+    cmd, profileName := opts.command, opts.profile
     
     if hasCmdArgs {
         // Initialize model to load configurations
-        cm := initialModel()
+        cm := initialModel(opts.configPath)
+        cm.forceLaunch = opts.force
+        cm.unprotect = opts.force
+        cm.foreground = opts.foreground
+        cm.override = opts.override
+        for _, t := range cm.messages {
+            if cmd == "refresh" {
+                break // It reports the fetch itself
+            }
+            fmt.Fprintf(os.Stderr, "%s: %s\n", t.level.label(), t.text)
+        }
+        if cm.err != nil && cmd != "version" && cmd != "presets" {
+            fmt.Printf("Error: %s\n", cm.err)
+            os.Exit(1)
+        }
         
         // Handle commands
         switch cmd {
-        case "launch":
-            fmt.Println("Launching browser with profile:", profileName)
-            message := cm.launchBrowser(profileName)
+        case "launch", "go", "slot":
+            ctx := interruptContext()
+            // Quiet and machine output leave stdout to the PID or JSON,
+            // and errors go to stderr
+            out, errOut := io.Writer(os.Stdout), io.Writer(os.Stdout)
+            if opts.quiet || opts.printPID || opts.jsonOut {
+                out, errOut = io.Discard, os.Stderr
+            }
+            if len(opts.profiles) > 0 {
+                fmt.Fprintf(out, "Launching %d profiles: %s\n", len(opts.profiles), strings.Join(opts.profiles, ", "))
+                failed := 0
+                for _, result := range cm.launchProfiles(ctx, opts.profiles) {
+                    if result.err != nil {
+                        failed++
+                        fmt.Fprintf(errOut, "  FAILED  %s: %s\n", result.name, result.err)
+                    } else if result.reused {
+                        fmt.Fprintf(out, "  running %s: %s\n", result.name, result.message)
+                    } else {
+                        fmt.Fprintf(out, "  ok      %s\n", result.name)
+                    }
+                }
+                fmt.Fprintf(out, "Launched %d of %d profiles\n", len(opts.profiles)-failed, len(opts.profiles))
+                if failed > 0 {
+                    waitForWebhooks()
+                    os.Exit(1)
+                }
+                waitForRAMSessions()
+                waitForIsolatedSessions()
+                waitForIdleWatches()
+                break
+            }
+            if cmd == "slot" {
+                name, err := cm.slotProfile(opts.slot)
+                if err != nil {
+                    fmt.Fprintf(errOut, "Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                profileName = name
+            } else if cmd == "go" {
+                profileName = cm.quickLaunchProfile()
+            } else if profileName == "" {
+                profileName = cm.defaultProfile()
+            }
+            if opts.keepAlive {
+                fmt.Fprintf(out, "Keeping profile '%s' running; press Ctrl+C to stop watching\n", profileName)
+                if err := cm.keepAlive(ctx, profileName, opts.maxRestart, out); err != nil {
+                    fmt.Fprintf(errOut, "Error: %s\n", err)
+                    waitForWebhooks()
+                    os.Exit(exitCode(err))
+                }
+                break
+            }
+            for _, crash := range cm.detectCrashes(profileName) {
+                fmt.Fprintf(errOut, "Warning: %s\n", crashSummary(profileName, crash))
+            }
+            fmt.Fprintln(out, "Launching browser with profile:", profileName)
+            message, err := cm.launchBrowser(ctx, profileName)
+            if err != nil {
+                fmt.Fprintf(errOut, "Error: %s\n", err)
+                waitForWebhooks()
+                os.Exit(exitCode(err))
+            }
+            switch {
+            case opts.jsonOut:
+                data, _ := json.Marshal(cm.launchInfo(profileName))
+                fmt.Println(string(data))
+            case opts.printPID:
+                fmt.Println(cm.launchInfo(profileName).PID)
+            default:
+                fmt.Fprintln(out, message)
+            }
+            waitForRAMSessions()
+            waitForIsolatedSessions()
+            waitForIdleWatches()
+            
+        case "pick":
+            if opts.menu == "" && !opts.fromStdin {
+                for _, name := range cm.pickNames(opts.tag) {
+                    fmt.Println(name)
+                }
+                break
+            }
+            var choice string
+            if opts.menu != "" {
+                var err error
+                if choice, err = runMenu(opts.menu, cm.pickNames(opts.tag)); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+            } else {
+                choice = readSelection(os.Stdin)
+            }
+            if choice == "" {
+                // Nothing chosen, e.g. the menu was dismissed
+                os.Exit(1)
+            }
+            message, err := cm.launchBrowser(interruptContext(), choice)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                waitForWebhooks()
+                os.Exit(exitCode(err))
+            }
+            fmt.Println(message)
+            waitForRAMSessions()
+            waitForIdleWatches()
+            
+        case "ps":
+            instances := findInstances(cm.runningDirs())
+            if opts.jsonOut {
+                infos := []instanceInfo{}
+                for _, inst := range instances {
+                    infos = append(infos, inst.info())
+                }
+                data, _ := json.MarshalIndent(infos, "", "  ")
+                fmt.Println(string(data))
+                break
+            }
+            if len(instances) == 0 {
+                fmt.Println("No browsers are running")
+                break
+            }
+            cm.printInstances(os.Stdout, instances)
+            
+        case "focus":
+            if profileName == "" {
+                profileName = cm.defaultProfile()
+            }
+            raised, err := cm.focusProfile(profileName)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            if raised {
+                fmt.Printf("Raised profile '%s'\n", profileName)
+                break
+            }
+            if !opts.orLaunch {
+                fmt.Printf("Error: profile '%s' is not running\n", profileName)
+                os.Exit(1)
+            }
+            message, err := cm.launchBrowser(interruptContext(), profileName)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                waitForWebhooks()
+                os.Exit(exitCode(err))
+            }
             fmt.Println(message)
+            waitForRAMSessions()
+            waitForIdleWatches()
+            
+        case "stop", "kill":
+            var names []string
+            if !opts.all {
+                names = matchProfilesOrExit(cm, profileName)
+            }
+            running := []instance{}
+            for _, inst := range findInstances(cm.runningDirs()) {
+                if opts.all || containsString(names, inst.name) {
+                    running = append(running, inst)
+                }
+            }
+            if len(running) == 0 {
+                if opts.all || isProfilePattern(profileName) {
+                    fmt.Println("No matching browsers are running")
+                    break
+                }
+                fmt.Printf("Error: profile '%s' is not running\n", profileName)
+                os.Exit(1)
+            }
+            failed := 0
+            if cmd == "kill" {
+                for _, inst := range running {
+                    if err := killBrowser(inst); err != nil {
+                        failed++
+                        fmt.Printf("Error killing '%s': %s\n", inst.name, err)
+                        continue
+                    }
+                    fmt.Printf("Killed '%s' (PID %d)\n", inst.name, inst.pid)
+                }
+                if failed > 0 {
+                    os.Exit(1)
+                }
+                break
+            }
+            for _, result := range stopBrowsers(running, opts.timeout) {
+                switch {
+                case result.err != nil:
+                    failed++
+                    fmt.Printf("Error stopping '%s': %s\n", result.name, result.err)
+                case result.killed:
+                    fmt.Printf("Killed '%s' after it didn't quit within %s\n", result.name, opts.timeout)
+                default:
+                    fmt.Printf("Stopped '%s'\n", result.name)
+                }
+            }
+            if failed > 0 {
+                os.Exit(1)
+            }
             
         case "clean":
-            fmt.Println("Cleaning profile:", profileName)
-            profilePath := filepath.Join(cm.profileDir, profileName)
-            if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-                fmt.Println("Error: Profile directory does not exist")
-            } else {
-                // Clean the profile directory
-                files, err := ioutil.ReadDir(profilePath)
+            ctx := interruptContext()
+            clean := func(name string) (string, error) {
+                if err := cm.checkProtected(name, "clean"); err != nil {
+                    return "", err
+                }
+                if err := cm.confirmLarge(ctx, name, "clean"); err != nil {
+                    return "", err
+                }
+                if opts.cleanKeep != nil {
+                    // Only for this clean; the config keeps its list
+                    profile := cm.profiles[name]
+                    profile.CleanKeep = opts.cleanKeep
+                    cm.profiles[name] = profile
+                }
+                report, done := cleanProgressPrinter()
+                defer done()
+                if opts.shred {
+                    note, err := cm.shredProfile(ctx, name, report)
+                    return keptNote(note, cm.profiles[name].CleanKeep), err
+                }
+                return keptNote("", cm.profiles[name].CleanKeep), cm.cleanProfile(ctx, name, report)
+            }
+            if !isProfilePattern(profileName) {
+                fmt.Println("Cleaning profile:", profileName)
+                note, err := clean(profileName)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                if note != "" {
+                    fmt.Printf("Profile '%s' completely cleared and reset (%s)\n", profileName, note)
+                    break
+                }
+                fmt.Printf("Profile '%s' completely cleared and reset\n", profileName)
+                break
+            }
+            names := matchProfilesOrExit(cm, profileName)
+            failed := 0
+            for _, name := range names {
+                // Never-launched profiles are already clean
+                if _, err := os.Stat(cm.profilePath(cm.profiles[name])); os.IsNotExist(err) {
+                    fmt.Printf("Profile '%s' has no data to clean\n", name)
+                    continue
+                }
+                note, err := clean(name)
+                switch {
+                case err != nil:
+                    fmt.Printf("Error cleaning '%s': %s\n", name, err)
+                    failed++
+                case note != "":
+                    fmt.Printf("Profile '%s' completely cleared and reset (%s)\n", name, note)
+                default:
+                    fmt.Printf("Profile '%s' completely cleared and reset\n", name)
+                }
+            }
+            fmt.Printf("Cleaned %d of %d profiles\n", len(names)-failed, len(names))
+            if failed > 0 {
+                waitForWebhooks()
+                os.Exit(1)
+            }
+            
+        case "remove":
+            ctx := interruptContext()
+            names := matchProfilesOrExit(cm, profileName)
+            failed := 0
+            for _, name := range names {
+                err := cm.checkProtected(name, "remove")
+                if err == nil {
+                    err = cm.confirmLarge(ctx, name, "remove")
+                }
+                if err == nil {
+                    err = cm.removeProfile(name, opts.purge)
+                }
                 if err != nil {
-                    fmt.Printf("Error reading directory: %s\n", err)
+                    fmt.Printf("Error removing '%s': %s\n", name, err)
+                    failed++
                 } else {
-                    for _, file := range files {
-                        filePath := filepath.Join(profilePath, file.Name())
-                        if err := os.RemoveAll(filePath); err != nil {
-                            fmt.Printf("Error cleaning profile: %s\n", err)
-                            os.Exit(1)
-                        }
+                    fmt.Printf("Profile '%s' removed\n", name)
+                }
+            }
+            if err := cm.saveProfiles(); err != nil {
+                fmt.Printf("Error saving config: %s\n", err)
+                os.Exit(1)
+            }
+            fmt.Printf("Removed %d of %d profiles\n", len(names)-failed, len(names))
+            if failed > 0 {
+                os.Exit(1)
+            }
+            
+        case "rename":
+            oldName, newName := opts.args[0], opts.args[1]
+            if err := cm.renameProfile(oldName, newName); err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            fmt.Printf("Profile '%s' renamed to '%s'\n", oldName, newName)
+            
+        case "clone":
+            message, err := cm.cloneProfile(interruptContext(), opts.args[0], opts.args[1])
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            fmt.Println(message)
+            
+        case "edit":
+            format := editTOML
+            if opts.yamlEdit {
+                format = editYAML
+            }
+            message, err := cm.editProfile(profileName, format)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            fmt.Println(message)
+            
+        case "generate":
+            data, err := ioutil.ReadFile(opts.spec)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            changes, err := cm.generateProfiles(data, opts.dryRun)
+            if err != nil {
+                fmt.Printf("Error: %s: %s\n", opts.spec, err)
+                os.Exit(1)
+            }
+            if opts.dryRun {
+                fmt.Printf("The spec makes %d profiles:\n", len(changes))
+            } else if len(changes) == 0 {
+                fmt.Println("The generated profiles are up to date")
+            }
+            for _, change := range changes {
+                fmt.Println("  " + change)
+            }
+            
+        case "import-csv":
+            changes, notes, err := cm.importCSV(opts.args[0], opts.prefix, opts.dryRun)
+            if err != nil {
+                fmt.Printf("Error: %s: %s\n", opts.args[0], err)
+                os.Exit(1)
+            }
+            writeImportNotes(os.Stdout, notes)
+            switch {
+            case opts.dryRun && len(changes) > 0:
+                fmt.Printf("The sheet changes %d profiles:\n", len(changes))
+            case len(changes) == 0:
+                fmt.Println("Nothing to import; the profiles are up to date")
+            }
+            for _, change := range changes {
+                fmt.Println("  " + change)
+            }
+            
+        case "diff":
+            sections, err := cm.diffProfiles(opts.args[0], opts.args[1], opts.data)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            if !printProfileDiff(os.Stdout, opts.args[0], opts.args[1], sections) {
+                fmt.Printf("Profiles '%s' and '%s' are the same\n", opts.args[0], opts.args[1])
+            }
+            
+        case "autostart":
+            switch opts.args[0] {
+            case "enable":
+                where, err := cm.enableAutostart(profileName)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("Profile '%s' will launch at login (%s)\n", profileName, where)
+            case "disable":
+                if err := disableAutostart(profileName); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("Profile '%s' will no longer launch at login\n", profileName)
+            case "status":
+                names := sortedProfileNames(cm.profiles)
+                if profileName != "" {
+                    names = []string{profileName}
+                }
+                fmt.Println("Autostart:")
+                for _, name := range names {
+                    enabled, err := autostartEnabled(name)
+                    if err != nil {
+                        fmt.Printf("Error: %s\n", err)
+                        os.Exit(1)
                     }
-                    fmt.Printf("Profile '%s' completely cleared and reset\n", profileName)
+                    status := "off"
+                    if enabled {
+                        status = "on"
+                    }
+                    fmt.Printf("  - %s  %s\n", name, status)
+                }
+            }
+            
+        case "serve":
+            if err := cm.runServer(opts.listen, opts.grpcListen); err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            
+        case "daemon":
+            if err := cm.runDaemon(cm.daemonSocketPath(opts.socket)); err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            waitForRAMSessions()
+            waitForIdleWatches()
+            
+        case "gc":
+            names := cm.dueCleans(time.Now())
+            if len(names) == 0 {
+                fmt.Println("No scheduled cleans are due")
+                break
+            }
+            failed := 0
+            for _, name := range names {
+                // A running browser holds its files; try again next time
+                if _, running := runningPID(cm.profilePath(cm.profiles[name])); running {
+                    fmt.Printf("Skipping '%s': browser is running\n", name)
+                    continue
+                }
+                if err := cm.scheduledClean(name); err != nil {
+                    fmt.Printf("Error cleaning '%s': %s\n", name, err)
+                    failed++
+                } else {
+                    fmt.Printf("Profile '%s' cleaned (%s)\n", name, cm.profiles[name].CleanSchedule)
+                }
+            }
+            if failed > 0 {
+                waitForWebhooks()
+                os.Exit(1)
+            }
+            
+        case "browsers":
+            browsers := installedBrowsers()
+            if len(browsers) == 0 {
+                fmt.Println("No browsers found; install Chromium or Chrome, or run 'launchium fetch-browser'")
+                break
+            }
+            rows := [][]string{}
+            widths := []int{0, 0, 0}
+            for _, b := range browsers {
+                version := browserVersion(b.Path)
+                if version == "" {
+                    version = "unknown"
+                }
+                row := []string{b.Channel, version, b.Product, b.Path}
+                if b.Path == cm.chromePath {
+                    row[3] += "  (default)"
+                }
+                for i := range widths {
+                    widths[i] = max(widths[i], len(row[i]))
+                }
+                rows = append(rows, row)
+            }
+            fmt.Println("Installed browsers:")
+            for _, row := range rows {
+                fmt.Printf("  %-*s  %-*s  %-*s  %s\n", widths[0], row[0], widths[1], row[1], widths[2], row[2], row[3])
+            }
+            
+        case "fetch-browser":
+            url, name, err := browserDownload(opts.version)
+            if opts.url != "" {
+                url = opts.url
+                name = strings.TrimSuffix(filepath.Base(opts.url), filepath.Ext(opts.url))
+            } else if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            fmt.Println("Downloading", url)
+            percent := -1
+            exe, err := fetchBrowser(interruptContext(), url, name, func(done, total int64) {
+                if total > 0 && int(done*100/total) != percent {
+                    percent = int(done * 100 / total)
+                    fmt.Printf("\r  %3d%% of %s", percent, formatBytes(total))
+                }
+            })
+            if percent >= 0 {
+                fmt.Println()
+            }
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            fmt.Println("Installed", exe)
+            fmt.Println("Pick it as a profile's browser in the editor, or set it for all profiles with:")
+            fmt.Printf("  [settings]\n  browser = %s\n", quoteString(exe))
+            
+        case "url":
+            switch opts.args[0] {
+            case "register":
+                where, err := cm.registerURLHandler()
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("%s:// links now open with launchium (%s)\n", urlScheme, where)
+            case "unregister":
+                if err := unregisterURLHandler(); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Println("URL handler removed")
+            default:
+                message, err := cm.handleURL(interruptContext(), opts.args[0])
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    waitForWebhooks()
+                    os.Exit(1)
+                }
+                fmt.Println(message)
+                waitForRAMSessions()
+                waitForIdleWatches()
+            }
+            
+        case "scheduler":
+            switch opts.args[0] {
+            case "install":
+                where, err := cm.installScheduler()
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("Scheduled cleans will run every %s (%s)\n", gcInterval, where)
+            case "uninstall":
+                if err := uninstallScheduler(); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Println("Scheduler removed")
+            case "status":
+                installed, err := schedulerInstalled()
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                if installed {
+                    fmt.Println("Scheduler: installed")
+                } else {
+                    fmt.Println("Scheduler: not installed")
+                }
+                fmt.Println("Clean schedules:")
+                for _, name := range cm.scheduledProfiles() {
+                    fmt.Printf("  - %s  %s\n", name, cm.scheduleSummary(name))
                 }
             }
             
         case "list":
+            if opts.scriptJSON {
+                data, err := cm.scriptFilterJSON(opts.tag)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Println(string(data))
+                break
+            }
+            if opts.format != "" {
+                format, err := parseListFormat(opts.format)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(2)
+                }
+                names := []string{}
+                for _, name := range sortedProfileNames(cm.profiles) {
+                    if opts.tag == "" || cm.profiles[name].hasTag(opts.tag) {
+                        names = append(names, name)
+                    }
+                }
+                if err := cm.printProfiles(os.Stdout, names, format); err != nil {
+                    fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+                    os.Exit(1)
+                }
+                break
+            }
             fmt.Println("Available profiles:")
-            for name := range cm.profiles {
-                fmt.Println("  -", name)
+            for _, name := range sortedProfileNames(cm.profiles) {
+                profile := cm.profiles[name]
+                if opts.tag != "" && !profile.hasTag(opts.tag) {
+                    continue
+                }
+                summary := profile.summary()
+                if profile.Slot != 0 {
+                    summary = strings.TrimSpace(fmt.Sprintf("(slot %d) %s", profile.Slot, summary))
+                }
+                if cm.isArchived(name) {
+                    summary = strings.TrimSpace("(archived) " + summary)
+                }
+                if summary != "" {
+                    fmt.Printf("  - %s  %s\n", name, summary)
+                } else {
+                    fmt.Println("  -", name)
+                }
+            }
+            
+        case "sync":
+            if profileName == "" {
+                profileName = cm.defaultProfile()
+            }
+            if err := cm.syncProfile(interruptContext(), opts.args[0], profileName, opts.remote); err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            if opts.args[0] == syncPush {
+                fmt.Printf("Profile '%s' pushed\n", profileName)
+            } else {
+                fmt.Printf("Profile '%s' pulled\n", profileName)
+            }
+            
+        case "config":
+            switch opts.args[0] {
+            case "path":
+                fmt.Println(cm.configFile)
+            case "open":
+                if err := cm.openConfig(); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+            case "log":
+                changes, err := cm.configLog(opts.count)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                for _, change := range changes {
+                    fmt.Println(change)
+                }
+            case "revert":
+                rev := ""
+                if len(opts.args) > 1 {
+                    rev = opts.args[1]
+                }
+                summary, err := cm.revertConfig(rev)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("Config reverted to %s\n", summary)
+            case "export":
+                data, err := exportProfiles(cm.exportableProfiles(), opts.output)
+                if opts.foreign != "" {
+                    data, err = exportForeign(cm.exportableProfiles(), opts.foreign)
+                }
+                if err == nil && opts.output != "" {
+                    err = ioutil.WriteFile(opts.output, data, 0644)
+                }
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                if opts.output == "" {
+                    os.Stdout.Write(data)
+                } else {
+                    fmt.Printf("Exported %d profiles to %s\n", len(cm.exportableProfiles()), opts.output)
+                }
+            case "import":
+                mode := importMerge
+                if opts.replace {
+                    mode = importReplace
+                }
+                resolve := askImportConflict(os.Stdin, os.Stdout, stdinIsTerminal())
+                var changes []string
+                var err error
+                if opts.foreign != "" {
+                    var foreign foreignImport
+                    if foreign, err = readForeignProfiles(opts.foreign, opts.args[1], cm.settings); err == nil {
+                        writeImportNotes(os.Stdout, foreign.notes)
+                        changes, err = cm.addImported(foreign.profiles, mode, resolve)
+                    }
+                } else {
+                    var data []byte
+                    if data, err = ioutil.ReadFile(opts.args[1]); err != nil {
+                        fmt.Printf("Error: %s\n", err)
+                        os.Exit(1)
+                    }
+                    changes, err = cm.importProfiles(data, mode, resolve)
+                }
+                if err != nil {
+                    fmt.Printf("Error: %s: %s\n", opts.args[1], err)
+                    os.Exit(1)
+                }
+                if len(changes) == 0 {
+                    fmt.Println("Nothing to import; the config has these profiles already")
+                }
+                for _, change := range changes {
+                    fmt.Println(change)
+                }
+            }
+            
+        case "crashes":
+            names := sortedProfileNames(cm.profiles)
+            if profileName != "" {
+                if _, ok := cm.profiles[profileName]; !ok {
+                    fmt.Printf("Error: %s\n", profileNotFound(profileName))
+                    os.Exit(exitCode(profileNotFound(profileName)))
+                }
+                names = []string{profileName}
+            }
+            if opts.open {
+                dirs := cm.crashDumpLocations(profileName)
+                if len(dirs) == 0 {
+                    fmt.Printf("Error: profile '%s' has no crash dumps; add %s to its flags to have the browser write them\n", profileName, crashReporterFlag)
+                    os.Exit(1)
+                }
+                if err := systemOpen(dirs[0]); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                break
+            }
+            found := 0
+            for _, name := range names {
+                cm.detectCrashes(name)
+                crashes := cm.state.Crashes[name]
+                if len(crashes) == 0 {
+                    continue
+                }
+                found += len(crashes)
+                fmt.Printf("%s:\n", name)
+                for i := len(crashes) - 1; i >= 0; i-- {
+                    fmt.Printf("  %s  %s\n", crashes[i].Time.Format("2006-01-02 15:04"), crashes[i].Reason)
+                    if crashes[i].Dump != "" {
+                        fmt.Printf("    %s\n", crashes[i].Dump)
+                    }
+                }
+                for _, dir := range cm.crashDumpLocations(name) {
+                    fmt.Printf("  dumps in %s\n", dir)
+                }
+            }
+            if found == 0 {
+                fmt.Println("No crashes found")
+            }
+            
+        case "dir":
+            path, err := cm.dataLocation(profileName)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            if !opts.open {
+                fmt.Println(path)
+                break
+            }
+            if _, err := os.Stat(path); os.IsNotExist(err) {
+                fmt.Printf("Error: profile '%s' has no data directory yet; launch it first\n", profileName)
+                os.Exit(1)
+            }
+            if err := systemOpen(path); err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(1)
+            }
+            
+        case "lint":
+            names := sortedProfileNames(cm.profiles)
+            if profileName != "" {
+                if _, ok := cm.profiles[profileName]; !ok {
+                    fmt.Printf("Error: profile '%s' not found\n", profileName)
+                    os.Exit(1)
+                }
+                names = []string{profileName}
+            }
+            found := 0
+            for _, name := range names {
+                for _, problem := range cm.profiles[name].lint() {
+                    fmt.Printf("%s: %s\n", name, problem)
+                    found++
+                }
+            }
+            if found > 0 {
+                os.Exit(1)
+            }
+            fmt.Printf("No problems found in %d profiles\n", len(names))
+            
+        case "reset":
+            ctx := interruptContext()
+            reset := func(name string) (string, error) {
+                if err := cm.checkProtected(name, "reset"); err != nil {
+                    return "", err
+                }
+                if err := cm.confirmLarge(ctx, name, "reset"); err != nil {
+                    return "", err
+                }
+                report, done := cleanProgressPrinter()
+                defer done()
+                return cm.resetProfile(ctx, name, report)
+            }
+            if !isProfilePattern(profileName) {
+                message, err := reset(profileName)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                fmt.Println(message)
+                break
+            }
+            names := matchProfilesOrExit(cm, profileName)
+            failed := 0
+            for _, name := range names {
+                message, err := reset(name)
+                if err != nil {
+                    fmt.Printf("Error resetting '%s': %s\n", name, err)
+                    failed++
+                    continue
+                }
+                fmt.Println(message)
+            }
+            fmt.Printf("Reset %d of %d profiles\n", len(names)-failed, len(names))
+            if failed > 0 {
+                waitForWebhooks()
+                os.Exit(1)
+            }
+            
+        case "golden":
+            if opts.args[0] == "drop" {
+                if err := cm.dropGolden(profileName); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                fmt.Printf("Dropped the golden image of '%s'\n", profileName)
+                break
+            }
+            message, err := cm.saveGolden(interruptContext(), profileName)
+            if err != nil {
+                fmt.Printf("Error: %s\n", err)
+                os.Exit(exitCode(err))
+            }
+            fmt.Println(message)
+            
+        case "app":
+            switch opts.args[0] {
+            case "add":
+                where, err := cm.addApp(opts.app, !opts.noShortcut)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                if where != "" {
+                    fmt.Printf("Added app '%s' for profile '%s' (%s)\n", opts.app.Name, opts.app.Profile, where)
+                } else {
+                    fmt.Printf("Added app '%s' for profile '%s'\n", opts.app.Name, opts.app.Profile)
+                }
+            case "launch":
+                message, err := cm.launchApp(interruptContext(), opts.args[1])
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    waitForWebhooks()
+                    os.Exit(exitCode(err))
+                }
+                fmt.Println(message)
+                waitForRAMSessions()
+                waitForIdleWatches()
+            case "remove":
+                if err := cm.removeApp(opts.args[1]); err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(1)
+                }
+                fmt.Printf("Removed app '%s'\n", opts.args[1])
+            case "list":
+                if len(cm.settings.Apps) == 0 {
+                    fmt.Println("No apps; add one with `launchium app add`")
+                    break
+                }
+                fmt.Println("Apps:")
+                for _, name := range cm.settings.appNames() {
+                    app := cm.settings.Apps[name]
+                    fmt.Printf("  - %s  %s  (%s)\n", name, app.URL, app.Profile)
+                }
+            }
+            
+        case "compact", "archive", "thaw":
+            ctx := interruptContext()
+            action := map[string]func(context.Context, string) (string, error){
+                "compact": cm.compactProfile,
+                "archive": cm.archiveProfile,
+                "thaw":    cm.thawProfile,
+            }[cmd]
+            if !isProfilePattern(profileName) {
+                message, err := action(ctx, profileName)
+                if err != nil {
+                    fmt.Printf("Error: %s\n", err)
+                    os.Exit(exitCode(err))
+                }
+                fmt.Println(message)
+                break
+            }
+            failed := 0
+            for _, name := range matchProfilesOrExit(cm, profileName) {
+                message, err := action(ctx, name)
+                if err != nil {
+                    fmt.Printf("Error with '%s': %s\n", name, err)
+                    failed++
+                    continue
+                }
+                fmt.Println(message)
+            }
+            if failed > 0 {
+                os.Exit(1)
+            }
+            
+        case "du":
+            names := sortedProfileNames(cm.profiles)
+            if profileName != "" {
+                names = matchProfilesOrExit(cm, profileName)
+            }
+            var total int64
+            failed := 0
+            for _, s := range cm.profileSizes(interruptContext(), names, opts.rescan) {
+                switch {
+                case s.err != nil:
+                    fmt.Printf("  %10s  %s: %s\n", "error", s.name, s.err)
+                    failed++
+                case s.noData:
+                    fmt.Printf("  %10s  %s\n", "no data", s.name)
+                case s.archived:
+                    fmt.Printf("  %10s  %s (archived)\n", formatBytes(s.size), s.name)
+                case s.cached:
+                    fmt.Printf("  %10s  %s (scanned %s)\n", formatBytes(s.size), s.name, formatAgo(cm.state.Sizes[s.name].Scanned))
+                default:
+                    fmt.Printf("  %10s  %s\n", formatBytes(s.size), s.name)
+                }
+                total += s.size
+            }
+            fmt.Printf("  %10s  total\n", formatBytes(total))
+            if failed > 0 {
+                os.Exit(1)
+            }
+            
+        case "presets":
+            switch opts.args[0] {
+            case "list":
+                for _, preset := range flagPresets {
+                    fmt.Printf("  - %-13s %s\n", preset.name, preset.description)
+                }
+            case "show":
+                preset, ok := findPreset(opts.args[1])
+                if !ok {
+                    fmt.Printf("Error: %s\n", validatePresets(opts.args[1:]))
+                    os.Exit(1)
+                }
+                fmt.Printf("%s: %s\n", preset.name, preset.description)
+                for _, flag := range preset.flags {
+                    fmt.Println("  " + flag)
+                }
+            }
+            
+        case "refresh":
+            switch {
+            case cm.configSource == "":
+                fmt.Printf("Error: %s is not a remote config; pass a URL with -config or %s\n", cm.configFile, configEnvVar)
+                os.Exit(1)
+            case cm.remoteErr != nil:
+                fmt.Printf("Error: %s\n", cm.remoteErr)
+                os.Exit(1)
+            case cm.remoteChanged:
+                fmt.Printf("Config updated from %s\n", cm.configSource)
+            default:
+                fmt.Printf("Config from %s is up to date\n", cm.configSource)
             }
             
         case "version":
-            fmt.Printf("Launchium version %s\n", VERSION)
+            fmt.Println(versionText())
+            if cm.settings.NoUpdateCheck {
+                break
+            }
+            latest, err := latestRelease(interruptContext())
+            if err != nil {
+                fmt.Printf("Could not check for updates: %s\n", err)
+            } else if newerVersion(latest, version) {
+                fmt.Printf("\nLaunchium %s is available: %s\n", latest, latestReleaseURL)
+            }
         }
         
+        waitForWebhooks()
         os.Exit(0)
     }
     
     // If no command-line arguments, start the interactive UI
-    p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+    cm := initialModel(opts.configPath)
+    cm.applyTheme()
+    if cm.firstRun {
+        cm.openSetup()
+    } else if cmd == "edit" && cm.err == nil {
+        if _, ok := cm.profiles[profileName]; !ok {
+            fmt.Printf("Error: %s\n", profileNotFound(profileName))
+            os.Exit(exitCode(profileNotFound(profileName)))
+        }
+        cm.openProfileForm(profileName)
+    }
+    var cancel context.CancelFunc
+    cm.ctx, cancel = context.WithCancel(context.Background())
+    p := tea.NewProgram(cm, tea.WithAltScreen())
+    webhookLog = io.Discard
     if _, err := p.Run(); err != nil {
         fmt.Printf("Error: %v", err)
         os.Exit(1)
     }
+    webhookLog = os.Stderr
+
+    // Stop background launches, cleans and disk scans still going
+    cancel()
+
+    // RAM disk sessions lose their data if launchium exits first
+    waitForRAMSessions()
+    waitForIdleWatches()
+    waitForWebhooks()
 }
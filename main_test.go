@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestProfilePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	root := filepath.Join(t.TempDir(), "profiles")
+	cm := &ChromiumManager{profileDir: root}
+	tests := []struct {
+		name    string
+		profile Profile
+		want    string
+	}{
+		{"default", Profile{Name: "work"}, filepath.Join(root, "work")},
+		{"absolute data dir", Profile{Name: "work", DataDir: filepath.Join(root, "elsewhere")}, filepath.Join(root, "elsewhere")},
+		{"home data dir", Profile{Name: "work", DataDir: "~/browsers/work"}, filepath.Join(home, "browsers", "work")},
+		{"cleaned data dir", Profile{Name: "work", DataDir: root + "/a/../b/"}, filepath.Join(root, "b")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cm.profilePath(tt.profile); got != tt.want {
+				t.Errorf("profilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// hasArg reports whether a command line has arg
+func TestValidProfileName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"work", true},
+		{"qa-eu.v2", true},
+		{"a..b", false},
+		{"..", false},
+		{"../work", false},
+		{"team/work", false},
+		{`team\work`, false},
+		{".hidden", false},
+	}
+	for _, tt := range tests {
+		if err := validProfileName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("validProfileName(%q) = %v, want ok %t", tt.name, err, tt.ok)
+		}
+		if _, err := checkProfile(Profile{Name: tt.name}, Settings{}); (err == nil) != tt.ok {
+			t.Errorf("checkProfile(%q) = %v, want ok %t", tt.name, err, tt.ok)
+		}
+	}
+
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	if err := cm.renameProfile("work", "../work"); err == nil {
+		t.Error("renameProfile to ../work succeeded")
+	}
+	if _, err := cm.cloneProfile(context.Background(), "work", "/tmp/work"); err == nil {
+		t.Error("cloneProfile to /tmp/work succeeded")
+	}
+	if len(cm.profiles) != 2 {
+		t.Errorf("profiles = %v, want only work and home", sortedProfileNames(cm.profiles))
+	}
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStartBrowserWith(t *testing.T) {
+	const browser = "/usr/bin/chromium"
+	proxied := Profile{Name: "work", Proxy: "127.0.0.1:9050", ProxyType: "socks5", Flags: parseFlagList("--no-first-run")}
+	tests := []struct {
+		name     string
+		profile  Profile
+		found    bool  // Whether LookPath finds the browser
+		exitErr  error // How the browser exits right away
+		wantErr  error
+		wantArgs []string
+	}{
+		{
+			name:    "launches with the profile's settings",
+			profile: proxied,
+			found:   true,
+			wantArgs: []string{
+				"--new-window",
+				"--window-name=work",
+				"--proxy-server=socks5://127.0.0.1:9050",
+				"--no-first-run",
+				"about:blank",
+			},
+		},
+		{
+			name:    "holds the WebRTC policy",
+			profile: Profile{Name: "private", Proxy: "127.0.0.1:9050", ProxyType: "socks5", WebRTCPolicy: webRTCNoUDP},
+			found:   true,
+			wantArgs: []string{
+				"--proxy-server=socks5://127.0.0.1:9050",
+				"--force-webrtc-ip-handling-policy=disable_non_proxied_udp",
+			},
+		},
+		{
+			name:    "browser not installed",
+			profile: proxied,
+			wantErr: ErrBrowserNotFound,
+		},
+		{
+			name:    "browser exits with an error",
+			profile: Profile{Name: "broken", Proxy: "none", ProxyType: "none"},
+			found:   true,
+			exitErr: errors.New("exit status 1"),
+			wantErr: ErrLaunchFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, runner := useFakes(t)
+			runner.paths[browser] = tt.found
+			runner.exitErr = tt.exitErr
+			// Not on the real disk, so the launch log isn't written either
+			root := filepath.Join(t.TempDir(), "fake", "profiles")
+			cm := &ChromiumManager{ctx: context.Background(), profileDir: root, profiles: map[string]Profile{tt.profile.Name: tt.profile}}
+
+			message, err := cm.startBrowserWith(context.Background(), tt.profile, browser, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(message, "Launched with profile: "+tt.profile.Name) {
+				t.Errorf("message %q doesn't name the launch", message)
+			}
+
+			commands := runner.commands()
+			if len(commands) != 1 {
+				t.Fatalf("started %d commands, want 1", len(commands))
+			}
+			args := commands[0]
+			if args[0] != browser {
+				t.Errorf("started %s, want %s", args[0], browser)
+			}
+			dataDir := filepath.Join(root, tt.profile.Name)
+			for _, want := range append(tt.wantArgs, "--user-data-dir="+dataDir) {
+				if !hasArg(args, want) {
+					t.Errorf("command line %q lacks %s", args, want)
+				}
+			}
+			if _, err := m.Stat(filepath.Join(dataDir, "Local State")); err != nil {
+				t.Errorf("the new data dir wasn't set up for its first run: %s", err)
+			}
+		})
+	}
+}
+
+func TestStartBrowserWithPasswordStore(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("password stores are only chosen on Linux")
+	}
+	const browser = "/usr/bin/chromium"
+	t.Setenv("XDG_CURRENT_DESKTOP", "KDE")
+	t.Setenv("KDE_SESSION_VERSION", "6")
+	m, runner := useFakes(t)
+	runner.paths[browser] = true
+	root := filepath.Join(t.TempDir(), "fake", "profiles")
+	profile := Profile{Name: "work", Proxy: "none", ProxyType: "none"}
+	cm := &ChromiumManager{ctx: context.Background(), profileDir: root, profiles: map[string]Profile{"work": profile}}
+	launch := func(profile Profile) (string, []string) {
+		t.Helper()
+		message, err := cm.startBrowserWith(context.Background(), profile, browser, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commands := runner.commands()
+		return message, commands[len(commands)-1]
+	}
+
+	// The first launch keeps to the desktop's store, later ones too
+	if _, args := launch(profile); !hasArg(args, "--password-store=kwallet6") {
+		t.Errorf("first launch %q doesn't use the desktop's store", args)
+	}
+	t.Setenv("XDG_CURRENT_DESKTOP", "GNOME")
+	if _, args := launch(profile); !hasArg(args, "--password-store=kwallet6") {
+		t.Errorf("launch on another desktop %q left the data dir's store", args)
+	}
+
+	// Pinning another store says the saved logins are lost, once
+	profile.PasswordStore = "basic"
+	message, args := launch(profile)
+	if !hasArg(args, "--password-store=basic") {
+		t.Errorf("pinned launch %q doesn't use the pinned store", args)
+	}
+	if !strings.Contains(message, "can't be read with basic") {
+		t.Errorf("switching stores wasn't warned about: %q", message)
+	}
+	if message, _ := launch(profile); strings.Contains(message, "can't be read") {
+		t.Errorf("the switch was warned about again: %q", message)
+	}
+	if got := savedPasswordStore(filepath.Join(root, "work")); got != "basic" {
+		t.Errorf("data dir records %q, want basic", got)
+	}
+	if _, err := m.Stat(filepath.Join(root, "work", passwordStoreFile)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFilterItems(t *testing.T) {
+	items := []item{
+		{title: "work", desc: "Office • client-a"},
+		{title: "personal", desc: "Home"},
+		{title: "scrape", desc: "Research • client-a, scraping"},
+	}
+	targets := []string{}
+	for _, i := range items {
+		targets = append(targets, i.FilterValue())
+	}
+	tests := []struct {
+		term string
+		want []int
+	}{
+		{"personal", []int{1}},
+		{"client-a", []int{0, 2}},
+		{"research", []int{2}},
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		got := []int(nil)
+		for _, rank := range filterItems(tt.term, targets) {
+			if rank.MatchedIndexes != nil {
+				t.Errorf("%q: matches are highlighted in %q", tt.term, targets[rank.Index])
+			}
+			got = append(got, rank.Index)
+		}
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matches %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Windows of a multi-profile launch cascade from the first one so they
+// don't open on top of each other
+const (
+	cascadeOrigin = 40
+	cascadeStep   = 48
+)
+
+// launchResult is the outcome of launching one of several profiles
+type launchResult struct {
+	name    string
+	message string
+	reused  bool // A singleton profile was already running
+	err     error
+}
+
+// parseProfileList splits a -profiles value such as "work, personal" into
+// names, dropping blanks and repeats
+func parseProfileList(value string) []string {
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" && !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// cascadeWindow places the profile's window at step i of the cascade,
+// unless its flags already position it
+func cascadeWindow(profile Profile, i int) Profile {
+	if profile.Flags.has("--window-position") {
+		return profile
+	}
+	offset := cascadeOrigin + i*cascadeStep
+	profile.Flags = profile.Flags.with(fmt.Sprintf("--window-position=%d,%d", offset, offset))
+	return profile
+}
+
+// launchProfiles starts the named profiles in parallel and returns their
+// results in the order given. Their windows cascade or tile as the tile
+// setting says. Webhooks, notifications and the launch history are updated
+// once all of them have started.
+func (cm *ChromiumManager) launchProfiles(ctx context.Context, names []string) []launchResult {
+	results := make([]launchResult, len(names))
+	place, err := cm.windowPlacer(len(names))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+	var wg sync.WaitGroup
+	for i, name := range names {
+		profile, ok := cm.profiles[name]
+		if !ok {
+			results[i] = launchResult{name: name, err: profileNotFound(name)}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, profile Profile) {
+			defer wg.Done()
+			if message, reused, err := cm.reuseRunning(profile, nil); reused {
+				results[i] = launchResult{name: profile.Name, message: message, reused: true, err: err}
+				return
+			}
+			message, err := cm.startBrowser(ctx, place(profile, i))
+			results[i] = launchResult{name: profile.Name, message: message, err: err}
+		}(i, profile)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if _, ok := cm.profiles[result.name]; !ok || result.reused {
+			continue
+		}
+		cm.reportLaunch(result.name, result.err)
+		if result.err == nil {
+			cm.recordLaunch(result.name)
+			cm.invalidateSize(result.name)
+		}
+	}
+	return results
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Values for Profile.Notify
+const (
+	notifyDefault = ""    // follow the notifications setting
+	notifyOn      = "on"  // always notify for this profile
+	notifyOff     = "off" // never notify for this profile
+)
+
+// Cleans finishing faster than this don't get a notification
+const longClean = 5 * time.Second
+
+// validNotifyMode reports whether mode is a known Profile.Notify value
+func validNotifyMode(mode string) bool {
+	return mode == notifyDefault || mode == notifyOn || mode == notifyOff
+}
+
+// wantsNotifications reports whether desktop notifications are on for the
+// profile
+func (cm *ChromiumManager) wantsNotifications(name string) bool {
+	switch cm.profiles[name].Notify {
+	case notifyOn:
+		return true
+	case notifyOff:
+		return false
+	}
+	return cm.settings.Notifications
+}
+
+// reportLaunch tells webhooks and the desktop about a finished launch, and
+// watches for the browser exiting or going idle after a successful one
+func (cm *ChromiumManager) reportLaunch(name string, err error) {
+	if err != nil {
+		cm.fireWebhooks(eventLaunchFailed, name, err.Error())
+		cm.notifyDesktop(name, fmt.Sprintf("Failed to launch '%s': %s", name, err))
+		return
+	}
+	cm.fireWebhooks(eventLaunched, name, "")
+	cm.notifyDesktop(name, fmt.Sprintf("Launched '%s'", name))
+	if hooks := cm.webhooksFor(eventExited); len(hooks) > 0 {
+		cm.watchExit(name, hooks)
+	}
+	if cm.profiles[name].IdleTimeout > 0 {
+		cm.watchIdle(cm.profiles[name])
+	}
+}
+
+// reportClean tells webhooks and, for long cleans, the desktop about a
+// finished clean. message describes the clean for webhooks.
+func (cm *ChromiumManager) reportClean(name, message string, took time.Duration, err error) {
+	if err == nil {
+		cm.fireWebhooks(eventCleanComplete, name, message)
+	}
+	if took < longClean {
+		return
+	}
+	if err != nil {
+		cm.notifyDesktop(name, fmt.Sprintf("Cleaning '%s' failed: %s", name, err))
+	} else {
+		cm.notifyDesktop(name, fmt.Sprintf("Cleaned '%s' in %s", name, formatUptime(took)))
+	}
+}
+
+// notifyDesktop shows a notification about the profile if they are on for
+// it. Notifications are best effort; a missing notifier is ignored.
+func (cm *ChromiumManager) notifyDesktop(name, body string) {
+	if cm.wantsNotifications(name) {
+		desktopNotify("Launchium", body)
+	}
+}
+
+// desktopNotify shows a native notification with notify-send, osascript or
+// a PowerShell toast. It doesn't wait for the notifier to finish.
+func desktopNotify(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", "--app-name=Launchium", title, body)
+	case "darwin":
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body)
+	case "windows":
+		// The text goes through the environment to avoid quoting it for
+		// PowerShell
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+		cmd.Env = append(os.Environ(), "LAUNCHIUM_TOAST_TITLE="+title, "LAUNCHIUM_TOAST_BODY="+body)
+	default:
+		return fmt.Errorf("notifications are not supported on %s", runtime.GOOS)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// windowsToastScript shows a toast with the title and body from the
+// environment. Toasts need a registered app ID, so it borrows PowerShell's.
+const windowsToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:LAUNCHIUM_TOAST_TITLE)) | Out-Null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:LAUNCHIUM_TOAST_BODY)) | Out-Null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show([Windows.UI.Notifications.ToastNotification]::new($xml))
+`
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// systemOpen opens a file with its default app, or a directory in Finder,
+// Explorer or the desktop's file manager, without waiting for it
+func systemOpen(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("explorer", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	return cmd.Process.Release()
+}
+
+// openConfig opens the config file in the user's editor, checking it once
+// the editor exits, or with the system's default app when no editor is
+// set. A remote config can't be edited here.
+func (cm *ChromiumManager) openConfig() error {
+	if cm.configSource != "" {
+		return cm.errRemoteConfig()
+	}
+	if _, err := os.Stat(cm.configFile); os.IsNotExist(err) {
+		// Start from the profiles launchium would write
+		if err := cm.saveProfiles(); err != nil {
+			return err
+		}
+	}
+	editor := userEditor()
+	if editor == nil {
+		return systemOpen(cm.configFile)
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], cm.configFile)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor[0], err)
+	}
+	data, err := os.ReadFile(cm.configFile)
+	if err != nil {
+		return err
+	}
+	if _, _, err := parseConfig(data); err != nil {
+		return fmt.Errorf("%s: %s", cm.configFile, err)
+	}
+	return nil
+}
+
+// dataLocation returns where a profile keeps its data, or its archive when
+// it is archived
+func (cm *ChromiumManager) dataLocation(name string) (string, error) {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		return "", profileNotFound(name)
+	}
+	path := cm.profilePath(profile)
+	if cm.isArchived(name) {
+		path += archiveExt
+	}
+	return path, nil
+}
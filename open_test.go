@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDataLocation(t *testing.T) {
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		profiles: map[string]Profile{
+			"work": {Name: "work"},
+			"old":  {Name: "old"},
+		},
+	}
+	cm.state.Archived = map[string]time.Time{"old": time.Now()}
+	if path, err := cm.dataLocation("work"); err != nil || path != filepath.Join("/profiles", "work") {
+		t.Errorf("work's data is at %q, %v", path, err)
+	}
+	if path, err := cm.dataLocation("old"); err != nil || path != filepath.Join("/profiles", "old")+archiveExt {
+		t.Errorf("an archived profile's data is at %q, %v", path, err)
+	}
+	if _, err := cm.dataLocation("missing"); err == nil {
+		t.Error("found data for a profile that doesn't exist")
+	}
+}
+
+func TestOpenConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor is a shell command")
+	}
+	cm := &ChromiumManager{
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		profiles:   map[string]Profile{"work": {Name: "work", Proxy: "none", ProxyType: "none"}},
+	}
+
+	// A missing config is written first, so there is something to edit
+	t.Setenv("VISUAL", "true")
+	if err := cm.openConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(cm.configFile); err != nil || !strings.Contains(string(data), "[profiles.work]") {
+		t.Errorf("the config wasn't written before editing: %q, %v", data, err)
+	}
+
+	// An edit that breaks the config is reported
+	editor := filepath.Join(t.TempDir(), "editor")
+	os.WriteFile(editor, []byte("#!/bin/sh\necho 'channel = \"nightly\"' >> \"$1\"\n"), 0755)
+	t.Setenv("VISUAL", editor)
+	if err := cm.openConfig(); err == nil || !strings.Contains(err.Error(), "channel must be") {
+		t.Errorf("a broken edit gave %v", err)
+	}
+}
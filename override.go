@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ways to open a profile's browser: a normal window, an incognito window,
+// or a guest session. The others are named after their Chromium switch.
+const (
+	launchNormal    = ""
+	launchIncognito = "incognito"
+	launchGuest     = "guest"
+)
+
+// Launch modes in the order the launch picker cycles through them
+var launchModes = []string{launchNormal, launchIncognito, launchGuest}
+
+// withLaunchMode adds the switch for mode to the profile's flags, keeping
+// its proxy and other flags
+func withLaunchMode(profile Profile, mode string) Profile {
+	if mode != launchNormal {
+		profile.Flags = profile.Flags.with("--" + mode)
+	}
+	return profile
+}
+
+// launchOverride changes a profile for the launches of one command line
+// without editing its config
+type launchOverride struct {
+	addFlags    []string
+	removeFlags []string
+	proxy       string // host:port, or "none"; empty keeps the profile's
+	proxyType   string
+	mode        string // Launch mode, such as incognito
+	fresh       bool   // Start from the profile's golden image
+	isolated    bool   // Run from a throwaway fork of the data dir
+	session     string // Named session to open, with its pages and window layout
+	tile        string // Layout of a multi-profile launch's windows, over the tile setting
+	app         string // Page to open as an app, in a window of its own
+}
+
+// flagList collects a command line flag that may be given more than once
+type flagList []string
+
+func (l *flagList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *flagList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseProxyOverride reads a -proxy value: "none", or host:port with an
+// optional http:// or socks5:// scheme, http being the default
+func parseProxyOverride(value string) (proxy, proxyType string, err error) {
+	if value == "none" {
+		return "none", "none", nil
+	}
+	proxyType, proxy = "http", value
+	if scheme, rest, ok := strings.Cut(value, "://"); ok {
+		proxyType, proxy = scheme, rest
+	}
+	if proxyType != "http" && proxyType != "socks5" {
+		return "", "", fmt.Errorf("proxy scheme must be http:// or socks5://, got %q", proxyType+"://")
+	}
+	if !strings.Contains(proxy, ":") {
+		return "", "", fmt.Errorf("proxy must be host:port, got %q", proxy)
+	}
+	return proxy, proxyType, nil
+}
+
+// profile returns the profile with the overridden proxy and launch mode
+func (o launchOverride) profile(p Profile) Profile {
+	if o.proxy != "" {
+		p.Proxy, p.ProxyType = o.proxy, o.proxyType
+	}
+	return withLaunchMode(p, o.mode)
+}
+
+// args removes and adds flags on the browser's command line. A removed
+// flag given without a value, such as --window-size, matches it with any
+// value.
+func (o launchOverride) args(args []string) []string {
+	if len(o.removeFlags) == 0 {
+		return append(args, o.addFlags...)
+	}
+	kept := []string{}
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		removed := false
+		for _, flag := range o.removeFlags {
+			if arg == flag || (!strings.Contains(flag, "=") && name == flag) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, arg)
+		}
+	}
+	return append(kept, o.addFlags...)
+}
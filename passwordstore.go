@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Values for Profile.PasswordStore, the keyring Chromium encrypts saved
+// logins with on Linux
+const (
+	passwordStoreUnchanged = ""                // keep the store the data dir's logins were saved with
+	passwordStoreBasic     = "basic"           // a fixed key, readable on any desktop but not kept secret
+	passwordStoreGnome     = "gnome-libsecret" // the Secret Service: GNOME Keyring, KeePassXC and the like
+	passwordStoreKWallet   = "kwallet"         // KWallet of KDE 4
+	passwordStoreKWallet5  = "kwallet5"
+	passwordStoreKWallet6  = "kwallet6"
+)
+
+// passwordStoreFile is the file in a data dir naming the store its saved
+// logins are encrypted with, written at launch
+const passwordStoreFile = "launchium-password-store"
+
+// passwordStores lists the values of the password_store setting. "gnome"
+// is short for gnome-libsecret.
+var passwordStores = []string{passwordStoreBasic, "gnome", passwordStoreGnome, passwordStoreKWallet, passwordStoreKWallet5, passwordStoreKWallet6}
+
+// validPasswordStore checks a Profile.PasswordStore value
+func validPasswordStore(store string) error {
+	if store == passwordStoreUnchanged || containsString(passwordStores, store) {
+		return nil
+	}
+	return fmt.Errorf("password_store must be basic, gnome, gnome-libsecret, kwallet, kwallet5 or kwallet6, got %q", store)
+}
+
+// passwordStoreName returns the --password-store value of a store
+func passwordStoreName(store string) string {
+	if store == "gnome" {
+		return passwordStoreGnome
+	}
+	return store
+}
+
+// desktopPasswordStore returns the store Chromium picks by itself on the
+// desktop launchium runs in, going by the same variables it does
+func desktopPasswordStore() string {
+	for _, desktop := range strings.Split(strings.ToUpper(os.Getenv("XDG_CURRENT_DESKTOP")), ":") {
+		switch desktop {
+		case "KDE":
+			switch os.Getenv("KDE_SESSION_VERSION") {
+			case "5":
+				return passwordStoreKWallet5
+			case "6":
+				return passwordStoreKWallet6
+			}
+			return passwordStoreKWallet
+		case "GNOME", "UNITY", "X-CINNAMON", "PANTHEON", "XFCE", "DEEPIN", "UKUI":
+			return passwordStoreGnome
+		}
+	}
+	return passwordStoreBasic
+}
+
+// savedPasswordStore returns the store recorded in a data dir, or "" for
+// a data dir launchium hasn't launched since it started recording it
+func savedPasswordStore(dataDir string) string {
+	data, err := fsys.ReadFile(filepath.Join(dataDir, passwordStoreFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// seedPasswordStore settles the store a launch of the profile uses and
+// records it in the data dir. Chromium picks one by the desktop it finds
+// itself on, so logins saved under GNOME can't be read under KDE or a
+// bare window manager. The profile's password_store wins, then a
+// --password-store flag of its own; without either, the data dir keeps
+// the store it was first launched with. Switching stores is allowed but
+// returns a warning, as the logins saved before are lost. Other systems
+// keep their logins in the system keychain and get no store.
+func seedPasswordStore(profile Profile, dataDir string) (string, string) {
+	if runtime.GOOS != "linux" {
+		return "", ""
+	}
+	saved := savedPasswordStore(dataDir)
+	store := passwordStoreName(profile.PasswordStore)
+	for _, arg := range profile.launchFlags() {
+		if f := parseFlag(arg); f.Switch == "--password-store" && profile.PasswordStore == passwordStoreUnchanged {
+			store = f.Value
+		}
+	}
+	if store == "" {
+		store = saved
+	}
+	if store == "" {
+		store = desktopPasswordStore()
+	}
+
+	warning := ""
+	if saved != "" && saved != store {
+		warning = fmt.Sprintf("saved logins were encrypted with the %s password store and can't be read with %s", saved, store)
+	}
+	if saved != store {
+		// A run_as profile's data dir may not be writable; the store is
+		// then settled again next time
+		fsys.WriteFile(filepath.Join(dataDir, passwordStoreFile), []byte(store+"\n"), 0644)
+	}
+	return store, warning
+}
+
+// passwordStoreFlags returns the switch that keeps the browser to a store
+func passwordStoreFlags(store string) []string {
+	if store == "" {
+		return nil
+	}
+	return []string{"--password-store=" + store}
+}
+
+// passwordStoreSummary describes a profile's store and the one its data
+// dir's logins were saved with for the detail pane
+func passwordStoreSummary(pinned, saved string) string {
+	pinned = passwordStoreName(pinned)
+	switch {
+	case pinned == passwordStoreUnchanged:
+		return saved + ", as first launched"
+	case saved != "" && saved != pinned:
+		return fmt.Sprintf("%s from the next launch; logins were saved with %s", pinned, saved)
+	}
+	return pinned
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Chromium's content setting values
+const (
+	contentAllow = 1
+	contentBlock = 2
+	contentAsk   = 3
+)
+
+// contentSettings maps the permission names of the permissions setting to
+// Chromium's content setting types
+var contentSettings = map[string]string{
+	"notifications": "notifications",
+	"geolocation":   "geolocation",
+	"camera":        "media_stream_camera",
+	"microphone":    "media_stream_mic",
+	"clipboard":     "clipboard",
+	"popups":        "popups",
+	"downloads":     "automatic_downloads",
+	"sensors":       "sensors",
+	"midi":          "midi_sysex",
+	"sound":         "sound",
+	"javascript":    "javascript",
+	"images":        "images",
+}
+
+// contentValues maps the values of the permissions setting to Chromium's
+var contentValues = map[string]int{"allow": contentAllow, "block": contentBlock, "ask": contentAsk}
+
+// permissionNames returns the names the permissions setting knows, sorted
+func permissionNames() []string {
+	names := make([]string, 0, len(contentSettings))
+	for name := range contentSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// permissionRule is one entry of a profile's permissions: the default of
+// a permission, or its setting for the sites matching Pattern
+type permissionRule struct {
+	Type    string // Chromium's content setting type
+	Value   int
+	Pattern string // Site pattern such as https://app.example.com; empty for the default
+}
+
+// parsePermission reads an entry such as "notifications=block" or
+// "clipboard=allow https://app.example.com"
+func parsePermission(entry string) (permissionRule, error) {
+	setting, pattern, _ := strings.Cut(strings.TrimSpace(entry), " ")
+	name, value, ok := strings.Cut(setting, "=")
+	if !ok {
+		return permissionRule{}, fmt.Errorf("permission %q must be <name>=<allow|block|ask>, optionally followed by a site", entry)
+	}
+	rule := permissionRule{Pattern: strings.TrimSpace(pattern)}
+	if rule.Type, ok = contentSettings[name]; !ok {
+		return rule, fmt.Errorf("unknown permission %q in %q (known: %s)", name, entry, strings.Join(permissionNames(), ", "))
+	}
+	if rule.Value, ok = contentValues[value]; !ok {
+		return rule, fmt.Errorf("permission %q must be allow, block or ask, got %q", name, value)
+	}
+	if rule.Pattern != "" && !strings.Contains(rule.Pattern, "://") {
+		return rule, fmt.Errorf("permission %q: %q is not a site such as https://app.example.com", entry, rule.Pattern)
+	}
+	return rule, nil
+}
+
+// validPermissions checks each of a profile's permissions entries
+func validPermissions(entries []string) error {
+	for _, entry := range entries {
+		if _, err := parsePermission(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedPermissions writes the profile's permission defaults and site
+// settings into the preferences of a data dir whose browser is closed.
+// Site settings the user made in the browser are kept, except for the
+// sites the profile sets itself.
+func seedPermissions(dataDir string, profile Profile) error {
+	rules := []permissionRule{}
+	for _, entry := range profile.Permissions {
+		if rule, err := parsePermission(entry); err == nil {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return updatePreferences(preferencesFile(dataDir), func(prefs map[string]interface{}) {
+		for _, rule := range rules {
+			if rule.Pattern == "" {
+				setPreference(prefs, []string{"profile", "default_content_setting_values", rule.Type}, rule.Value)
+				continue
+			}
+			// Exceptions are keyed by the site pattern and the embedder's
+			setPreference(prefs, []string{"profile", "content_settings", "exceptions", rule.Type, rule.Pattern + ",*"},
+				map[string]interface{}{"setting": rule.Value})
+		}
+	})
+}
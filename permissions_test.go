@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParsePermission(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    permissionRule
+		wantErr string
+	}{
+		{"notifications=block", permissionRule{Type: "notifications", Value: contentBlock}, ""},
+		{"camera=ask", permissionRule{Type: "media_stream_camera", Value: contentAsk}, ""},
+		{" clipboard=allow https://app.example.com ", permissionRule{Type: "clipboard", Value: contentAllow, Pattern: "https://app.example.com"}, ""},
+		{"notifications", permissionRule{}, "must be <name>=<allow|block|ask>"},
+		{"usb=allow", permissionRule{}, "unknown permission"},
+		{"camera=never", permissionRule{}, "must be allow, block or ask"},
+		{"camera=allow app.example.com", permissionRule{}, "is not a site"},
+	}
+	for _, tt := range tests {
+		rule, err := parsePermission(tt.entry)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parsePermission(%q) error = %v, want %q", tt.entry, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || rule != tt.want {
+			t.Errorf("parsePermission(%q) = %+v, %v, want %+v", tt.entry, rule, err, tt.want)
+		}
+	}
+}
+
+func TestSeedPermissions(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/profiles/apps/Default", 0755)
+	// A site setting made in the browser, and one the profile replaces
+	m.WriteFile(preferencesFile("/profiles/apps"), []byte(`{"profile":{"content_settings":{"exceptions":{"clipboard":{
+		"https://other.example.com,*":{"setting":1},
+		"https://app.example.com,*":{"setting":2}
+	}}}}}`), 0644)
+	profile := Profile{Name: "apps", Permissions: []string{"notifications=block", "clipboard=allow https://app.example.com"}}
+	if err := seedPermissions("/profiles/apps", profile); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := m.ReadFile(preferencesFile("/profiles/apps"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prefs struct {
+		Profile struct {
+			Defaults        map[string]int `json:"default_content_setting_values"`
+			ContentSettings struct {
+				Exceptions map[string]map[string]struct {
+					Setting int `json:"setting"`
+				} `json:"exceptions"`
+			} `json:"content_settings"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		t.Fatal(err)
+	}
+	if prefs.Profile.Defaults["notifications"] != contentBlock {
+		t.Errorf("notifications default is %d, want blocked", prefs.Profile.Defaults["notifications"])
+	}
+	clipboard := prefs.Profile.ContentSettings.Exceptions["clipboard"]
+	if clipboard["https://app.example.com,*"].Setting != contentAllow {
+		t.Errorf("the profile's site setting wasn't applied: %s", data)
+	}
+	if clipboard["https://other.example.com,*"].Setting != contentAllow {
+		t.Errorf("a site setting made in the browser was lost: %s", data)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Menu programs `launchium pick -menu` can run. Each reads the choices on
+// stdin and prints the selected one.
+var pickerMenus = map[string][]string{
+	"rofi":  {"rofi", "-dmenu", "-i", "-p", "launchium"},
+	"wofi":  {"wofi", "--dmenu", "--insensitive", "--prompt", "launchium"},
+	"dmenu": {"dmenu", "-i", "-p", "launchium"},
+	"fzf":   {"fzf", "--prompt", "launchium> "},
+}
+
+// pickerMenuNames returns the supported -menu values, sorted
+func pickerMenuNames() []string {
+	names := []string{}
+	for name := range pickerMenus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pickNames returns the profiles to offer in a picker, optionally limited
+// to a tag: most recently launched first, then the rest by name
+func (cm *ChromiumManager) pickNames(tag string) []string {
+	names := []string{}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		if tag == "" || cm.profiles[name].hasTag(tag) {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return cm.state.LastLaunch[names[i]].After(cm.state.LastLaunch[names[j]])
+	})
+	return names
+}
+
+// runMenu shows names in the menu program and returns the selection, or
+// "" if the menu was dismissed
+func runMenu(menu string, names []string) (string, error) {
+	args, ok := pickerMenus[menu]
+	if !ok {
+		return "", fmt.Errorf("unknown menu %q (expected %s)", menu, strings.Join(pickerMenuNames(), ", "))
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(strings.Join(names, "\n") + "\n")
+	cmd.Stderr = os.Stderr // fzf draws its UI here
+	out, err := cmd.Output()
+	if _, dismissed := err.(*exec.ExitError); dismissed {
+		// Menus exit non-zero when closed without a choice
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("running %s: %s", args[0], err)
+	}
+	return readSelection(strings.NewReader(string(out))), nil
+}
+
+// readSelection returns the first non-empty line a picker printed
+func readSelection(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line
+		}
+	}
+	return ""
+}
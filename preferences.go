@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// preferencesFile is the per-profile preferences Chromium keeps inside the
+// user-data-dir
+func preferencesFile(dataDir string) string {
+	return filepath.Join(dataDir, "Default", "Preferences")
+}
+
+// mergePreferences deep-merges values into the JSON file at path, creating
+// it if needed. Keys are dotted paths such as "browser.theme.user_color".
+// Existing unrelated settings are kept, so it is safe to run before every
+// launch while the browser is closed.
+func mergePreferences(path string, values map[string]interface{}) error {
+	return updatePreferences(path, func(prefs map[string]interface{}) {
+		for key, value := range values {
+			setPreference(prefs, strings.Split(key, "."), value)
+		}
+	})
+}
+
+// updatePreferences has update change the JSON file at path, creating it
+// if needed, for keys such as site patterns that have dots in them
+func updatePreferences(path string, update func(prefs map[string]interface{})) error {
+	prefs := map[string]interface{}{}
+	if data, err := fsys.ReadFile(path); err == nil {
+		if err := decodePreferences(data, &prefs); err != nil {
+			return fmt.Errorf("reading %s: %s", path, err)
+		}
+	}
+
+	update(prefs)
+
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFile(path, data, 0644)
+}
+
+// decodePreferences reads preferences JSON into v with its numbers as
+// json.Number. Chromium keeps int64 values such as timestamps in its
+// preferences, which a float64 would round when they are written back.
+func decodePreferences(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// setPreference assigns value at the nested key path, replacing any
+// non-object values in the way
+func setPreference(prefs map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := prefs[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			prefs[key] = next
+		}
+		prefs = next
+	}
+	prefs[path[len(path)-1]] = value
+}
+
+// parseHexColor converts "#rrggbb" or "#rgb" to its RGB value
+func parseHexColor(color string) (uint32, error) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 || !strings.HasPrefix(color, "#") {
+		return 0, fmt.Errorf("color must look like #rrggbb, got %q", color)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("color must look like #rrggbb, got %q", color)
+	}
+	return uint32(rgb), nil
+}
+
+// labelPreferences returns the preference values that theme the browser
+// with the profile's color label
+func labelPreferences(profile Profile) map[string]interface{} {
+	values := map[string]interface{}{}
+	if rgb, err := parseHexColor(profile.Color); err == nil {
+		// Chromium stores theme colors as a signed 32-bit ARGB SkColor
+		values["browser.theme.user_color"] = int32(0xFF000000 | rgb)
+		values["browser.theme.color_variant"] = 1 // tonal spot
+		values["browser.theme.is_grayscale"] = false
+		values["extensions.theme.id"] = ""
+		values["autogenerated.theme.color"] = int32(0xFF000000 | rgb)
+	}
+	return values
+}
+
+// windowName is the title label passed via --window-name
+func (p Profile) windowName() string {
+	if p.Icon != "" {
+		return p.Icon + " " + p.Name
+	}
+	return p.Name
+}
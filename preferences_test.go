@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMergePreferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // The Preferences file before, none if empty
+		values   map[string]interface{}
+		want     string
+	}{
+		{
+			name:   "new file",
+			values: map[string]interface{}{"browser.theme.color": 255},
+			want:   `{"browser":{"theme":{"color":255}}}`,
+		},
+		{
+			name:     "keeps other settings",
+			existing: `{"browser":{"window_placement":{"top":10}},"homepage":"about:blank"}`,
+			values:   map[string]interface{}{"browser.theme.color": 255},
+			want:     `{"browser":{"theme":{"color":255},"window_placement":{"top":10}},"homepage":"about:blank"}`,
+		},
+		{
+			name:     "keeps large numbers exact",
+			existing: `{"profile":{"created_by_version":"120.0","last_engagement_time":"13350000000000000"},"counter":9007199254740993}`,
+			values:   map[string]interface{}{"profile.name": "work"},
+			want:     `{"counter":9007199254740993,"profile":{"created_by_version":"120.0","last_engagement_time":"13350000000000000","name":"work"}}`,
+		},
+		{
+			name:     "replaces a value in the way",
+			existing: `{"browser":"old"}`,
+			values:   map[string]interface{}{"browser.theme.color": json.Number("16711680")},
+			want:     `{"browser":{"theme":{"color":16711680}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := useFakes(t)
+			path := preferencesFile("/profiles/work")
+			if tt.existing != "" {
+				if err := m.MkdirAll("/profiles/work/Default", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := m.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := mergePreferences(path, tt.values); err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Preferences are\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePreferencesRefusesBadJSON(t *testing.T) {
+	m, _ := useFakes(t)
+	path := preferencesFile("/profiles/work")
+	if err := m.MkdirAll("/profiles/work/Default", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteFile(path, []byte(`{"a":1} {"b":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := mergePreferences(path, map[string]interface{}{"c": 3})
+	if err == nil || !strings.Contains(err.Error(), "reading "+path) {
+		t.Errorf("got error %v, want one reading %s", err, path)
+	}
+}
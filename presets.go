@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flagPreset is a named bundle of browser flags that profiles can list in
+// presets instead of repeating the flags
+type flagPreset struct {
+	name        string
+	description string
+	flags       []string
+}
+
+// Presets shipped with launchium, in the order they are listed
+var flagPresets = []flagPreset{
+	{"privacy", "Turn off sync, pings and background requests to Google", []string{
+		"--disable-sync",
+		"--no-pings",
+		"--disable-background-networking",
+		"--disable-domain-reliability",
+		"--disable-features=MediaRouter,OptimizationHints",
+	}},
+	{"performance", "Keep background tabs and hidden windows running at full speed", []string{
+		"--disable-renderer-backgrounding",
+		"--disable-background-timer-throttling",
+		"--disable-backgrounding-occluded-windows",
+		"--enable-features=ParallelDownloading",
+	}},
+	{"low-memory", "Share renderer processes and keep fewer pages in memory", []string{
+		"--process-per-site",
+		"--renderer-process-limit=4",
+		"--enable-low-end-device-mode",
+		"--disable-features=BackForwardCache",
+	}},
+	{"debugging", "Open DevTools with every tab and allow remote debugging", []string{
+		"--auto-open-devtools-for-tabs",
+		"--remote-debugging-port=0", // The port is written to DevToolsActivePort in the data dir
+	}},
+	{"media-codecs", "Play media without a click and use hardware HEVC decoding where available", []string{
+		"--autoplay-policy=no-user-gesture-required",
+		"--enable-features=PlatformHEVCDecoderSupport",
+	}},
+}
+
+// findPreset returns the preset with the given name
+func findPreset(name string) (flagPreset, bool) {
+	for _, preset := range flagPresets {
+		if preset.name == name {
+			return preset, true
+		}
+	}
+	return flagPreset{}, false
+}
+
+// presetNames returns the names of the shipped presets
+func presetNames() []string {
+	names := make([]string, len(flagPresets))
+	for i, preset := range flagPresets {
+		names[i] = preset.name
+	}
+	return names
+}
+
+// validatePresets checks that every name is a known preset
+func validatePresets(names []string) error {
+	for _, name := range names {
+		if _, ok := findPreset(name); !ok {
+			return fmt.Errorf("unknown preset %q, expected one of %s", name, strings.Join(presetNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// launchFlags returns the flags the profile's browser is started with: the
+// flags of its presets in the order listed, then its own flags, so those
+// can override a preset's
+func (p Profile) launchFlags() []string {
+	flags := []string{}
+	for _, name := range p.Presets {
+		if preset, ok := findPreset(name); ok {
+			flags = append(flags, preset.flags...)
+		}
+	}
+	return append(flags, p.Flags.args()...)
+}
+
+// isFeatureList reports whether a switch takes a list of features, which
+// Chromium reads from only the last of them when repeated
+func isFeatureList(name string) bool {
+	return name == "--enable-features" || name == "--disable-features"
+}
+
+// joinFeatures adds the comma separated features of more to those of
+// features that aren't already there
+func joinFeatures(features, more string) string {
+	for _, feature := range strings.Split(more, ",") {
+		if feature == "" || containsString(strings.Split(features, ","), feature) {
+			continue
+		}
+		if features != "" {
+			features += ","
+		}
+		features += feature
+	}
+	return features
+}
+
+// composeFlags cleans up the browser's command line. Chromium only reads
+// the last of a repeated switch, so repeated --enable-features and
+// --disable-features switches are combined into one of each at the place
+// of the first, keeping the features set by presets and the profile, and
+// of any other switch only the last is kept.
+func composeFlags(args []string) []string {
+	merged := []string{}
+	at := map[string]int{}
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || !isFeatureList(name) {
+			merged = append(merged, arg)
+			continue
+		}
+		i, seen := at[name]
+		if !seen {
+			at[name] = len(merged)
+			merged = append(merged, arg)
+			continue
+		}
+		merged[i] = name + "=" + joinFeatures(strings.TrimPrefix(merged[i], name+"="), value)
+	}
+
+	composed := []string{}
+	seen := map[string]bool{}
+	for i := len(merged) - 1; i >= 0; i-- {
+		name, _, _ := strings.Cut(merged[i], "=")
+		if strings.HasPrefix(name, "--") {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+		}
+		composed = append([]string{merged[i]}, composed...)
+	}
+	return composed
+}
@@ -0,0 +1,48 @@
+package main
+
+import "strings"
+
+// Chromium's profile.cookie_controls_mode that blocks third-party cookies
+// everywhere rather than only in incognito windows
+const cookieControlsBlockThirdParty = 1
+
+// privacyPreferences returns the preference values of the profile's
+// privacy toggles. A toggle that is off leaves the browser's own setting.
+func privacyPreferences(profile Profile) map[string]interface{} {
+	values := map[string]interface{}{}
+	if profile.DoNotTrack {
+		values["enable_do_not_track"] = true
+	}
+	if profile.BlockThirdPartyCookies {
+		values["profile.cookie_controls_mode"] = cookieControlsBlockThirdParty
+		values["profile.block_third_party_cookies"] = true
+	}
+	if profile.DisableHyperlinkAuditing {
+		values["enable_a_ping"] = false
+	}
+	return values
+}
+
+// privacyFlags returns the switches of the profile's privacy toggles
+func (p Profile) privacyFlags() []string {
+	if p.DisableHyperlinkAuditing {
+		return []string{"--no-pings"}
+	}
+	return nil
+}
+
+// privacySummary describes the profile's privacy toggles for the detail
+// pane, "" when none is on
+func (p Profile) privacySummary() string {
+	on := []string{}
+	if p.DoNotTrack {
+		on = append(on, "do not track")
+	}
+	if p.BlockThirdPartyCookies {
+		on = append(on, "no third-party cookies")
+	}
+	if p.DisableHyperlinkAuditing {
+		on = append(on, "no pings")
+	}
+	return strings.Join(on, ", ")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrivacySummary(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		want    string
+	}{
+		{Profile{}, ""},
+		{Profile{DoNotTrack: true}, "do not track"},
+		{Profile{DoNotTrack: true, BlockThirdPartyCookies: true, DisableHyperlinkAuditing: true}, "do not track, no third-party cookies, no pings"},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.privacySummary(); got != tt.want {
+			t.Errorf("privacySummary() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLaunchAppliesPrivacyToggles(t *testing.T) {
+	const browser = "/usr/bin/chromium"
+	m, runner := useFakes(t)
+	runner.paths[browser] = true
+	root := filepath.Join(t.TempDir(), "fake", "profiles")
+	profile := Profile{Name: "private", Proxy: "none", ProxyType: "none", DoNotTrack: true, BlockThirdPartyCookies: true, DisableHyperlinkAuditing: true}
+	cm := &ChromiumManager{ctx: context.Background(), profileDir: root, profiles: map[string]Profile{"private": profile}}
+	if _, err := cm.startBrowserWith(context.Background(), profile, browser, nil); err != nil {
+		t.Fatal(err)
+	}
+	if args := runner.commands()[0]; !hasArg(args, "--no-pings") {
+		t.Errorf("command line %q lacks --no-pings", args)
+	}
+
+	data, err := m.ReadFile(preferencesFile(filepath.Join(root, "private")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prefs struct {
+		DoNotTrack bool  `json:"enable_do_not_track"`
+		APing      *bool `json:"enable_a_ping"`
+		Profile    struct {
+			CookieControls int  `json:"cookie_controls_mode"`
+			BlockCookies   bool `json:"block_third_party_cookies"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		t.Fatal(err)
+	}
+	if !prefs.DoNotTrack || prefs.APing == nil || *prefs.APing || prefs.Profile.CookieControls != cookieControlsBlockThirdParty || !prefs.Profile.BlockCookies {
+		t.Errorf("privacy preferences are %s", data)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runningPID reports whether a browser is using the given user-data-dir,
+// based on the lock Chromium keeps inside it. The PID is 0 when the lock
+// doesn't record one (Windows) or it belongs to another host.
+func runningPID(dataDir string) (int, bool) {
+	if runtime.GOOS == "windows" {
+		// Chromium holds "lockfile" open without sharing while it runs
+		lockFile := filepath.Join(dataDir, "lockfile")
+		if _, err := fsys.Stat(lockFile); err != nil {
+			return 0, false
+		}
+		f, err := fsys.OpenFile(lockFile, os.O_RDWR, 0)
+		if err != nil {
+			return 0, true
+		}
+		f.Close()
+		return 0, false
+	}
+
+	// On macOS and Linux SingletonLock is a symlink to "<hostname>-<pid>"
+	target, err := fsys.Readlink(filepath.Join(dataDir, "SingletonLock"))
+	if err != nil {
+		return 0, false
+	}
+	dash := strings.LastIndex(target, "-")
+	if dash < 0 {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(target[dash+1:])
+	if err != nil {
+		return 0, false
+	}
+
+	// A lock taken on another machine (e.g. a synced data dir) can't be
+	// checked, so treat it as held
+	if hostname, err := os.Hostname(); err == nil && target[:dash] != hostname {
+		return 0, true
+	}
+
+	if !procs.Alive(pid) {
+		return 0, false
+	}
+	return pid, true
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only succeeds on Windows if the process exists
+		proc.Release()
+		return true
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// procStat is what ps reports about one process
+type procStat struct {
+	ppid    int
+	rss     int64 // Resident memory in bytes
+	elapsed time.Duration
+}
+
+// processStats lists every process with its parent, memory and age. It
+// relies on ps and returns nil where that isn't available (Windows).
+func processStats() map[int]procStat {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=,etime=").Output()
+	if err != nil {
+		return nil
+	}
+
+	stats := map[int]procStat{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		rss, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		stats[pid] = procStat{ppid: ppid, rss: rss * 1024, elapsed: parseElapsed(fields[3])}
+	}
+	return stats
+}
+
+// parseElapsed reads ps's etime format, [[dd-]hh:]mm:ss
+func parseElapsed(etime string) time.Duration {
+	var days int
+	if dash := strings.Index(etime, "-"); dash >= 0 {
+		days, _ = strconv.Atoi(etime[:dash])
+		etime = etime[dash+1:]
+	}
+	var total time.Duration
+	for _, part := range strings.Split(etime, ":") {
+		n, _ := strconv.Atoi(part)
+		total = total*60 + time.Duration(n)
+	}
+	return total*time.Second + time.Duration(days)*24*time.Hour
+}
+
+// treeMemory sums the resident memory of pid and all its descendants,
+// since Chromium spreads a browser over many processes
+func treeMemory(stats map[int]procStat, pid int) int64 {
+	children := map[int][]int{}
+	for p, st := range stats {
+		children[st.ppid] = append(children[st.ppid], p)
+	}
+	var total int64
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		total += stats[p].rss
+		queue = append(queue, children[p]...)
+	}
+	return total
+}
+
+// terminateProcess asks a process to exit. Chromium saves its session on
+// SIGTERM; Windows has no equivalent, so the process is killed there.
+func terminateProcess(pid int) error {
+	if pid <= 0 {
+		return fmt.Errorf("the browser's PID is unknown")
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		return proc.Kill()
+	}
+	return proc.Signal(syscall.SIGTERM)
+}
+
+// focusWindow raises the windows of a browser: those of the process with
+// the given PID or, where the PID is unknown, the one whose title starts
+// with the profile's window name. It uses System Events on macOS, xdotool
+// or wmctrl on Linux, and WScript.Shell's AppActivate on Windows.
+func focusWindow(pid int, title string) error {
+	if pid <= 0 && (title == "" || runtime.GOOS == "darwin") {
+		return fmt.Errorf("the browser's PID is unknown")
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(`tell application "System Events" to set frontmost of (first process whose unix id is %d) to true`, pid)
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		var err error
+		if cmd, err = linuxFocusCommand(pid, title); err != nil {
+			return err
+		}
+	case "windows":
+		// AppActivate takes a process ID or the start of a window title
+		target := strconv.Itoa(pid)
+		if pid <= 0 {
+			target = "'" + strings.ReplaceAll(title, "'", "''") + "'"
+		}
+		script := fmt.Sprintf("if (-not (New-Object -ComObject WScript.Shell).AppActivate(%s)) { exit 1 }", target)
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default:
+		return fmt.Errorf("raising windows is not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		if _, exited := err.(*exec.ExitError); exited {
+			return fmt.Errorf("no window of the browser was found")
+		}
+		return err
+	}
+	return nil
+}
+
+// linuxFocusCommand returns the xdotool or, without it, wmctrl command
+// that raises a browser's windows
+func linuxFocusCommand(pid int, title string) (*exec.Cmd, error) {
+	if _, err := procs.LookPath("xdotool"); err == nil {
+		if pid > 0 {
+			return exec.Command("xdotool", "search", "--pid", strconv.Itoa(pid), "windowactivate"), nil
+		}
+		return exec.Command("xdotool", "search", "--name", "^"+regexp.QuoteMeta(title), "windowactivate"), nil
+	}
+	if _, err := procs.LookPath("wmctrl"); err != nil {
+		return nil, fmt.Errorf("raising windows needs xdotool or wmctrl")
+	}
+	if pid <= 0 {
+		return exec.Command("wmctrl", "-a", title), nil
+	}
+	// wmctrl -lp lists "<id> <desktop> <pid> <host> <title>"
+	out, err := exec.Command("wmctrl", "-lp").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing windows: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[2] == strconv.Itoa(pid) {
+			return exec.Command("wmctrl", "-i", "-a", fields[0]), nil
+		}
+	}
+	return nil, fmt.Errorf("no window of the browser was found")
+}
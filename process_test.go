@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunningPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows locks the data dir with a held file instead")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname")
+	}
+	tests := []struct {
+		name        string
+		lock        string // SingletonLock's target, none if empty
+		alive       bool
+		wantPID     int
+		wantRunning bool
+	}{
+		{"no lock", "", false, 0, false},
+		{"running here", hostname + "-4242", true, 4242, true},
+		{"stale lock", hostname + "-4242", false, 0, false},
+		{"another host", "elsewhere.example.com-4242", false, 0, true},
+		{"no pid", hostname, false, 0, false},
+		{"bad pid", hostname + "-abc", false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, runner := useFakes(t)
+			dataDir := "/profiles/work"
+			if err := m.MkdirAll(dataDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.lock != "" {
+				if err := m.Symlink(tt.lock, filepath.Join(dataDir, "SingletonLock")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			runner.alive[4242] = tt.alive
+			pid, running := runningPID(dataDir)
+			if pid != tt.wantPID || running != tt.wantRunning {
+				t.Errorf("runningPID() = %d, %t, want %d, %t", pid, running, tt.wantPID, tt.wantRunning)
+			}
+		})
+	}
+}
+
+func TestLinuxFocusCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		tools   []string
+		pid     int
+		want    []string
+		wantErr string
+	}{
+		{"xdotool by pid", []string{"xdotool", "wmctrl"}, 4242, []string{"xdotool", "search", "--pid", "4242", "windowactivate"}, ""},
+		{"xdotool by title", []string{"xdotool"}, 0, []string{"xdotool", "search", "--name", `^🔒 bank\.example`, "windowactivate"}, ""},
+		{"wmctrl by title", []string{"wmctrl"}, 0, []string{"wmctrl", "-a", "🔒 bank.example"}, ""},
+		{"neither", nil, 4242, nil, "needs xdotool or wmctrl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, runner := useFakes(t)
+			for _, tool := range tt.tools {
+				runner.paths[tool] = true
+			}
+			cmd, err := linuxFocusCommand(tt.pid, "🔒 bank.example")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("command is %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
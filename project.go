@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// projectConfigPath is where a project keeps its own profiles, relative to
+// the project's root directory
+var projectConfigPath = filepath.Join(".launchium", "profiles.toml")
+
+// Project profiles are named "<project>.<name>" after the directory holding
+// .launchium
+const projectSeparator = "."
+
+// findProjectConfig looks for a project config in dir and its parents
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, projectConfigPath)
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadProjectProfiles adds the profiles of the project the working
+// directory is in, prefixed with the project's name. A project can't pick
+// the browser binary or the user it runs as, since its config comes with
+// the repository rather than from the user. It returns what the file had
+// that this version doesn't know.
+func (cm *ChromiumManager) loadProjectProfiles(profiles map[string]Profile) ([]string, error) {
+	cm.projectFile = ""
+	cm.projectProfiles = map[string]Profile{}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil
+	}
+	path := findProjectConfig(wd)
+	if path == "" || path == cm.configFile {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, settings, err := parseTables(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if len(settings.fields()) > 0 || len(settings.Keys) > 0 || len(settings.Themes) > 0 || len(settings.Webhooks) > 0 {
+		return nil, fmt.Errorf("%s: only [profiles.<name>] tables belong in a project config", path)
+	}
+
+	root := filepath.Dir(filepath.Dir(path))
+	prefix := filepath.Base(root) + projectSeparator
+	for name, profile := range project {
+		if profile.Browser != "" || profile.RunAs != "" {
+			return nil, fmt.Errorf("%s: profile '%s' can't set browser or run_as in a project config", path, name)
+		}
+		profile.Name = prefix + name
+		if _, ok := profiles[profile.Name]; ok {
+			return nil, fmt.Errorf("%s: profile '%s' is already defined", path, profile.Name)
+		}
+		// Relative data dirs are inside the project
+		if profile.DataDir != "" && !filepath.IsAbs(profile.DataDir) && profile.DataDir[0] != '~' {
+			profile.DataDir = filepath.Join(root, profile.DataDir)
+		}
+		profiles[profile.Name] = profile
+		cm.projectProfiles[profile.Name] = profile
+	}
+	cm.projectFile = path
+
+	unknown := []string{}
+	for _, u := range settings.Unknown {
+		unknown = append(unknown, path+" "+u)
+	}
+	return unknown, nil
+}
+
+// checkProjectProfiles refuses to save changes to project profiles, which
+// belong to the project's repository
+func (cm *ChromiumManager) checkProjectProfiles() error {
+	for name, loaded := range cm.projectProfiles {
+		if profile, ok := cm.profiles[name]; !ok || !reflect.DeepEqual(profile, loaded) {
+			return fmt.Errorf("profile '%s' comes from %s; change it there", name, cm.projectFile)
+		}
+	}
+	return nil
+}
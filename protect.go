@@ -0,0 +1,62 @@
+package main
+
+import "strings"
+
+// errProtected reports that a protected profile was left alone. how says
+// what overrides the protection where the request came from.
+func errProtected(name, action, how string) error {
+	return errorOf(ErrProtected, "profile '%s' is protected; %s to %s it anyway", name, how, action)
+}
+
+// checkProtected refuses to remove, clean or overwrite a protected profile
+// unless the command line was given -force
+func (cm *ChromiumManager) checkProtected(name, action string) error {
+	if cm.profiles[name].Protected && !cm.unprotect {
+		return errProtected(name, action, "pass -force")
+	}
+	return nil
+}
+
+// protectedNames returns the protected profiles among names
+func (cm *ChromiumManager) protectedNames(names []string) []string {
+	protected := []string{}
+	for _, name := range names {
+		if cm.profiles[name].Protected {
+			protected = append(protected, name)
+		}
+	}
+	return protected
+}
+
+// confirmingNames returns the profiles a delete or clean confirmation is for
+func (cm *ChromiumManager) confirmingNames() []string {
+	if len(cm.bulkNames) > 0 {
+		return cm.bulkNames
+	}
+	return []string{cm.selected}
+}
+
+// confirmProtected is called on the y of a delete or clean confirmation.
+// When protected profiles are among those confirmed, the first y only asks
+// again, and it returns false.
+func (cm *ChromiumManager) confirmProtected() bool {
+	if cm.protectedAsked || len(cm.protectedNames(cm.confirmingNames())) == 0 {
+		cm.protectedAsked = false
+		return true
+	}
+	cm.protectedAsked = true
+	return false
+}
+
+// protectedWarning is shown under a delete or clean confirmation once the
+// first y was pressed for protected profiles
+func (cm *ChromiumManager) protectedWarning(action string) string {
+	if !cm.protectedAsked {
+		return ""
+	}
+	protected := cm.protectedNames(cm.confirmingNames())
+	if len(protected) == 1 {
+		return "\n\n" + errStyle.Render("'"+protected[0]+"' is protected.") + " Press y again to " + action + " it anyway, n to cancel."
+	}
+	return "\n\n" + errStyle.Render("'"+strings.Join(protected, "', '")+"' are protected.") + " Press y again to " + action + " them anyway, n to cancel."
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckProtected(t *testing.T) {
+	cm := &ChromiumManager{profiles: map[string]Profile{
+		"bank": {Name: "bank", Protected: true},
+		"test": {Name: "test"},
+	}}
+	err := cm.checkProtected("bank", "clean")
+	if !errors.Is(err, ErrProtected) || !strings.Contains(err.Error(), "pass -force to clean it anyway") {
+		t.Errorf("cleaning a protected profile gave %v", err)
+	}
+	if exitCode(err) != exitProtected {
+		t.Errorf("exit code is %d, want %d", exitCode(err), exitProtected)
+	}
+	if err := cm.checkProtected("test", "clean"); err != nil {
+		t.Errorf("cleaning an unprotected profile gave %v", err)
+	}
+	cm.unprotect = true
+	if err := cm.checkProtected("bank", "remove"); err != nil {
+		t.Errorf("-force didn't override the protection: %v", err)
+	}
+}
+
+func TestConfirmProtected(t *testing.T) {
+	cm := &ChromiumManager{profiles: map[string]Profile{
+		"bank": {Name: "bank", Protected: true},
+		"mail": {Name: "mail", Protected: true},
+		"test": {Name: "test"},
+	}}
+
+	cm.selected = "test"
+	if !cm.confirmProtected() || cm.protectedWarning("delete") != "" {
+		t.Error("an unprotected profile needed a second confirmation")
+	}
+
+	cm.bulkNames = []string{"bank", "test", "mail"}
+	if got := cm.protectedNames(cm.confirmingNames()); !reflect.DeepEqual(got, []string{"bank", "mail"}) {
+		t.Errorf("protected names are %q", got)
+	}
+	if cm.confirmProtected() {
+		t.Fatal("the first y deleted protected profiles")
+	}
+	if warning := cm.protectedWarning("delete"); !strings.Contains(warning, "are protected") || !strings.Contains(warning, "delete them anyway") {
+		t.Errorf("warning is %q", warning)
+	}
+	if !cm.confirmProtected() {
+		t.Error("the second y didn't confirm")
+	}
+	if cm.protectedAsked {
+		t.Error("the second confirmation carried over to the next one")
+	}
+}
+
+func TestImportKeepsProtectedProfiles(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := exchangeManager(m)
+	work := cm.profiles["work"]
+	work.Protected = true
+	cm.profiles["work"] = work
+
+	changes, err := cm.importProfiles([]byte("profiles:\n  play:\n    description: Games\n"), importReplace, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.profiles["work"]; !ok || !containsString(changes, "kept work: it is protected (-force to remove it)") {
+		t.Errorf("replace removed a protected profile: %q", changes)
+	}
+
+	cm.unprotect = true
+	if _, err := cm.importProfiles([]byte("profiles:\n  play:\n    description: Games\n"), importReplace, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.profiles["work"]; ok {
+		t.Error("-force didn't let replace remove a protected profile")
+	}
+}
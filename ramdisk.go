@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RAM disk modes for Profile.RAMDisk
+const (
+	ramDiskOff     = ""
+	ramDiskDiscard = "discard" // start empty, throw the data away on exit
+	ramDiskPersist = "persist" // seed from disk, copy back on exit
+)
+
+// macOS RAM disk volume created on demand (1 GiB in 512-byte sectors)
+const (
+	macRAMDiskName    = "LaunchiumRAM"
+	macRAMDiskSectors = 2097152
+)
+
+// ramSessions tracks browsers running from a RAM disk so the process can
+// wait for them to exit before it quits and the data is lost
+var (
+	ramSessions     sync.WaitGroup
+	ramSessionCount int32
+)
+
+// ramDiskMu keeps parallel launches from each creating a macOS RAM disk
+var ramDiskMu sync.Mutex
+
+// validRAMDiskMode reports whether mode is one of the known RAM disk modes
+func validRAMDiskMode(mode string) bool {
+	return mode == ramDiskOff || mode == ramDiskDiscard || mode == ramDiskPersist
+}
+
+// ramDiskRoot returns a RAM-backed directory to hold user-data-dirs,
+// creating the RAM disk first on macOS
+func ramDiskRoot() (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+			return "/dev/shm", nil
+		}
+		return "", fmt.Errorf("/dev/shm is not available")
+
+	case "darwin":
+		ramDiskMu.Lock()
+		defer ramDiskMu.Unlock()
+		volume := filepath.Join("/Volumes", macRAMDiskName)
+		if _, err := os.Stat(volume); err == nil {
+			return volume, nil
+		}
+		out, err := exec.Command("hdiutil", "attach", "-nomount", fmt.Sprintf("ram://%d", macRAMDiskSectors)).Output()
+		if err != nil {
+			return "", fmt.Errorf("creating RAM disk: %w", err)
+		}
+		device := strings.TrimSpace(string(out))
+		if err := exec.Command("diskutil", "erasevolume", "HFS+", macRAMDiskName, device).Run(); err != nil {
+			// Unformatted, nothing will use it or detach it later
+			exec.Command("hdiutil", "detach", device).Run()
+			return "", fmt.Errorf("formatting RAM disk: %w", err)
+		}
+		return volume, nil
+	}
+
+	return "", fmt.Errorf("RAM disk mode is not supported on %s", runtime.GOOS)
+}
+
+// releaseRAMDisk detaches the macOS RAM disk, giving its memory back, once
+// no profile's RAM copy is left on it, including other launchium
+// processes'. A disk still in use stays attached.
+func releaseRAMDisk() {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+	ramDiskMu.Lock()
+	defer ramDiskMu.Unlock()
+	volume := filepath.Join("/Volumes", macRAMDiskName)
+	entries, err := os.ReadDir(volume)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ramDirName("")) {
+			return
+		}
+	}
+	exec.Command("hdiutil", "detach", volume).Run()
+}
+
+// ramDirName is the directory a profile's RAM copy gets below the RAM disk
+func ramDirName(name string) string {
+	return "launchium-" + name
+}
+
+// ramDiskPath returns where the profile's RAM copy lives while it runs,
+// without creating the RAM disk. It is "" where RAM disks are unsupported.
+func ramDiskPath(name string) string {
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join("/dev/shm", ramDirName(name))
+	case "darwin":
+		return filepath.Join("/Volumes", macRAMDiskName, ramDirName(name))
+	}
+	return ""
+}
+
+// prepareRAMDisk creates the RAM copy of a profile's data dir and returns
+// its path. In persist mode the on-disk data is copied in first.
+func (cm *ChromiumManager) prepareRAMDisk(profile Profile) (string, error) {
+	root, err := ramDiskRoot()
+	if err != nil {
+		return "", err
+	}
+
+	ramPath := filepath.Join(root, ramDirName(profile.Name))
+	if err := os.RemoveAll(ramPath); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(ramPath, 0700); err != nil {
+		return "", err
+	}
+
+	if profile.RAMDisk == ramDiskPersist {
+		diskPath := cm.profilePath(profile)
+		if _, err := os.Stat(diskPath); err == nil {
+			if err := copyDir(diskPath, ramPath); err != nil {
+				return "", fmt.Errorf("copying profile to RAM disk: %w", err)
+			}
+		}
+	}
+
+	return ramPath, nil
+}
+
+// watchRAMSession waits for the browser to exit in the background, then
+// persists or discards the RAM copy according to the profile's mode
+func (cm *ChromiumManager) watchRAMSession(exit *processExit, profile Profile, ramPath string) {
+	ramSessions.Add(1)
+	atomic.AddInt32(&ramSessionCount, 1)
+	go func() {
+		defer ramSessions.Done()
+		defer atomic.AddInt32(&ramSessionCount, -1)
+		<-exit.done
+		if err := cm.finishRAMSession(profile, ramPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving RAM disk for profile '%s': %s\n", profile.Name, err)
+		}
+	}()
+}
+
+// waitForRAMSessions blocks until every RAM disk browser has exited and its
+// data has been saved or discarded
+func waitForRAMSessions() {
+	if atomic.LoadInt32(&ramSessionCount) > 0 {
+		fmt.Println("Waiting for RAM disk browser sessions to exit...")
+	}
+	ramSessions.Wait()
+}
+
+// finishRAMSession copies a persist-mode session back to disk and removes
+// the RAM copy, then the macOS RAM disk if it was the last one
+func (cm *ChromiumManager) finishRAMSession(profile Profile, ramPath string) error {
+	defer releaseRAMDisk()
+	// Files a run_as browser wrote belong to its user
+	if err := reclaimDataDir(ramPath); err != nil {
+		return err
+	}
+	defer os.RemoveAll(ramPath)
+
+	if profile.RAMDisk != ramDiskPersist {
+		return nil
+	}
+
+	// Copy into a sibling directory first so a failed copy never leaves a
+	// half-written profile behind
+	diskPath := cm.profilePath(profile)
+	staging := diskPath + ".ramdisk-tmp"
+	os.RemoveAll(staging)
+	if err := copyDir(ramPath, staging); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	if err := os.RemoveAll(diskPath); err != nil {
+		return err
+	}
+	return os.Rename(staging, diskPath)
+}
+
+// copyDir recursively copies src into dst, preserving symlinks and modes.
+// Chromium's Singleton* lock files are skipped.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), "Singleton") {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
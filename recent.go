@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// How many recently launched profiles the main menu offers, unless the
+// recent_profiles setting says otherwise
+const (
+	defaultRecentProfiles = 3
+	maxRecentProfiles     = 5
+)
+
+// recentCount returns how many recent profiles to show, 0 when they are
+// turned off
+func (s Settings) recentCount() int {
+	switch {
+	case s.RecentProfiles < 0:
+		return 0
+	case s.RecentProfiles == 0:
+		return defaultRecentProfiles
+	}
+	return s.RecentProfiles
+}
+
+// validRecentProfiles checks the recent_profiles setting
+func validRecentProfiles(n int) error {
+	if n < 0 || n > maxRecentProfiles {
+		return fmt.Errorf("recent_profiles must be between 0 and %d, got %d", maxRecentProfiles, n)
+	}
+	return nil
+}
+
+// recentProfiles returns the profiles launched most recently, newest
+// first. Archived profiles are left out since they can't be launched.
+func (cm *ChromiumManager) recentProfiles() []string {
+	names := []string{}
+	for name := range cm.state.LastLaunch {
+		if _, ok := cm.profiles[name]; ok && !cm.isArchived(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return cm.state.LastLaunch[names[i]].After(cm.state.LastLaunch[names[j]])
+	})
+	if n := cm.settings.recentCount(); len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+// recentItems are the main menu entries that launch the recent profiles
+func (cm *ChromiumManager) recentItems() []list.Item {
+	items := []list.Item{}
+	for _, name := range cm.recentProfiles() {
+		profile := cm.profiles[name]
+		desc := "Recently launched"
+		if summary := profile.summary(); summary != "" {
+			desc += " • " + summary
+		}
+		items = append(items, item{title: name, desc: desc, label: profile.label(), slot: profile.Slot, profile: name})
+	}
+	return items
+}
+
+// refreshRecent updates the main menu's recent profiles after launches or
+// profile changes, keeping the highlighted entry where it was
+func (cm *ChromiumManager) refreshRecent() {
+	items := cm.mainItems()
+	current := cm.mainList.Items()
+	changed := len(items) != len(current)
+	for i := 0; !changed && i < len(items); i++ {
+		changed = items[i] != current[i]
+	}
+	if !changed {
+		return
+	}
+	selected, _ := cm.mainList.SelectedItem().(item)
+	cm.mainList.SetItems(items)
+	for i, it := range items {
+		if it.(item) == selected {
+			cm.mainList.Select(i)
+			break
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecentProfiles(t *testing.T) {
+	now := time.Now()
+	cm := &ChromiumManager{profiles: map[string]Profile{}}
+	for _, name := range []string{"a", "b", "c", "d", "old"} {
+		cm.profiles[name] = Profile{Name: name}
+	}
+	cm.state.LastLaunch = map[string]time.Time{
+		"a":       now.Add(-4 * time.Hour),
+		"b":       now.Add(-1 * time.Hour),
+		"c":       now.Add(-3 * time.Hour),
+		"d":       now.Add(-2 * time.Hour),
+		"old":     now,
+		"removed": now,
+	}
+	cm.state.Archived = map[string]time.Time{"old": now}
+
+	tests := []struct {
+		setting int
+		want    []string
+	}{
+		{0, []string{"b", "d", "c"}},
+		{1, []string{"b"}},
+		{5, []string{"b", "d", "c", "a"}},
+		{-1, []string{}},
+	}
+	for _, tt := range tests {
+		cm.settings.RecentProfiles = tt.setting
+		if got := cm.recentProfiles(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("recent_profiles %d: got %q, want %q", tt.setting, got, tt.want)
+		}
+	}
+}
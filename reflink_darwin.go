@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// reflinkFile makes dst a copy-on-write clone of src with clonefile(2),
+// which APFS supports
+func reflinkFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
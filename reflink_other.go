@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// reflinkFile would clone a file copy-on-write; there is no support for
+// it here, so files are copied
+func reflinkFile(src, dst string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// instancesFileName is the registry of the browsers launchium started,
+// kept next to the config like the state file
+const instancesFileName = "instances.json"
+
+// How long a registered browser that hasn't taken the data dir's lock yet
+// is taken to be starting rather than gone
+const registryGrace = 30 * time.Second
+
+// instanceRecord is a browser in the registry. Running browsers are found
+// by the lock in their data dir; the registry adds the data dirs launchium
+// wouldn't otherwise look in, such as isolated copies, and what the lock
+// doesn't tell, such as the PID on Windows and the start time.
+type instanceRecord struct {
+	Profile      string    `json:"profile"`
+	DataDir      string    `json:"data_dir"`
+	PID          int       `json:"pid,omitempty"`
+	DevToolsPort int       `json:"devtools_port,omitempty"`
+	Started      time.Time `json:"started"`
+}
+
+// The registry file of the config in use, set once the config is known,
+// and the lock for reading and rewriting it from this process
+var (
+	instancesPath string
+	instancesMu   sync.Mutex
+)
+
+// instancesFile returns the path of the instance registry for the config
+func (cm *ChromiumManager) instancesFile() string {
+	return filepath.Join(filepath.Dir(cm.configFile), instancesFileName)
+}
+
+// registeredInstances reads the registry, keeping only the browsers that
+// are still running: those whose data dir is locked, or that were started
+// moments ago and are still taking the lock. The file is rewritten when
+// records are dropped or learn their PID or DevTools port.
+func registeredInstances() []instanceRecord {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	records, changed := validInstances(readInstances())
+	if changed {
+		writeInstances(records)
+	}
+	return records
+}
+
+// registerInstance adds a launched browser to the registry, in place of
+// any earlier one using the same data dir
+func registerInstance(record instanceRecord) {
+	if instancesPath == "" {
+		return
+	}
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	records, _ := validInstances(readInstances())
+	kept := []instanceRecord{}
+	for _, r := range records {
+		if r.DataDir != record.DataDir {
+			kept = append(kept, r)
+		}
+	}
+	writeInstances(append(kept, record))
+}
+
+// readInstances reads the registry file, empty if it is missing or
+// unreadable
+func readInstances() []instanceRecord {
+	records := []instanceRecord{}
+	if instancesPath == "" {
+		return records
+	}
+	data, err := fsys.ReadFile(instancesPath)
+	if err != nil {
+		return records
+	}
+	json.Unmarshal(data, &records)
+	return records
+}
+
+// writeInstances replaces the registry file, or removes it when nothing
+// is running
+func writeInstances(records []instanceRecord) {
+	if len(records) == 0 {
+		fsys.Remove(instancesPath)
+		return
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Started.Before(records[j].Started) })
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return
+	}
+	// Write beside it and rename, so another launchium never reads half
+	tmp := instancesPath + ".tmp"
+	if err := fsys.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	fsys.Rename(tmp, instancesPath)
+}
+
+// validInstances drops the records of browsers that have exited and fills
+// in the PIDs and DevTools ports that have become known. changed is set
+// if any record was dropped or updated.
+func validInstances(records []instanceRecord) (valid []instanceRecord, changed bool) {
+	valid = []instanceRecord{}
+	for _, r := range records {
+		pid, locked := runningPID(r.DataDir)
+		switch {
+		case locked && pid > 0 && pid != r.PID:
+			r.PID, changed = pid, true
+		case locked && pid == 0 && r.PID > 0 && !procs.Alive(r.PID):
+			// A launcher that has exited; the lock doesn't say more
+			r.PID, changed = 0, true
+		case !locked && (time.Since(r.Started) > registryGrace || !procs.Alive(r.PID)):
+			changed = true
+			continue
+		}
+		if r.DevToolsPort == 0 && locked {
+			if port, ok := devToolsPort(r.DataDir); ok {
+				r.DevToolsPort, changed = port, true
+			}
+		}
+		valid = append(valid, r)
+	}
+	return valid, changed
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// useRegistry points the instance registry at the fake filesystem
+func useRegistry(t *testing.T, m *memFS) {
+	old := instancesPath
+	t.Cleanup(func() { instancesPath = old })
+	m.MkdirAll("/config", 0755)
+	instancesPath = "/config/" + instancesFileName
+}
+
+func TestInstanceRegistry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows locks the data dir with a held file instead")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname")
+	}
+	m, runner := useFakes(t)
+	useRegistry(t, m)
+
+	// A browser running from an isolated copy, holding its lock
+	running := "/profiles/work" + isolatedPrefix + "1"
+	m.MkdirAll(running, 0755)
+	m.Symlink(hostname+"-4242", filepath.Join(running, "SingletonLock"))
+	runner.alive[4242] = true
+	registerInstance(instanceRecord{Profile: "work", DataDir: running, Started: time.Now()})
+	// One that exited long ago, and one still starting up
+	registerInstance(instanceRecord{Profile: "old", DataDir: "/profiles/old", PID: 1111, Started: time.Now().Add(-time.Hour)})
+	registerInstance(instanceRecord{Profile: "new", DataDir: "/profiles/new", PID: 2222, Started: time.Now()})
+	runner.alive[2222] = true
+
+	records := registeredInstances()
+	if len(records) != 2 || records[0].DataDir != running || records[1].Profile != "new" {
+		t.Fatalf("registry holds %+v", records)
+	}
+	if records[0].PID != 4242 {
+		t.Errorf("the registry didn't learn the PID from the lock: %d", records[0].PID)
+	}
+
+	// Relaunching into a data dir replaces its record
+	registerInstance(instanceRecord{Profile: "new", DataDir: "/profiles/new", PID: 3333, Started: time.Now()})
+	runner.alive[3333] = true
+	if records := registeredInstances(); len(records) != 2 || records[1].PID != 3333 {
+		t.Errorf("after relaunching the registry holds %+v", records)
+	}
+
+	// Once nothing runs, the file goes
+	runner.alive = map[int]bool{}
+	if records := registeredInstances(); len(records) != 0 {
+		t.Errorf("exited browsers are still registered: %+v", records)
+	}
+	if _, err := m.Stat(instancesPath); !os.IsNotExist(err) {
+		t.Errorf("the registry file is still there: %v", err)
+	}
+}
+
+func TestInstanceCopy(t *testing.T) {
+	cm := &ChromiumManager{profileDir: "/profiles", profiles: map[string]Profile{"work": {Name: "work"}}}
+	tests := []struct {
+		dataDir string
+		want    string
+	}{
+		{"/profiles/work", ""},
+		{"/profiles/work" + isolatedPrefix + "123", "isolated copy"},
+		{"/dev/shm/launchium/work", "RAM disk"},
+	}
+	for _, tt := range tests {
+		if got := cm.instanceCopy(instance{name: "work", dataDir: filepath.FromSlash(tt.dataDir)}); got != tt.want {
+			t.Errorf("instanceCopy(%s) = %q, want %q", tt.dataDir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configKeyEnvVar names the environment variable holding the base64
+// ed25519 public key a remote config must be signed with
+const configKeyEnvVar = "LAUNCHIUM_CONFIG_KEY"
+
+// Files kept next to the cached copy of a remote config
+const (
+	remoteDirName   = "remote"
+	remoteETagFile  = "etag"
+	remoteKeyFile   = "key"
+	remoteRepoDir   = "repo"
+	remoteSigSuffix = ".sig"
+)
+
+// Remote configs are fetched with this timeout, and servers check for a
+// new version when they reload the config after remoteRefreshInterval
+const (
+	remoteFetchTimeout    = 10 * time.Second
+	remoteRefreshInterval = 15 * time.Minute
+)
+
+// isRemoteConfig reports whether a -config value is a URL to fetch the
+// config from rather than a file. Plain http:// is recognized only to be
+// refused by checkConfigSource.
+func isRemoteConfig(path string) bool {
+	for _, prefix := range []string{"https://", "http://", "s3://", "git+"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigSource refuses sources fetched without TLS. The config picks
+// the browser binary, its flags and the user it runs as, so anyone on the
+// network path could swap it and run their own code; a signature key set
+// only later wouldn't stop the first fetch. A copy cached from such a
+// source isn't used either.
+func checkConfigSource(source string) error {
+	for _, prefix := range []string{"http://", "git+http://", "git+git://"} {
+		if strings.HasPrefix(strings.ToLower(source), prefix) {
+			return fmt.Errorf("refusing the config at %s, which would be fetched without TLS; use https:// or git+ssh://", source)
+		}
+	}
+	return nil
+}
+
+// remoteCacheDir is where the copy of the config fetched from source is
+// kept, along with the state file and sockets that normally sit next to
+// the config. Each source gets its own directory.
+func remoteCacheDir(profileDir, source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(profileDir, remoteDirName, hex.EncodeToString(sum[:6]))
+}
+
+// remoteFetch is what a source returned: the config, its signature if
+// there is one, and a tag that changes whenever the config does
+type remoteFetch struct {
+	data      []byte
+	signature []byte
+	etag      string
+	unchanged bool // The cached copy is current
+}
+
+// refreshConfig fetches the remote config into the cache if it changed,
+// after checking its signature, and reports whether it did. Canceling ctx
+// abandons the fetch and keeps the cached copy.
+func (cm *ChromiumManager) refreshConfig(ctx context.Context) (bool, error) {
+	if err := checkConfigSource(cm.configSource); err != nil {
+		return false, err
+	}
+	dir := filepath.Dir(cm.configFile)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return false, err
+	}
+	cm.remoteChecked = time.Now()
+
+	etag := ""
+	if _, err := os.Stat(cm.configFile); err == nil {
+		data, _ := ioutil.ReadFile(filepath.Join(dir, remoteETagFile))
+		etag = strings.TrimSpace(string(data))
+	}
+	key, err := remoteConfigKey(dir)
+	if err != nil {
+		return false, err
+	}
+	pinned, _ := ioutil.ReadFile(filepath.Join(dir, remoteKeyFile))
+	if key != nil && strings.TrimSpace(string(pinned)) != base64.StdEncoding.EncodeToString(key) {
+		etag = "" // The cached copy hasn't been checked against this key
+	}
+
+	var fetched remoteFetch
+	switch {
+	case strings.HasPrefix(cm.configSource, "git+"):
+		fetched, err = fetchGitConfig(ctx, strings.TrimPrefix(cm.configSource, "git+"), filepath.Join(dir, remoteRepoDir), etag)
+	case strings.HasPrefix(cm.configSource, "s3://"):
+		fetched, err = fetchHTTPConfig(ctx, s3URL(cm.configSource), etag, key != nil)
+	default:
+		fetched, err = fetchHTTPConfig(ctx, cm.configSource, etag, key != nil)
+	}
+	if err != nil {
+		return false, err
+	}
+	if fetched.unchanged {
+		return false, nil
+	}
+
+	if key != nil {
+		if err := verifyConfig(fetched.data, fetched.signature, key); err != nil {
+			return false, err
+		}
+		// Later refreshes need the same key even when started without it
+		ioutil.WriteFile(filepath.Join(dir, remoteKeyFile), []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+	}
+	if _, _, err := parseConfig(fetched.data); err != nil {
+		return false, fmt.Errorf("fetched config: %w", err)
+	}
+
+	staging := cm.configFile + ".new"
+	if err := ioutil.WriteFile(staging, fetched.data, 0644); err != nil {
+		return false, err
+	}
+	if err := os.Rename(staging, cm.configFile); err != nil {
+		os.Remove(staging)
+		return false, err
+	}
+	ioutil.WriteFile(filepath.Join(dir, remoteETagFile), []byte(fetched.etag+"\n"), 0644)
+	return true, nil
+}
+
+// remoteConfigKey returns the public key from LAUNCHIUM_CONFIG_KEY, or the
+// one remembered from an earlier signed fetch, or nil when configs from
+// this source aren't signed
+func remoteConfigKey(dir string) (ed25519.PublicKey, error) {
+	encoded := os.Getenv(configKeyEnvVar)
+	if encoded == "" {
+		data, err := ioutil.ReadFile(filepath.Join(dir, remoteKeyFile))
+		if err != nil {
+			return nil, nil
+		}
+		encoded = string(data)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%s must be a base64 ed25519 public key", configKeyEnvVar)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// verifyConfig checks an ed25519 signature of the config, given either as
+// the raw 64 bytes openssl writes or in base64
+func verifyConfig(data, signature []byte, key ed25519.PublicKey) error {
+	if len(signature) == 0 {
+		return fmt.Errorf("the config is not signed")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("unreadable config signature")
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("the config's signature doesn't match %s", configKeyEnvVar)
+	}
+	return nil
+}
+
+// s3URL turns s3://bucket/key into the bucket's HTTPS address. Only
+// public objects can be fetched this way; use a presigned https:// URL
+// for private ones.
+func s3URL(source string) string {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(source, "s3://"), "/")
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key)
+}
+
+// fetchHTTPConfig downloads the config unless the server says it still
+// has etag. The signature is fetched from the same URL with .sig added
+// to the path.
+func fetchHTTPConfig(ctx context.Context, source, etag string, signed bool) (remoteFetch, error) {
+	client := &http.Client{Timeout: remoteFetchTimeout}
+	get := func(rawURL, etag string) (*http.Response, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("User-Agent", "launchium/"+version)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			body, err := ioutil.ReadAll(resp.Body)
+			return resp, body, err
+		case http.StatusNotModified:
+			return resp, nil, nil
+		}
+		return nil, nil, fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+
+	resp, data, err := get(source, etag)
+	if err != nil {
+		return remoteFetch{}, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return remoteFetch{unchanged: true}, nil
+	}
+	fetched := remoteFetch{data: data, etag: resp.Header.Get("ETag")}
+	if signed {
+		u, err := url.Parse(source)
+		if err != nil {
+			return remoteFetch{}, err
+		}
+		u.Path += remoteSigSuffix
+		if _, fetched.signature, err = get(u.String(), ""); err != nil {
+			return remoteFetch{}, fmt.Errorf("fetching signature: %w", err)
+		}
+	}
+	return fetched, nil
+}
+
+// fetchGitConfig updates a shallow clone of the repository and reads the
+// config from it. The source is a git URL with the file's path in the
+// fragment, profiles.toml at the top of the repository by default. The
+// commit is the tag, and the signature is the file with .sig added.
+func fetchGitConfig(ctx context.Context, source, repo, etag string) (remoteFetch, error) {
+	repoURL, file, _ := strings.Cut(source, "#")
+	if file == "" {
+		file = configFileName
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return remoteFetch{}, fmt.Errorf("fetching a config from git needs git")
+	}
+
+	git := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+		if err != nil && ctx.Err() != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctx.Err())
+		}
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git")); err != nil {
+		os.RemoveAll(repo)
+		if _, err := git("clone", "--quiet", "--depth", "1", repoURL, repo); err != nil {
+			return remoteFetch{}, err
+		}
+	} else {
+		if _, err := git("-C", repo, "fetch", "--quiet", "--depth", "1", repoURL); err != nil {
+			return remoteFetch{}, err
+		}
+		if _, err := git("-C", repo, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return remoteFetch{}, err
+		}
+	}
+	head, err := git("-C", repo, "rev-parse", "HEAD")
+	if err != nil {
+		return remoteFetch{}, err
+	}
+	if head == etag {
+		return remoteFetch{unchanged: true}, nil
+	}
+
+	path := filepath.Join(repo, filepath.FromSlash(file))
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return remoteFetch{}, fmt.Errorf("%s is not in the repository", file)
+	}
+	signature, _ := ioutil.ReadFile(path + remoteSigSuffix)
+	return remoteFetch{data: data, signature: signature, etag: head}, nil
+}
+
+// refreshIfStale checks the remote config for a new version when it was
+// last checked over remoteRefreshInterval ago. A failed check keeps the
+// cached copy.
+func (cm *ChromiumManager) refreshIfStale(ctx context.Context) {
+	if cm.configSource != "" && time.Since(cm.remoteChecked) >= remoteRefreshInterval {
+		cm.remoteChanged, cm.remoteErr = cm.refreshConfig(ctx)
+	}
+}
+
+// errRemoteConfig is returned when saving a config fetched from elsewhere
+func (cm *ChromiumManager) errRemoteConfig() error {
+	return fmt.Errorf("the config is managed at %s and can't be changed here", cm.configSource)
+}
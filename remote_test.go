@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stalledServer answers nothing until the client gives up, like a config
+// host that accepted the connection and then hung.
+func stalledServer(t *testing.T, start func(http.Handler) *httptest.Server) *httptest.Server {
+	release := make(chan struct{})
+	server := start(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server
+}
+
+func TestCheckConfigSource(t *testing.T) {
+	tests := []struct {
+		source string
+		ok     bool
+	}{
+		{"https://config.example.com/profiles.toml", true},
+		{"s3://bucket/profiles.toml", true},
+		{"git+ssh://git@example.com/team/config.git", true},
+		{"git+https://example.com/team/config.git", true},
+		{"http://config.example.com/profiles.toml", false},
+		{"HTTP://config.example.com/profiles.toml", false},
+		{"git+http://example.com/team/config.git", false},
+		{"git+git://example.com/team/config.git", false},
+	}
+	for _, tt := range tests {
+		if err := checkConfigSource(tt.source); (err == nil) != tt.ok {
+			t.Errorf("checkConfigSource(%q) = %v, want ok %t", tt.source, err, tt.ok)
+		}
+	}
+}
+
+func TestRefreshConfigRefusesPlainHTTP(t *testing.T) {
+	dir := t.TempDir()
+	cm := &ChromiumManager{configSource: "http://config.example.com/profiles.toml", configFile: dir + "/profiles.toml"}
+	if _, err := cm.refreshConfig(context.Background()); err == nil || !strings.Contains(err.Error(), "without TLS") {
+		t.Errorf("refreshConfig = %v, want plain http refused", err)
+	}
+	cm.loadProfiles()
+	if cm.err == nil || !strings.Contains(cm.err.Error(), "without TLS") {
+		t.Errorf("loadProfiles left err = %v, want plain http refused", cm.err)
+	}
+}
+
+func TestReloadProfilesCanceledByStalledHost(t *testing.T) {
+	server := stalledServer(t, httptest.NewTLSServer)
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = transport })
+	t.Setenv(configKeyEnvVar, "")
+
+	cm := &ChromiumManager{configSource: server.URL + "/profiles.toml", configFile: t.TempDir() + "/profiles.toml"}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := cm.reloadProfiles(ctx)
+	if elapsed := time.Since(start); elapsed > remoteFetchTimeout/2 {
+		t.Errorf("reloadProfiles took %s, want it canceled with the context", elapsed)
+	}
+	if !errors.Is(cm.remoteErr, context.DeadlineExceeded) {
+		t.Errorf("remoteErr = %v, want context.DeadlineExceeded", cm.remoteErr)
+	}
+	if err == nil {
+		t.Error("reloadProfiles = nil, want an error with no cached config")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// resetProfile wipes a closed profile's data dir, clean_keep and all, and
+// seeds it again the way its first launch would: the first run setup,
+// search engine, homepage, languages, permissions and label. The profile
+// then opens like new, without a first run to click through.
+func (cm *ChromiumManager) resetProfile(ctx context.Context, name string, report func(cleanProgress)) (string, error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return "", profileNotFound(name)
+	}
+	if cm.isArchived(name) {
+		return "", errArchived(name)
+	}
+	path := cm.profilePath(profile)
+	if _, running := runningPID(path); running {
+		return "", fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	browserPath, err := cm.browserFor(profile)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := fsys.Stat(path); err == nil {
+		if err := reclaimDataDir(path); err != nil {
+			return "", err
+		}
+		defer cm.invalidateSize(name)
+		started := time.Now()
+		err := cleanDataDirWith(ctx, path, nil, report)
+		cm.reportClean(name, "reset to a fresh data dir", time.Since(started), err)
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := fsys.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("creating profile directory: %w", err)
+	}
+	if err := cm.seedDataDir(profile, browserPath, path); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Profile '%s' reset to a fresh data dir", name), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResetProfile(t *testing.T) {
+	m, _ := useFakes(t)
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		configFile: filepath.Join(t.TempDir(), "profiles.toml"),
+		chromePath: "/usr/bin/chromium",
+		sizes:      map[string]int64{},
+		profiles: map[string]Profile{
+			"demo": {Name: "demo", Locale: "de-DE", CleanKeep: []string{"Default/Bookmarks"}},
+		},
+	}
+	cm.loadState()
+	m.MkdirAll("/profiles/demo/Default", 0755)
+	m.WriteFile("/profiles/demo/Default/History", []byte("visited"), 0644)
+	m.WriteFile("/profiles/demo/Default/Bookmarks", []byte("{}"), 0644)
+	m.WriteFile("/profiles/demo/Local State", []byte(`{"browser":{"has_seen_welcome_page":true}}`), 0644)
+
+	message, err := cm.resetProfile(context.Background(), "demo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(message, "reset to a fresh data dir") {
+		t.Errorf("reset said %q", message)
+	}
+	// A reset keeps nothing, clean_keep or not
+	for _, path := range []string{"Default/History", "Default/Bookmarks"} {
+		if _, err := m.Stat(filepath.Join("/profiles/demo", path)); err == nil {
+			t.Errorf("%s survived the reset", path)
+		}
+	}
+	data, err := m.ReadFile("/profiles/demo/Local State")
+	if err != nil {
+		t.Fatalf("the data dir wasn't seeded again: %v", err)
+	}
+	if !strings.Contains(string(data), `"app_locale":"de-DE"`) {
+		t.Errorf("Local State after the reset is %s", data)
+	}
+}
+
+func TestResetProfileRefuses(t *testing.T) {
+	useFakes(t)
+	cm := &ChromiumManager{
+		profileDir: "/profiles",
+		profiles:   map[string]Profile{"old": {Name: "old"}},
+	}
+	cm.state.Archived = map[string]time.Time{"old": time.Now()}
+	if _, err := cm.resetProfile(context.Background(), "old", nil); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("resetting an archived profile gave %v", err)
+	}
+	if _, err := cm.resetProfile(context.Background(), "missing", nil); err == nil {
+		t.Error("reset a profile that doesn't exist")
+	}
+}
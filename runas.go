@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Display variables sudo passes through so the browser can open windows in
+// the current session
+var runAsEnv = []string{"DISPLAY", "WAYLAND_DISPLAY", "XAUTHORITY", "XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS"}
+
+// runAsCommand wraps the browser command line so it runs as the profile's
+// run_as user, after handing the data dir to that user. sudo waits for the
+// browser, but runas on Windows returns at once, so waitable reports
+// whether the command lasts as long as the browser.
+func runAsCommand(profile Profile, dataDir, browserPath string, args []string) (path string, wrapped []string, waitable bool, err error) {
+	if profile.RunAs == "" {
+		return browserPath, args, true, nil
+	}
+	if runtime.GOOS != "windows" {
+		if _, err := exec.LookPath("sudo"); err != nil {
+			return "", nil, false, fmt.Errorf("running as another user needs sudo")
+		}
+	}
+	if err := giveToUser(dataDir, profile.RunAs); err != nil {
+		return "", nil, false, fmt.Errorf("handing %s to %s: %s", dataDir, profile.RunAs, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		// /savecred asks for the password the first time only
+		line := append([]string{browserPath}, args...)
+		return "runas", []string{"/user:" + profile.RunAs, "/savecred", taskCommand(line)}, false, nil
+	}
+
+	// -n fails instead of prompting, which a launch from the TUI can't answer
+	wrapped = []string{"-n", "-u", profile.RunAs, "-H", "--preserve-env=" + strings.Join(runAsEnv, ",")}
+	return "sudo", append(append(wrapped, "--", browserPath), args...), true, nil
+}
+
+// giveToUser makes name the owner of dir and everything in it, so a browser
+// running as that user can write its profile. Chowning needs root, so it
+// goes through sudo when launchium isn't root.
+func giveToUser(dir, name string) error {
+	if runtime.GOOS == "windows" {
+		out, err := exec.Command("icacls", dir, "/grant", name+":(OI)(CI)F", "/T", "/Q").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("icacls: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return err
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+
+	if os.Geteuid() == 0 {
+		return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return os.Lchown(path, uid, gid)
+		})
+	}
+	out, err := exec.Command("sudo", "-n", "chown", "-R", u.Uid+":"+u.Gid, dir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("sudo chown: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
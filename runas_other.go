@@ -0,0 +1,31 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// reclaimDataDir takes back a data dir that giveToUser handed to a run_as
+// user, so launchium can write to and clean it again
+func reclaimDataDir(dir string) error {
+	info, err := fsys.Stat(dir)
+	if err != nil {
+		return nil
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(st.Uid) == os.Geteuid() || os.Geteuid() == 0 {
+		return nil
+	}
+	owner := strconv.Itoa(os.Geteuid()) + ":" + strconv.Itoa(os.Getegid())
+	out, err := exec.Command("sudo", "-n", "chown", "-R", owner, dir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("taking back %s: %s", dir, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
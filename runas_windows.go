@@ -0,0 +1,7 @@
+package main
+
+// reclaimDataDir has nothing to do on Windows, where giveToUser grants the
+// run_as user access without changing the owner
+func reclaimDataDir(dir string) error {
+	return nil
+}
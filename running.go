@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How often the running view rescans for browsers
+const runningRefresh = 2 * time.Second
+
+// instance is a browser found running with one of the profiles
+type instance struct {
+	name         string
+	dataDir      string // Data dir in use, the RAM disk copy for RAM disk profiles
+	pid          int    // 0 when neither the lock nor the registry records it
+	uptime       time.Duration
+	memory       int64 // Resident memory of the browser and its children
+	devToolsPort int   // Set when the registry knows the browser's DevTools port
+}
+
+// runningMsg carries the result of a background scan for running browsers.
+// gen tells scans for an earlier visit to the view apart.
+type runningMsg struct {
+	gen       int
+	instances []instance
+}
+
+// runningTickMsg asks for the next rescan of the running view
+type runningTickMsg struct{ gen int }
+
+// runningDirs lists the data dirs each profile's browser may be running
+// from: its data dir, its RAM disk copy for RAM disk profiles, and the
+// dirs the instance registry has it running in
+func (cm *ChromiumManager) runningDirs() map[string][]string {
+	dirs := map[string][]string{}
+	for name, profile := range cm.profiles {
+		dirs[name] = []string{cm.profilePath(profile)}
+		if profile.RAMDisk != ramDiskOff {
+			if ram := ramDiskPath(name); ram != "" {
+				dirs[name] = append(dirs[name], ram)
+			}
+		}
+	}
+	// Browsers launched into other dirs, such as isolated copies
+	for _, r := range registeredInstances() {
+		if _, ok := cm.profiles[r.Profile]; ok && !containsString(dirs[r.Profile], r.DataDir) {
+			dirs[r.Profile] = append(dirs[r.Profile], r.DataDir)
+		}
+	}
+	return dirs
+}
+
+// findInstances looks for browsers holding the lock in the dirs from
+// runningDirs, filling in what the lock doesn't say from the instance
+// registry. A profile may run more than once, from isolated copies. It
+// doesn't touch the model, so it can run in the background.
+func findInstances(dirs map[string][]string) []instance {
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	registered := map[string]instanceRecord{}
+	for _, r := range registeredInstances() {
+		registered[r.DataDir] = r
+	}
+	stats := processStats()
+	found := []instance{}
+	for _, name := range names {
+		for _, dir := range dirs[name] {
+			pid, running := runningPID(dir)
+			if !running {
+				continue
+			}
+			inst := instance{name: name, dataDir: dir, pid: pid}
+			record, known := registered[dir]
+			if known {
+				if inst.pid == 0 {
+					inst.pid = record.PID
+				}
+				inst.devToolsPort = record.DevToolsPort
+			}
+			if st, ok := stats[inst.pid]; ok && inst.pid > 0 {
+				inst.uptime = st.elapsed
+				inst.memory = treeMemory(stats, inst.pid)
+			} else if known {
+				inst.uptime = time.Since(record.Started)
+			}
+			found = append(found, inst)
+		}
+	}
+	return found
+}
+
+// scanRunning looks for running browsers in the background
+func (cm *ChromiumManager) scanRunning() tea.Cmd {
+	dirs := cm.runningDirs()
+	gen := cm.runningGen
+	return func() tea.Msg {
+		return runningMsg{gen: gen, instances: findInstances(dirs)}
+	}
+}
+
+// openRunningView shows the running browsers and starts refreshing them
+func (cm *ChromiumManager) openRunningView() tea.Cmd {
+	cm.runningGen++
+	cm.instances = nil
+	width, height := cm.contentSize()
+	cm.runningList = list.New(nil, cm.listDelegate(2), width, height)
+	cm.runningList.Title = "Running Browsers"
+	cm.runningList.SetStatusBarItemName("browser", "browsers")
+	cm.runningList.SetShowStatusBar(true)
+	cm.runningList.SetFilteringEnabled(false)
+	cm.keys.configureList(&cm.runningList)
+	styleList(&cm.runningList)
+	cm.currentView = "running"
+	return cm.scanRunning()
+}
+
+// setInstances shows the result of a scan, keeping the cursor in place
+func (cm *ChromiumManager) setInstances(instances []instance) {
+	cm.instances = instances
+	items := make([]list.Item, len(instances))
+	for i, inst := range instances {
+		details := []string{"PID unknown"}
+		if inst.pid > 0 {
+			details = []string{fmt.Sprintf("PID %d", inst.pid)}
+		}
+		if inst.uptime > 0 {
+			details = append(details, "up "+formatUptime(inst.uptime))
+		}
+		if inst.memory > 0 {
+			details = append(details, formatBytes(inst.memory))
+		}
+		if inst.devToolsPort > 0 {
+			details = append(details, fmt.Sprintf("DevTools :%d", inst.devToolsPort))
+		}
+		if where := cm.instanceCopy(inst); where != "" {
+			details = append(details, where)
+		}
+		items[i] = item{title: inst.name, desc: strings.Join(details, " • "), label: cm.profiles[inst.name].label()}
+	}
+	cm.runningList.SetItems(items)
+}
+
+// selectedInstance returns the browser under the cursor in the running view
+func (cm *ChromiumManager) selectedInstance() (instance, bool) {
+	index := cm.runningList.Index()
+	if index < 0 || index >= len(cm.instances) {
+		return instance{}, false
+	}
+	return cm.instances[index], true
+}
+
+// openURL opens url in a new tab of a running browser. Chromium hands the
+// request to the instance that holds the data dir and exits.
+func (cm *ChromiumManager) openURL(inst instance, url string) error {
+	browserPath, err := cm.browserFor(cm.profiles[inst.name])
+	if err != nil {
+		return err
+	}
+	args := []string{"--user-data-dir=" + inst.dataDir, url}
+	cmd := exec.Command(browserPath, args...)
+	if isAppBundle(browserPath) {
+		cmd = openCommand(appBundle(browserPath), args)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// newURLInput creates the prompt for the URL to open in a running browser
+func newURLInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "https://"
+	input.Prompt = "URL: "
+	input.Width = formInputWidth
+	input.Focus()
+	return input
+}
+
+// updateRunning handles keys in the running view and its URL prompt
+func (cm *ChromiumManager) updateRunning(msg tea.KeyMsg) tea.Cmd {
+	if cm.currentView == "open_url" {
+		switch {
+		case key.Matches(msg, cm.keys.Back):
+			cm.currentView = "running"
+			return nil
+		case key.Matches(msg, cm.keys.Select):
+			cm.currentView = "running"
+			url := strings.TrimSpace(cm.urlInput.Value())
+			inst, ok := cm.selectedInstance()
+			if !ok || url == "" {
+				return nil
+			}
+			if err := cm.openURL(inst, url); err != nil {
+				cm.notify(levelError, "opening %s: %s", url, err)
+			} else {
+				cm.notify(levelInfo, "Opened %s in '%s'", url, inst.name)
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		cm.urlInput, cmd = cm.urlInput.Update(msg)
+		return cmd
+	}
+
+	inst, ok := cm.selectedInstance()
+	switch {
+	case key.Matches(msg, cm.keys.Refresh):
+		return cm.scanRunning()
+	case !ok:
+		// The remaining actions need a browser
+	case key.Matches(msg, cm.keys.Focus, cm.keys.Select):
+		if err := focusWindow(inst.pid, cm.profiles[inst.name].windowName()); err != nil {
+			cm.notify(levelError, "raising '%s': %s", inst.name, err)
+		} else {
+			cm.clearMessage()
+		}
+		return nil
+	case key.Matches(msg, cm.keys.Kill):
+		if err := terminateProcess(inst.pid); err != nil {
+			cm.notify(levelError, "closing '%s': %s", inst.name, err)
+			return nil
+		}
+		cm.notify(levelInfo, "Closing '%s' (PID %d)", inst.name, inst.pid)
+		return cm.scanRunning()
+	case key.Matches(msg, cm.keys.OpenURL):
+		cm.urlInput = newURLInput()
+		cm.currentView = "open_url"
+		return textinput.Blink
+	}
+
+	var cmd tea.Cmd
+	cm.runningList, cmd = cm.runningList.Update(msg)
+	return cmd
+}
+
+// runningView renders the running browsers, or the URL prompt over them
+func (cm *ChromiumManager) runningView() string {
+	if cm.currentView == "open_url" {
+		inst, _ := cm.selectedInstance()
+		return fmt.Sprintf("Open a URL in '%s'\n\n%s", inst.name, cm.urlInput.View())
+	}
+	return cm.runningList.View()
+}
+
+// formatUptime renders a process age in the two largest units
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours()/24), int(d.Hours())%24)
+	}
+}
+
+// printInstances writes running browsers as a table for `launchium ps`
+func (cm *ChromiumManager) printInstances(w io.Writer, instances []instance) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROFILE\tPID\tUPTIME\tMEMORY\tDEVTOOLS\tDATA DIR")
+	for _, inst := range instances {
+		pid, uptime, memory, port := "?", "", "", ""
+		if inst.pid > 0 {
+			pid = strconv.Itoa(inst.pid)
+		}
+		if inst.uptime > 0 {
+			uptime = formatUptime(inst.uptime)
+		}
+		if inst.memory > 0 {
+			memory = formatBytes(inst.memory)
+		}
+		if inst.devToolsPort > 0 {
+			port = strconv.Itoa(inst.devToolsPort)
+		}
+		dir := inst.dataDir
+		if where := cm.instanceCopy(inst); where != "" {
+			dir += " (" + where + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", inst.name, pid, uptime, memory, port, dir)
+	}
+	tw.Flush()
+}
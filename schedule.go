@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// What a scheduled clean removes
+const (
+	cleanScopeCache = "cache" // Browser caches only; logins and history stay
+	cleanScopeAll   = "all"   // Everything, like `launchium clean`
+)
+
+// How often a scheduled clean runs
+var cleanIntervals = map[string]time.Duration{
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+}
+
+// How often the OS timer runs `launchium gc`; cleans run on the first run
+// after they fall due
+const gcInterval = time.Hour
+
+// Cache directories inside each Chromium profile (Default, Profile 1, ...)
+var profileCacheDirs = []string{
+	"Cache",
+	"Code Cache",
+	"GPUCache",
+	filepath.Join("Service Worker", "CacheStorage"),
+	filepath.Join("Service Worker", "ScriptCache"),
+}
+
+// Cache directories at the top of the user-data-dir
+var dataDirCacheDirs = []string{"ShaderCache", "GrShaderCache", "GraphiteDawnCache", "component_crx_cache"}
+
+// cleanSchedule is a parsed Profile.CleanSchedule such as "cache weekly"
+type cleanSchedule struct {
+	scope string
+	every time.Duration
+}
+
+// parseCleanSchedule reads "<cache|all> <daily|weekly|monthly>"
+func parseCleanSchedule(s string) (cleanSchedule, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return cleanSchedule{}, fmt.Errorf("clean schedule must look like \"cache weekly\", got %q", s)
+	}
+	scope, interval := parts[0], parts[1]
+	if scope != cleanScopeCache && scope != cleanScopeAll {
+		return cleanSchedule{}, fmt.Errorf("clean schedule must start with \"cache\" or \"all\", got %q", scope)
+	}
+	every, ok := cleanIntervals[interval]
+	if !ok {
+		return cleanSchedule{}, fmt.Errorf("clean schedule must end with daily, weekly or monthly, got %q", interval)
+	}
+	return cleanSchedule{scope: scope, every: every}, nil
+}
+
+// nextClean returns when the profile's scheduled clean is due, and false
+// for profiles without a schedule
+func (cm *ChromiumManager) nextClean(name string) (time.Time, bool) {
+	schedule, err := parseCleanSchedule(cm.profiles[name].CleanSchedule)
+	if err != nil {
+		return time.Time{}, false
+	}
+	last, ok := cm.state.LastClean[name]
+	if !ok {
+		return time.Time{}, true
+	}
+	return last.Add(schedule.every), true
+}
+
+// dueCleans returns the profiles whose scheduled clean is due at now
+func (cm *ChromiumManager) dueCleans(now time.Time) []string {
+	names := []string{}
+	for _, name := range sortedProfileNames(cm.profiles) {
+		if next, ok := cm.nextClean(name); ok && !next.After(now) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// scheduledClean runs the profile's scheduled clean and records it
+func (cm *ChromiumManager) scheduledClean(name string) error {
+	profile := cm.profiles[name]
+	schedule, err := parseCleanSchedule(profile.CleanSchedule)
+	if err != nil {
+		return err
+	}
+
+	// Never-launched profiles are already clean
+	path := cm.profilePath(profile)
+	if _, err := os.Stat(path); err == nil {
+		started := time.Now()
+		if schedule.scope == cleanScopeAll {
+			err = cleanDataDir(context.Background(), path, profile.CleanKeep)
+		} else {
+			err = cleanCaches(path)
+		}
+		cm.reportClean(name, "scheduled "+profile.CleanSchedule, time.Since(started), err)
+		if err != nil {
+			return err
+		}
+	} else {
+		cm.fireWebhooks(eventCleanComplete, name, "scheduled "+profile.CleanSchedule)
+	}
+
+	cm.state.LastClean[name] = time.Now()
+	cm.saveState()
+	return nil
+}
+
+// cleanCaches removes the browser caches from a user-data-dir
+func cleanCaches(dataDir string) error {
+	dirs := []string{}
+	for _, dir := range dataDirCacheDirs {
+		dirs = append(dirs, filepath.Join(dataDir, dir))
+	}
+	entries, err := fsys.ReadDir(dataDir)
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, dir := range profileCacheDirs {
+			dirs = append(dirs, filepath.Join(dataDir, entry.Name(), dir))
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := fsys.RemoveAll(dir); err != nil {
+			return fmt.Errorf("removing %s: %s", dir, err)
+		}
+	}
+	return nil
+}
+
+// schedulerPath returns the timer or launch agent that runs `launchium gc`,
+// or "" on Windows where it is a scheduled task
+func schedulerPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(homeDir, ".config", "systemd", "user", "launchium-gc.timer"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "LaunchAgents", "com.launchium.gc.plist"), nil
+	case "windows":
+		return "", nil
+	}
+	return "", fmt.Errorf("the scheduler is not supported on %s", runtime.GOOS)
+}
+
+// Task Scheduler name of the gc task on Windows
+const schedulerTask = `Launchium\gc`
+
+// installScheduler registers the OS timer that runs `launchium gc` and
+// returns where it was put
+func (cm *ChromiumManager) installScheduler() (string, error) {
+	args, err := cm.launchiumCommand("gc")
+	if err != nil {
+		return "", err
+	}
+	path, err := schedulerPath()
+	if err != nil {
+		return "", err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		service := strings.TrimSuffix(path, ".timer") + ".service"
+		if err := writeServiceFile(service, fmt.Sprintf(`[Unit]
+Description=Launchium scheduled profile cleaning
+
+[Service]
+Type=oneshot
+ExecStart=%s
+`, systemdExec(args))); err != nil {
+			return "", err
+		}
+		if err := writeServiceFile(path, fmt.Sprintf(`[Unit]
+Description=Run launchium gc every %s
+
+[Timer]
+OnBootSec=5min
+OnUnitActiveSec=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, gcInterval, gcInterval)); err != nil {
+			return "", err
+		}
+		err := systemctl("daemon-reload")
+		if err == nil {
+			err = systemctl("enable", "--now", filepath.Base(path))
+		}
+		if err != nil {
+			os.Remove(path)
+			os.Remove(service)
+			return "", err
+		}
+		return path, nil
+
+	case "darwin":
+		extra := fmt.Sprintf("<key>StartInterval</key>\n\t<integer>%d</integer>", int(gcInterval.Seconds()))
+		if err := writeServiceFile(path, launchdPlist("com.launchium.gc", args, extra)); err != nil {
+			return "", err
+		}
+		// Unlike login agents this one should start ticking right away
+		if out, err := exec.Command("launchctl", "load", "-w", path).CombinedOutput(); err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("launchctl: %s", strings.TrimSpace(string(out)))
+		}
+		return path, nil
+
+	default:
+		out, err := exec.Command("schtasks", "/Create", "/TN", schedulerTask,
+			"/TR", taskCommand(args), "/SC", "HOURLY", "/F").CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("schtasks: %s", strings.TrimSpace(string(out)))
+		}
+		return "Task Scheduler: " + schedulerTask, nil
+	}
+}
+
+// uninstallScheduler removes the OS timer that runs `launchium gc`
+func uninstallScheduler() error {
+	path, err := schedulerPath()
+	if err != nil {
+		return err
+	}
+	if installed, _ := schedulerInstalled(); !installed {
+		return fmt.Errorf("the scheduler is not installed")
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := systemctl("disable", "--now", filepath.Base(path)); err != nil {
+			return err
+		}
+		os.Remove(strings.TrimSuffix(path, ".timer") + ".service")
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return systemctl("daemon-reload")
+
+	case "darwin":
+		exec.Command("launchctl", "unload", "-w", path).Run()
+		return os.Remove(path)
+
+	default:
+		out, err := exec.Command("schtasks", "/Delete", "/TN", schedulerTask, "/F").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("schtasks: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// schedulerInstalled reports whether the OS timer is registered
+func schedulerInstalled() (bool, error) {
+	path, err := schedulerPath()
+	if err != nil {
+		return false, err
+	}
+	if runtime.GOOS == "windows" {
+		return exec.Command("schtasks", "/Query", "/TN", schedulerTask).Run() == nil, nil
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+// scheduleSummary describes the profile's clean schedule for status output
+func (cm *ChromiumManager) scheduleSummary(name string) string {
+	next, ok := cm.nextClean(name)
+	if !ok {
+		return ""
+	}
+	s := cm.profiles[name].CleanSchedule
+	if last, ok := cm.state.LastClean[name]; ok {
+		s += ", last cleaned " + formatAgo(last)
+	}
+	if next.After(time.Now()) {
+		s += ", next " + next.Format("2006-01-02 15:04")
+	} else {
+		s += ", due now"
+	}
+	return s
+}
+
+// scheduledProfiles returns the names of profiles with a clean schedule
+func (cm *ChromiumManager) scheduledProfiles() []string {
+	names := []string{}
+	for name, profile := range cm.profiles {
+		if profile.CleanSchedule != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanCaches(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	for _, path := range []string{
+		"ShaderCache/data_0",
+		"Default/Cache/data_0",
+		"Default/Code Cache/js/index",
+		"Profile 1/GPUCache/data_0",
+		"Default/History",
+		"Local State",
+	} {
+		path = filepath.Join(dataDir, path)
+		if err := m.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cleanCaches(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"ShaderCache", "Default/Cache", "Default/Code Cache", "Profile 1/GPUCache"} {
+		if _, err := m.Stat(filepath.Join(dataDir, dir)); err == nil {
+			t.Errorf("%s was left", dir)
+		}
+	}
+	for _, path := range []string{"Default/History", "Local State"} {
+		if _, err := m.Stat(filepath.Join(dataDir, path)); err != nil {
+			t.Errorf("%s was removed: %s", path, err)
+		}
+	}
+	if err := cleanCaches("/profiles/none"); err == nil {
+		t.Error("cleaning the caches of a missing data dir succeeded")
+	}
+}
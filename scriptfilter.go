@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// scriptFilter is the Script Filter JSON Alfred reads, which Raycast and
+// other launcher apps accept too
+type scriptFilter struct {
+	Items []scriptFilterItem `json:"items"`
+}
+
+// scriptFilterItem is one result row; arg is handed to the action, which
+// runs `launchium launch -profile <arg>`
+type scriptFilterItem struct {
+	UID          string `json:"uid"`
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle"`
+	Arg          string `json:"arg"`
+	Autocomplete string `json:"autocomplete"`
+	Match        string `json:"match"` // Words Alfred filters on
+}
+
+// scriptFilterJSON renders the profiles, optionally limited to a tag, in
+// the order pick offers them
+func (cm *ChromiumManager) scriptFilterJSON(tag string) ([]byte, error) {
+	filter := scriptFilter{Items: []scriptFilterItem{}}
+	for _, name := range cm.pickNames(tag) {
+		profile := cm.profiles[name]
+
+		title := name
+		if profile.Icon != "" {
+			title = profile.Icon + " " + name
+		}
+		details := []string{}
+		if summary := profile.summary(); summary != "" {
+			details = append(details, summary)
+		}
+		if name == cm.settings.DefaultProfile {
+			details = append(details, "default")
+		}
+		if _, running := runningPID(cm.profilePath(profile)); running {
+			details = append(details, "running")
+		}
+		if t, ok := cm.state.LastLaunch[name]; ok {
+			details = append(details, "launched "+formatAgo(t))
+		}
+
+		filter.Items = append(filter.Items, scriptFilterItem{
+			UID:          name,
+			Title:        title,
+			Subtitle:     strings.Join(details, " • "),
+			Arg:          name,
+			Autocomplete: name,
+			Match:        strings.Join(strings.Fields(name+" "+profile.Description+" "+strings.Join(profile.Tags, " ")), " "),
+		})
+	}
+	return json.MarshalIndent(filter, "", "  ")
+}
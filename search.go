@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// searchEngine is a search provider a profile can default to
+type searchEngine struct {
+	name string // Shown in the browser's search engine settings
+	url  string // Query URL with {searchTerms} for the search
+}
+
+// Search engines profiles can name instead of giving a URL
+var searchEngines = map[string]searchEngine{
+	"google":     {"Google", "https://www.google.com/search?q={searchTerms}"},
+	"bing":       {"Bing", "https://www.bing.com/search?q={searchTerms}"},
+	"duckduckgo": {"DuckDuckGo", "https://duckduckgo.com/?q={searchTerms}"},
+	"startpage":  {"Startpage", "https://www.startpage.com/do/search?query={searchTerms}"},
+	"brave":      {"Brave", "https://search.brave.com/search?q={searchTerms}"},
+	"ecosia":     {"Ecosia", "https://www.ecosia.org/search?q={searchTerms}"},
+}
+
+// searchEngineNames returns the names of the known search engines, sorted
+func searchEngineNames() []string {
+	names := []string{}
+	for name := range searchEngines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveSearchEngine turns a profile's search_engine, the name of a known
+// engine or a query URL with %s or {searchTerms} where the search goes,
+// into the engine it stands for
+func resolveSearchEngine(value string) (searchEngine, error) {
+	if engine, ok := searchEngines[strings.ToLower(value)]; ok {
+		return engine, nil
+	}
+	query := strings.Replace(value, "%s", "{searchTerms}", 1)
+	u, err := url.Parse(query)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !strings.Contains(query, "{searchTerms}") {
+		return searchEngine{}, fmt.Errorf("search engine must be one of %s or a URL with %%s for the search, got %q",
+			strings.Join(searchEngineNames(), ", "), value)
+	}
+	return searchEngine{name: u.Hostname(), url: query}, nil
+}
+
+// validHomepage checks a profile's homepage is a web address
+func validHomepage(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+		return fmt.Errorf("homepage must be an http, https or file URL, got %q", value)
+	}
+	return nil
+}
+
+// startPreferences returns the preference values that make the profile's
+// search engine the default and open its homepage on startup and from
+// the home button
+func startPreferences(profile Profile) map[string]interface{} {
+	values := map[string]interface{}{}
+	if engine, err := resolveSearchEngine(profile.SearchEngine); err == nil && profile.SearchEngine != "" {
+		keyword := engine.name
+		if u, err := url.Parse(engine.url); err == nil {
+			keyword = u.Hostname()
+		}
+		values["default_search_provider_data.template_url_data"] = map[string]interface{}{
+			"short_name": engine.name,
+			"keyword":    keyword,
+			"url":        engine.url,
+		}
+	}
+	if profile.Homepage != "" {
+		values["homepage"] = profile.Homepage
+		values["homepage_is_newtabpage"] = false
+		values["browser.show_home_button"] = true
+		values["session.restore_on_startup"] = 4 // Open the startup URLs
+		values["session.startup_urls"] = []string{profile.Homepage}
+	}
+	return values
+}
+
+// seedStartPreferences writes the profile's search engine and homepage
+// into a data dir the browser hasn't run in yet. Once the browser has run
+// they are the user's to change, so they are left alone.
+func seedStartPreferences(dataDir string, profile Profile) error {
+	values := startPreferences(profile)
+	if len(values) == 0 {
+		return nil
+	}
+	return mergePreferences(preferencesFile(dataDir), values)
+}
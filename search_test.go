@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveSearchEngine(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantURL string
+		wantErr bool
+	}{
+		{"google", "https://www.google.com/search?q={searchTerms}", false},
+		{"DuckDuckGo", "https://duckduckgo.com/?q={searchTerms}", false},
+		{"https://search.example.com/?q=%s", "https://search.example.com/?q={searchTerms}", false},
+		{"https://search.example.com/?q={searchTerms}", "https://search.example.com/?q={searchTerms}", false},
+		{"https://search.example.com/", "", true},
+		{"ftp://search.example.com/?q=%s", "", true},
+		{"altavista", "", true},
+	}
+	for _, tt := range tests {
+		engine, err := resolveSearchEngine(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveSearchEngine(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if engine.url != tt.wantURL {
+			t.Errorf("resolveSearchEngine(%q) = %s, want %s", tt.value, engine.url, tt.wantURL)
+		}
+	}
+}
+
+func TestSeedStartPreferences(t *testing.T) {
+	m, _ := useFakes(t)
+	profile := Profile{Name: "work", SearchEngine: "https://search.example.com/?q=%s", Homepage: "https://intranet.example.com/"}
+	if err := seedStartPreferences("/profiles/work", profile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.ReadFile(preferencesFile("/profiles/work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prefs struct {
+		Homepage string `json:"homepage"`
+		Search   struct {
+			Data struct {
+				Keyword string `json:"keyword"`
+				URL     string `json:"url"`
+			} `json:"template_url_data"`
+		} `json:"default_search_provider_data"`
+		Session struct {
+			StartupURLs []string `json:"startup_urls"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		t.Fatal(err)
+	}
+	if prefs.Homepage != profile.Homepage || len(prefs.Session.StartupURLs) != 1 {
+		t.Errorf("homepage seeded as %+v", prefs)
+	}
+	if prefs.Search.Data.Keyword != "search.example.com" || prefs.Search.Data.URL != "https://search.example.com/?q={searchTerms}" {
+		t.Errorf("search engine seeded as %+v", prefs.Search.Data)
+	}
+
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// isProfilePattern reports whether a -profile value selects several
+// profiles rather than naming exactly one
+func isProfilePattern(pattern string) bool {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return true
+	}
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchProfiles returns the sorted names of the profiles selected by
+// pattern, which is one of:
+//
+//	work        an exact profile name
+//	test-*      a shell glob
+//	/^tmp-/     a regular expression between slashes
+func (cm *ChromiumManager) matchProfiles(pattern string) ([]string, error) {
+	var match func(string) bool
+
+	switch {
+	case len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %s: %s", pattern, err)
+		}
+		match = re.MatchString
+
+	case strings.ContainsAny(pattern, "*?["):
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %s", pattern, err)
+		}
+		match = func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		}
+
+	default:
+		match = func(name string) bool { return name == pattern }
+	}
+
+	names := []string{}
+	for name := range cm.profiles {
+		if match(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default address for `launchium serve`; loopback only unless told otherwise
+const defaultListenAddr = "127.0.0.1:7777"
+
+// The API token comes from this variable, or from a file next to the config
+// that is created on first use
+const (
+	tokenEnvVar   = "LAUNCHIUM_TOKEN"
+	tokenFileName = "api-token"
+)
+
+// apiRequestTimeout bounds how long one API or daemon request may take,
+// such as cleaning a large profile
+const apiRequestTimeout = 5 * time.Minute
+
+// apiServer serves the REST and gRPC APIs. Requests are handled one at a time since
+// the manager isn't safe for concurrent use.
+type apiServer struct {
+	cm    *ChromiumManager
+	mu    sync.Mutex // Guards cm
+	token string
+}
+
+// apiError is an error with the HTTP status it is reported with
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string { return e.msg }
+
+// apiErrorf builds an apiError
+func apiErrorf(status int, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// apiToken returns the token clients must send, creating the token file
+// if neither it nor LAUNCHIUM_TOKEN exists. The second result is where the
+// token came from.
+func (cm *ChromiumManager) apiToken() (string, string, error) {
+	if token := os.Getenv(tokenEnvVar); token != "" {
+		return token, tokenEnvVar, nil
+	}
+	path := filepath.Join(filepath.Dir(cm.configFile), tokenFileName)
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, path, nil
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := ioutil.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", "", fmt.Errorf("writing API token: %w", err)
+	}
+	return token, path, nil
+}
+
+// runServer serves the REST API on addr, and the gRPC API on grpcAddr if
+// it is set, until the process is stopped
+func (cm *ChromiumManager) runServer(addr, grpcAddr string) error {
+	token, source, err := cm.apiToken()
+	if err != nil {
+		return err
+	}
+	s := &apiServer{cm: cm, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /profiles", s.handle(func(r *http.Request) (int, interface{}, error) {
+		return http.StatusOK, s.listProfiles(r.URL.Query().Get("tag")), nil
+	}))
+	mux.HandleFunc("POST /profiles", s.handle(func(r *http.Request) (int, interface{}, error) {
+		profile, err := readProfile(r)
+		if err == nil {
+			profile, err = s.createProfile(profile)
+		}
+		return http.StatusCreated, profile, err
+	}))
+	mux.HandleFunc("GET /profiles/{name}", s.handle(func(r *http.Request) (int, interface{}, error) {
+		profile, err := s.profile(r.PathValue("name"))
+		return http.StatusOK, profile, err
+	}))
+	mux.HandleFunc("PUT /profiles/{name}", s.handle(func(r *http.Request) (int, interface{}, error) {
+		profile, err := readProfile(r)
+		if err == nil {
+			profile, err = s.updateProfile(r.PathValue("name"), profile)
+		}
+		return http.StatusOK, profile, err
+	}))
+	mux.HandleFunc("DELETE /profiles/{name}", s.handle(func(r *http.Request) (int, interface{}, error) {
+		return http.StatusNoContent, nil, s.deleteProfile(r.PathValue("name"), r.URL.Query().Get("purge") == "true", r.URL.Query().Get("force") == "true")
+	}))
+	mux.HandleFunc("POST /profiles/{name}/launch", s.handle(func(r *http.Request) (int, interface{}, error) {
+		message, err := s.launchProfile(r.Context(), r.PathValue("name"))
+		return http.StatusOK, map[string]string{"message": message}, err
+	}))
+	mux.HandleFunc("POST /profiles/{name}/clean", s.handle(func(r *http.Request) (int, interface{}, error) {
+		message, err := s.cleanProfile(r.Context(), r.PathValue("name"), r.URL.Query().Get("force") == "true")
+		return http.StatusOK, map[string]string{"message": message}, err
+	}))
+	mux.HandleFunc("GET /running", s.handle(func(r *http.Request) (int, interface{}, error) {
+		return http.StatusOK, s.running(), nil
+	}))
+
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		go s.serveGRPC(listener)
+		fmt.Printf("Serving gRPC on %s\n", grpcAddr)
+	}
+
+	fmt.Printf("Listening on http://%s (token from %s)\n", addr, source)
+	return http.ListenAndServe(addr, mux)
+}
+
+// authorized reports whether an Authorization header carries the token
+func (s *apiServer) authorized(header string) bool {
+	auth := strings.TrimPrefix(header, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(auth), []byte(s.token)) == 1
+}
+
+// call runs op with the manager locked and the config freshly loaded.
+// ctx bounds the reload as well as op.
+func (s *apiServer) call(ctx context.Context, op func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.cm.reloadProfiles(ctx); err != nil {
+		return err
+	}
+	return op()
+}
+
+// handle wraps a REST endpoint with authentication, locking, config
+// reloads and JSON encoding of its result
+func (s *apiServer) handle(endpoint func(r *http.Request) (int, interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !s.authorized(r.Header.Get("Authorization")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "missing or invalid token"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), apiRequestTimeout)
+		defer cancel()
+		var status int
+		var result interface{}
+		err := s.call(ctx, func() (err error) {
+			status, result, err = endpoint(r.WithContext(ctx))
+			return err
+		})
+
+		if err != nil {
+			status = http.StatusInternalServerError
+			if e, ok := err.(*apiError); ok {
+				status = e.status
+			} else if errors.Is(err, ErrProfileNotFound) {
+				status = http.StatusNotFound
+			}
+			result = map[string]string{"error": err.Error()}
+		}
+		w.WriteHeader(status)
+		if result != nil {
+			json.NewEncoder(w).Encode(result)
+		}
+	}
+}
+
+// profile returns the named profile
+func (s *apiServer) profile(name string) (Profile, error) {
+	profile, ok := s.cm.profiles[name]
+	if !ok {
+		return Profile{}, apiErrorf(http.StatusNotFound, "profile '%s' not found", name)
+	}
+	return profile, nil
+}
+
+// readProfile decodes a profile sent in the request body
+func readProfile(r *http.Request) (Profile, error) {
+	var p Profile
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return Profile{}, apiErrorf(http.StatusBadRequest, "invalid profile: %s", err)
+	}
+	return p, nil
+}
+
+// checkProfile fills in defaults and validates a profile sent by a client
+func checkProfile(p Profile, settings Settings) (Profile, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Proxy == "" {
+		p.Proxy = "none"
+	}
+	if p.ProxyType == "" {
+		p.ProxyType = "none"
+	}
+	// Sessions come keyed by name, without it
+	for name, session := range p.Sessions {
+		session.Name = name
+		p.Sessions[name] = session
+	}
+	if err := validateProfile(p); err != nil {
+		return Profile{}, apiErrorf(http.StatusBadRequest, "%s", err)
+	}
+	if err := settings.validFirstRun(p.FirstRun); err != nil {
+		return Profile{}, apiErrorf(http.StatusBadRequest, "%s", err)
+	}
+	return p, nil
+}
+
+// validateProfile applies the checks the config parser and the profile
+// form make
+func validateProfile(p Profile) error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := validProfileName(p.Name); err != nil {
+		return err
+	}
+	switch p.ProxyType {
+	case "none", "http", "socks5":
+	default:
+		return fmt.Errorf("proxy_type must be none, http or socks5, got %q", p.ProxyType)
+	}
+	if p.ProxyType != "none" && !strings.Contains(p.Proxy, ":") {
+		return fmt.Errorf("proxy must be host:port for %s", p.ProxyType)
+	}
+	if !validRAMDiskMode(p.RAMDisk) {
+		return fmt.Errorf("ramdisk must be \"discard\" or \"persist\", got %q", p.RAMDisk)
+	}
+	if p.Color != "" {
+		if _, err := parseHexColor(p.Color); err != nil {
+			return err
+		}
+	}
+	if p.CleanSchedule != "" {
+		if _, err := parseCleanSchedule(p.CleanSchedule); err != nil {
+			return err
+		}
+	}
+	if err := validCleanKeep(p.CleanKeep); err != nil {
+		return err
+	}
+	if err := validSessions(p.Sessions); err != nil {
+		return err
+	}
+	if !validNotifyMode(p.Notify) {
+		return fmt.Errorf("notify must be \"on\" or \"off\", got %q", p.Notify)
+	}
+	if err := validateLimits(p); err != nil {
+		return err
+	}
+	if err := validSlot(p.Slot); err != nil {
+		return err
+	}
+	if p.IdleTimeout < 0 {
+		return fmt.Errorf("idle_timeout must be a number of minutes, got %d", p.IdleTimeout)
+	}
+	if !validChannel(p.Channel) {
+		return fmt.Errorf("channel must be one of %s, got %q", strings.Join(browserChannels, ", "), p.Channel)
+	}
+	if p.SearchEngine != "" {
+		if _, err := resolveSearchEngine(p.SearchEngine); err != nil {
+			return err
+		}
+	}
+	if p.Homepage != "" {
+		if err := validHomepage(p.Homepage); err != nil {
+			return err
+		}
+	}
+	if p.Locale != "" {
+		if err := validLanguage(p.Locale); err != nil {
+			return err
+		}
+	}
+	if err := validLanguages(p.SpellcheckLanguages); err != nil {
+		return err
+	}
+	if _, err := p.trustedCAs(); err != nil {
+		return err
+	}
+	if err := validAutoSelectCerts(p.AutoSelectCerts); err != nil {
+		return err
+	}
+	if err := validWebRTCPolicy(p.WebRTCPolicy); err != nil {
+		return err
+	}
+	if err := validPasswordStore(p.PasswordStore); err != nil {
+		return err
+	}
+	if err := validPermissions(p.Permissions); err != nil {
+		return err
+	}
+	return validatePresets(p.Presets)
+}
+
+// saveConfig writes the config, reporting failures as server errors
+func (s *apiServer) saveConfig() error {
+	if err := s.cm.saveProfiles(); err != nil {
+		return apiErrorf(http.StatusInternalServerError, "saving config: %s", err)
+	}
+	return nil
+}
+
+// listProfiles returns every profile, or those with tag
+func (s *apiServer) listProfiles(tag string) []Profile {
+	profiles := []Profile{}
+	for _, name := range sortedProfileNames(s.cm.profiles) {
+		if profile := s.cm.profiles[name]; tag == "" || profile.hasTag(tag) {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// createProfile adds a new profile
+func (s *apiServer) createProfile(profile Profile) (Profile, error) {
+	profile, err := checkProfile(profile, s.cm.settings)
+	if err != nil {
+		return Profile{}, err
+	}
+	if _, exists := s.cm.profiles[profile.Name]; exists {
+		return Profile{}, apiErrorf(http.StatusConflict, "profile '%s' already exists", profile.Name)
+	}
+	if other := slotHolder(s.cm.profiles, profile.Slot, ""); other != "" {
+		return Profile{}, apiErrorf(http.StatusConflict, "profile '%s' already has slot %d", other, profile.Slot)
+	}
+	s.cm.profiles[profile.Name] = profile
+	if err := s.saveConfig(); err != nil {
+		delete(s.cm.profiles, profile.Name)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// updateProfile replaces a profile, renaming it (and moving its data) when
+// the new profile has a different name
+func (s *apiServer) updateProfile(name string, profile Profile) (Profile, error) {
+	if _, err := s.profile(name); err != nil {
+		return Profile{}, err
+	}
+	profile, err := checkProfile(profile, s.cm.settings)
+	if err != nil {
+		return Profile{}, err
+	}
+	if other := slotHolder(s.cm.profiles, profile.Slot, name); other != "" {
+		return Profile{}, apiErrorf(http.StatusConflict, "profile '%s' already has slot %d", other, profile.Slot)
+	}
+	if profile.Name != name {
+		if err := s.cm.renameProfile(name, profile.Name); err != nil {
+			return Profile{}, apiErrorf(http.StatusConflict, "%s", err)
+		}
+	}
+	s.cm.profiles[profile.Name] = profile
+	if err := s.saveConfig(); err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// deleteProfile removes a profile, and its data directory with purge.
+// Protected profiles need force.
+func (s *apiServer) deleteProfile(name string, purge, force bool) error {
+	profile, err := s.profile(name)
+	if err != nil {
+		return err
+	}
+	if profile.Protected && !force {
+		return apiErrorf(http.StatusConflict, "%s", errProtected(name, "delete", "add force=true"))
+	}
+	if err := s.cm.removeProfile(name, purge); err != nil {
+		return apiErrorf(http.StatusConflict, "%s", err)
+	}
+	return s.saveConfig()
+}
+
+// launchProfile launches a profile
+func (s *apiServer) launchProfile(ctx context.Context, name string) (string, error) {
+	if _, err := s.profile(name); err != nil {
+		return "", err
+	}
+	return s.cm.launchBrowser(ctx, name)
+}
+
+// cleanProfile cleans a profile whose browser isn't running. Protected
+// profiles need force.
+func (s *apiServer) cleanProfile(ctx context.Context, name string, force bool) (string, error) {
+	profile, err := s.profile(name)
+	if err != nil {
+		return "", err
+	}
+	if profile.Protected && !force {
+		return "", apiErrorf(http.StatusConflict, "%s", errProtected(name, "clean", "add force=true"))
+	}
+	if _, running := runningPID(s.cm.profilePath(profile)); running {
+		return "", apiErrorf(http.StatusConflict, "profile '%s' is running, close the browser first", name)
+	}
+	if err := s.cm.cleanProfile(ctx, name, nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Profile '%s' completely cleared and reset", name), nil
+}
+
+// running returns the running browsers
+func (s *apiServer) running() []instanceInfo {
+	instances := []instanceInfo{}
+	for _, inst := range findInstances(s.cm.runningDirs()) {
+		instances = append(instances, inst.info())
+	}
+	return instances
+}
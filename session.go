@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Session is a named set of pages a profile can be opened with, and where
+// its window goes. A [profiles.<name>.sessions.<session>] table of the
+// config is one, launched by `launchium launch -profile <name> -session
+// <session>` or from the session picker.
+type Session struct {
+	Name           string   `json:"-"`
+	URLs           []string `json:"urls,omitempty"`            // Pages opened, one tab each
+	WindowSize     string   `json:"window_size,omitempty"`     // "width,height" of the window in pixels
+	WindowPosition string   `json:"window_position,omitempty"` // "x,y" of the window's top left corner
+	Maximized      bool     `json:"maximized,omitempty"`       // Open the window maximized
+}
+
+// Sessions are a profile's sessions by name
+type Sessions map[string]Session
+
+// fields returns the table's config entries in the order they are written
+func (s Session) fields() []configField {
+	fields := []configField{}
+	if len(s.URLs) > 0 {
+		fields = append(fields, configField{"urls", quoteStringArray(s.URLs)})
+	}
+	if s.WindowSize != "" {
+		fields = append(fields, configField{"window_size", quoteString(s.WindowSize)})
+	}
+	if s.WindowPosition != "" {
+		fields = append(fields, configField{"window_position", quoteString(s.WindowPosition)})
+	}
+	if s.Maximized {
+		fields = append(fields, configField{"maximized", "true"})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching session field
+func (s *Session) setField(key, value string) error {
+	switch key {
+	case "urls":
+		urls, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		s.URLs = urls
+		return nil
+	case "window_size":
+		if err := unquoteInto(&s.WindowSize, value); err != nil {
+			return err
+		}
+		return validWindowPair("window_size", s.WindowSize, false)
+	case "window_position":
+		if err := unquoteInto(&s.WindowPosition, value); err != nil {
+			return err
+		}
+		return validWindowPair("window_position", s.WindowPosition, true)
+	case "maximized":
+		return parseBoolInto(&s.Maximized, value)
+	default:
+		return unknownKeyError{"session key", key}
+	}
+}
+
+// validWindowPair checks a "width,height" or "x,y" value. Sizes must be
+// positive; positions may be negative, for screens left of or above the
+// primary one.
+func validWindowPair(key, value string, negative bool) error {
+	if value == "" {
+		return nil
+	}
+	a, b, ok := strings.Cut(value, ",")
+	if !ok {
+		return fmt.Errorf("%s must be two numbers such as \"1280,800\", got %q", key, value)
+	}
+	for _, part := range []string{a, b} {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || (n <= 0 && !negative) {
+			return fmt.Errorf("%s must be two numbers such as \"1280,800\", got %q", key, value)
+		}
+	}
+	return nil
+}
+
+// validSessions checks sessions the way the config parser does, for
+// profiles sent to the API or imported
+func validSessions(sessions Sessions) error {
+	for name, s := range sessions {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("session names can't be empty")
+		}
+		if err := validWindowPair("window_size", s.WindowSize, false); err != nil {
+			return fmt.Errorf("session %q: %s", name, err)
+		}
+		if err := validWindowPair("window_position", s.WindowPosition, true); err != nil {
+			return fmt.Errorf("session %q: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// sessionTableName parses what follows "profiles." in the header of a
+// [profiles.<name>.sessions.<session>] table
+func sessionTableName(rest string) (profile, session string, ok bool) {
+	const sep = ".sessions."
+	// Either name may be quoted and hold the separator, so try each place
+	for i := strings.Index(rest, sep); i >= 0; {
+		p, perr := parseKey(strings.TrimSpace(rest[:i]))
+		s, serr := parseKey(strings.TrimSpace(rest[i+len(sep):]))
+		if perr == nil && serr == nil {
+			return p, s, true
+		}
+		next := strings.Index(rest[i+1:], sep)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return "", "", false
+}
+
+// names returns the names of the sessions, sorted
+func (s Sessions) names() []string {
+	names := []string{}
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// session returns the profile's session of that name
+func (p Profile) session(name string) (Session, error) {
+	if session, ok := p.Sessions[name]; ok {
+		return session, nil
+	}
+	if names := p.Sessions.names(); len(names) > 0 {
+		return Session{}, fmt.Errorf("profile '%s' has no session '%s' (have %s)", p.Name, name, strings.Join(names, ", "))
+	}
+	return Session{}, fmt.Errorf("profile '%s' has no sessions; add a [profiles.%s.sessions.%s] table to the config", p.Name, formatKey(p.Name), formatKey(name))
+}
+
+// apply adds the session's window layout to the profile's flags. They
+// come last, so they win over the profile's own.
+func (s Session) apply(profile Profile) Profile {
+	flags := []string{}
+	if s.WindowSize != "" {
+		flags = append(flags, "--window-size="+strings.ReplaceAll(s.WindowSize, " ", ""))
+	}
+	if s.WindowPosition != "" {
+		flags = append(flags, "--window-position="+strings.ReplaceAll(s.WindowPosition, " ", ""))
+	}
+	if s.Maximized {
+		flags = append(flags, "--start-maximized")
+	}
+	profile.Flags = profile.Flags.with(flags...)
+	return profile
+}
+
+// summary describes the session in a line, in the session picker
+func (s Session) summary() string {
+	parts := []string{}
+	switch len(s.URLs) {
+	case 0:
+		parts = append(parts, "no pages")
+	case 1:
+		parts = append(parts, s.URLs[0])
+	default:
+		parts = append(parts, fmt.Sprintf("%d pages", len(s.URLs)))
+	}
+	if s.WindowSize != "" {
+		parts = append(parts, strings.Replace(strings.ReplaceAll(s.WindowSize, " ", ""), ",", "x", 1))
+	}
+	if s.WindowPosition != "" {
+		parts = append(parts, "at "+strings.ReplaceAll(s.WindowPosition, " ", ""))
+	}
+	if s.Maximized {
+		parts = append(parts, "maximized")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// openSessionPicker lists the selected profile's sessions to launch one
+func (cm *ChromiumManager) openSessionPicker(name string) {
+	profile := cm.profiles[name]
+	if len(profile.Sessions) == 0 {
+		cm.notify(levelInfo, "Profile '%s' has no sessions; add [profiles.%s.sessions.<name>] tables to the config", name, formatKey(name))
+		return
+	}
+	items := []list.Item{}
+	for _, s := range profile.Sessions.names() {
+		items = append(items, item{title: s, desc: profile.Sessions[s].summary()})
+	}
+	width, height := cm.contentSize()
+	cm.sessionList = list.New(items, cm.listDelegate(2), width, height)
+	cm.sessionList.Title = fmt.Sprintf("Sessions of '%s'", name)
+	if cm.launchMode != launchNormal {
+		cm.sessionList.Title += " [" + cm.launchMode + "]"
+	}
+	cm.sessionList.SetShowStatusBar(false)
+	cm.sessionList.SetFilteringEnabled(false)
+	cm.keys.configureList(&cm.sessionList)
+	styleList(&cm.sessionList)
+	cm.selected = name
+	cm.currentView = "sessions"
+}
+
+// updateSessionPicker handles keys in the session picker. Esc goes back to
+// the launch picker.
+func (cm *ChromiumManager) updateSessionPicker(msg tea.KeyMsg) tea.Cmd {
+	if key.Matches(msg, cm.keys.Back) {
+		cm.currentView = "select_profile"
+		return nil
+	}
+	if key.Matches(msg, cm.keys.Select) {
+		if i, ok := cm.sessionList.SelectedItem().(item); ok {
+			cm.currentView = "main"
+			return cm.launchSessionAsync(cm.selected, i.title)
+		}
+	}
+	var cmd tea.Cmd
+	cm.sessionList, cmd = cm.sessionList.Update(msg)
+	return cmd
+}
+
+// launchSessionAsync launches a profile with one of its sessions in the
+// background
+func (cm *ChromiumManager) launchSessionAsync(name, sessionName string) tea.Cmd {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		cm.notify(levelError, "profile '%s' not found", name)
+		return nil
+	}
+	session, err := profile.session(sessionName)
+	if err != nil {
+		cm.notify(levelError, "%s", err)
+		return nil
+	}
+	profile = withLaunchMode(session.apply(profile), cm.launchMode)
+	return tea.Batch(
+		cm.beginTask("Launching '"+name+"'"),
+		func() tea.Msg {
+			message, err := cm.startBrowser(cm.ctx, profile, session.URLs...)
+			return launchDoneMsg{name: name, message: message, err: err}
+		},
+	)
+}
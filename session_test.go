@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidWindowPair(t *testing.T) {
+	tests := []struct {
+		value    string
+		negative bool
+		ok       bool
+	}{
+		{"", false, true},
+		{"1280,800", false, true},
+		{"1280, 800", false, true},
+		{"-1920,0", true, true},
+		{"-1920,0", false, false},
+		{"0,800", false, false},
+		{"1280x800", false, false},
+		{"wide,800", false, false},
+	}
+	for _, tt := range tests {
+		err := validWindowPair("window_size", tt.value, tt.negative)
+		if (err == nil) != tt.ok {
+			t.Errorf("validWindowPair(%q, %v) = %v, want ok %v", tt.value, tt.negative, err, tt.ok)
+		}
+	}
+}
+
+func TestSessionTableName(t *testing.T) {
+	tests := []struct {
+		rest, profile, session string
+		ok                     bool
+	}{
+		{"work.sessions.standup", "work", "standup", true},
+		{`"a.sessions.b".sessions.standup`, "a.sessions.b", "standup", true},
+		{`work.sessions."x.sessions.y"`, "work", "x.sessions.y", true},
+		{"work.flags", "", "", false},
+	}
+	for _, tt := range tests {
+		profile, session, ok := sessionTableName(tt.rest)
+		if profile != tt.profile || session != tt.session || ok != tt.ok {
+			t.Errorf("sessionTableName(%q) = %q, %q, %v; want %q, %q, %v", tt.rest, profile, session, ok, tt.profile, tt.session, tt.ok)
+		}
+	}
+}
+
+func TestSessionApply(t *testing.T) {
+	profile := Profile{Name: "work", Flags: parseFlagList("--window-size=800,600")}
+	session := Session{WindowSize: "1280, 800", WindowPosition: "-1920,0", Maximized: true}
+	got := session.apply(profile).Flags.args()
+	want := []string{"--window-size=800,600", "--window-size=1280,800", "--window-position=-1920,0", "--start-maximized"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flags = %q, want %q", got, want)
+	}
+	if len(profile.Flags) != 1 {
+		t.Errorf("apply changed the profile's own flags: %q", profile.Flags.args())
+	}
+}
+
+func TestProfileSession(t *testing.T) {
+	profile := Profile{Name: "work", Sessions: Sessions{
+		"standup": {URLs: []string{"https://meet.example.com"}},
+		"review":  {},
+	}}
+	if s, err := profile.session("standup"); err != nil || len(s.URLs) != 1 {
+		t.Errorf("session(standup) = %#v, %v", s, err)
+	}
+	if _, err := profile.session("retro"); err == nil || !strings.Contains(err.Error(), "review, standup") {
+		t.Errorf("session(retro) = %v, want the sessions listed", err)
+	}
+	if _, err := (Profile{Name: "home"}).session("retro"); err == nil || !strings.Contains(err.Error(), "[profiles.home.sessions.retro]") {
+		t.Errorf("session of a profile with none = %v, want the table to add", err)
+	}
+}
+
+func TestSessionSummary(t *testing.T) {
+	tests := []struct {
+		session Session
+		want    string
+	}{
+		{Session{}, "no pages"},
+		{Session{URLs: []string{"https://a.example"}, WindowSize: "1280, 800"}, "https://a.example · 1280x800"},
+		{Session{URLs: []string{"a", "b"}, WindowPosition: "0,0", Maximized: true}, "2 pages · at 0,0 · maximized"},
+	}
+	for _, tt := range tests {
+		if got := tt.session.summary(); got != tt.want {
+			t.Errorf("summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Steps of the first-run setup. The last step, creating the first
+// profile, is the profile form.
+const (
+	setupBrowser = iota
+	setupStorage
+)
+
+// setupWizard walks a new user through choosing a browser and where the
+// profiles live before anything is written to the config
+type setupWizard struct {
+	step       int
+	browsers   []string // Detected browsers; the choice after them is a typed path
+	choice     int
+	pathInput  textinput.Model // Browser path when none of the detected ones fits
+	dirInput   textinput.Model
+	defaultDir string // Profile dir used when the setting is left out
+	err        string
+}
+
+// defaultProfiles are the profiles a new config starts with when setup is
+// skipped
+func defaultProfiles() map[string]Profile {
+	return map[string]Profile{
+		"default": {Name: "default", Proxy: "none", ProxyType: "none", Flags: parseFlagList("--no-first-run --disable-features=RendererCodeIntegrity")},
+		"clean":   {Name: "clean", Proxy: "none", ProxyType: "none", Flags: parseFlagList("--no-first-run --disable-features=RendererCodeIntegrity,UseChromeOSDirectVideoDecoder --disable-gpu-driver-bug-workarounds --ignore-gpu-blacklist --disable-gpu-compositing --disable-infobars")},
+	}
+}
+
+// newSetupInput creates one of the setup's path prompts
+func newSetupInput(placeholder, value string) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.SetValue(value)
+	input.Width = formInputWidth
+	input.Prompt = ""
+	return input
+}
+
+// openSetup starts the first-run setup
+func (cm *ChromiumManager) openSetup() tea.Cmd {
+	cm.setup = &setupWizard{
+		browsers:   browserCandidates(),
+		pathInput:  newSetupInput("/path/to/chromium", ""),
+		dirInput:   newSetupInput("", tildePath(cm.profileDir)),
+		defaultDir: cm.profileDir,
+	}
+	cm.setup.focus()
+	cm.currentView = "setup"
+	return textinput.Blink
+}
+
+// focus puts the cursor in the input of the current step, if it has one
+func (w *setupWizard) focus() {
+	w.pathInput.Blur()
+	w.dirInput.Blur()
+	switch {
+	case w.step == setupStorage:
+		w.dirInput.Focus()
+	case w.typingPath():
+		w.pathInput.Focus()
+	}
+}
+
+// typingPath reports whether the browser choice is the typed path
+func (w *setupWizard) typingPath() bool {
+	return w.step == setupBrowser && w.choice == len(w.browsers)
+}
+
+// updateInputs passes cursor blinks and typing to the focused input
+func (w *setupWizard) updateInputs(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case w.step == setupStorage:
+		w.dirInput, cmd = w.dirInput.Update(msg)
+	case w.typingPath():
+		w.pathInput, cmd = w.pathInput.Update(msg)
+	}
+	return cmd
+}
+
+// browser returns the chosen browser binary
+func (w *setupWizard) browser() string {
+	if w.typingPath() {
+		return strings.TrimSpace(w.pathInput.Value())
+	}
+	return w.browsers[w.choice]
+}
+
+// updateSetup handles keys in the setup steps
+func (cm *ChromiumManager) updateSetup(msg tea.KeyMsg) tea.Cmd {
+	w := cm.setup
+
+	if w.step == setupBrowser {
+		switch {
+		case key.Matches(msg, cm.keys.Back):
+			cm.skipSetup()
+			return nil
+		case key.Matches(msg, cm.keys.NextField):
+			w.choice = (w.choice + 1) % (len(w.browsers) + 1)
+			w.err = ""
+			w.focus()
+			return nil
+		case key.Matches(msg, cm.keys.PrevField):
+			w.choice = (w.choice + len(w.browsers)) % (len(w.browsers) + 1)
+			w.err = ""
+			w.focus()
+			return nil
+		case key.Matches(msg, cm.keys.Select):
+			if w.browser() == "" {
+				w.err = "Enter the path to the browser binary"
+				return nil
+			}
+			path := expandPath(w.browser())
+			if info, err := os.Stat(path); err != nil || (info.IsDir() && !isAppBundle(path)) {
+				w.err = fmt.Sprintf("No browser binary at %s", path)
+				return nil
+			}
+			cm.settings.Browser = w.browser()
+			cm.chromePath = path
+			w.step = setupStorage
+			w.err = ""
+			w.focus()
+			return nil
+		}
+		return w.updateInputs(msg)
+	}
+
+	switch {
+	case key.Matches(msg, cm.keys.Back):
+		w.step = setupBrowser
+		w.err = ""
+		w.focus()
+		return nil
+	case key.Matches(msg, cm.keys.Select):
+		value := strings.TrimSpace(w.dirInput.Value())
+		if value == "" {
+			w.err = "Enter a directory for the profile data"
+			return nil
+		}
+		dir := expandPath(value)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			w.err = fmt.Sprintf("Can't use %s: %s", dir, err)
+			return nil
+		}
+		cm.profileDir = dir
+		cm.settings.ProfileDir = ""
+		if dir != w.defaultDir {
+			cm.settings.ProfileDir = value
+		}
+		w.err = ""
+
+		// The first profile is the only one in the new config
+		cm.profiles = map[string]Profile{}
+		return cm.showProfileForm("", Profile{Name: "default", Proxy: "none", ProxyType: "none", Flags: parseFlagList(defaultNewProfileFlags)})
+	}
+	return w.updateInputs(msg)
+}
+
+// skipSetup writes the default profiles and the detected browser, as
+// earlier versions did on first start
+func (cm *ChromiumManager) skipSetup() {
+	cm.setup = nil
+	cm.firstRun = false
+	cm.settings.Browser = ""
+	cm.settings.ProfileDir = ""
+	cm.profiles = defaultProfiles()
+	cm.currentView = "main"
+	if err := cm.saveProfiles(); err != nil {
+		cm.notify(levelError, "saving config: %s", err)
+		return
+	}
+	cm.notify(levelInfo, "Setup skipped; using %s with the default profiles", cm.chromePath)
+}
+
+// setupView renders the current setup step
+func (cm *ChromiumManager) setupView() string {
+	w := cm.setup
+	title := lipgloss.NewStyle().Bold(true).Render("Welcome to Launchium")
+
+	var s string
+	if w.step == setupBrowser {
+		s = "Step 1 of 3: choose the browser to launch profiles with\n\n"
+		if len(w.browsers) == 0 {
+			s += formHintStyle.Render("No Chrome or Chromium found in the usual places") + "\n\n"
+		}
+		for i, b := range w.browsers {
+			s += setupChoice(i == w.choice, b) + "\n"
+		}
+		other := "Other: " + w.pathInput.View()
+		s += setupChoice(w.typingPath(), other) + "\n"
+	} else {
+		s = "Step 2 of 3: choose where profile data is stored\n\n"
+		s += "  " + w.dirInput.View() + "\n"
+		s += "  " + formHintStyle.Render("Each profile gets its own directory here") + "\n"
+	}
+
+	if w.err != "" {
+		s += "\n" + formInvalidStyle.Render(w.err) + "\n"
+	}
+	return title + "\n\n" + s
+}
+
+// setupChoice renders one option of a setup step
+func setupChoice(selected bool, label string) string {
+	if selected {
+		return formSelectStyle.Render("> ") + formFocusStyle.UnsetWidth().Render(label)
+	}
+	return "  " + label
+}
+
+// tildePath shortens a path under the home directory to ~/...
+func tildePath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
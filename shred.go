@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// shredProfile cleans a profile like cleanProfile, overwriting each file
+// with random data before it is deleted. It returns a note when the files
+// could only be deleted, because the filesystem writes the new data to
+// other blocks and keeps the old ones until it reuses them.
+//
+// Chromium keeps the key it encrypts passwords and cookies with in one
+// keychain entry ("Chrome Safe Storage" and the like) shared by all of a
+// browser's profiles, and launchium creates none of its own, so there are
+// no keychain entries that belong to the profile alone to clear. What the
+// key protected goes with the data dir.
+func (cm *ChromiumManager) shredProfile(ctx context.Context, profileName string, report func(cleanProgress)) (string, error) {
+	profile, exists := cm.profiles[profileName]
+	if !exists {
+		return "", profileNotFound(profileName)
+	}
+	if cm.isArchived(profileName) {
+		return "", errArchived(profileName)
+	}
+
+	defer cm.invalidateSize(profileName)
+	started := time.Now()
+	note, err := shredDataDir(ctx, cm.profilePath(profile), profile.CleanKeep, report)
+	cm.reportClean(profileName, "", time.Since(started), err)
+	return note, err
+}
+
+// shredDataDir overwrites and removes everything inside a profile's data
+// directory but the paths to keep. report is told how the deleting goes.
+func shredDataDir(ctx context.Context, profilePath string, keep []string, report func(cleanProgress)) (string, error) {
+	if _, err := fsys.Stat(profilePath); os.IsNotExist(err) {
+		return "", fmt.Errorf("Profile directory does not exist")
+	}
+	// A running browser would write its files again behind the overwrite,
+	// and be left with garbage where it expects its databases
+	if _, running := runningPID(profilePath); running {
+		return "", fmt.Errorf("profile is running, close the browser first")
+	}
+	if err := reclaimDataDir(profilePath); err != nil {
+		return "", err
+	}
+
+	// Overwriting in place means nothing on a copy-on-write filesystem
+	if fsName := copyOnWrite(profilePath); fsName != "" {
+		note := fmt.Sprintf("files were deleted without overwriting them since %s is copy-on-write", fsName)
+		return note, cleanDataDirWith(ctx, profilePath, keep, report)
+	}
+
+	err := walkDir(profilePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path != profilePath && keptPath(profilePath, path, keep) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil // Links are removed without touching what they point to
+		}
+		if err := overwriteFile(path); err != nil {
+			return fmt.Errorf("overwriting %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "", cleanDataDirWith(ctx, profilePath, keep, report)
+}
+
+// overwriteFile replaces a file's contents with as many random bytes and
+// makes sure they reach the disk
+func overwriteFile(path string) error {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0200 == 0 {
+		if err := fsys.Chmod(path, info.Mode().Perm()|0200); err != nil {
+			return err
+		}
+	}
+	f, err := fsys.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(f, rand.Reader, info.Size()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
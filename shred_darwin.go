@@ -0,0 +1,26 @@
+package main
+
+import "syscall"
+
+// copyOnWrite returns the name of the filesystem holding path when it is
+// copy-on-write, "" otherwise. APFS, the default since macOS 10.13, is.
+func copyOnWrite(path string) string {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return ""
+	}
+	name := ""
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name += string(rune(c))
+	}
+	switch name {
+	case "apfs":
+		return "APFS"
+	case "zfs":
+		return "ZFS"
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package main
+
+import "syscall"
+
+// Filesystem magic numbers from statfs(2) of the copy-on-write filesystems
+var copyOnWriteFilesystems = map[int64]string{
+	0x9123683e: "btrfs",
+	0x2fc12fc1: "ZFS",
+	0xca451a4e: "bcachefs",
+}
+
+// copyOnWrite returns the name of the filesystem holding path when it is
+// copy-on-write, "" otherwise
+func copyOnWrite(path string) string {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return ""
+	}
+	return copyOnWriteFilesystems[int64(st.Type)]
+}
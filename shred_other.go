@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package main
+
+// copyOnWrite returns the name of the filesystem holding path when it is
+// copy-on-write. NTFS, which Windows profiles normally live on, isn't.
+func copyOnWrite(path string) string {
+	return ""
+}
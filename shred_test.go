@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOverwriteFile(t *testing.T) {
+	m, _ := useFakes(t)
+	original := []byte("secret cookie value")
+	if err := m.WriteFile("/Cookies", original, 0400); err != nil {
+		t.Fatal(err)
+	}
+	if err := overwriteFile("/Cookies"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.ReadFile("/Cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(original) {
+		t.Errorf("overwritten file has %d bytes, want %d", len(got), len(original))
+	}
+	if bytes.Equal(got, original) {
+		t.Error("file still holds its contents")
+	}
+}
+
+func TestShredDataDir(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	if err := m.MkdirAll(filepath.Join(dataDir, "Default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"Local State", "Default/Cookies", "Default/History"} {
+		if err := m.WriteFile(filepath.Join(dataDir, path), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Symlink("Default/History", filepath.Join(dataDir, "Last")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shredDataDir(context.Background(), dataDir, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	left, err := m.ReadDir(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d entries were left in the data dir", len(left))
+	}
+}
+
+func TestShredMissingDataDir(t *testing.T) {
+	useFakes(t)
+	if _, err := shredDataDir(context.Background(), "/profiles/none", nil, nil); err == nil {
+		t.Error("shredding a missing data dir succeeded")
+	}
+}
+
+func TestCleanKeepsPaths(t *testing.T) {
+	m, _ := useFakes(t)
+	dataDir := "/profiles/work"
+	for _, dir := range []string{"Default/Extensions", "Default/Cache"} {
+		if err := m.MkdirAll(filepath.Join(dataDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{"Default/Extensions/ext.crx", "Default/Cache/data_0", "Default/History"} {
+		if err := m.WriteFile(filepath.Join(dataDir, path), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cleanDataDirWith(context.Background(), dataDir, []string{"Default/Extensions"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(filepath.Join(dataDir, "Default/Extensions/ext.crx")); err != nil {
+		t.Errorf("kept path was removed: %s", err)
+	}
+	for _, path := range []string{"Default/Cache/data_0", "Default/History"} {
+		if _, err := m.Stat(filepath.Join(dataDir, path)); err == nil {
+			t.Errorf("%s was left", path)
+		}
+	}
+}
+
+func TestCleanAndShredRefuseRunningProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows locks the data dir with a held file instead")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname")
+	}
+	tests := []struct {
+		name  string
+		clean func(dataDir string) error
+	}{
+		{"clean", func(dataDir string) error {
+			return cleanDataDirWith(context.Background(), dataDir, nil, nil)
+		}},
+		{"shred", func(dataDir string) error {
+			_, err := shredDataDir(context.Background(), dataDir, nil, nil)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, runner := useFakes(t)
+			dataDir := "/profiles/work"
+			if err := m.MkdirAll(filepath.Join(dataDir, "Default"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			prefs := filepath.Join(dataDir, "Default", "Preferences")
+			if err := m.WriteFile(prefs, []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Symlink(hostname+"-4242", filepath.Join(dataDir, "SingletonLock")); err != nil {
+				t.Fatal(err)
+			}
+			runner.alive[4242] = true
+
+			err := tt.clean(dataDir)
+			if err == nil || !strings.Contains(err.Error(), "close the browser first") {
+				t.Fatalf("got error %v, want the browser to be closed first", err)
+			}
+			if _, err := m.Stat(prefs); err != nil {
+				t.Errorf("the running profile's files were touched: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reuseRunning brings a singleton profile's running browser forward instead
+// of launching a second one, opening urls in it if there are any. reused is
+// false when the profile should be launched as usual: it isn't a
+// singleton, isn't running, or the launch was forced.
+func (cm *ChromiumManager) reuseRunning(profile Profile, urls []string) (message string, reused bool, err error) {
+	if !profile.Singleton || cm.forceLaunch {
+		return "", false, nil
+	}
+	running := findInstances(map[string][]string{profile.Name: cm.runningDirs()[profile.Name]})
+	if len(running) == 0 {
+		return "", false, nil
+	}
+	inst := running[0]
+
+	if len(urls) > 0 {
+		for _, url := range urls {
+			if err := cm.openURL(inst, url); err != nil {
+				return "", true, err
+			}
+		}
+		return fmt.Sprintf("Profile '%s' is already running; opened %s in it", profile.Name, strings.Join(urls, ", ")), true, nil
+	}
+	if err := focusWindow(inst.pid, profile.windowName()); err != nil {
+		return fmt.Sprintf("Profile '%s' is already running (could not raise it: %s)", profile.Name, err), true, nil
+	}
+	return fmt.Sprintf("Profile '%s' is already running; raised its window", profile.Name), true, nil
+}
+
+// focusProfile raises the window of a profile's running browser. raised is
+// false when the profile isn't running.
+func (cm *ChromiumManager) focusProfile(name string) (raised bool, err error) {
+	profile, exists := cm.profiles[name]
+	if !exists {
+		return false, profileNotFound(name)
+	}
+	running := findInstances(map[string][]string{name: cm.runningDirs()[name]})
+	if len(running) == 0 {
+		return false, nil
+	}
+	if err := focusWindow(running[0].pid, profile.windowName()); err != nil {
+		return false, fmt.Errorf("raising '%s': %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profiles can take a quick-launch slot from 1 to maxSlot. Pressing the
+// number in the main menu or running `launchium <n>` launches the profile.
+const maxSlot = 9
+
+// slotKeys are the number keys that launch the profiles in their slots.
+// They aren't in the [keys] table since each key stands for its slot.
+var slotKeys = key.NewBinding(
+	key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+	key.WithHelp("1-9", "launch slot"),
+)
+
+// validSlot checks a profile's slot, 0 being none
+func validSlot(slot int) error {
+	if slot < 0 || slot > maxSlot {
+		return fmt.Errorf("slot must be between 1 and %d, got %d", maxSlot, slot)
+	}
+	return nil
+}
+
+// parseSlot reads a slot number given on the command line, such as the 3
+// of `launchium 3`
+func parseSlot(arg string) (int, bool) {
+	slot, err := strconv.Atoi(arg)
+	if err != nil || len(arg) != 1 || slot < 1 {
+		return 0, false
+	}
+	return slot, true
+}
+
+// slotHolder returns the profile other than except that has the slot, or ""
+func slotHolder(profiles map[string]Profile, slot int, except string) string {
+	if slot == 0 {
+		return ""
+	}
+	for _, name := range sortedProfileNames(profiles) {
+		if name != except && profiles[name].Slot == slot {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkSlots reports the first slot taken by two profiles
+func checkSlots(profiles map[string]Profile) error {
+	for _, name := range sortedProfileNames(profiles) {
+		if other := slotHolder(profiles, profiles[name].Slot, name); other != "" {
+			return fmt.Errorf("profiles %q and %q both have slot %d", name, other, profiles[name].Slot)
+		}
+	}
+	return nil
+}
+
+// slotProfile returns the profile in a slot
+func (cm *ChromiumManager) slotProfile(slot int) (string, error) {
+	if name := slotHolder(cm.profiles, slot, ""); name != "" {
+		return name, nil
+	}
+	return "", errorOf(ErrProfileNotFound, "no profile has slot %d", slot)
+}
+
+// launchSlot launches the profile in the slot of a pressed number key
+func (cm *ChromiumManager) launchSlot(msg tea.KeyMsg) tea.Cmd {
+	slot, _ := parseSlot(msg.String())
+	name, err := cm.slotProfile(slot)
+	if err != nil {
+		cm.notify(levelWarn, "%s; set one in the profile's Slot field", err)
+		return nil
+	}
+	cm.currentView = "main"
+	return cm.launchAsync(name)
+}
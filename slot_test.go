@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSlot(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want int
+		ok   bool
+	}{
+		{"1", 1, true},
+		{"9", 9, true},
+		{"0", 0, false},
+		{"10", 0, false},
+		{"-1", 0, false},
+		{"work", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseSlot(tt.arg); got != tt.want || ok != tt.ok {
+			t.Errorf("parseSlot(%q) = %d, %v, want %d, %v", tt.arg, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSlotProfile(t *testing.T) {
+	cm := &ChromiumManager{profiles: map[string]Profile{
+		"work":     {Name: "work", Slot: 1},
+		"personal": {Name: "personal", Slot: 2},
+		"test":     {Name: "test"},
+	}}
+	if name, err := cm.slotProfile(2); err != nil || name != "personal" {
+		t.Errorf("slot 2 holds %q, %v, want personal", name, err)
+	}
+	if _, err := cm.slotProfile(3); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("an empty slot gave %v, want ErrProfileNotFound", err)
+	}
+	if holder := slotHolder(cm.profiles, 1, "work"); holder != "" {
+		t.Errorf("slotHolder counted the profile being edited: %q", holder)
+	}
+	if holder := slotHolder(cm.profiles, 0, ""); holder != "" {
+		t.Errorf("slot 0 is held by %q", holder)
+	}
+}
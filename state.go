@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+)
+
+// stateFileName is launchium's own bookkeeping, kept next to the config so
+// separate configs don't share history
+const stateFileName = "state.json"
+
+// State records usage history that is not part of the user's config
+type State struct {
+	LastUsed    string                   `json:"last_used,omitempty"`
+	LastLaunch  map[string]time.Time     `json:"last_launch,omitempty"`
+	LastClean   map[string]time.Time     `json:"last_clean,omitempty"`   // Scheduled cleans run by gc
+	LastBrowser map[string]string        `json:"last_browser,omitempty"` // Browser binary each profile last ran with
+	Sizes       map[string]diskUsage     `json:"sizes,omitempty"`        // Data dir sizes as last scanned
+	Archived    map[string]time.Time     `json:"archived,omitempty"`     // When archived profiles were packed away
+	Crashes     map[string][]crashRecord `json:"crashes,omitempty"`      // Crashes found of each profile's browser, newest last
+}
+
+// stateFile returns the path of the state file for the current config
+func (cm *ChromiumManager) stateFile() string {
+	return filepath.Join(filepath.Dir(cm.configFile), stateFileName)
+}
+
+// loadState reads the state file, starting empty if it is missing or unreadable
+func (cm *ChromiumManager) loadState() {
+	cm.state = State{LastLaunch: make(map[string]time.Time), LastClean: make(map[string]time.Time), LastBrowser: make(map[string]string), Sizes: make(map[string]diskUsage), Archived: make(map[string]time.Time)}
+
+	data, err := ioutil.ReadFile(cm.stateFile())
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &cm.state)
+	if cm.state.LastLaunch == nil {
+		cm.state.LastLaunch = make(map[string]time.Time)
+	}
+	if cm.state.LastClean == nil {
+		cm.state.LastClean = make(map[string]time.Time)
+	}
+	if cm.state.LastBrowser == nil {
+		cm.state.LastBrowser = make(map[string]string)
+	}
+	if cm.state.Sizes == nil {
+		cm.state.Sizes = make(map[string]diskUsage)
+	}
+	if cm.state.Archived == nil {
+		cm.state.Archived = make(map[string]time.Time)
+	}
+}
+
+// saveState writes the state file
+func (cm *ChromiumManager) saveState() {
+	data, err := json.MarshalIndent(cm.state, "", "  ")
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(cm.stateFile(), data, 0644)
+}
+
+// recordLaunch remembers a successful launch of the named profile
+func (cm *ChromiumManager) recordLaunch(profileName string) {
+	cm.state.LastUsed = profileName
+	cm.state.LastLaunch[profileName] = time.Now()
+	if browser, ok := takeLaunchedBrowser(profileName); ok {
+		cm.state.LastBrowser[profileName] = browser
+	}
+	cm.saveState()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long `launchium stop` waits for a browser to quit before killing it
+const defaultStopTimeout = 10 * time.Second
+
+// How often stopBrowser checks whether the browser has quit
+const stopPoll = 200 * time.Millisecond
+
+// stopResult is the outcome of stopping one running browser
+type stopResult struct {
+	name   string
+	killed bool // It didn't quit in time and was killed
+	err    error
+}
+
+// stopBrowsers stops the running browsers in parallel and returns the
+// results in the same order
+func stopBrowsers(instances []instance, timeout time.Duration) []stopResult {
+	results := make([]stopResult, len(instances))
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		go func(i int, inst instance) {
+			defer wg.Done()
+			killed, err := stopBrowser(inst, timeout)
+			results[i] = stopResult{name: inst.name, killed: killed, err: err}
+		}(i, inst)
+	}
+	wg.Wait()
+	return results
+}
+
+// stopBrowser asks a browser to quit the way closing it from its menu
+// would, so it writes out its session and preferences. It uses Chrome
+// DevTools' Browser.close when the browser has remote debugging on, and
+// SIGTERM otherwise. A browser still running after timeout is killed.
+func stopBrowser(inst instance, timeout time.Duration) (killed bool, err error) {
+	if err := devToolsClose(inst.dataDir); err != nil {
+		if runtime.GOOS == "windows" {
+			// Without a PID there is nothing to signal
+			return false, fmt.Errorf("%s; on Windows, stopping needs --remote-debugging-port=0 in the profile's flags", err)
+		}
+		if err := terminateProcess(inst.pid); err != nil {
+			return false, err
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, running := runningPID(inst.dataDir); !running {
+			return false, nil
+		}
+		time.Sleep(stopPoll)
+	}
+	if inst.pid <= 0 {
+		return false, fmt.Errorf("still running after %s and its PID is unknown", timeout)
+	}
+	proc, err := os.FindProcess(inst.pid)
+	if err != nil {
+		return false, err
+	}
+	if err := proc.Kill(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// killBrowser kills a browser at once, without letting it write out its
+// session
+func killBrowser(inst instance) error {
+	if inst.pid <= 0 {
+		return fmt.Errorf("the browser's PID is unknown")
+	}
+	proc, err := os.FindProcess(inst.pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+// devToolsClose sends Browser.close over the DevTools protocol to the
+// browser using dataDir. Chromium writes the port and WebSocket path to
+// DevToolsActivePort when started with --remote-debugging-port.
+func devToolsClose(dataDir string) error {
+	data, err := ioutil.ReadFile(filepath.Join(dataDir, "DevToolsActivePort"))
+	if err != nil {
+		return fmt.Errorf("remote debugging is off")
+	}
+	lines := strings.Fields(string(data))
+	if len(lines) < 2 {
+		return fmt.Errorf("unexpected DevToolsActivePort contents")
+	}
+	port, path := lines[0], lines[1]
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", port), 2*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// A minimal WebSocket handshake. Chromium refuses connections that
+	// send an Origin header, which rules out golang.org/x/net/websocket.
+	key := make([]byte, 16)
+	rand.Read(key)
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: 127.0.0.1:%s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n",
+		path, port, base64.StdEncoding.EncodeToString(key))
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(status, " 101 ") {
+		return fmt.Errorf("DevTools refused the connection: %s", strings.TrimSpace(status))
+	}
+
+	_, err = conn.Write(webSocketTextFrame([]byte(`{"id":1,"method":"Browser.close"}`)))
+	return err
+}
+
+// webSocketTextFrame wraps a payload of under 126 bytes in a masked client
+// text frame
+func webSocketTextFrame(payload []byte) []byte {
+	frame := []byte{0x81, 0x80 | byte(len(payload))} // FIN + text, masked
+	mask := make([]byte, 4)
+	rand.Read(mask)
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
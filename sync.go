@@ -0,0 +1,298 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// syncPasswordEnvVar holds the WebDAV password when the sync_remote URL
+// has only a user name, so it needn't be kept in the config
+const syncPasswordEnvVar = "LAUNCHIUM_SYNC_PASSWORD"
+
+// Directions of `launchium sync`
+const (
+	syncPush = "push"
+	syncPull = "pull"
+)
+
+// WebDAV transfers can be large, so they get a generous timeout
+const webDAVTimeout = 30 * time.Minute
+
+// Paths in a data dir that sync leaves out: caches, which are rebuilt, and
+// files that only make sense on the machine the browser ran on. A pattern
+// matches a path component, or consecutive components when it has a slash.
+var syncExcludes = []string{
+	"Singleton*",
+	"lockfile",
+	"DevToolsActivePort",
+	launchLogName,
+	"Crashpad",
+	"Crash Reports",
+	"BrowserMetrics*",
+	"*.tmp",
+}
+
+// syncExcluded builds the full exclusion list from the built-in patterns,
+// the cache dirs scheduled cleans remove, and the sync_exclude setting
+func (cm *ChromiumManager) syncExcluded() []string {
+	patterns := append([]string{}, syncExcludes...)
+	for _, dir := range append(append([]string{}, profileCacheDirs...), dataDirCacheDirs...) {
+		patterns = append(patterns, filepath.ToSlash(dir))
+	}
+	return append(patterns, cm.settings.SyncExclude...)
+}
+
+// excludedPath reports whether rel, a slash-separated path inside the data
+// dir, matches one of the patterns
+func excludedPath(rel string, patterns []string) bool {
+	parts := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		want := strings.Split(pattern, "/")
+		for i := 0; i+len(want) <= len(parts); i++ {
+			matched := true
+			for j, w := range want {
+				if ok, _ := path.Match(w, parts[i+j]); !ok {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// syncProfile copies the profile's data dir to (push) or from (pull) the
+// remote, which is the sync_remote setting unless one is given. Each
+// profile is kept under its name at the remote. Canceling ctx stops the
+// transfer.
+func (cm *ChromiumManager) syncProfile(ctx context.Context, direction, name, remote string) error {
+	profile, ok := cm.profiles[name]
+	if !ok {
+		return profileNotFound(name)
+	}
+	if remote == "" {
+		remote = cm.settings.SyncRemote
+	}
+	if remote == "" {
+		return fmt.Errorf("no sync remote; set sync_remote in [settings] or pass -remote")
+	}
+	dataDir := cm.profilePath(profile)
+	if _, running := runningPID(dataDir); running {
+		return fmt.Errorf("profile '%s' is running, close the browser first", name)
+	}
+	if direction == syncPush {
+		if _, err := fsys.Stat(dataDir); err != nil {
+			return fmt.Errorf("profile '%s' has no data to push", name)
+		}
+	}
+	if err := fsys.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+	reclaimDataDir(dataDir)
+
+	excludes := cm.syncExcluded()
+	switch {
+	case strings.HasPrefix(remote, "s3://"):
+		return syncS3(ctx, direction, dataDir, strings.TrimSuffix(remote, "/")+"/"+name, excludes)
+	case strings.HasPrefix(remote, "webdav://"), strings.HasPrefix(remote, "webdavs://"):
+		return syncWebDAV(ctx, direction, dataDir, remote, name, excludes)
+	default:
+		return syncRsync(ctx, direction, dataDir, remote, name, excludes)
+	}
+}
+
+// syncS3 mirrors the data dir with `aws s3 sync`, which uses the AWS CLI's
+// credentials and only copies files that changed
+func syncS3(ctx context.Context, direction, dataDir, remote string, excludes []string) error {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return fmt.Errorf("syncing with S3 needs the AWS CLI (aws)")
+	}
+	args := []string{"s3", "sync", "--delete", "--only-show-errors"}
+	for _, pattern := range excludes {
+		// aws matches patterns against the whole relative path
+		for _, p := range []string{pattern, pattern + "/*", "*/" + pattern, "*/" + pattern + "/*"} {
+			args = append(args, "--exclude", p)
+		}
+	}
+	if direction == syncPush {
+		args = append(args, dataDir, remote)
+	} else {
+		args = append(args, remote, dataDir)
+	}
+	return runSyncTool(ctx, "aws", args)
+}
+
+// syncRsync mirrors the data dir over ssh with rsync. The remote is an
+// rsync destination such as "laptop:launchium" or ssh://user@host/path.
+func syncRsync(ctx context.Context, direction, dataDir, remote, name string, excludes []string) error {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return fmt.Errorf("syncing over ssh needs rsync")
+	}
+	host, dir := remote, ""
+	if u, err := url.Parse(remote); err == nil && u.Scheme == "ssh" {
+		host, dir = u.Host, u.Path
+		if u.User != nil {
+			host = u.User.Username() + "@" + host
+		}
+	} else if i := strings.Index(remote, ":"); i > 0 {
+		host, dir = remote[:i], remote[i+1:]
+	} else {
+		return fmt.Errorf("sync remote %q should be s3://, webdav(s)://, ssh:// or host:path", remote)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	dir = path.Join(dir, name)
+
+	args := []string{"-a", "--delete", "-e", "ssh"}
+	for _, pattern := range excludes {
+		args = append(args, "--exclude="+pattern)
+	}
+	local := strings.TrimSuffix(dataDir, string(filepath.Separator)) + string(filepath.Separator)
+	if direction == syncPush {
+		// Create the profile's directory on the remote first
+		args = append(args, "--rsync-path=mkdir -p '"+strings.ReplaceAll(dir, "'", `'\''`)+"' && rsync", local, host+":"+dir+"/")
+	} else {
+		args = append(args, host+":"+dir+"/", local)
+	}
+	return runSyncTool(ctx, "rsync", args)
+}
+
+// runSyncTool runs an external sync tool, returning its output on failure
+func runSyncTool(ctx context.Context, name string, args []string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s: %s", name, msg)
+	}
+	return nil
+}
+
+// syncWebDAV stores the data dir as a single <profile>.tar.gz on a WebDAV
+// server, since WebDAV has no way to copy only what changed. webdavs://
+// uses HTTPS.
+func syncWebDAV(ctx context.Context, direction, dataDir, remote, name string, excludes []string) error {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "webdavs" {
+		u.Scheme = "https"
+	} else {
+		u.Scheme = "http"
+	}
+	user, password := "", ""
+	if u.User != nil {
+		user = u.User.Username()
+		password, _ = u.User.Password()
+		if password == "" {
+			password = os.Getenv(syncPasswordEnvVar)
+		}
+		u.User = nil
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+
+	client := &http.Client{Timeout: webDAVTimeout}
+	do := func(method, target string, body io.Reader) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, body)
+		if err != nil {
+			return nil, err
+		}
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		req.Header.Set("User-Agent", "launchium/"+version)
+		return client.Do(req)
+	}
+	archive := u.String() + url.PathEscape(name) + ".tar.gz"
+
+	if direction == syncPull {
+		resp, err := do("GET", archive, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("WebDAV server answered %s for %s.tar.gz", resp.Status, name)
+		}
+		return unpackProfile(ctx, resp.Body, dataDir)
+	}
+
+	// The collection may not exist yet; MKCOL fails harmlessly if it does
+	if resp, err := do("MKCOL", u.String(), nil); err == nil {
+		resp.Body.Close()
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(packProfile(ctx, pw, dataDir, excludes))
+	}()
+	resp, err := do("PUT", archive, pr)
+	pr.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("WebDAV server answered %s for %s.tar.gz", resp.Status, name)
+	}
+	return nil
+}
+
+// packProfile writes the data dir, less the excluded paths, to w as a
+// gzipped tar
+func packProfile(ctx context.Context, w io.Writer, dataDir string, excludes []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	skip := func(rel string) bool { return excludedPath(rel, excludes) }
+	if err := writeTree(ctx, tw, dataDir, skip); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// unpackProfile replaces the data dir with the contents of a gzipped tar
+// from packProfile. It unpacks next to the data dir first, so a broken
+// download leaves the old data in place.
+func unpackProfile(ctx context.Context, r io.Reader, dataDir string) error {
+	staging, err := fsys.MkdirTemp(filepath.Dir(dataDir), "."+filepath.Base(dataDir)+"-pull-")
+	if err != nil {
+		return err
+	}
+	defer fsys.RemoveAll(staging)
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("reading profile archive: %w", err)
+	}
+	if err := readTree(ctx, tar.NewReader(gz), staging); err != nil {
+		return fmt.Errorf("reading profile archive: %w", err)
+	}
+
+	if err := fsys.RemoveAll(dataDir); err != nil {
+		return err
+	}
+	return fsys.Rename(staging, dataDir)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnpackProfileRefusesEscapingLinks(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/profiles/work", 0755)
+	m.WriteFile("/profiles/work/Local State", []byte("{}"), 0644)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "Default/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "Default/keys", Typeflag: tar.TypeSymlink, Linkname: "../../../.ssh"})
+	tw.Close()
+	gz.Close()
+
+	err := unpackProfile(context.Background(), &buf, "/profiles/work")
+	if err == nil || !strings.Contains(err.Error(), "unsafe link") {
+		t.Fatalf("unpackProfile = %v, want an unsafe link refused", err)
+	}
+	if data, err := m.ReadFile("/profiles/work/Local State"); err != nil || string(data) != "{}" {
+		t.Errorf("the data dir was touched by a refused pull: %q, %v", data, err)
+	}
+}
+
+func TestSyncWebDAVCanceledByStalledHost(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/profiles/work", 0755)
+	server := stalledServer(t, httptest.NewServer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := syncWebDAV(ctx, syncPull, "/profiles/work", "webdav://"+server.Listener.Addr().String()+"/profiles", "work", nil)
+	if elapsed := time.Since(start); elapsed > webDAVTimeout/2 {
+		t.Errorf("syncWebDAV took %s, want it canceled with the context", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("syncWebDAV = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// The launcher and config code reach the filesystem, processes and the
+// installed browsers through these, so tests can swap in fakes for the
+// real ones below.
+var (
+	fsys          fileSystem    = osFileSystem{}
+	procs         processRunner = execRunner{}
+	browserSource browserFinder = systemBrowsers{}
+)
+
+// fileSystem is the part of the os package the config, data dirs,
+// preferences, cleans, archives and syncs use
+type fileSystem interface {
+	Stat(path string) (fs.FileInfo, error)
+	Lstat(path string) (fs.FileInfo, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+	ReadDir(path string) ([]fs.DirEntry, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	MkdirTemp(dir, pattern string) (string, error)
+	Remove(path string) error
+	RemoveAll(path string) error
+	Rename(oldPath, newPath string) error
+	Open(path string) (io.ReadCloser, error)
+	OpenFile(path string, flag int, perm fs.FileMode) (file, error)
+	Readlink(path string) (string, error)
+	Symlink(target, path string) error
+	Chmod(path string, mode fs.FileMode) error
+	Chtimes(path string, atime, mtime time.Time) error
+}
+
+// file is an open file of a fileSystem
+type file interface {
+	io.ReadWriteCloser
+	Sync() error
+}
+
+// processRunner finds, runs and checks on browser processes
+type processRunner interface {
+	LookPath(file string) (string, error)
+	Start(cmd *exec.Cmd) error
+	Wait(cmd *exec.Cmd) error
+	Alive(pid int) bool
+}
+
+// browserFinder lists the browsers installed on the machine
+type browserFinder interface {
+	installed() []installedBrowser
+}
+
+// walkDir walks the tree at root like filepath.WalkDir, through fsys
+func walkDir(root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(walkFS{root}, ".", func(rel string, d fs.DirEntry, err error) error {
+		return fn(filepath.Join(root, filepath.FromSlash(rel)), d, err)
+	})
+}
+
+// walkFS presents the tree at root in fsys to fs.WalkDir. Like
+// filepath.WalkDir, it doesn't follow a symlink at the root.
+type walkFS struct{ root string }
+
+func (w walkFS) path(name string) string { return filepath.Join(w.root, filepath.FromSlash(name)) }
+func (w walkFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+}
+func (w walkFS) Stat(name string) (fs.FileInfo, error)      { return fsys.Lstat(w.path(name)) }
+func (w walkFS) ReadDir(name string) ([]fs.DirEntry, error) { return fsys.ReadDir(w.path(name)) }
+
+// osFileSystem is the real filesystem
+type osFileSystem struct{}
+
+func (osFileSystem) Stat(path string) (fs.FileInfo, error)  { return os.Stat(path) }
+func (osFileSystem) Lstat(path string) (fs.FileInfo, error) { return os.Lstat(path) }
+func (osFileSystem) ReadFile(path string) ([]byte, error)   { return ioutil.ReadFile(path) }
+func (osFileSystem) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	return ioutil.WriteFile(path, data, perm)
+}
+func (osFileSystem) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(path) }
+func (osFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+func (osFileSystem) MkdirTemp(dir, pattern string) (string, error) { return os.MkdirTemp(dir, pattern) }
+func (osFileSystem) Remove(path string) error                      { return os.Remove(path) }
+func (osFileSystem) RemoveAll(path string) error                   { return os.RemoveAll(path) }
+func (osFileSystem) Rename(oldPath, newPath string) error          { return os.Rename(oldPath, newPath) }
+func (osFileSystem) Open(path string) (io.ReadCloser, error)       { return os.Open(path) }
+func (osFileSystem) OpenFile(path string, flag int, perm fs.FileMode) (file, error) {
+	f, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err // Not a typed nil in the interface
+	}
+	return f, nil
+}
+func (osFileSystem) Readlink(path string) (string, error)      { return os.Readlink(path) }
+func (osFileSystem) Symlink(target, path string) error         { return os.Symlink(target, path) }
+func (osFileSystem) Chmod(path string, mode fs.FileMode) error { return os.Chmod(path, mode) }
+func (osFileSystem) Chtimes(path string, atime, mtime time.Time) error {
+	return os.Chtimes(path, atime, mtime)
+}
+
+// execRunner runs real processes
+type execRunner struct{}
+
+func (execRunner) LookPath(file string) (string, error) { return exec.LookPath(file) }
+func (execRunner) Start(cmd *exec.Cmd) error            { return cmd.Start() }
+func (execRunner) Wait(cmd *exec.Cmd) error             { return cmd.Wait() }
+func (execRunner) Alive(pid int) bool                   { return processAlive(pid) }
+
+// systemBrowsers looks for browsers where they are usually installed and
+// among the fetched ones
+type systemBrowsers struct{}
+
+func (systemBrowsers) installed() []installedBrowser { return detectBrowsers() }
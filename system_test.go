@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memFS is an in-memory fileSystem. Paths are cleaned and the root
+// always exists; like the real one, files need their directory to exist.
+type memFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+	temps int
+}
+
+// memNode is a file, directory or symlink of a memFS
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	link    string
+	modTime time.Time
+}
+
+func newMemFS() *memFS {
+	return &memFS{nodes: map[string]*memNode{}}
+}
+
+func (m *memFS) key(path string) string { return filepath.Clean(path) }
+
+func pathErr(op, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+// lookup finds a node, following symlinks when follow is set. The caller
+// holds m.mu.
+func (m *memFS) lookup(path string, follow bool) (string, *memNode, error) {
+	key := m.key(path)
+	for hops := 0; hops < 40; hops++ {
+		if key == filepath.Dir(key) {
+			return key, &memNode{mode: fs.ModeDir | 0755}, nil
+		}
+		n, ok := m.nodes[key]
+		if !ok {
+			return key, nil, fs.ErrNotExist
+		}
+		if !follow || n.mode&fs.ModeSymlink == 0 {
+			return key, n, nil
+		}
+		target := n.link
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(key), target)
+		}
+		key = m.key(target)
+	}
+	return key, nil, errors.New("too many links")
+}
+
+// parentExists reports whether path's directory exists. The caller holds
+// m.mu.
+func (m *memFS) parentExists(path string) bool {
+	_, n, err := m.lookup(filepath.Dir(path), true)
+	return err == nil && n.mode.IsDir()
+}
+
+func (m *memFS) stat(op, path string, follow bool) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, n, err := m.lookup(path, follow)
+	if err != nil {
+		return nil, pathErr(op, path, err)
+	}
+	return memInfo{name: filepath.Base(key), node: *n}, nil
+}
+
+func (m *memFS) Stat(path string) (fs.FileInfo, error)  { return m.stat("stat", path, true) }
+func (m *memFS) Lstat(path string) (fs.FileInfo, error) { return m.stat("lstat", path, false) }
+
+func (m *memFS) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, n, err := m.lookup(path, true)
+	if err != nil {
+		return nil, pathErr("open", path, err)
+	}
+	if n.mode.IsDir() {
+		return nil, pathErr("read", path, errors.New("is a directory"))
+	}
+	return append([]byte{}, n.data...), nil
+}
+
+func (m *memFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write(path, append([]byte{}, data...), perm)
+}
+
+// write replaces a file's data, creating it with perm. The caller holds
+// m.mu.
+func (m *memFS) write(path string, data []byte, perm fs.FileMode) error {
+	key, n, err := m.lookup(path, true)
+	switch {
+	case err == nil && n.mode.IsDir():
+		return pathErr("open", path, errors.New("is a directory"))
+	case err == nil:
+		n.data, n.modTime = data, time.Now()
+		return nil
+	case !m.parentExists(key):
+		return pathErr("open", path, fs.ErrNotExist)
+	}
+	m.nodes[key] = &memNode{data: data, mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, n, err := m.lookup(path, true)
+	if err != nil {
+		return nil, pathErr("open", path, err)
+	}
+	if !n.mode.IsDir() {
+		return nil, pathErr("readdirent", path, errors.New("not a directory"))
+	}
+	entries := []fs.DirEntry{}
+	for p, child := range m.nodes {
+		if filepath.Dir(p) == key && p != key {
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: filepath.Base(p), node: *child}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *memFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := m.key(path)
+	if key == filepath.Dir(key) {
+		return nil
+	}
+	if _, n, err := m.lookup(key, true); err == nil {
+		if !n.mode.IsDir() {
+			return pathErr("mkdir", path, errors.New("not a directory"))
+		}
+		return nil
+	}
+	m.mu.Unlock()
+	err := m.MkdirAll(filepath.Dir(key), perm)
+	m.mu.Lock()
+	if err != nil {
+		return err
+	}
+	m.nodes[key] = &memNode{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) MkdirTemp(dir, pattern string) (string, error) {
+	m.mu.Lock()
+	m.temps++
+	path := filepath.Join(dir, strings.Replace(pattern, "*", "", 1)+strings.Repeat("x", m.temps))
+	ok := m.parentExists(path)
+	m.mu.Unlock()
+	if !ok {
+		return "", pathErr("mkdirtemp", path, fs.ErrNotExist)
+	}
+	return path, m.MkdirAll(path, 0700)
+}
+
+func (m *memFS) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, n, err := m.lookup(path, false)
+	if err != nil {
+		return pathErr("remove", path, err)
+	}
+	if n.mode.IsDir() {
+		for p := range m.nodes {
+			if filepath.Dir(p) == key {
+				return pathErr("remove", path, errors.New("directory not empty"))
+			}
+		}
+	}
+	delete(m.nodes, key)
+	return nil
+}
+
+func (m *memFS) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := m.key(path)
+	for p := range m.nodes {
+		if p == key || strings.HasPrefix(p, key+string(filepath.Separator)) {
+			delete(m.nodes, p)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	from, to := m.key(oldPath), m.key(newPath)
+	if _, ok := m.nodes[from]; !ok {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: fs.ErrNotExist}
+	}
+	if !m.parentExists(to) {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: fs.ErrNotExist}
+	}
+	for p, n := range m.nodes {
+		if p == from || strings.HasPrefix(p, from+string(filepath.Separator)) {
+			delete(m.nodes, p)
+			m.nodes[to+strings.TrimPrefix(p, from)] = n
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Open(path string) (io.ReadCloser, error) {
+	data, err := m.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memFS) OpenFile(path string, flag int, perm fs.FileMode) (file, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, n, err := m.lookup(path, true)
+	switch {
+	case err != nil && flag&os.O_CREATE == 0:
+		return nil, pathErr("open", path, err)
+	case err == nil && n.mode.IsDir():
+		return nil, pathErr("open", path, errors.New("is a directory"))
+	case err != nil:
+		if err := m.write(path, nil, perm); err != nil {
+			return nil, err
+		}
+	}
+	h := &memHandle{fs: m, path: path}
+	if flag&os.O_TRUNC == 0 {
+		_, n, _ := m.lookup(path, true)
+		h.data = append([]byte{}, n.data...)
+		if flag&os.O_APPEND != 0 {
+			h.offset = len(h.data)
+		}
+	}
+	return h, nil
+}
+
+func (m *memFS) Readlink(path string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, n, err := m.lookup(path, false)
+	if err != nil {
+		return "", pathErr("readlink", path, err)
+	}
+	if n.mode&fs.ModeSymlink == 0 {
+		return "", pathErr("readlink", path, fs.ErrInvalid)
+	}
+	return n.link, nil
+}
+
+func (m *memFS) Symlink(target, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := m.key(path)
+	if _, ok := m.nodes[key]; ok {
+		return &os.LinkError{Op: "symlink", Old: target, New: path, Err: fs.ErrExist}
+	}
+	if !m.parentExists(key) {
+		return &os.LinkError{Op: "symlink", Old: target, New: path, Err: fs.ErrNotExist}
+	}
+	m.nodes[key] = &memNode{link: target, mode: fs.ModeSymlink | 0777, modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) Chmod(path string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, n, err := m.lookup(path, true)
+	if err != nil {
+		return pathErr("chmod", path, err)
+	}
+	n.mode = n.mode&fs.ModeType | mode.Perm()
+	return nil
+}
+
+func (m *memFS) Chtimes(path string, atime, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, n, err := m.lookup(path, true)
+	if err != nil {
+		return pathErr("chtimes", path, err)
+	}
+	n.modTime = mtime
+	return nil
+}
+
+// memHandle is an open memFS file. Writes reach the file when it is
+// synced or closed.
+type memHandle struct {
+	fs     *memFS
+	path   string
+	data   []byte
+	offset int
+	dirty  bool
+}
+
+func (h *memHandle) Read(p []byte) (int, error) {
+	if h.offset >= len(h.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, h.data[h.offset:])
+	h.offset += n
+	return n, nil
+}
+
+func (h *memHandle) Write(p []byte) (int, error) {
+	for len(h.data) < h.offset+len(p) {
+		h.data = append(h.data, 0)
+	}
+	copy(h.data[h.offset:], p)
+	h.offset += len(p)
+	h.dirty = true
+	return len(p), nil
+}
+
+func (h *memHandle) Sync() error {
+	if !h.dirty {
+		return nil
+	}
+	h.dirty = false
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	return h.fs.write(h.path, append([]byte{}, h.data...), 0644)
+}
+
+func (h *memHandle) Close() error { return h.Sync() }
+
+// memInfo describes a memFS node
+type memInfo struct {
+	name string
+	node memNode
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i memInfo) Sys() interface{}   { return nil }
+
+// fakeRunner stands in for starting browsers. Started commands are kept
+// for the test to look at, and each exits with exitErr.
+type fakeRunner struct {
+	mu      sync.Mutex
+	paths   map[string]bool // Binaries LookPath finds
+	started []*exec.Cmd
+	exitErr error
+	alive   map[int]bool
+}
+
+func (r *fakeRunner) LookPath(file string) (string, error) {
+	if r.paths[file] {
+		return file, nil
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+func (r *fakeRunner) Start(cmd *exec.Cmd) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, cmd)
+	// The launcher wants a process to note; this test's own stands in
+	proc, err := os.FindProcess(os.Getpid())
+	cmd.Process = proc
+	return err
+}
+
+func (r *fakeRunner) Wait(cmd *exec.Cmd) error { return r.exitErr }
+func (r *fakeRunner) Alive(pid int) bool       { return r.alive[pid] }
+
+// commands returns the command lines started so far
+func (r *fakeRunner) commands() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := [][]string{}
+	for _, cmd := range r.started {
+		lines = append(lines, cmd.Args)
+	}
+	return lines
+}
+
+// fakeBrowsers is a fixed list of installed browsers
+type fakeBrowsers []installedBrowser
+
+func (b fakeBrowsers) installed() []installedBrowser { return b }
+
+// useFakes swaps the filesystem, process runner and browser list for
+// fakes until the test ends
+func useFakes(t *testing.T) (*memFS, *fakeRunner) {
+	t.Helper()
+	oldFS, oldProcs, oldBrowsers := fsys, procs, browserSource
+	t.Cleanup(func() { fsys, procs, browserSource = oldFS, oldProcs, oldBrowsers })
+	m := newMemFS()
+	r := &fakeRunner{paths: map[string]bool{}, alive: map[int]bool{}}
+	fsys, procs, browserSource = m, r, fakeBrowsers{}
+	return m, r
+}
+
+func TestMemFSWalk(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/data/Default/Cache", 0755)
+	m.WriteFile("/data/Local State", []byte("{}"), 0644)
+	m.WriteFile("/data/Default/Cache/a", []byte("aa"), 0644)
+	m.Symlink("host-1", "/data/SingletonLock")
+
+	seen := []string{}
+	err := walkDir("/data", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == "Cache" {
+			return fs.SkipDir
+		}
+		seen = append(seen, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/data", "/data/Default", "/data/Local State", "/data/SingletonLock"}
+	if strings.Join(seen, "|") != strings.Join(want, "|") {
+		t.Errorf("walked %q, want %q", seen, want)
+	}
+}
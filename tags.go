@@ -0,0 +1,72 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// hasTag reports whether the profile carries the given tag
+func (p Profile) hasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// allTags returns every tag used by the profiles, sorted
+func allTags(profiles map[string]Profile) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, p := range profiles {
+		for _, t := range p.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// parseTagList splits comma- or space-separated tags as typed by the user,
+// dropping blanks and duplicates
+func parseTagList(s string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// nextTagFilter cycles through "" (all profiles) and each known tag
+func nextTagFilter(current string, tags []string) string {
+	if current == "" {
+		if len(tags) == 0 {
+			return ""
+		}
+		return tags[0]
+	}
+	for i, t := range tags {
+		if t == current && i+1 < len(tags) {
+			return tags[i+1]
+		}
+	}
+	return ""
+}
+
+// sortedProfileNames returns profile names in alphabetical order
+func sortedProfileNames(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeTree writes the files under dir to tw, named by their paths
+// relative to it with forward slashes. Paths skip returns true for are left
+// out, along with everything below them. Sockets and the like, such as
+// Chromium's SingletonSocket, have no place in a tarball and are skipped.
+func writeTree(ctx context.Context, tw *tar.Writer, dir string, skip func(rel string) bool) error {
+	return walkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if skip != nil && skip(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = fsys.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return nil
+		}
+		header.Name = rel
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		src, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+}
+
+// readTree unpacks the tarball tr into dir. Tarballs come from other
+// machines and servers, so an entry whose path leaves dir is refused, as
+// is a symlink that could point out of it: files unpacked later are
+// written through links, and one to /home/u/.ssh would let the tarball
+// write anywhere.
+func readTree(ctx context.Context, tr *tar.Reader, dir string) error {
+	if err := fsys.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	root := filepath.Clean(dir) + string(filepath.Separator)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, root) {
+			return fmt.Errorf("%s points outside the archive", header.Name)
+		}
+		mode := fs.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = fsys.MkdirAll(path, mode|0700)
+		case tar.TypeSymlink:
+			if err := checkLinkTarget(header.Linkname); err != nil {
+				return fmt.Errorf("%s is an unsafe link: %w", header.Name, err)
+			}
+			if err = fsys.MkdirAll(filepath.Dir(path), 0700); err == nil {
+				err = fsys.Symlink(header.Linkname, path)
+			}
+		case tar.TypeReg:
+			err = unpackFile(tr, path, mode)
+			if err == nil {
+				fsys.Chtimes(path, header.ModTime, header.ModTime)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unpackFile writes the current entry of a tarball to path
+func unpackFile(tr *tar.Reader, path string, mode fs.FileMode) error {
+	if err := fsys.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	out, err := fsys.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, tr); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// checkLinkTarget refuses a symlink target that is absolute or climbs out
+// with "..". Links within a data dir, such as Chromium's SingletonLock,
+// are relative and stay below it.
+func checkLinkTarget(link string) error {
+	switch {
+	case link == "":
+		return fmt.Errorf("no target")
+	case filepath.IsAbs(link), strings.HasPrefix(link, "/"), strings.HasPrefix(link, `\`), len(link) > 1 && link[1] == ':':
+		return fmt.Errorf("absolute target %s", link)
+	}
+	for _, part := range strings.FieldsFunc(link, func(c rune) bool { return c == '/' || c == '\\' }) {
+		if part == ".." {
+			return fmt.Errorf("target %s leaves the directory", link)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLinkTarget(t *testing.T) {
+	tests := []struct {
+		link string
+		ok   bool
+	}{
+		{"myhost-4242", true},
+		{"Default/Cache", true},
+		{"./a/b", true},
+		{"a..b", true},
+		{"", false},
+		{"/home/u/.ssh", false},
+		{`\\server\share`, false},
+		{`C:\Users`, false},
+		{"..", false},
+		{"../../.ssh", false},
+		{"a/../../b", false},
+		{`a\..\..\b`, false},
+	}
+	for _, tt := range tests {
+		if err := checkLinkTarget(tt.link); (err == nil) != tt.ok {
+			t.Errorf("checkLinkTarget(%q) = %v, want ok %t", tt.link, err, tt.ok)
+		}
+	}
+}
+
+func TestTreeRoundTrip(t *testing.T) {
+	m, _ := useFakes(t)
+	for _, dir := range []string{"/src/Default/Cache", "/src/Default/Local Storage"} {
+		if err := m.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"/src/Local State":                   `{"os_crypt":{}}`,
+		"/src/Default/Preferences":           `{"homepage":"about:blank"}`,
+		"/src/Default/Local Storage/leveldb": "data",
+		"/src/Default/Cache/data_0":          "cached",
+	}
+	for path, data := range files {
+		if err := m.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Symlink("Default/Preferences", "/src/Prefs"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	skip := func(rel string) bool { return rel == "Default/Cache" }
+	if err := writeTree(context.Background(), tw, "/src", skip); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := readTree(context.Background(), tar.NewReader(&buf), "/dst"); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, data := range files {
+		rel, _ := filepath.Rel("/src", path)
+		got, err := m.ReadFile(filepath.Join("/dst", rel))
+		if filepath.Dir(rel) == filepath.Join("Default", "Cache") {
+			if err == nil {
+				t.Errorf("skipped %s was unpacked", rel)
+			}
+			continue
+		}
+		if err != nil || string(got) != data {
+			t.Errorf("%s unpacked as %q, %v, want %q", rel, got, err, data)
+		}
+	}
+	if link, err := m.Readlink("/dst/Prefs"); err != nil || link != "Default/Preferences" {
+		t.Errorf("link unpacked as %q, %v", link, err)
+	}
+}
+
+func TestReadTreeRefusesEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{"path out", []tar.Header{{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"absolute link", []tar.Header{{Name: "ssh", Typeflag: tar.TypeSymlink, Linkname: "/home/u/.ssh"}}},
+		{"climbing link", []tar.Header{{Name: "a/up", Typeflag: tar.TypeSymlink, Linkname: "../../.."}}},
+		{"write through a link", []tar.Header{
+			{Name: "ssh", Typeflag: tar.TypeSymlink, Linkname: "/home/u/.ssh"},
+			{Name: "ssh/authorized_keys", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := useFakes(t)
+			if err := m.MkdirAll("/home/u/.ssh", 0700); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, header := range tt.entries {
+				header := header
+				if err := tw.WriteHeader(&header); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tw.Close()
+			if err := readTree(context.Background(), tar.NewReader(&buf), "/staging"); err == nil {
+				t.Error("the tarball was unpacked")
+			}
+			if _, err := m.Stat("/home/u/.ssh/authorized_keys"); err == nil {
+				t.Error("a file was written outside the staging dir")
+			}
+		})
+	}
+}
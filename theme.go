@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the set of colors the TUI is drawn with. Colors are "#rrggbb"
+// or ANSI 256 numbers. User themes in [themes.<name>] only set the colors
+// they change; the rest come from Base.
+type Theme struct {
+	Name      string
+	Base      string // Built-in theme the user theme starts from
+	Text      string // Regular and focused text
+	Muted     string // Labels and help text
+	Subtle    string // Field hints
+	Border    string // Pane borders
+	Accent    string // Selected form values and the progress bar
+	Selected  string // Highlighted list item
+	Title     string // List title background
+	TitleText string // List title text
+	Error     string
+	Warning   string
+	Success   string
+}
+
+// Built-in themes. "dark" keeps the original launchium colors.
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Name: "dark", Text: "#FFFFFF", Muted: "#888888", Subtle: "#666666", Border: "#555555",
+		Accent: "#00AFFF", Selected: "#EE6FF8", Title: "62", TitleText: "230",
+		Error: "#FF0000", Warning: "#FFAF00", Success: "#00FF00",
+	},
+	"light": {
+		Name: "light", Text: "#1A1A1A", Muted: "#5F5F5F", Subtle: "#8A8A8A", Border: "#BCBCBC",
+		Accent: "#005FD7", Selected: "#AF00AF", Title: "62", TitleText: "230",
+		Error: "#D70000", Warning: "#AF5F00", Success: "#008700",
+	},
+	"high-contrast": {
+		Name: "high-contrast", Text: "#FFFFFF", Muted: "#FFFFFF", Subtle: "#FFFF00", Border: "#FFFFFF",
+		Accent: "#00FFFF", Selected: "#FFFF00", Title: "#FFFF00", TitleText: "#000000",
+		Error: "#FF5F5F", Warning: "#FFFF00", Success: "#00FF00",
+	},
+}
+
+// Theme setting that picks dark or light from the terminal background
+const themeAuto = "auto"
+
+// themeColor is one named, configurable color of a theme
+type themeColor struct {
+	key   string
+	value *string
+}
+
+// colors lists the theme's colors in the order they are written
+func (t *Theme) colors() []themeColor {
+	return []themeColor{
+		{"text", &t.Text},
+		{"muted", &t.Muted},
+		{"subtle", &t.Subtle},
+		{"border", &t.Border},
+		{"accent", &t.Accent},
+		{"selected", &t.Selected},
+		{"title", &t.Title},
+		{"title_text", &t.TitleText},
+		{"error", &t.Error},
+		{"warning", &t.Warning},
+		{"success", &t.Success},
+	}
+}
+
+// validThemeColor accepts "#rrggbb", "#rgb" or an ANSI color number
+func validThemeColor(color string) bool {
+	if n, err := strconv.Atoi(color); err == nil {
+		return n >= 0 && n <= 255
+	}
+	_, err := parseHexColor(color)
+	return err == nil
+}
+
+// fields returns the theme's config entries in the order they are written
+func (t Theme) fields() []configField {
+	fields := []configField{}
+	if t.Base != "" {
+		fields = append(fields, configField{"base", quoteString(t.Base)})
+	}
+	for _, c := range t.colors() {
+		if *c.value != "" {
+			fields = append(fields, configField{c.key, quoteString(*c.value)})
+		}
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching theme color
+func (t *Theme) setField(key, value string) error {
+	if key == "base" {
+		if err := unquoteInto(&t.Base, value); err != nil {
+			return err
+		}
+		if _, ok := builtinThemes[t.Base]; !ok {
+			return fmt.Errorf("base must be one of %s, got %q", strings.Join(builtinThemeNames(), ", "), t.Base)
+		}
+		return nil
+	}
+	for _, c := range t.colors() {
+		if c.key != key {
+			continue
+		}
+		if err := unquoteInto(c.value, value); err != nil {
+			return err
+		}
+		if !validThemeColor(*c.value) {
+			return fmt.Errorf("%s must be #rrggbb or an ANSI color number, got %q", key, *c.value)
+		}
+		return nil
+	}
+	return unknownKeyError{"theme key", key}
+}
+
+// builtinThemeNames returns the built-in theme names, sorted
+func builtinThemeNames() []string {
+	names := []string{}
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveTheme returns the theme selected by the settings, filling user
+// themes in from their base. "auto" (or no setting) asks the terminal for
+// its background color, so only call this when the TUI is about to start.
+func resolveTheme(settings Settings) Theme {
+	name := settings.Theme
+	if name == "" || name == themeAuto {
+		name = "dark"
+		if !lipgloss.HasDarkBackground() {
+			name = "light"
+		}
+	}
+	if t, ok := builtinThemes[name]; ok {
+		return t
+	}
+
+	custom := settings.Themes[name]
+	base := custom.Base
+	if base == "" {
+		base = "dark"
+	}
+	t := builtinThemes[base]
+	t.Name = name
+	for i, c := range custom.colors() {
+		if *c.value != "" {
+			*t.colors()[i].value = *c.value
+		}
+	}
+	return t
+}
+
+// validThemeName reports whether name is a built-in or user theme
+func (s Settings) validThemeName(name string) bool {
+	if name == themeAuto {
+		return true
+	}
+	if _, ok := builtinThemes[name]; ok {
+		return true
+	}
+	_, ok := s.Themes[name]
+	return ok
+}
+
+// The theme the TUI is drawn with
+var theme = builtinThemes["dark"]
+
+func init() {
+	setStyles(theme)
+}
+
+// setStyles rebuilds the shared styles from t
+func setStyles(t Theme) {
+	theme = t
+	docStyle = lipgloss.NewStyle().Margin(1, 2)
+	errStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error))
+	okStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Success))
+	warnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Warning))
+	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Muted)).Italic(true)
+	archivedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Subtle))
+
+	detailStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.Border)).
+		Padding(0, 1)
+	detailLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Muted))
+
+	formLabelStyle = lipgloss.NewStyle().Width(14).Foreground(lipgloss.Color(t.Muted))
+	formFocusStyle = lipgloss.NewStyle().Width(14).Foreground(lipgloss.Color(t.Text)).Bold(true)
+	formSelectStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Accent))
+	formHintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Subtle))
+	formInvalidStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error))
+}
+
+// newListDelegate returns a list item delegate drawn in the current theme
+func newListDelegate(height, spacing int) list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	d.SetHeight(height)
+	d.SetSpacing(spacing)
+
+	selected := lipgloss.Color(theme.Selected)
+	d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(lipgloss.Color(theme.Text))
+	d.Styles.NormalDesc = d.Styles.NormalDesc.Foreground(lipgloss.Color(theme.Muted))
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(selected).BorderForeground(selected)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(selected).BorderForeground(selected)
+	return d
+}
+
+// styleList draws a list's title and status bar in the current theme
+func styleList(l *list.Model) {
+	l.Styles.Title = l.Styles.Title.
+		Background(lipgloss.Color(theme.Title)).
+		Foreground(lipgloss.Color(theme.TitleText))
+	l.Styles.StatusBar = l.Styles.StatusBar.Foreground(lipgloss.Color(theme.Muted))
+}
+
+// styleHelp draws the key help in the current theme
+func styleHelp(h *help.Model) {
+	key := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	desc := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	sep := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Subtle))
+	h.Styles.ShortKey, h.Styles.FullKey = key, key
+	h.Styles.ShortDesc, h.Styles.FullDesc = desc, desc
+	h.Styles.ShortSeparator, h.Styles.FullSeparator = sep, sep
+}
+
+// applyTheme switches the TUI to the theme chosen in the settings and
+// rebuilds the lists so they pick up the new colors
+func (cm *ChromiumManager) applyTheme() {
+	setStyles(resolveTheme(cm.settings))
+	styleHelp(&cm.help)
+	cm.spinner = newSpinner()
+	cm.updateMainList()
+	cm.updateManageList()
+	cm.updateProfileList()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Layouts the windows of a multi-profile launch can take. Cascade is the
+// default; the others split the screen between the windows.
+const (
+	tileCascade = "cascade"
+	tileGrid    = "grid"
+	tileColumns = "columns"
+	tileRows    = "rows"
+)
+
+// tileLayouts lists the valid values of the tile setting and -tile
+var tileLayouts = []string{tileCascade, tileGrid, tileColumns, tileRows}
+
+// Screen sizes printed by xrandr and xdpyinfo on Linux
+var (
+	xrandrPrimary = regexp.MustCompile(`\bconnected primary (\d+)x(\d+)\+(-?\d+)\+(-?\d+)`)
+	xrandrOutput  = regexp.MustCompile(`\bconnected (\d+)x(\d+)\+(-?\d+)\+(-?\d+)`)
+	xdpyinfoSize  = regexp.MustCompile(`dimensions:\s+(\d+)x(\d+) pixels`)
+)
+
+// screenRect is an area of the screen in pixels
+type screenRect struct {
+	x, y, width, height int
+}
+
+// validTileLayout checks the tile setting or the -tile flag
+func validTileLayout(layout string) error {
+	if layout != "" && !containsString(tileLayouts, layout) {
+		return fmt.Errorf("tile must be one of %s, got %q", strings.Join(tileLayouts, ", "), layout)
+	}
+	return nil
+}
+
+// tileLayout returns the layout for this run's multi-profile launches:
+// -tile, else the tile setting, else a cascade
+func (cm *ChromiumManager) tileLayout() string {
+	if cm.override.tile != "" {
+		return cm.override.tile
+	}
+	if cm.settings.Tile != "" {
+		return cm.settings.Tile
+	}
+	return tileCascade
+}
+
+// windowPlacer returns what positions the i-th of n windows of a
+// multi-profile launch. A tiled layout the screen can't be measured for
+// falls back to a cascade, with the reason why.
+func (cm *ChromiumManager) windowPlacer(n int) (func(Profile, int) Profile, error) {
+	layout := cm.tileLayout()
+	if layout == tileCascade || n < 2 {
+		return cascadeWindow, nil
+	}
+	screen, err := screenGeometry()
+	if err == nil && (screen.width <= 0 || screen.height <= 0) {
+		err = fmt.Errorf("the screen measured %dx%d", screen.width, screen.height)
+	}
+	if err != nil {
+		return cascadeWindow, fmt.Errorf("can't tile windows, cascading them instead: %w", err)
+	}
+	tiles := tileRects(layout, n, screen)
+	return func(profile Profile, i int) Profile {
+		return tileWindow(profile, tiles[i%len(tiles)])
+	}, nil
+}
+
+// tileRects splits the screen into n tiles: as square a grid as fits n,
+// side by side columns or stacked rows. A grid with a gap in its last row
+// leaves it empty rather than stretching a window across it.
+func tileRects(layout string, n int, screen screenRect) []screenRect {
+	cols, rows := n, 1
+	switch layout {
+	case tileGrid:
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+		rows = (n + cols - 1) / cols
+	case tileRows:
+		cols, rows = 1, n
+	}
+	width, height := screen.width/cols, screen.height/rows
+	tiles := []screenRect{}
+	for i := 0; i < n; i++ {
+		col, row := i%cols, i/cols
+		tiles = append(tiles, screenRect{screen.x + col*width, screen.y + row*height, width, height})
+	}
+	return tiles
+}
+
+// tileWindow places the profile's window in the tile, unless its flags
+// already position or maximize it
+func tileWindow(profile Profile, tile screenRect) Profile {
+	if profile.Flags.has("--window-position") || profile.Flags.has("--start-maximized") {
+		return profile
+	}
+	profile.Flags = profile.Flags.with(
+		fmt.Sprintf("--window-position=%d,%d", tile.x, tile.y),
+		fmt.Sprintf("--window-size=%d,%d", tile.width, tile.height),
+	)
+	return profile
+}
+
+// screenGeometry returns the area of the primary screen windows can use.
+// It asks xrandr or xdpyinfo on Linux, Finder on macOS and Windows Forms on
+// Windows; only the latter leaves out the taskbar.
+func screenGeometry() (screenRect, error) {
+	switch runtime.GOOS {
+	case "linux":
+		if out, err := exec.Command("xrandr", "--query").Output(); err == nil {
+			m := xrandrPrimary.FindSubmatch(out)
+			if m == nil {
+				m = xrandrOutput.FindSubmatch(out)
+			}
+			if m != nil {
+				return parseScreenRect(string(m[3]), string(m[4]), string(m[1]), string(m[2]))
+			}
+		}
+		out, err := exec.Command("xdpyinfo").Output()
+		if m := xdpyinfoSize.FindSubmatch(out); err == nil && m != nil {
+			return parseScreenRect("0", "0", string(m[1]), string(m[2]))
+		}
+		return screenRect{}, fmt.Errorf("measuring the screen needs xrandr or xdpyinfo (X11 or XWayland)")
+
+	case "darwin":
+		// Finder's desktop bounds are "left, top, right, bottom"
+		out, err := exec.Command("osascript", "-e", `tell application "Finder" to get bounds of window of desktop`).Output()
+		if err != nil {
+			return screenRect{}, fmt.Errorf("osascript: %w", err)
+		}
+		b := strings.Split(strings.TrimSpace(string(out)), ",")
+		if len(b) != 4 {
+			return screenRect{}, fmt.Errorf("unexpected desktop bounds %q", strings.TrimSpace(string(out)))
+		}
+		rect, err := parseScreenRect(b[0], b[1], b[2], b[3])
+		rect.width -= rect.x
+		rect.height -= rect.y
+		return rect, err
+
+	case "windows":
+		script := `Add-Type -AssemblyName System.Windows.Forms; $a = [System.Windows.Forms.Screen]::PrimaryScreen.WorkingArea; "$($a.X),$($a.Y),$($a.Width),$($a.Height)"`
+		out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Output()
+		if err != nil {
+			return screenRect{}, fmt.Errorf("powershell: %w", err)
+		}
+		b := strings.Split(strings.TrimSpace(string(out)), ",")
+		if len(b) != 4 {
+			return screenRect{}, fmt.Errorf("unexpected screen area %q", strings.TrimSpace(string(out)))
+		}
+		return parseScreenRect(b[0], b[1], b[2], b[3])
+	}
+	return screenRect{}, fmt.Errorf("measuring the screen is not supported on %s", runtime.GOOS)
+}
+
+// parseScreenRect reads the numbers of a screen area
+func parseScreenRect(x, y, width, height string) (screenRect, error) {
+	values := []int{}
+	for _, s := range []string{x, y, width, height} {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return screenRect{}, fmt.Errorf("unexpected screen size %q", s)
+		}
+		values = append(values, n)
+	}
+	return screenRect{values[0], values[1], values[2], values[3]}, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTileRects(t *testing.T) {
+	screen := screenRect{0, 0, 1200, 800}
+	tests := []struct {
+		layout string
+		n      int
+		want   []screenRect
+	}{
+		{tileColumns, 3, []screenRect{{0, 0, 400, 800}, {400, 0, 400, 800}, {800, 0, 400, 800}}},
+		{tileRows, 2, []screenRect{{0, 0, 1200, 400}, {0, 400, 1200, 400}}},
+		{tileGrid, 4, []screenRect{{0, 0, 600, 400}, {600, 0, 600, 400}, {0, 400, 600, 400}, {600, 400, 600, 400}}},
+		{tileGrid, 3, []screenRect{{0, 0, 600, 400}, {600, 0, 600, 400}, {0, 400, 600, 400}}},
+	}
+	for _, tt := range tests {
+		if got := tileRects(tt.layout, tt.n, screen); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tileRects(%s, %d) = %v, want %v", tt.layout, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTileRectsOffsetScreen(t *testing.T) {
+	got := tileRects(tileColumns, 2, screenRect{-1920, 40, 1920, 1040})
+	want := []screenRect{{-1920, 40, 960, 1040}, {-960, 40, 960, 1040}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tileRects = %v, want %v", got, want)
+	}
+}
+
+func TestTileWindow(t *testing.T) {
+	tile := screenRect{600, 0, 600, 400}
+	got := tileWindow(Profile{Name: "work"}, tile).Flags.args()
+	want := []string{"--window-position=600,0", "--window-size=600,400"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flags = %q, want %q", got, want)
+	}
+	for _, flags := range []string{"--start-maximized", "--window-position=0,0"} {
+		profile := Profile{Name: "work", Flags: parseFlagList(flags)}
+		if got := tileWindow(profile, tile).Flags.args(); len(got) != 1 {
+			t.Errorf("a profile with %s was tiled: %q", flags, got)
+		}
+	}
+}
+
+func TestTileLayout(t *testing.T) {
+	cm := &ChromiumManager{}
+	if got := cm.tileLayout(); got != tileCascade {
+		t.Errorf("default layout = %q, want %q", got, tileCascade)
+	}
+	cm.settings.Tile = tileGrid
+	if got := cm.tileLayout(); got != tileGrid {
+		t.Errorf("layout from the setting = %q, want %q", got, tileGrid)
+	}
+	cm.override.tile = tileRows
+	if got := cm.tileLayout(); got != tileRows {
+		t.Errorf("layout from -tile = %q, want %q", got, tileRows)
+	}
+	if err := validTileLayout("spiral"); err == nil {
+		t.Error("validTileLayout accepted an unknown layout")
+	}
+}
+
+func TestParseScreenRect(t *testing.T) {
+	got, err := parseScreenRect(" 0", "25 ", "1440", "875")
+	if err != nil || got != (screenRect{0, 25, 1440, 875}) {
+		t.Errorf("parseScreenRect = %v, %v", got, err)
+	}
+	if _, err := parseScreenRect("0", "0", "wide", "875"); err == nil {
+		t.Error("parseScreenRect accepted a word")
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Severity of a status message
+type messageLevel int
+
+const (
+	levelInfo messageLevel = iota
+	levelWarn
+	levelError
+)
+
+// How long each level of message stays on screen
+var messageTimeouts = map[messageLevel]time.Duration{
+	levelInfo:  4 * time.Second,
+	levelWarn:  8 * time.Second,
+	levelError: 12 * time.Second,
+}
+
+// Messages kept for the history view
+const messageHistoryLimit = 200
+
+// toast is a status message shown below the current view until it expires
+type toast struct {
+	seq   int
+	level messageLevel
+	text  string
+	at    time.Time
+}
+
+// expireMsg hides the toast with the given sequence number if it is still
+// the one shown
+type expireMsg struct{ seq int }
+
+// notify shows a message and adds it to the history
+func (cm *ChromiumManager) notify(level messageLevel, format string, args ...interface{}) {
+	cm.toastSeq++
+	t := toast{seq: cm.toastSeq, level: level, text: fmt.Sprintf(format, args...), at: time.Now()}
+	cm.toast = &t
+	cm.messages = append(cm.messages, t)
+	if len(cm.messages) > messageHistoryLimit {
+		cm.messages = cm.messages[len(cm.messages)-messageHistoryLimit:]
+	}
+}
+
+// clearMessage hides the current message; it stays in the history
+func (cm *ChromiumManager) clearMessage() {
+	cm.toast = nil
+}
+
+// expireToast schedules the current message to disappear
+func (cm *ChromiumManager) expireToast() tea.Cmd {
+	if cm.toast == nil {
+		return nil
+	}
+	seq := cm.toast.seq
+	return tea.Tick(messageTimeouts[cm.toast.level], func(time.Time) tea.Msg { return expireMsg{seq: seq} })
+}
+
+// label is the prefix a message is shown with
+func (l messageLevel) label() string {
+	switch l {
+	case levelWarn:
+		return "Warning"
+	case levelError:
+		return "Error"
+	}
+	return "Info"
+}
+
+// style is how messages of the level are drawn
+func (l messageLevel) style() lipgloss.Style {
+	switch l {
+	case levelWarn:
+		return warnStyle
+	case levelError:
+		return errStyle
+	}
+	return okStyle
+}
+
+// render draws the message as shown below the views
+func (t toast) render(width int) string {
+	text := t.text
+	if t.level != levelInfo {
+		text = t.level.label() + ": " + text
+	}
+	return t.level.style().Width(width).Render(text)
+}
+
+// openHistory shows the message history, returning to the current view
+// when it is closed
+func (cm *ChromiumManager) openHistory() {
+	width, height := cm.contentSize()
+	cm.history = viewport.New(width, height-2)
+	cm.history.SetContent(cm.historyContent(width))
+	cm.history.GotoBottom()
+	cm.historyFrom = cm.currentView
+	cm.currentView = "messages"
+}
+
+// historyContent renders every kept message, oldest first
+func (cm *ChromiumManager) historyContent(width int) string {
+	if len(cm.messages) == 0 {
+		return helpStyle.Render("No messages yet")
+	}
+	lines := make([]string, len(cm.messages))
+	for i, t := range cm.messages {
+		prefix := detailLabelStyle.Render(t.at.Format("15:04:05")) + " " +
+			t.level.style().Width(8).Render(strings.ToUpper(t.level.label()))
+		text := lipgloss.NewStyle().Width(max(width-lipgloss.Width(prefix)-1, 10)).Render(t.text)
+		lines[i] = lipgloss.JoinHorizontal(lipgloss.Top, prefix, " ", text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateHistory scrolls the message history or closes it
+func (cm *ChromiumManager) updateHistory(msg tea.KeyMsg) tea.Cmd {
+	if key.Matches(msg, cm.keys.Back, cm.keys.History) {
+		cm.currentView = cm.historyFrom
+		return nil
+	}
+	var cmd tea.Cmd
+	cm.history, cmd = cm.history.Update(msg)
+	return cmd
+}
+
+// historyView renders the message history
+func (cm *ChromiumManager) historyView() string {
+	title := lipgloss.NewStyle().Bold(true).Render("Messages")
+	return title + "\n\n" + cm.history.View()
+}
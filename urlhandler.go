@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// URL scheme launchium registers, e.g. launchium://launch/work
+const urlScheme = "launchium"
+
+// Name of the handler entry on Linux and macOS
+const (
+	urlDesktopFile = "launchium-url.desktop"
+	urlHandlerApp  = "Launchium URL Handler.app"
+)
+
+// Registry key holding the scheme on Windows
+const urlRegistryKey = `HKCU\Software\Classes\` + urlScheme
+
+// handleURL runs the action in a launchium:// link:
+//
+//	launchium://launch/<profile>
+//	launchium://open/<profile>?url=https://example.com
+//
+// Links can come from any web page, so they can only launch profiles and
+// open http(s) pages in them.
+func (cm *ChromiumManager) handleURL(ctx context.Context, link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != urlScheme {
+		return "", fmt.Errorf("not a %s:// link: %s", urlScheme, link)
+	}
+
+	// launchium://launch/work puts the action in the host, but accept
+	// launchium:///launch/work too
+	parts := strings.SplitN(strings.Trim(u.Host+u.Path, "/"), "/", 2)
+	action, name := parts[0], ""
+	if len(parts) == 2 {
+		name = parts[1]
+	}
+	if name == "" {
+		name = cm.defaultProfile()
+	}
+	if _, ok := cm.profiles[name]; !ok {
+		return "", profileNotFound(name)
+	}
+
+	switch action {
+	case "launch":
+		return cm.launchBrowser(ctx, name)
+
+	case "open":
+		target := u.Query().Get("url")
+		page, err := url.Parse(target)
+		if err != nil || (page.Scheme != "http" && page.Scheme != "https") {
+			return "", fmt.Errorf("open needs an http or https url, got %q", target)
+		}
+		// A running browser gets a new tab instead of a second window
+		if running := findInstances(map[string][]string{name: cm.runningDirs()[name]}); len(running) > 0 {
+			if err := cm.openURL(running[0], target); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Opened %s in profile: %s", target, name), nil
+		}
+		return cm.launchBrowser(ctx, name, target)
+	}
+	return "", fmt.Errorf("unknown action %q (expected launch or open)", action)
+}
+
+// urlHandlerPath returns the desktop entry or app bundle that handles the
+// scheme, or "" on Windows where it lives in the registry
+func urlHandlerPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(homeDir, ".local", "share", "applications", urlDesktopFile), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Applications", urlHandlerApp), nil
+	case "windows":
+		return "", nil
+	}
+	return "", fmt.Errorf("URL handling is not supported on %s", runtime.GOOS)
+}
+
+// registerURLHandler makes the OS open launchium:// links with this
+// launchium and returns where the handler was put
+func (cm *ChromiumManager) registerURLHandler() (string, error) {
+	args, err := cm.launchiumCommand("url")
+	if err != nil {
+		return "", err
+	}
+	path, err := urlHandlerPath()
+	if err != nil {
+		return "", err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := writeServiceFile(path, fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Launchium
+Comment=Open %s:// links
+Exec=%s %%u
+Terminal=false
+NoDisplay=true
+MimeType=x-scheme-handler/%s;
+`, urlScheme, desktopExec(args), urlScheme)); err != nil {
+			return "", err
+		}
+		if out, err := exec.Command("xdg-mime", "default", urlDesktopFile, "x-scheme-handler/"+urlScheme).CombinedOutput(); err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("xdg-mime: %s", strings.TrimSpace(string(out)+" "+err.Error()))
+		}
+		exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+		return path, nil
+
+	case "darwin":
+		// Links reach apps as Apple events, so a small AppleScript app
+		// passes them on to launchium
+		script := fmt.Sprintf("on open location theURL\n\tdo shell script \"%s \" & quoted form of theURL\nend open location",
+			appleScriptString(shellCommand(args)))
+		os.RemoveAll(path)
+		if out, err := exec.Command("osacompile", "-o", path, "-e", script).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("osacompile: %s", strings.TrimSpace(string(out)))
+		}
+		plist := filepath.Join(path, "Contents", "Info.plist")
+		types := fmt.Sprintf(`[{"CFBundleURLName":"Launchium","CFBundleURLSchemes":[%q]}]`, urlScheme)
+		for _, edit := range [][]string{
+			{"-replace", "CFBundleURLTypes", "-json", types},
+			{"-replace", "LSUIElement", "-bool", "true"},
+		} {
+			if out, err := exec.Command("plutil", append(edit, plist)...).CombinedOutput(); err != nil {
+				os.RemoveAll(path)
+				return "", fmt.Errorf("plutil: %s", strings.TrimSpace(string(out)))
+			}
+		}
+		if out, err := exec.Command(lsregister, "-f", path).CombinedOutput(); err != nil {
+			os.RemoveAll(path)
+			return "", fmt.Errorf("lsregister: %s", strings.TrimSpace(string(out)))
+		}
+		return path, nil
+
+	default:
+		command := taskCommand(args) + ` "%1"`
+		for _, entry := range [][]string{
+			{urlRegistryKey, "/ve", "/d", "URL:Launchium"},
+			{urlRegistryKey, "/v", "URL Protocol", "/d", ""},
+			{urlRegistryKey + `\shell\open\command`, "/ve", "/d", command},
+		} {
+			if out, err := exec.Command("reg", append(append([]string{"add"}, entry...), "/f")...).CombinedOutput(); err != nil {
+				return "", fmt.Errorf("reg: %s", strings.TrimSpace(string(out)))
+			}
+		}
+		return urlRegistryKey, nil
+	}
+}
+
+// unregisterURLHandler removes the handler registerURLHandler installed
+func unregisterURLHandler() error {
+	path, err := urlHandlerPath()
+	if err != nil {
+		return err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := os.Remove(path); os.IsNotExist(err) {
+			return fmt.Errorf("the URL handler is not registered")
+		} else if err != nil {
+			return err
+		}
+		exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+		return nil
+
+	case "darwin":
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("the URL handler is not registered")
+		}
+		exec.Command(lsregister, "-u", path).Run()
+		return os.RemoveAll(path)
+
+	default:
+		out, err := exec.Command("reg", "delete", urlRegistryKey, "/f").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("reg: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// lsregister updates the Launch Services database on macOS
+const lsregister = "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+
+// desktopExec quotes a command line for the Exec key of a desktop entry
+func desktopExec(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		for _, c := range []string{`\`, `"`, "`", "$"} {
+			arg = strings.ReplaceAll(arg, c, `\`+c)
+		}
+		quoted[i] = `"` + arg + `"`
+	}
+	// The Exec value is itself a string with escapes, and % starts a
+	// field code
+	line := strings.Join(quoted, " ")
+	line = strings.ReplaceAll(line, `\`, `\\`)
+	return strings.ReplaceAll(line, "%", "%%")
+}
+
+// shellCommand quotes a command line for sh
+func shellCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// appleScriptString escapes s for use inside an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
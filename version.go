@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Build metadata, set when building a release:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without ldflags the commit and date come from the VCS stamp Go embeds.
+var (
+	version   = "0.1.0"
+	commit    = ""
+	buildDate = ""
+)
+
+// Where `launchium version` looks for newer releases
+const (
+	latestReleaseAPI = "https://api.github.com/repos/mlinton/launchium/releases/latest"
+	latestReleaseURL = "https://github.com/mlinton/launchium/releases/latest"
+)
+
+// The update check gives up quickly so an offline machine isn't held up
+const updateCheckTimeout = 3 * time.Second
+
+// buildMetadata returns the commit and build date, falling back to the VCS
+// stamp in the binary and then "unknown"
+func buildMetadata() (string, string) {
+	rev, date := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok && (rev == "" || date == "") {
+		modified := false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if rev == "" {
+					rev = s.Value
+					if len(rev) > 12 {
+						rev = rev[:12]
+					}
+				}
+			case "vcs.time":
+				if date == "" {
+					date = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && commit == "" && rev != "" {
+			rev += "-dirty"
+		}
+	}
+	if rev == "" {
+		rev = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return rev, date
+}
+
+// versionText describes this build for `launchium version`
+func versionText() string {
+	rev, date := buildMetadata()
+	return fmt.Sprintf("Launchium version %s\n  Commit:   %s\n  Built:    %s\n  Go:       %s\n  Platform: %s/%s",
+		version, rev, date, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// latestRelease asks GitHub for the newest release's version
+func latestRelease(ctx context.Context) (string, error) {
+	client := &http.Client{Timeout: updateCheckTimeout}
+	req, err := http.NewRequestWithContext(ctx, "GET", latestReleaseAPI, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "launchium/"+version)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub answered %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// newerVersion reports whether latest is a higher dotted version than
+// current. Versions it can't compare are never newer.
+func newerVersion(latest, current string) bool {
+	a, ok := versionNumbers(latest)
+	if !ok {
+		return false
+	}
+	b, ok := versionNumbers(current)
+	if !ok {
+		return false
+	}
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// versionNumbers splits "1.2.3" into its numbers, ignoring a leading v and
+// a -suffix
+func versionNumbers(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if dash := strings.Index(v, "-"); dash >= 0 {
+		v = v[:dash]
+	}
+	nums := []int{}
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+	return nums, true
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// How long the config must stay unchanged before it is reloaded. Editors
+// and git write a file in several steps, and a sync may touch several.
+const configSettle = 300 * time.Millisecond
+
+// configChangedMsg tells the TUI the config changed on disk
+type configChangedMsg struct{}
+
+// configFiles lists the files the profiles are read from: the config, its
+// drop-in files and the project config
+func (cm *ChromiumManager) configFiles() []string {
+	files := append([]string{cm.configFile}, cm.dropInFiles()...)
+	if cm.projectFile != "" {
+		files = append(files, cm.projectFile)
+	}
+	return files
+}
+
+// configStamp sums up the config files' sizes and modification times, to
+// tell a change made elsewhere from launchium's own saves
+func (cm *ChromiumManager) configStamp() string {
+	var b strings.Builder
+	for _, path := range cm.configFiles() {
+		if info, err := fsys.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// configChanged reports whether the config files changed since they were
+// last loaded or saved, and takes note of the change
+func (cm *ChromiumManager) configChanged() bool {
+	stamp := cm.configStamp()
+	if stamp == cm.loadedStamp {
+		return false
+	}
+	cm.loadedStamp = stamp
+	return true
+}
+
+// watchConfig watches the directories of the config files and sends on the
+// returned channel once a change to them has settled. Directories are
+// watched rather than files, so files replaced by a rename, as editors
+// save them, and drop-in files added later are still seen.
+func (cm *ChromiumManager) watchConfig() (<-chan struct{}, func(), error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	configDir := filepath.Dir(cm.configFile)
+	if err := watcher.Add(configDir); err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+	// The drop-in dir may not exist yet; it is picked up when created
+	dropIns := cm.dropInDir()
+	watcher.Add(dropIns)
+	project := cm.projectFile
+	if project != "" {
+		watcher.Add(filepath.Dir(project))
+	}
+
+	relevant := func(path string) bool {
+		if path == cm.configFile || path == project || path == dropIns {
+			return true
+		}
+		return filepath.Dir(path) == dropIns && strings.HasSuffix(path, ".toml") && !strings.HasPrefix(filepath.Base(path), ".")
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		var settle <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Name == dropIns && event.Has(fsnotify.Create) {
+					watcher.Add(dropIns)
+				}
+				if relevant(event.Name) {
+					settle = time.After(configSettle)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-settle:
+				settle = nil
+				select {
+				case changes <- struct{}{}:
+				default:
+					// A reload is already pending
+				}
+			}
+		}
+	}()
+	return changes, func() { watcher.Close() }, nil
+}
+
+// waitConfigChange waits for the next change to the config files
+func waitConfigChange(changes <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-changes
+		return configChangedMsg{}
+	}
+}
+
+// startConfigWatch starts watching the config for the TUI. Not being able
+// to watch only means edits need a restart, so it is reported and
+// otherwise ignored.
+func (cm *ChromiumManager) startConfigWatch() tea.Cmd {
+	if cm.configSource != "" {
+		return nil
+	}
+	changes, _, err := cm.watchConfig()
+	if err != nil {
+		cm.notify(levelWarn, "Not watching the config for changes: %s", err)
+		return nil
+	}
+	cm.configChanges = changes
+	return waitConfigChange(changes)
+}
+
+// reloadConfig picks up a config changed on disk, refreshing the lists
+// shown. A config that doesn't load, such as one saved halfway through an
+// edit, leaves the profiles as they were until it is fixed.
+func (cm *ChromiumManager) reloadConfig() tea.Cmd {
+	next := waitConfigChange(cm.configChanges)
+	if cm.firstRun || cm.currentView == "setup" || !cm.configChanged() {
+		return next
+	}
+	keys := cm.keys
+	cm.keys = defaultKeyMap()
+	cm.loadProfiles()
+	if cm.err != nil {
+		cm.notify(levelError, "Config changed but didn't load, keeping the profiles as they were: %s", cm.err)
+		cm.err = nil
+		cm.keys = keys
+		return next
+	}
+	cm.applyTheme()
+	cm.resize()
+	if cm.currentView == "dashboard" {
+		cm.setDashboardItems()
+	}
+	cm.notify(levelInfo, "Config changed on disk, reloaded %d profiles", len(cm.profiles))
+	return next
+}
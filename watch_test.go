@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigChanged(t *testing.T) {
+	m, _ := useFakes(t)
+	m.MkdirAll("/config", 0755)
+	m.WriteFile("/config/profiles.toml", []byte("[settings]\n"), 0644)
+	cm := &ChromiumManager{configFile: "/config/profiles.toml"}
+	cm.loadedStamp = cm.configStamp()
+	if cm.configChanged() {
+		t.Error("an untouched config was reported changed")
+	}
+	m.WriteFile("/config/profiles.toml", []byte("[settings]\ntheme = \"dark\"\n"), 0644)
+	if !cm.configChanged() {
+		t.Error("an edited config wasn't reported changed")
+	}
+	if cm.configChanged() {
+		t.Error("the change was reported twice")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	cm := &ChromiumManager{configFile: filepath.Join(dir, "profiles.toml")}
+	if err := os.WriteFile(cm.configFile, []byte("[settings]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changes, stop, err := cm.watchConfig()
+	if err != nil {
+		t.Skipf("can't watch files here: %v", err)
+	}
+	defer stop()
+
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("unrelated"), 0644)
+	select {
+	case <-changes:
+		t.Fatal("a file next to the config was taken for a change to it")
+	case <-time.After(2 * configSettle):
+	}
+
+	// The drop-in dir is picked up once it is created
+	os.Mkdir(cm.dropInDir(), 0755)
+	waitFor(t, changes)
+	os.WriteFile(filepath.Join(cm.dropInDir(), "team.toml"), []byte("[profiles.team]\n"), 0644)
+	waitFor(t, changes)
+	os.WriteFile(cm.configFile, []byte("[settings]\ntheme = \"dark\"\n"), 0644)
+	waitFor(t, changes)
+}
+
+// waitFor fails the test if no change arrives in time
+func waitFor(t *testing.T, changes <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change was seen")
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Events a webhook can subscribe to
+const (
+	eventLaunched      = "launched"
+	eventLaunchFailed  = "launch_failed"
+	eventExited        = "exited"
+	eventCleanComplete = "clean_completed"
+)
+
+var webhookEvents = []string{eventLaunched, eventLaunchFailed, eventExited, eventCleanComplete}
+
+// Delivery gives up on a receiver after this long, and retries failures a
+// couple of times before dropping the event
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookAttempts = 3
+	webhookBackoff  = 2 * time.Second
+)
+
+// How long an exit watch waits for a launched browser to show up before
+// deciding it handed off to one that was already running
+const exitWatchStartup = 30 * time.Second
+
+// webhookDeliveries tracks requests in flight so commands can finish
+// sending before the process exits
+var webhookDeliveries sync.WaitGroup
+
+// webhookLog receives delivery failures. The TUI sets it to io.Discard
+// while it owns the terminal.
+var webhookLog io.Writer = os.Stderr
+
+// Webhook is a [webhooks.<name>] table: a URL to POST events to
+type Webhook struct {
+	Name   string
+	URL    string
+	Events []string // Events to send; empty sends all of them
+}
+
+// webhookPayload is the JSON body POSTed for an event
+type webhookPayload struct {
+	Event   string    `json:"event"`
+	Profile string    `json:"profile"`
+	Time    time.Time `json:"time"`
+	Host    string    `json:"host,omitempty"`
+	Message string    `json:"message,omitempty"` // Launch message, error or other detail
+	Text    string    `json:"text"`              // One-line summary; Slack incoming webhooks show this
+}
+
+// fields returns the webhook's config entries in the order they are written
+func (w Webhook) fields() []configField {
+	fields := []configField{{"url", quoteString(w.URL)}}
+	if len(w.Events) > 0 {
+		fields = append(fields, configField{"events", quoteStringArray(w.Events)})
+	}
+	return fields
+}
+
+// setField assigns a raw config value to the matching webhook field
+func (w *Webhook) setField(key, value string) error {
+	switch key {
+	case "url":
+		if err := unquoteInto(&w.URL, value); err != nil {
+			return err
+		}
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http or https URL, got %q", w.URL)
+		}
+		return nil
+	case "events":
+		events, err := unquoteStringArray(value)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if !validWebhookEvent(event) {
+				return fmt.Errorf("unknown event %q (expected %s)", event, strings.Join(webhookEvents, ", "))
+			}
+		}
+		w.Events = events
+		return nil
+	default:
+		return unknownKeyError{"webhook key", key}
+	}
+}
+
+// validWebhookEvent reports whether event is one webhooks can subscribe to
+func validWebhookEvent(event string) bool {
+	for _, e := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// wants reports whether the webhook subscribes to event
+func (w Webhook) wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhooksFor returns the webhooks subscribed to event, sorted by name
+func (cm *ChromiumManager) webhooksFor(event string) []Webhook {
+	hooks := []Webhook{}
+	for _, hook := range cm.settings.Webhooks {
+		if hook.URL != "" && hook.wants(event) {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Name < hooks[j].Name })
+	return hooks
+}
+
+// fireWebhooks POSTs the event to every webhook subscribed to it. Delivery
+// happens in the background; waitForWebhooks waits for it.
+func (cm *ChromiumManager) fireWebhooks(event, profile, message string) {
+	sendWebhooks(cm.webhooksFor(event), event, profile, message)
+}
+
+// sendWebhooks POSTs an event to hooks. It doesn't touch the model, so it
+// can run in the background. Deliveries aren't tied to the request or TUI
+// that fired them, which may be gone by then; waitForWebhooks waits for
+// them before launchium exits.
+func sendWebhooks(hooks []Webhook, event, profile, message string) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload := webhookPayload{Event: event, Profile: profile, Time: time.Now(), Message: message}
+	payload.Host, _ = os.Hostname()
+	payload.Text = webhookText(payload)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	for _, hook := range hooks {
+		webhookDeliveries.Add(1)
+		go func(hook Webhook) {
+			defer webhookDeliveries.Done()
+			if err := deliverWebhook(context.Background(), hook.URL, body); err != nil {
+				fmt.Fprintf(webhookLog, "Error sending %s event to webhook '%s': %s\n", event, hook.Name, err)
+			}
+		}(hook)
+	}
+}
+
+// webhookText summarizes an event in a sentence
+func webhookText(p webhookPayload) string {
+	var text string
+	switch p.Event {
+	case eventLaunched:
+		text = fmt.Sprintf("Launched profile '%s'", p.Profile)
+	case eventLaunchFailed:
+		text = fmt.Sprintf("Failed to launch profile '%s'", p.Profile)
+	case eventExited:
+		text = fmt.Sprintf("Browser for profile '%s' exited", p.Profile)
+	case eventCleanComplete:
+		text = fmt.Sprintf("Cleaned profile '%s'", p.Profile)
+	}
+	if p.Host != "" {
+		text += " on " + p.Host
+	}
+	if p.Message != "" {
+		text += ": " + p.Message
+	}
+	return text
+}
+
+// deliverWebhook POSTs body to url, retrying network errors and 5xx
+// responses until ctx is canceled
+func deliverWebhook(ctx context.Context, url string, body []byte) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	var err error
+	for attempt := 0; attempt < webhookAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(webhookBackoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("receiver answered %s", resp.Status)
+		if resp.StatusCode < 500 {
+			// The receiver rejected the event; sending it again won't help
+			return err
+		}
+	}
+	return err
+}
+
+// waitForWebhooks blocks until queued webhook deliveries are done
+func waitForWebhooks() {
+	webhookDeliveries.Wait()
+}
+
+// watchExit sends the exited event to hooks once the profile's browser has
+// gone. Only browsers exiting while launchium still runs are reported.
+func (cm *ChromiumManager) watchExit(name string, hooks []Webhook) {
+	dirs := cm.runningDirs()[name]
+	go func() {
+		if ran, started := waitForExit(dirs, runningRefresh); started {
+			sendWebhooks(hooks, eventExited, name, "ran for "+formatUptime(ran))
+		}
+	}()
+}
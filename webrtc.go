@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+// Values for Profile.WebRTCPolicy
+const (
+	webRTCUnchanged  = ""                        // leave the browser's own setting
+	webRTCDefault    = "default"                 // WebRTC may use every route, revealing local addresses
+	webRTCNoUDP      = "disable-non-proxied-udp" // only routes through the proxy, so the real IP stays hidden
+	webRTCDisable    = "disable"                 // no routes of its own and no camera or microphone
+	webRTCPolicyName = "disable_non_proxied_udp" // Chromium's name for the proxied-only policy
+)
+
+// webRTCPolicies lists the values of the webrtc_policy setting
+var webRTCPolicies = []string{webRTCDefault, webRTCNoUDP, webRTCDisable}
+
+// validWebRTCPolicy checks a Profile.WebRTCPolicy value
+func validWebRTCPolicy(policy string) error {
+	if policy == webRTCUnchanged || containsString(webRTCPolicies, policy) {
+		return nil
+	}
+	return fmt.Errorf("webrtc_policy must be default, disable-non-proxied-udp or disable, got %q", policy)
+}
+
+// webRTCPreferences returns the preference values of the profile's WebRTC
+// policy. They are what the WebRtcIPHandlingPolicy policy sets, kept to
+// the profile rather than the whole machine.
+func webRTCPreferences(profile Profile) map[string]interface{} {
+	switch profile.WebRTCPolicy {
+	case webRTCDefault:
+		return map[string]interface{}{
+			"webrtc.ip_handling_policy":      "default",
+			"webrtc.multiple_routes_enabled": true,
+			"webrtc.nonproxied_udp_enabled":  true,
+		}
+	case webRTCNoUDP:
+		return map[string]interface{}{
+			"webrtc.ip_handling_policy":      webRTCPolicyName,
+			"webrtc.multiple_routes_enabled": false,
+			"webrtc.nonproxied_udp_enabled":  false,
+		}
+	case webRTCDisable:
+		// Chromium has no switch that turns WebRTC off, so leave it no
+		// route of its own and no devices to capture
+		return map[string]interface{}{
+			"webrtc.ip_handling_policy":                                  webRTCPolicyName,
+			"webrtc.multiple_routes_enabled":                             false,
+			"webrtc.nonproxied_udp_enabled":                              false,
+			"profile.default_content_setting_values.media_stream_camera": 2,
+			"profile.default_content_setting_values.media_stream_mic":    2,
+		}
+	}
+	return nil
+}
+
+// webRTCFlags returns the switches that hold the profile's WebRTC policy
+// even where the preferences get changed in the browser
+func (p Profile) webRTCFlags() []string {
+	switch p.WebRTCPolicy {
+	case webRTCNoUDP:
+		return []string{"--force-webrtc-ip-handling-policy=" + webRTCPolicyName}
+	case webRTCDisable:
+		return []string{"--force-webrtc-ip-handling-policy=" + webRTCPolicyName, "--enforce-webrtc-ip-permission-check"}
+	}
+	return nil
+}
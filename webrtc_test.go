@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebRTCPolicy(t *testing.T) {
+	tests := []struct {
+		policy    string
+		flags     []string
+		ipHandled interface{} // webrtc.ip_handling_policy, nil if not set
+		camera    interface{}
+	}{
+		{webRTCUnchanged, nil, nil, nil},
+		{webRTCDefault, nil, "default", nil},
+		{webRTCNoUDP, []string{"--force-webrtc-ip-handling-policy=disable_non_proxied_udp"}, "disable_non_proxied_udp", nil},
+		{webRTCDisable, []string{"--force-webrtc-ip-handling-policy=disable_non_proxied_udp", "--enforce-webrtc-ip-permission-check"}, "disable_non_proxied_udp", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			if err := validWebRTCPolicy(tt.policy); err != nil {
+				t.Fatal(err)
+			}
+			profile := Profile{WebRTCPolicy: tt.policy}
+			if got := profile.webRTCFlags(); !reflect.DeepEqual(got, tt.flags) {
+				t.Errorf("webRTCFlags() = %q, want %q", got, tt.flags)
+			}
+			prefs := webRTCPreferences(profile)
+			if got := prefs["webrtc.ip_handling_policy"]; got != tt.ipHandled {
+				t.Errorf("ip_handling_policy = %v, want %v", got, tt.ipHandled)
+			}
+			if got := prefs["profile.default_content_setting_values.media_stream_camera"]; got != tt.camera {
+				t.Errorf("camera setting = %v, want %v", got, tt.camera)
+			}
+		})
+	}
+	if err := validWebRTCPolicy("off"); err == nil {
+		t.Error("validWebRTCPolicy accepted off")
+	}
+}